@@ -2,18 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"simbirGo/internal/config"
 	"simbirGo/internal/database"
+	"simbirGo/internal/health"
+	"simbirGo/internal/idempotency"
+	"simbirGo/internal/logging"
+	"simbirGo/internal/metrics"
+	"simbirGo/internal/payments"
+	"simbirGo/internal/ratelimit"
 	"simbirGo/internal/server"
 	"simbirGo/internal/tokens"
+	"simbirGo/internal/tracing"
 	"simbirGo/internal/usecase/authUsecase"
 	"simbirGo/internal/usecase/paymentUsecase"
+	"simbirGo/internal/usecase/pricingUsecase"
+	"simbirGo/internal/usecase/promoUsecase"
 	"simbirGo/internal/usecase/rentUsecase"
+	"simbirGo/internal/usecase/roleUsecase"
 	transportusecase "simbirGo/internal/usecase/transportUsecase"
 	"syscall"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // @title           SimbirGO REST API
@@ -35,22 +52,281 @@ import (
 
 func main() {
 	cfg := config.Init()
+	// logger becomes default, so messages of log package are written by it too
+	logger := logging.New(cfg.Log, os.Stderr)
+	slog.SetDefault(logger)
+
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("unknown command: %s", args[0])
+		}
+		if err := migrate(cfg, logger, args[1:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
 
-	db, err := database.Connect(cfg)
+	db, err := database.Connect(cfg, logger)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	log.Print("succesfully connect to database")
+	logger.Info("succesfully connect to database")
 
-	tokens.InitBlackList()
+	if cfg.Auth.JWTDevKey {
+		err = tokens.InitDevKey()
+	} else {
+		err = tokens.InitKeys(cfg.Auth.JWTKeysDir, cfg.Auth.JWTSigningKid, cfg.Auth.JWTSecret)
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	tokens.AccessTokenTTL = cfg.Auth.AccessTokenTTL
+	tokens.RefreshTokenTTL = cfg.Auth.RefreshTokenTTL
+
+	var revocationStore tokens.RevocationStore
+	switch cfg.Auth.RevocationStore {
+	case "memory":
+		revocationStore = tokens.NewMemoryRevocationStore()
+	case "postgres":
+		revocationStore = database.NewRevocationStore(db)
+	default:
+		log.Fatalf("unknown revocation store: %s", cfg.Auth.RevocationStore)
+	}
+
+	var idempotencyStore idempotency.Store
+	switch cfg.Idempotency.Store {
+	case "memory":
+		idempotencyStore = idempotency.NewMemoryStore(cfg.Idempotency.LockTimeout)
+	case "postgres":
+		idempotencyStore = database.NewIdempotencyStore(db, cfg.Idempotency.LockTimeout)
+	default:
+		log.Fatalf("unknown idempotency store: %s", cfg.Idempotency.Store)
+	}
+
+	var transportLocator rentUsecase.TransportLocator
+	switch cfg.Rent.GeoSearch {
+	case "haversine":
+		transportLocator = database.NewHaversineLocator(db)
+	case "postgis":
+		transportLocator, err = database.NewPostGISLocator(db)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	default:
+		log.Fatalf("unknown geo search: %s", cfg.Rent.GeoSearch)
+	}
 
-	authUc := authUsecase.New(db)
-	paymentUc := paymentUsecase.New(db)
+	var paymentGateway payments.Gateway
+	switch cfg.Payment.Gateway {
+	case "none":
+		// top-ups are not available without gateway
+	case "fake":
+		paymentGateway = payments.NewFakeGateway(cfg.Payment.WebhookSecret, cfg.Payment.WebhookURL)
+	default:
+		log.Fatalf("unknown payment gateway: %s", cfg.Payment.Gateway)
+	}
+
+	authUsecase.LockoutThreshold = cfg.Auth.LockoutThreshold
+	authUsecase.LockoutDuration = cfg.Auth.LockoutDuration
+	authUsecase.MaxLockoutDuration = cfg.Auth.MaxLockoutDuration
+
+	appMetrics := metrics.New()
+	authUc := authUsecase.New(db, database.NewTransactor[authUsecase.AuthRepository](db), revocationStore, logger, appMetrics)
+	paymentUc := paymentUsecase.New(db, database.NewTransactor[paymentUsecase.PaymentRepository](db), paymentGateway, logger)
 	transportUc := transportusecase.New(db)
-	rentUc := rentUsecase.New(db)
-	srv := server.New(":80")
+	rentUc := rentUsecase.New(db, database.NewTransactor[rentUsecase.RentRepository](db), transportLocator, logger, appMetrics, rentUsecase.Options{
+		ReservationGracePeriod: cfg.Rent.ReservationGracePeriod,
+		MaxReservationDuration: cfg.Rent.MaxReservationDuration,
+		MaxReservationAdvance:  cfg.Rent.MaxReservationAdvance,
+		AutoEndInterval:        cfg.Rent.AutoEndInterval,
+		MinutesHoldPeriod:      cfg.Pricing.MinutesHoldPeriod,
+		DaysHoldPeriod:         cfg.Pricing.DaysHoldPeriod,
+	})
+	roleUc := roleUsecase.New(db)
+	pricingUc := pricingUsecase.New(db)
+	promoUc := promoUsecase.New(db)
+	srv := server.New(cfg.HTTP, logger, revocationStore, idempotencyStore, cfg.Idempotency.TTL)
+	if cfg.Payment.DevMode {
+		srv.SimulatePayments()
+	}
+	if cfg.Metrics.Enabled {
+		if err := db.ObserveQueries(appMetrics); err != nil {
+			log.Fatal(err.Error())
+		}
+		if err := appMetrics.RegisterStats(rentUc, logger); err != nil {
+			log.Fatal(err.Error())
+		}
+		srv.ServeMetrics(cfg.Metrics.Path, appMetrics)
+	}
+	if cfg.RateLimit.Enabled {
+		srv.LimitRates(server.RateLimits{
+			IP:   ratelimit.New(ratelimit.Limit{Requests: cfg.RateLimit.IPRequests, Period: cfg.RateLimit.Period}),
+			User: ratelimit.New(ratelimit.Limit{Requests: cfg.RateLimit.UserRequests, Period: cfg.RateLimit.Period}),
+			Auth: ratelimit.New(ratelimit.Limit{Requests: cfg.RateLimit.AuthRequests, Period: cfg.RateLimit.Period}),
+		})
+	}
+	readiness := health.NewChecker(cfg.HTTP.ReadyTimeout)
+	readiness.Add("database", db.Ping)
+	readiness.Add("migrations", db.CheckMigrations)
+	srv.ServeReadiness(readiness)
+
+	tp, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if tp != nil {
+		defer shutdownTracing(logger, tp, cfg.HTTP.ShutdownTimeout)
+		if err := db.TraceQueries(tp); err != nil {
+			log.Fatal(err.Error())
+		}
+		srv.Trace(tp, tracing.Propagator)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, os.Interrupt)
 	defer stop()
 
-	srv.Run(ctx, authUc, paymentUc, transportUc, rentUc)
+	go autoEndRents(ctx, logger, rentUc, cfg.Rent.AutoEndInterval)
+	go expireReservations(ctx, logger, rentUc, cfg.Rent.ReservationExpireInterval)
+	if store, ok := idempotencyStore.(database.IdempotencyStore); ok {
+		go deleteExpiredIdempotencyKeys(ctx, logger, store, cfg.Idempotency.SweepInterval)
+	}
+
+	srv.Run(ctx, authUc, paymentUc, transportUc, rentUc, roleUc, pricingUc, promoUc)
+}
+
+// shutdownTracing exports spans left in the batch before exit
+func shutdownTracing(log *slog.Logger, tp *sdktrace.TracerProvider, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Error("failed to export remaining spans", slog.Any("error", err))
+	}
+}
+
+// autoEndRents periodically ends rents which exceed available money of users
+func autoEndRents(ctx context.Context, logger *slog.Logger, rentUc rentUsecase.RentUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ended, err := rentUc.AutoEndRents(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to end rents automatically", slog.Any("error", err))
+			}
+			if ended > 0 {
+				logger.InfoContext(ctx, "rents are ended automatically", slog.Int("count", ended))
+			}
+		}
+	}
+}
+
+// expireReservations periodically expires reservations which were not converted into rent in time
+func expireReservations(ctx context.Context, logger *slog.Logger, rentUc rentUsecase.RentUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := rentUc.ExpireReservations(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to expire reservations", slog.Any("error", err))
+			}
+			if expired > 0 {
+				logger.InfoContext(ctx, "reservations are expired", slog.Int("count", expired))
+			}
+		}
+	}
+}
+
+// deleteExpiredIdempotencyKeys periodically removes idempotency keys which are not replayed anymore
+func deleteExpiredIdempotencyKeys(ctx context.Context, logger *slog.Logger, store database.IdempotencyStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := store.DeleteExpired(ctx, now)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to delete expired idempotency keys", slog.Any("error", err))
+			}
+			if deleted > 0 {
+				logger.InfoContext(ctx, "expired idempotency keys are deleted", slog.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+// migrate runs migrate subcommand: up, down, status or create <name>
+func migrate(cfg *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|status|create <name>")
+	}
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		paths, err := database.CreateMigration(cfg.Migrations.Dir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+		return nil
+	}
+
+	db, err := database.Open(cfg, logger)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Println("applied", migration)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("database schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down()
+		if errors.Is(err, database.ErrNoMigrations) {
+			fmt.Println("there are no applied migrations")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("reverted", reverted)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			if status.AppliedAt == nil {
+				fmt.Printf("%s\tpending\n", status.Migration)
+				continue
+			}
+			fmt.Printf("%s\tapplied at %s\n", status.Migration, status.AppliedAt.Format(time.RFC3339))
+		}
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+	return nil
 }
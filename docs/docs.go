@@ -19,6 +19,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Публичные ключи в формате JWKS для проверки подписи jwt другими сервисами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccountController"
+                ],
+                "summary": "Публичные ключи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
         "/api/Account/Me": {
             "get": {
                 "security": [
@@ -44,13 +64,82 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Account/Refresh": {
+            "post": {
+                "description": "Обмен refresh токена на новую пару токенов. Каждый refresh токен можно использовать только один раз.\nПовторное использование refresh токена отзывает все токены текущей сессии.\nЕсли refreshToken не указан в теле запроса, используется cookie refresh_token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AccountController"
+                ],
+                "summary": "Обновление токена доступа",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authHandler.UserRefresh.refreshData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -84,13 +173,31 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/entities.TokenPair"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -103,11 +210,19 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Внесение текущего используемого токена доступа в черный список токенов",
+                "description": "Отзыв текущего используемого токена доступа и refresh токенов текущей сессии",
                 "tags": [
                     "AccountController"
                 ],
                 "summary": "Выход из аккаунта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -115,13 +230,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -161,7 +288,25 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -194,25 +339,49 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/authHandler.UserUpdate.userData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/authHandler.UserUpdate.userData"
+                            "$ref": "#/definitions/authHandler.UserUpdate.responseData"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -225,7 +394,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Возращает информацию о count аккаунтах пользователей начиная с id = start",
+                "description": "Возвращает страницу аккаунтов пользователей.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
                 "produces": [
                     "application/json"
                 ],
@@ -236,45 +405,87 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "start",
-                        "name": "start",
-                        "in": "query",
-                        "required": true
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "count",
-                        "name": "count",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "username",
+                            "-username"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало имени пользователя",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только администраторы или только не администраторы",
+                        "name": "isAdmin",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.User"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.User"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -285,7 +496,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создание нового пользователя с указанными данными",
+                "description": "Создание нового пользователя с указанными данными.\nДля isAdmin = true требуется разрешение roles:manage, для ненулевого balance - balances:adjust.",
                 "consumes": [
                     "application/json"
                 ],
@@ -305,6 +516,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/authHandler.AdminCreateUser.userData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -317,19 +534,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -369,19 +604,31 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -392,7 +639,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Обновление данных пользователя с id={id}",
+                "description": "Обновление данных пользователя с id={id}.\nisAdmin и balance не меняются, если не указаны. Для изменения isAdmin требуется разрешение roles:manage, для изменения balance - balances:adjust.",
                 "consumes": [
                     "application/json"
                 ],
@@ -419,6 +666,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/authHandler.AdminUpdateUser.userData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -431,19 +684,37 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -466,6 +737,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -475,377 +752,475 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Admin/Rent": {
+        "/api/Admin/Account/{id}/RevokeSessions": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создание аренды транспорта с id = transportId пользователем с id = userId",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Отзыв всех выданных токенов пользователя с id={id}",
                 "tags": [
-                    "AdminRentController"
+                    "AdminAccountController"
                 ],
-                "summary": "Создание новой аренды",
+                "summary": "Завершение всех сессий пользователя",
                 "parameters": [
                     {
-                        "description": "Rent data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/rentHandler.AdminCreateRent.rentData"
-                        }
+                        "type": "integer",
+                        "description": "Account id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/entities.Rent"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Admin/Rent/End/{rentId}": {
+        "/api/Admin/Account/{id}/Unlock": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Завершение аренды с id = {rentId}.\nПроисходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то в завершить аренду нельзя.",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Снятие блокировки входа после неудачных попыток и сброс счетчика неудачных попыток пользователя с id={id}",
                 "tags": [
-                    "AdminRentController"
+                    "AdminAccountController"
                 ],
-                "summary": "Завершение аренды",
+                "summary": "Разблокировка аккаунта",
                 "parameters": [
-                    {
-                        "type": "number",
-                        "description": "lat",
-                        "name": "lat",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "long",
-                        "name": "long",
-                        "in": "query",
-                        "required": true
-                    },
                     {
                         "type": "integer",
-                        "description": "Rent id",
-                        "name": "rentId",
+                        "description": "Account id",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/entities.Rent"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
-                    }
-                }
-            }
-        },
-        "/api/Admin/Rent/{rentId}": {
-            "get": {
-                "security": [
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Payment/Debtors": {
+            "get": {
+                "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение информации об аренде с id = {rentId}",
+                "description": "Страница пользователей с отрицательным балансом, по умолчанию начиная с наибольшего долга.\ndebtSince - время, когда баланс стал отрицательным. Пользователи с долгом не могут начинать аренды и бронировать транспорт.\ntotalDebt - сумма долгов всех должников, а не только текущей страницы.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminRentController"
+                    "AdminPaymentController"
                 ],
-                "summary": "Информации об аренде",
+                "summary": "Должники",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rent id",
-                        "name": "rentId",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debt",
+                            "-debt",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "-debt",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entities.Rent"
+                            "$ref": "#/definitions/paymentHandler.debtorsPage"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/Admin/Payment/Payout/{id}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Обновление информации об аренде с id = {rentId}\nЕсли в обновлении аренды указывается дата ее окончания, то аренда считается завершенной.\nПроисходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то обновить аренду нельзя.",
+                "description": "Перевод суммы amount со счета выручки на баланс пользователя с id = {id}",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "AdminRentController"
+                    "AdminPaymentController"
                 ],
-                "summary": "Обновление аренды",
+                "summary": "Выплата владельцу",
                 "parameters": [
                     {
-                        "description": "Rent data",
+                        "type": "integer",
+                        "description": "Owner id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payout data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/rentHandler.AdminUpdateRent.rentData"
+                            "$ref": "#/definitions/paymentHandler.payoutData"
                         }
                     },
                     {
-                        "type": "integer",
-                        "description": "Rent id",
-                        "name": "rentId",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/entities.Rent"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/Admin/Payment/Reconciliation": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаление аренды с id = {rentId}",
+                "description": "Сравнение балансов пользователей с суммами проводок по их кошелькам.\nimbalance - сумма всех проводок, должна быть равна 0.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminRentController"
-                ],
-                "summary": "Удаление аренды",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Rent id",
-                        "name": "rentId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "AdminPaymentController"
                 ],
+                "summary": "Сверка балансов",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/entities.ReconciliationReport"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Admin/Transport": {
+        "/api/Admin/Pricing": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение count транспортных средств с id \u003e= start и с типом транспорта transportType",
+                "description": "Получение страницы тарифных политик.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminTransportController"
+                    "AdminPricingController"
                 ],
-                "summary": "Информация о транспортных средствах",
+                "summary": "Список тарифов",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "start",
-                        "name": "start",
-                        "in": "query",
-                        "required": true
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "count",
-                        "name": "count",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
                         "enum": [
-                            "Car",
-                            "Bike",
-                            "Scooter"
+                            "id",
+                            "-id"
                         ],
                         "type": "string",
-                        "description": "transportType",
-                        "name": "transportType",
-                        "in": "query",
-                        "required": true
+                        "default": "id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Transport"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.PricingPolicy"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -856,7 +1231,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создание транспортного средства. При создании указывается id владельца транспортного средства - ownerId",
+                "description": "Создание тарифной политики для типа транспорта (transportType) или конкретного транспорта (transportId).\nПолитика транспорта имеет приоритет над политикой его типа. Условия тарифа сохраняются в аренде при ее начале.",
                 "consumes": [
                     "application/json"
                 ],
@@ -864,67 +1239,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "AdminTransportController"
+                    "AdminPricingController"
                 ],
-                "summary": "Создание транспортного средства",
+                "summary": "Создание тарифа",
                 "parameters": [
                     {
-                        "description": "Transport data",
+                        "description": "Policy data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.AdminCreateTransport.transportData"
+                            "$ref": "#/definitions/pricingHandler.policyData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/entities.Transport"
+                            "$ref": "#/definitions/entities.PricingPolicy"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Admin/Transport/{id}": {
+        "/api/Admin/Pricing/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение информации о транспортном средстве с id = {id}",
+                "description": "Получение тарифной политики с id = {id}",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminTransportController"
+                    "AdminPricingController"
                 ],
-                "summary": "Информация о транспортном средстве",
+                "summary": "Информация о тарифе",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
+                        "description": "Policy id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -934,25 +1333,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entities.Transport"
+                            "$ref": "#/definitions/entities.PricingPolicy"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -963,7 +1374,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Обновление информации о транспортном средстве с id = {id}",
+                "description": "Обновление тарифной политики с id = {id}. Уже начатые аренды рассчитываются по прежним условиям.",
                 "consumes": [
                     "application/json"
                 ],
@@ -971,50 +1382,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "AdminTransportController"
+                    "AdminPricingController"
                 ],
-                "summary": "Обновление транспортного средства",
+                "summary": "Обновление тарифа",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
+                        "description": "Policy id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Transport data",
+                        "description": "Policy data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.AdminUpdateTransport.transportData"
+                            "$ref": "#/definitions/pricingHandler.policyData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entities.Transport"
+                            "$ref": "#/definitions/entities.PricingPolicy"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
@@ -1025,18 +1460,24 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаление информации о транспортном средстве с id = {id}",
+                "description": "Удаление тарифной политики с id = {id}",
                 "tags": [
-                    "AdminTransportController"
+                    "AdminPricingController"
                 ],
-                "summary": "Удаление транспортного средства",
+                "summary": "Удаление тарифа",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
+                        "description": "Policy id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1046,150 +1487,226 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Admin/TransportHistory/{transportId}": {
+        "/api/Admin/PromoCodes": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение информации обо всех арендах транспорта с id = {transportId}",
+                "description": "Получение страницы промокодов с числом использований.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminRentController"
+                    "AdminPromoCodeController"
                 ],
-                "summary": "История аренды транспорта",
+                "summary": "Список промокодов",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
-                        "name": "transportId",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "code",
+                            "-code"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Rent"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.PromoCode"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/Admin/UserHistory/{userId}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение информации обо всех арендах пользователем с id = {userId}",
+                "description": "Создание промокода со скидкой в процентах (percent) или фиксированной суммой (fixed).\nПромокод действует в промежутке [validFrom, validTo). maxUses и maxUsesPerUser ограничивают число аренд с промокодом, 0 - без ограничений.\nЕсли transportTypes не указаны, промокод действует для всех типов транспорта. Регистр кода не учитывается.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AdminRentController"
+                    "AdminPromoCodeController"
                 ],
-                "summary": "История аренды пользователя",
+                "summary": "Создание промокода",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User id",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
+                        "description": "Promo code data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/promoHandler.promoCodeData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Rent"
-                            }
+                            "$ref": "#/definitions/entities.PromoCode"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Payment/Hesoyam/{id}": {
-            "post": {
+        "/api/Admin/PromoCodes/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Пополняет баланс пользователся с id = {id} на 250 000.\nАдминистраторы могут пополнять баланс любому пользователю, обычные пользователи только себе.",
+                "description": "Получение промокода с id = {id}",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "PaymentController"
+                    "AdminPromoCodeController"
                 ],
-                "summary": "Пополнение баланса",
+                "summary": "Информация о промокоде",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Account id",
+                        "description": "Promo code id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1197,162 +1714,231 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.PromoCode"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/Rent/End/{rentId}": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Окончание аренды транспорта с id = {transportid}.\nПроисходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то в завершить аренду нельзя.",
+                "description": "Обновление промокода с id = {id}. Скидка уже начатых аренд не меняется.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "RentController"
+                    "AdminPromoCodeController"
                 ],
-                "summary": "Окончание аренды",
+                "summary": "Обновление промокода",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
-                        "name": "rentId",
+                        "description": "Promo code id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "lat",
-                        "name": "lat",
-                        "in": "query",
-                        "required": true
+                        "description": "Promo code data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/promoHandler.promoCodeData"
+                        }
                     },
                     {
-                        "type": "number",
-                        "description": "long",
-                        "name": "long",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entities.Rent"
+                            "$ref": "#/definitions/entities.PromoCode"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/Rent/MyHistory": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение данных всех аренд текущего авторизованного пользователя",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Удаление промокода с id = {id}. Скидка уже начатых аренд сохраняется.",
                 "tags": [
-                    "RentController"
+                    "AdminPromoCodeController"
+                ],
+                "summary": "Удаление промокода",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promo code id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Истории аренды пользователя",
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Rent"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Rent/New/{transportId}": {
+        "/api/Admin/Rent": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создание новой аредны транспорта с id = {transportid}.\nВ параметра rentType указывается тип аренды: [Minutes, Days].",
+                "description": "Создание аренды транспорта с id = transportId пользователем с id = userId\nЕсли указана дата окончания, стоимость аренды списывается с баланса пользователя,\nиначе с баланса резервируется залог, как при начале аренды пользователем, и транспорт становится недоступным для аренды.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "RentController"
+                    "AdminRentController"
                 ],
-                "summary": "Создание новой аренды транспорта",
+                "summary": "Создание новой аренды",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transport id",
-                        "name": "transportId",
-                        "in": "path",
-                        "required": true
+                        "description": "Rent data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rentHandler.AdminCreateRent.rentData"
+                        }
                     },
                     {
-                        "enum": [
-                            "Minutes",
-                            "Days"
-                        ],
                         "type": "string",
-                        "description": "Rent type: [Minutes, Days]",
-                        "name": "rentType",
-                        "in": "query",
-                        "required": true
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1365,152 +1951,234 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Rent/Transport": {
-            "get": {
-                "description": "Получение информации о транспорте, доступного для аренды по месту его расположения и типу.",
+        "/api/Admin/Rent/End/{rentId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершение аренды с id = {rentId}.\nПроисходит рассчет итоговой суммы аренды, залог списывается в счет стоимости. Если средств недостаточно, аренда все равно завершается, а баланс пользователя становится отрицательным (долг).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "RentController"
+                    "AdminRentController"
                 ],
-                "summary": "Доступный транспорт для аренды",
+                "summary": "Завершение аренды",
                 "parameters": [
                     {
                         "type": "number",
-                        "description": "географическая широта",
+                        "description": "lat",
                         "name": "lat",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "number",
-                        "description": "радиус поиска",
-                        "name": "radius",
+                        "description": "long",
+                        "name": "long",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "description": "географическая долгота",
-                        "name": "long",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Rent id",
+                        "name": "rentId",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "All",
-                            "Car",
-                            "Bike",
-                            "Scooter"
-                        ],
                         "type": "string",
-                        "description": "transportType",
-                        "name": "transportType",
-                        "in": "query",
-                        "required": true
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Transport"
-                            }
+                            "$ref": "#/definitions/entities.Rent"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Rent/TransportHistory/{transportId}": {
-            "get": {
+        "/api/Admin/Rent/Refund/{rentId}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение данных обо всех арендах транспорта с id = {transportId}.\nДанные может получить только владелец транспорта.",
+                "description": "Возврат итоговой суммы завершенной аренды с id = {rentId} на баланс арендатора. Аренду можно вернуть только один раз.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "RentController"
+                    "AdminRentController"
                 ],
-                "summary": "Истории аренды транспорта",
+                "summary": "Возврат средств за аренду",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
-                        "name": "transportId",
+                        "description": "Rent id",
+                        "name": "rentId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/entities.Rent"
-                            }
+                            "$ref": "#/definitions/entities.Rent"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Rent/{rentid}": {
+        "/api/Admin/Rent/{rentId}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Получение данных аренды с id = {rentid}. Данные могут получить только арендатор и арендодатель.",
+                "description": "Получение информации об аренде с id = {rentId}",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "RentController"
+                    "AdminRentController"
                 ],
-                "summary": "Получение аренды пользователя",
+                "summary": "Информации об аренде",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Rent id",
-                        "name": "rentid",
+                        "name": "rentId",
                         "in": "path",
                         "required": true
                     }
@@ -1525,26 +2193,42 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
-                    }
-                }
-            }
-        },
-        "/api/Transport": {
-            "post": {
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Создание транспорта у текущего авторизованного пользователя",
+                "description": "Обновление информации об аренде с id = {rentId}\nЕсли в обновлении аренды указывается дата ее окончания, то аренда считается завершенной.\nПроисходит рассчет итоговой суммы аренды, она списывается с баланса пользователя так же, как при завершении аренды.\nПри изменении завершенной аренды с баланса списывается или возвращается разница в стоимости, возобновить завершенную аренду нельзя.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1552,83 +2236,254 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "TransportController"
+                    "AdminRentController"
                 ],
-                "summary": "Создаение транспорта",
+                "summary": "Обновление аренды",
                 "parameters": [
                     {
-                        "description": "Transport data",
+                        "description": "Rent data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.UserCreateTransport.transportData"
+                            "$ref": "#/definitions/rentHandler.AdminUpdateRent.rentData"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rent id",
+                        "name": "rentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.UserCreateTransport.responseData"
+                            "$ref": "#/definitions/entities.Rent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление завершенной аренды с id = {rentId}. Незавершенную аренду и аренду с проводками в журнале удалить нельзя, вместо этого используется возврат средств.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRentController"
+                ],
+                "summary": "Удаление аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rent id",
+                        "name": "rentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/Transport/{id}": {
+        "/api/Admin/Roles": {
             "get": {
-                "description": "Просмотр информации о транспорте с id = {id}",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение страницы ролей с их разрешениями.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "TransportController"
+                    "AdminRoleController"
                 ],
-                "summary": "Получение информации о транспотре",
+                "summary": "Список ролей",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "name",
+                            "-name"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.UserGetTransport.transportData"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Role"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Обновление информации о транспорте с id = {id}",
+                "description": "Создание роли с указанным набором разрешений",
                 "consumes": [
                     "application/json"
                 ],
@@ -1636,288 +2491,3999 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "TransportController"
+                    "AdminRoleController"
                 ],
-                "summary": "Обновление информации о транспотре",
+                "summary": "Создание роли",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Transport id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Transport data",
+                        "description": "Role data",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.UserUpdateTransport.transportData"
+                            "$ref": "#/definitions/roleHandler.CreateRole.roleData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/transportHandler.UserUpdateTransport.responseData"
+                            "$ref": "#/definitions/entities.Role"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/Admin/Roles/Permissions": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Удаление транспорта с id = {id}. Удалить данные о транспорте может только владелец транспорта.",
+                "description": "Получение списка всех разрешений, которые можно назначить роли",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "TransportController"
+                    "AdminRoleController"
                 ],
-                "summary": "Удаление транспорта",
+                "summary": "Список разрешений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Roles/User/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение ролей пользователя с id = {userId}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Роли пользователя",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transport id",
-                        "name": "id",
+                        "description": "User id",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.UserRole"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/httpUtil.ResponseError"
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
                         }
                     }
                 }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Назначение роли пользователю с id = {userId}.\nЕсли указан operatorId, разрешения роли на управление транспортом действуют только для транспорта этого владельца.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Назначение роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/roleHandler.AssignRole.assignData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Roles/User/{userId}/{roleId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзыв роли с id = {roleId} у пользователя с id = {userId}",
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Отзыв роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role id",
+                        "name": "roleId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение информации о роли с id = {id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Информация о роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Обновление названия и разрешений роли с id = {id}. Роль admin изменить нельзя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Обновление роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/roleHandler.UpdateRole.roleData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление роли с id = {id}. Роль admin удалить нельзя.",
+                "tags": [
+                    "AdminRoleController"
+                ],
+                "summary": "Удаление роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Transport": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение страницы транспортных средств с типом транспорта transportType.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminTransportController"
+                ],
+                "summary": "Информация о транспортных средствах",
+                "parameters": [
+                    {
+                        "enum": [
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "transportType",
+                        "name": "transportType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "model",
+                            "-model"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Transport"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание транспортного средства. При создании указывается id владельца транспортного средства - ownerId",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminTransportController"
+                ],
+                "summary": "Создание транспортного средства",
+                "parameters": [
+                    {
+                        "description": "Transport data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.AdminCreateTransport.transportData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Transport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/Transport/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение информации о транспортном средстве с id = {id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminTransportController"
+                ],
+                "summary": "Информация о транспортном средстве",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Transport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Обновление информации о транспортном средстве с id = {id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminTransportController"
+                ],
+                "summary": "Обновление транспортного средства",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transport data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.AdminUpdateTransport.transportData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Transport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление информации о транспортном средстве с id = {id}",
+                "tags": [
+                    "AdminTransportController"
+                ],
+                "summary": "Удаление транспортного средства",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/TransportHistory/{transportId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение информации обо всех арендах транспорта с id = {transportId}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRentController"
+                ],
+                "summary": "История аренды транспорта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "transportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timeStart",
+                            "-timeStart",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "timeStart",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые не раньше, в формате RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые раньше, в формате RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус аренды",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "Тип транспорта",
+                        "name": "transportType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Rent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Admin/UserHistory/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение информации обо всех арендах пользователем с id = {userId}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AdminRentController"
+                ],
+                "summary": "История аренды пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timeStart",
+                            "-timeStart",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "timeStart",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые не раньше, в формате RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые раньше, в формате RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус аренды",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "Тип транспорта",
+                        "name": "transportType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Rent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Payment/TopUp": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создает платеж на сумму amount в платежном шлюзе. Баланс пополняется после подтверждения платежа шлюзом через webhook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PaymentController"
+                ],
+                "summary": "Создание пополнения",
+                "parameters": [
+                    {
+                        "description": "Top-up data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/paymentHandler.topUpData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.TopUp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Payment/TopUp/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение пополнения текущего пользователя с id = {id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PaymentController"
+                ],
+                "summary": "Статус пополнения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Top-up id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.TopUp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Payment/TopUp/{id}/Simulate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает пополнение с id = {id} через встроенный тестовый шлюз (fake), который отправляет подписанный webhook.\nДоступно только при запуске с флагом payment-dev-mode, для других шлюзов недоступно.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PaymentController"
+                ],
+                "summary": "Имитация оплаты",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Top-up id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment result",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/paymentHandler.simulationData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.TopUp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Payment/Transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение страницы проводок по кошельку текущего пользователя, по умолчанию новые первыми.\nПоложительная сумма увеличивает баланс, отрицательная уменьшает.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PaymentController"
+                ],
+                "summary": "История операций",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "-id",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Transaction"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Payment/Webhook": {
+            "post": {
+                "description": "Принимает подписанное уведомление шлюза о результате платежа. Повторные уведомления по завершенному пополнению игнорируются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PaymentController"
+                ],
+                "summary": "Webhook платежного шлюза",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/End/{rentId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Окончание аренды транспорта с id = {transportid}.\nПроисходит рассчет итоговой суммы аренды, залог списывается в счет стоимости. Если средств недостаточно, аренда все равно завершается, а баланс пользователя становится отрицательным (долг).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Окончание аренды",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "rentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "lat",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "long",
+                        "name": "long",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Rent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/MyHistory": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение страницы аренд текущего авторизованного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Истории аренды пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timeStart",
+                            "-timeStart",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "timeStart",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые не раньше, в формате RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые раньше, в формате RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус аренды",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "Тип транспорта",
+                        "name": "transportType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Rent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/New/{transportId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание новой аредны транспорта с id = {transportid}.\nВ параметра rentType указывается тип аренды: [Minutes, Days].\nВ параметре promoCode можно указать промокод, скидка применяется при завершении аренды.\nПри начале аренды с баланса резервируется залог (hold), при недостатке средств или наличии долга аренда не создается.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Создание новой аренды транспорта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "transportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "Minutes",
+                            "Days"
+                        ],
+                        "type": "string",
+                        "description": "Rent type: [Minutes, Days]",
+                        "name": "rentType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "promoCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Rent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/Reservations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение страницы бронирований текущего пользователя, по умолчанию по времени начала.\nСледующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Мои бронирования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timeStart",
+                            "-timeStart",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "timeStart",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Reservation"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Бронирование транспорта на промежуток времени [timeStart, timeEnd) в формате RFC 3339.\nБронирования одного транспорта не могут пересекаться. Если аренда не начата в течение льготного периода после timeStart, бронирование истекает.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Бронирование транспорта",
+                "parameters": [
+                    {
+                        "description": "Reservation data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rentHandler.UserCreateReservation.reservationData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/Reservations/Transport/{id}": {
+            "get": {
+                "description": "Промежутки времени, на которые забронирован транспорт с id = {id}.\nПо умолчанию возвращаются бронирования на неделю вперед.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Календарь бронирований транспорта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода в формате RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода в формате RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.ReservationSlot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/Reservations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение информации о бронировании по id. Доступно только пользователю, создавшему бронирование.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Получение бронирования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отмена активного бронирования.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Отмена бронирования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/Reservations/{id}/Start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Начинает аренду забронированного транспорта. Аренду можно начать в течение льготного периода до и после начала бронирования.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ReservationController"
+                ],
+                "summary": "Начало аренды по бронированию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "Minutes",
+                            "Days"
+                        ],
+                        "type": "string",
+                        "description": "Rent type: [Minutes, Days]",
+                        "name": "rentType",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "promoCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Rent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/Transport": {
+            "get": {
+                "description": "Получение информации о транспорте, доступного для аренды по месту его расположения и типу.\nТранспорт отсортирован по расстоянию до точки поиска, расстояние в метрах указывается в поле distance.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Доступный транспорт для аренды",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "географическая широта",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "радиус поиска в метрах",
+                        "name": "radius",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "географическая долгота",
+                        "name": "long",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "All",
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "transportType",
+                        "name": "transportType",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.NearbyTransport"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/TransportHistory/{transportId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение данных обо всех арендах транспорта с id = {transportId}.\nДанные может получить только владелец транспорта.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Истории аренды транспорта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "transportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor предыдущей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timeStart",
+                            "-timeStart",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "default": "timeStart",
+                        "description": "Поле сортировки, - в начале для сортировки по убыванию",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые не раньше, в формате RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Аренды, начатые раньше, в формате RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "ended"
+                        ],
+                        "type": "string",
+                        "description": "Статус аренды",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "Car",
+                            "Bike",
+                            "Scooter"
+                        ],
+                        "type": "string",
+                        "description": "Тип транспорта",
+                        "name": "transportType",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/pagination.Page"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "items": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entities.Rent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Rent/{rentid}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение данных аренды с id = {rentid}. Данные могут получить только арендатор и арендодатель.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RentController"
+                ],
+                "summary": "Получение аренды пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rent id",
+                        "name": "rentid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Rent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Transport": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание транспорта у текущего авторизованного пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TransportController"
+                ],
+                "summary": "Создаение транспорта",
+                "parameters": [
+                    {
+                        "description": "Transport data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.UserCreateTransport.transportData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.UserCreateTransport.responseData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/Transport/{id}": {
+            "get": {
+                "description": "Просмотр информации о транспорте с id = {id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TransportController"
+                ],
+                "summary": "Получение информации о транспотре",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.UserGetTransport.transportData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Обновление информации о транспорте с id = {id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TransportController"
+                ],
+                "summary": "Обновление информации о транспотре",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transport data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.UserUpdateTransport.transportData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transportHandler.UserUpdateTransport.responseData"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление транспорта с id = {id}. Удалить данные о транспорте может только владелец транспорта.",
+                "tags": [
+                    "TransportController"
+                ],
+                "summary": "Удаление транспорта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transport id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ключ идемпотентности для безопасного повтора запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpUtil.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Процесс запущен и отвечает на запросы, зависимости не проверяются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "HealthController"
+                ],
+                "summary": "Проверка жизни",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/healthHandler.liveness"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверка подключения к базе данных и применённых миграций.\nПосле получения SIGTERM сервис сразу сообщает о неготовности, чтобы балансировщик перестал отправлять запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "HealthController"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "authHandler.AdminCreateUser.userData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "isAdmin": {
+                    "type": "boolean"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.AdminUpdateUser.userData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "isAdmin": {
+                    "type": "boolean"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.UserRefresh.refreshData": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.UserSignIn.userCreadentials": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.UserSignUp.userData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.UserUpdate.responseData": {
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authHandler.UserUpdate.userData": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.BalanceMismatch": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "ledgerBalance": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Debtor": {
+            "type": "object",
+            "properties": {
+                "debt": {
+                    "type": "number"
+                },
+                "debtSince": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "username"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "field is required"
+                }
+            }
+        },
+        "entities.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.JSONWebKey"
+                    }
+                }
+            }
+        },
+        "entities.NearbyTransport": {
+            "type": "object",
+            "properties": {
+                "canBeRented": {
+                    "type": "boolean"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "dayPrice": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "Distance to the search center in meters",
+                    "type": "number",
+                    "example": 125.4
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identifier": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "minutePrice": {
+                    "type": "number"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "transportType": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        " Scooter",
+                        " Bike"
+                    ]
+                }
+            }
+        },
+        "entities.Permission": {
+            "type": "string",
+            "enum": [
+                "users:read",
+                "users:manage",
+                "rents:read",
+                "rents:end",
+                "rents:manage",
+                "transports:manage",
+                "balances:adjust",
+                "roles:manage",
+                "pricing:manage",
+                "promo:manage"
+            ],
+            "x-enum-varnames": [
+                "PermissionUsersRead",
+                "PermissionUsersManage",
+                "PermissionRentsRead",
+                "PermissionRentsEnd",
+                "PermissionRentsManage",
+                "PermissionTransportsManage",
+                "PermissionBalancesAdjust",
+                "PermissionRolesManage",
+                "PermissionPricingManage",
+                "PermissionPromoManage"
+            ]
+        },
+        "entities.PricingPolicy": {
+            "type": "object",
+            "properties": {
+                "dailyCap": {
+                    "description": "DailyCap limits the charge for every 24 hours of the rent",
+                    "type": "number"
+                },
+                "dayRateSwitch": {
+                    "description": "DayRateSwitch charges per-minute rent by the day price for days where it is cheaper",
+                    "type": "boolean"
+                },
+                "daysHold": {
+                    "type": "number"
+                },
+                "freeMinutes": {
+                    "description": "FreeMinutes at the beginning of per-minute rent are not charged",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "minimumCharge": {
+                    "description": "MinimumCharge is the least price of the rent, unlock fee included",
+                    "type": "number"
+                },
+                "minutesHold": {
+                    "description": "MinutesHold and DaysHold are reserved from the balance when per-minute and per-day rents start,\n0 means the price of default hold period",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nightEnd": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "nightMultiplier": {
+                    "description": "NightMultiplier applies to minutes started in [NightStart, NightEnd) hours, 0 means no multiplier",
+                    "type": "number"
+                },
+                "nightStart": {
+                    "type": "integer",
+                    "example": 23
+                },
+                "timezone": {
+                    "description": "Timezone of night hours and weekends in IANA format, server timezone if empty",
+                    "type": "string",
+                    "example": "Europe/Ulyanovsk"
+                },
+                "transportId": {
+                    "type": "integer"
+                },
+                "transportType": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        " Bike",
+                        " Scooter"
+                    ]
+                },
+                "unlockFee": {
+                    "description": "UnlockFee is charged once for every rent",
+                    "type": "number"
+                },
+                "weekendMultiplier": {
+                    "description": "WeekendMultiplier applies to minutes started on Saturday and Sunday, 0 means no multiplier",
+                    "type": "number"
+                }
+            }
+        },
+        "entities.PromoCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "enum": [
+                        "percent",
+                        " fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pricing.DiscountKind"
+                        }
+                    ]
+                },
+                "maxUses": {
+                    "type": "integer"
+                },
+                "maxUsesPerUser": {
+                    "type": "integer"
+                },
+                "transportTypes": {
+                    "description": "TransportTypes restricts the code to the types, empty means all types",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "uses": {
+                    "description": "Uses is the number of rents the code is attached to",
+                    "type": "integer"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validTo": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "entities.ReconciliationReport": {
+            "type": "object",
+            "properties": {
+                "generatedAt": {
+                    "type": "string"
+                },
+                "imbalance": {
+                    "description": "Imbalance is sum of all postings, it must be zero",
+                    "type": "number"
+                },
+                "mismatches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.BalanceMismatch"
+                    }
+                },
+                "systemAccounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Rent": {
+            "type": "object",
+            "properties": {
+                "debt": {
+                    "description": "Debt is part of the final price which was not covered by the balance",
+                    "type": "number"
+                },
+                "discount": {
+                    "type": "number"
+                },
+                "finalPrice": {
+                    "type": "number"
+                },
+                "hold": {
+                    "description": "Hold is money reserved from the balance at the start of the rent",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "originalPrice": {
+                    "description": "OriginalPrice is the price before promo code discount",
+                    "type": "number"
+                },
+                "priceOfUnit": {
+                    "type": "number"
+                },
+                "priceType": {
+                    "type": "string",
+                    "enum": [
+                        "Minutes",
+                        " Days"
+                    ]
+                },
+                "promoCodeId": {
+                    "type": "integer"
+                },
+                "tariff": {
+                    "$ref": "#/definitions/pricing.Tariff"
+                },
+                "timeEnd": {
+                    "type": "string"
+                },
+                "timeStart": {
+                    "type": "string"
+                },
+                "transportId": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Reservation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "rentId": {
+                    "description": "RentId is id of the rent the reservation was converted into",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Active",
+                        " Converted",
+                        " Cancelled",
+                        " Expired"
+                    ]
+                },
+                "timeEnd": {
+                    "type": "string"
+                },
+                "timeStart": {
+                    "type": "string"
+                },
+                "transportId": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.ReservationSlot": {
+            "type": "object",
+            "properties": {
+                "timeEnd": {
+                    "type": "string"
+                },
+                "timeStart": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Role": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Permission"
+                    }
+                }
+            }
+        },
+        "entities.TokenPair": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                },
+                "refreshTokenExpiresAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "tokenExpiresAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.TopUp": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "confirmationUrl": {
+                    "description": "ConfirmationURL is the page of the gateway where the user pays",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "failureReason": {
+                    "type": "string"
+                },
+                "gateway": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        " succeeded",
+                        " failed"
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Transaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "opening_balance",
+                        " top_up",
+                        " hold",
+                        " hold_capture",
+                        " hold_release",
+                        " rent_charge",
+                        " promo_credit",
+                        " refund",
+                        " owner_payout",
+                        " adjustment"
+                    ]
+                },
+                "rentId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Transport": {
+            "type": "object",
+            "properties": {
+                "canBeRented": {
+                    "type": "boolean"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "dayPrice": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identifier": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "minutePrice": {
+                    "type": "number"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "transportType": {
+                    "type": "string",
+                    "enum": [
+                        "Car",
+                        " Scooter",
+                        " Bike"
+                    ]
+                }
+            }
+        },
+        "entities.User": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isAdmin": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.UserRole": {
+            "type": "object",
+            "properties": {
+                "operatorId": {
+                    "type": "integer"
+                },
+                "roleId": {
+                    "type": "integer"
+                },
+                "roleName": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "database schema is behind"
+                },
+                "latencyMs": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
             }
-        }
-    },
-    "definitions": {
-        "authHandler.AdminCreateUser.userData": {
+        },
+        "healthHandler.liveness": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "balance": {
-                    "type": "number"
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "httpUtil.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "rent_not_found"
                 },
-                "isAdmin": {
-                    "type": "boolean"
+                "detail": {
+                    "type": "string",
+                    "example": "rent is not exist"
                 },
-                "password": {
-                    "type": "string"
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.FieldError"
+                    }
                 },
-                "username": {
+                "instance": {
+                    "type": "string",
+                    "example": "/api/Rent/1"
+                },
+                "requestId": {
+                    "description": "RequestId is the same as X-Request-ID response header, it helps to find the request in logs",
+                    "type": "string",
+                    "example": "3f2a9c1b7d4e4f0a8b6c5d2e1f0a9b8c"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "pagination.Page": {
+            "type": "object",
+            "properties": {
+                "items": {},
+                "nextCursor": {
                     "type": "string"
+                },
+                "total": {
+                    "description": "Total is number of items matching the filters on all pages",
+                    "type": "integer"
                 }
             }
         },
-        "authHandler.AdminUpdateUser.userData": {
+        "paymentHandler.debtorsPage": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "balance": {
-                    "type": "number"
+                "generatedAt": {
+                    "type": "string"
                 },
-                "isAdmin": {
-                    "type": "boolean"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Debtor"
+                    }
                 },
-                "password": {
+                "nextCursor": {
                     "type": "string"
                 },
-                "username": {
-                    "type": "string"
+                "total": {
+                    "description": "Total is number of items matching the filters on all pages",
+                    "type": "integer"
+                },
+                "totalDebt": {
+                    "type": "number"
                 }
             }
         },
-        "authHandler.UserSignIn.userCreadentials": {
+        "paymentHandler.payoutData": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "amount"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+                "amount": {
+                    "type": "number",
+                    "example": 1500
                 }
             }
         },
-        "authHandler.UserSignUp.userData": {
+        "paymentHandler.simulationData": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "status"
             ],
             "properties": {
-                "isAdmin": {
-                    "type": "boolean"
-                },
-                "password": {
-                    "type": "string"
+                "failureReason": {
+                    "type": "string",
+                    "example": "card declined"
                 },
-                "username": {
-                    "type": "string"
+                "status": {
+                    "enum": [
+                        "succeeded",
+                        " failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payments.Status"
+                        }
+                    ]
                 }
             }
         },
-        "authHandler.UserUpdate.userData": {
+        "paymentHandler.topUpData": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "amount"
             ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "amount": {
+                    "type": "number",
+                    "example": 1000
+                }
+            }
+        },
+        "payments.Status": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "Succeeded",
+                "Failed"
+            ]
+        },
+        "pricing.Discount": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "enum": [
+                        "percent",
+                        " fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pricing.DiscountKind"
+                        }
+                    ]
                 },
-                "username": {
-                    "type": "string"
+                "value": {
+                    "type": "number"
                 }
             }
         },
-        "entities.Rent": {
+        "pricing.DiscountKind": {
+            "type": "string",
+            "enum": [
+                "percent",
+                "fixed"
+            ],
+            "x-enum-varnames": [
+                "Percent",
+                "Fixed"
+            ]
+        },
+        "pricing.Tariff": {
             "type": "object",
             "properties": {
-                "finalPrice": {
+                "dailyCap": {
+                    "description": "DailyCap limits the charge for every 24 hours of the rent",
                     "type": "number"
                 },
-                "id": {
-                    "type": "integer"
+                "dayPrice": {
+                    "type": "number"
                 },
-                "priceOfUnit": {
+                "dayRateSwitch": {
+                    "description": "DayRateSwitch charges per-minute rent by the day price for days where it is cheaper",
+                    "type": "boolean"
+                },
+                "daysHold": {
                     "type": "number"
                 },
-                "priceType": {
-                    "type": "string",
-                    "enum": [
-                        "Minutes",
-                        " Days"
+                "discount": {
+                    "description": "Discount of promo code attached to the rent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pricing.Discount"
+                        }
                     ]
                 },
-                "timeEnd": {
-                    "type": "string"
+                "freeMinutes": {
+                    "description": "FreeMinutes at the beginning of per-minute rent are not charged",
+                    "type": "integer"
                 },
-                "timeStart": {
-                    "type": "string"
+                "minimumCharge": {
+                    "description": "MinimumCharge is the least price of the rent, unlock fee included",
+                    "type": "number"
                 },
-                "transportId": {
-                    "type": "integer"
+                "minutePrice": {
+                    "type": "number"
                 },
-                "userId": {
-                    "type": "integer"
+                "minutesHold": {
+                    "description": "MinutesHold and DaysHold are reserved from the balance when per-minute and per-day rents start,\n0 means the price of default hold period",
+                    "type": "number"
+                },
+                "nightEnd": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "nightMultiplier": {
+                    "description": "NightMultiplier applies to minutes started in [NightStart, NightEnd) hours, 0 means no multiplier",
+                    "type": "number"
+                },
+                "nightStart": {
+                    "type": "integer",
+                    "example": 23
+                },
+                "timezone": {
+                    "description": "Timezone of night hours and weekends in IANA format, server timezone if empty",
+                    "type": "string",
+                    "example": "Europe/Ulyanovsk"
+                },
+                "unlockFee": {
+                    "description": "UnlockFee is charged once for every rent",
+                    "type": "number"
+                },
+                "weekendMultiplier": {
+                    "description": "WeekendMultiplier applies to minutes started on Saturday and Sunday, 0 means no multiplier",
+                    "type": "number"
                 }
             }
         },
-        "entities.Transport": {
+        "pricingHandler.policyData": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "canBeRented": {
-                    "type": "boolean"
+                "dailyCap": {
+                    "description": "DailyCap limits the charge for every 24 hours of the rent",
+                    "type": "number"
                 },
-                "color": {
-                    "type": "string"
+                "dayRateSwitch": {
+                    "description": "DayRateSwitch charges per-minute rent by the day price for days where it is cheaper",
+                    "type": "boolean"
                 },
-                "dayPrice": {
+                "daysHold": {
                     "type": "number"
                 },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
+                "freeMinutes": {
+                    "description": "FreeMinutes at the beginning of per-minute rent are not charged",
                     "type": "integer"
                 },
-                "identifier": {
-                    "type": "string"
-                },
-                "latitude": {
+                "minimumCharge": {
+                    "description": "MinimumCharge is the least price of the rent, unlock fee included",
                     "type": "number"
                 },
-                "longitude": {
+                "minutesHold": {
+                    "description": "MinutesHold and DaysHold are reserved from the balance when per-minute and per-day rents start,\n0 means the price of default hold period",
                     "type": "number"
                 },
-                "minutePrice": {
+                "name": {
+                    "type": "string"
+                },
+                "nightEnd": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "nightMultiplier": {
+                    "description": "NightMultiplier applies to minutes started in [NightStart, NightEnd) hours, 0 means no multiplier",
                     "type": "number"
                 },
-                "model": {
-                    "type": "string"
+                "nightStart": {
+                    "type": "integer",
+                    "example": 23
                 },
-                "ownerId": {
+                "timezone": {
+                    "description": "Timezone of night hours and weekends in IANA format, server timezone if empty",
+                    "type": "string",
+                    "example": "Europe/Ulyanovsk"
+                },
+                "transportId": {
                     "type": "integer"
                 },
                 "transportType": {
                     "type": "string",
                     "enum": [
                         "Car",
-                        " Scooter",
-                        " Bike"
+                        " Bike",
+                        " Scooter"
                     ]
+                },
+                "unlockFee": {
+                    "description": "UnlockFee is charged once for every rent",
+                    "type": "number"
+                },
+                "weekendMultiplier": {
+                    "description": "WeekendMultiplier applies to minutes started on Saturday and Sunday, 0 means no multiplier",
+                    "type": "number"
                 }
             }
         },
-        "entities.User": {
+        "promoHandler.promoCodeData": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "code",
+                "kind",
+                "validFrom",
+                "validTo",
+                "value"
             ],
             "properties": {
-                "balance": {
-                    "type": "number"
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER23"
                 },
-                "id": {
+                "kind": {
+                    "enum": [
+                        "percent",
+                        " fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pricing.DiscountKind"
+                        }
+                    ]
+                },
+                "maxUses": {
                     "type": "integer"
                 },
-                "isAdmin": {
-                    "type": "boolean"
+                "maxUsesPerUser": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string"
+                "transportTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "httpUtil.ResponseError": {
-            "type": "object",
-            "properties": {
-                "err": {
+                "validFrom": {
+                    "type": "string",
+                    "example": "2023-06-01T00:00:00+04:00"
+                },
+                "validTo": {
                     "type": "string",
-                    "example": "error occures"
+                    "example": "2023-09-01T00:00:00+04:00"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 15
                 }
             }
         },
@@ -1981,6 +6547,81 @@ const docTemplate = `{
                 }
             }
         },
+        "rentHandler.UserCreateReservation.reservationData": {
+            "type": "object",
+            "required": [
+                "timeEnd",
+                "timeStart",
+                "transportId"
+            ],
+            "properties": {
+                "timeEnd": {
+                    "type": "string",
+                    "example": "2023-05-01T12:00:00+04:00"
+                },
+                "timeStart": {
+                    "type": "string",
+                    "example": "2023-05-01T10:00:00+04:00"
+                },
+                "transportId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "roleHandler.AssignRole.assignData": {
+            "type": "object",
+            "required": [
+                "roleId"
+            ],
+            "properties": {
+                "operatorId": {
+                    "type": "integer"
+                },
+                "roleId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "roleHandler.CreateRole.roleData": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Permission"
+                    }
+                }
+            }
+        },
+        "roleHandler.UpdateRole.roleData": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Permission"
+                    }
+                }
+            }
+        },
         "transportHandler.AdminCreateTransport.transportData": {
             "type": "object",
             "required": [
@@ -1,44 +1,584 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
+// Config is loaded in layers: defaults, then config file, then environment, then flags.
+// Keys of the file are mapstructure tags, nested by sections. Environment variable of a key
+// is the key with the EnvPrefix, e.g. SIMBIRGO_DB_PASSWORD for db.password.
+// Fields tagged as secret are redacted when config is printed.
 type Config struct {
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Host     string `mapstructure:"host"`
-	DBName   string `mapstructure:"dbname"`
-	Port     int    `mapstructure:"port"`
-	SSLMode  string `mapstructure:"sslmode"`
+	HTTP        HTTPConfig        `mapstructure:"http"`
+	DB          DBConfig          `mapstructure:"db"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Rent        RentConfig        `mapstructure:"rent"`
+	Pricing     PricingConfig     `mapstructure:"pricing"`
+	Payment     PaymentConfig     `mapstructure:"payment"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Migrations  MigrationsConfig  `mapstructure:"migrations"`
+	Log         LogConfig         `mapstructure:"log"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+}
+
+type HTTPConfig struct {
+	Addr            string        `mapstructure:"addr" flag:"http-addr" usage:"address the server listens on"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout" flag:"http-read-timeout" usage:"timeout of reading the request, 0 means no timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout" flag:"http-write-timeout" usage:"timeout of writing the response, 0 means no timeout"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout" flag:"http-idle-timeout" usage:"how long keep-alive connection waits for the next request"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" flag:"shutdown-timeout" usage:"how long running requests are waited for on shutdown"`
+	DrainDelay      time.Duration `mapstructure:"drain_delay" flag:"http-drain-delay" usage:"how long /readyz reports not ready before shutdown starts"`
+	ReadyTimeout    time.Duration `mapstructure:"ready_timeout" flag:"http-ready-timeout" usage:"timeout of every readiness check"`
+	// TrustedProxies are comma separated ips or cidrs, client ip is taken from X-Forwarded-For only behind them
+	TrustedProxies string `mapstructure:"trusted_proxies" flag:"http-trusted-proxies" usage:"comma separated ips or cidrs of proxies setting X-Forwarded-For"`
+}
+
+type DBConfig struct {
+	User            string        `mapstructure:"user" flag:"username" usage:"if required username is not postgres, then use this flag"`
+	Password        string        `mapstructure:"password" flag:"password" usage:"if required password is not postgres, then use this flag" secret:"true"`
+	Host            string        `mapstructure:"host" flag:"host" usage:"if required host is not localhost, then use this flag"`
+	DBName          string        `mapstructure:"dbname" flag:"dbname" usage:"if required database is not postgres, then use this flag"`
+	Port            int           `mapstructure:"port" flag:"port" usage:"if required port is not 5432, then use this flag"`
+	SSLMode         string        `mapstructure:"sslmode" flag:"sslmode" usage:"if required sslmode is not 'disabled', then use this flag"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns" flag:"db-max-open-conns" usage:"maximum number of open connections, 0 means unlimited"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" flag:"db-max-idle-conns" usage:"maximum number of idle connections"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" flag:"db-conn-max-lifetime" usage:"maximum lifetime of a connection, 0 means unlimited"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" flag:"db-conn-max-idle-time" usage:"maximum idle time of a connection, 0 means unlimited"`
+}
+
+type AuthConfig struct {
+	JWTKeysDir      string        `mapstructure:"jwt_keys_dir" flag:"jwt-keys-dir" usage:"directory with jwt keys in pem format, file name is used as key id"`
+	JWTSigningKid   string        `mapstructure:"jwt_signing_kid" flag:"jwt-signing-kid" usage:"id of the key used to sign new tokens"`
+	JWTSecret       string        `mapstructure:"jwt_secret" flag:"jwt-secret" usage:"secret for HS256 signed tokens" secret:"true"`
+	JWTDevKey       bool          `mapstructure:"jwt_dev_key" flag:"jwt-dev-key" usage:"sign tokens with a random key generated on start, tokens die on restart, for development only"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl" flag:"access-token-ttl" usage:"lifetime of access tokens"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl" flag:"refresh-token-ttl" usage:"lifetime of refresh tokens"`
+	RevocationStore string        `mapstructure:"revocation_store" flag:"revocation-store" usage:"storage of revoked tokens: postgres or memory"`
+	// LockoutThreshold is number of failed sign-ins in a row locking the account
+	LockoutThreshold   int           `mapstructure:"lockout_threshold" flag:"lockout-threshold" usage:"failed sign-ins in a row locking the account, 0 disables lockout"`
+	LockoutDuration    time.Duration `mapstructure:"lockout_duration" flag:"lockout-duration" usage:"first lock of the account, it doubles with every next failure"`
+	MaxLockoutDuration time.Duration `mapstructure:"lockout_max_duration" flag:"lockout-max-duration" usage:"maximum lock of the account"`
+}
+
+type RentConfig struct {
+	GeoSearch                 string        `mapstructure:"geo_search" flag:"geo-search" usage:"implementation of transport search by location: haversine or postgis"`
+	ReservationGracePeriod    time.Duration `mapstructure:"reservation_grace_period" flag:"reservation-grace" usage:"how long reservation waits to be converted into rent"`
+	MaxReservationDuration    time.Duration `mapstructure:"max_reservation_duration" flag:"max-reservation-duration" usage:"maximum length of the reserved window"`
+	MaxReservationAdvance     time.Duration `mapstructure:"max_reservation_advance" flag:"max-reservation-advance" usage:"how far in the future reservation can start"`
+	ReservationExpireInterval time.Duration `mapstructure:"reservation_expire_interval" flag:"reservation-expire-interval" usage:"how often reservations not converted into rent are expired"`
+	AutoEndInterval           time.Duration `mapstructure:"auto_end_interval" flag:"rent-auto-end-interval" usage:"how often rents exceeding available money are checked"`
+}
+
+// PricingConfig is used when pricing policy of the transport does not set the value
+type PricingConfig struct {
+	MinutesHoldPeriod time.Duration `mapstructure:"minutes_hold_period" flag:"minutes-hold-period" usage:"rent time whose price is held when per-minute rent starts"`
+	DaysHoldPeriod    time.Duration `mapstructure:"days_hold_period" flag:"days-hold-period" usage:"rent time whose price is held when per-day rent starts"`
+}
+
+type PaymentConfig struct {
+	Gateway       string `mapstructure:"gateway" flag:"payment-gateway" usage:"payment gateway for top-ups: none or fake (requires payment-dev-mode)"`
+	DevMode       bool   `mapstructure:"dev_mode" flag:"payment-dev-mode" usage:"enable fake payment gateway and simulation of payments, for development and tests only"`
+	WebhookSecret string `mapstructure:"webhook_secret" flag:"payment-webhook-secret" usage:"secret for signatures of payment webhooks, random if empty" secret:"true"`
+	WebhookURL    string `mapstructure:"webhook_url" flag:"payment-webhook-url" usage:"url where fake gateway sends webhooks"`
+}
+
+type IdempotencyConfig struct {
+	Store         string        `mapstructure:"store" flag:"idempotency-store" usage:"storage of idempotency keys: postgres or memory"`
+	TTL           time.Duration `mapstructure:"ttl" flag:"idempotency-ttl" usage:"how long responses are replayed for the same idempotency key"`
+	LockTimeout   time.Duration `mapstructure:"lock_timeout" flag:"idempotency-lock-timeout" usage:"how long idempotency key is locked by the request, after that a retry can take the key which was not completed"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval" flag:"idempotency-sweep-interval" usage:"period of removing expired idempotency keys from postgres"`
+}
+
+type MigrationsConfig struct {
+	Dir string `mapstructure:"dir" flag:"migrations-dir" usage:"directory where migrate create writes new migrations"`
+}
+
+type LogConfig struct {
+	Level  string `mapstructure:"level" flag:"log-level" usage:"minimal level of logs: debug, info, warn or error"`
+	Format string `mapstructure:"format" flag:"log-format" usage:"format of logs: text or json"`
+	// SlowQueryThreshold is duration after which sql query is logged as slow
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold" flag:"log-slow-query-threshold" usage:"sql queries running longer are logged with warn level, 0 disables it"`
+}
+
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled" flag:"metrics-enabled" usage:"serve prometheus metrics"`
+	Path    string `mapstructure:"path" flag:"metrics-path" usage:"path of prometheus metrics endpoint"`
+}
+
+// RateLimitConfig sets token buckets refilled in Period, requests are counted per route
+type RateLimitConfig struct {
+	Enabled      bool          `mapstructure:"enabled" flag:"rate-limit-enabled" usage:"limit rate of requests"`
+	Period       time.Duration `mapstructure:"period" flag:"rate-limit-period" usage:"period the limits of requests are set for"`
+	IPRequests   int           `mapstructure:"ip_requests" flag:"rate-limit-ip" usage:"requests to a route from one ip in a period"`
+	UserRequests int           `mapstructure:"user_requests" flag:"rate-limit-user" usage:"requests to a route of one user in a period"`
+	AuthRequests int           `mapstructure:"auth_requests" flag:"rate-limit-auth" usage:"sign in, sign up and refresh requests from one ip in a period"`
+}
+
+type TracingConfig struct {
+	Exporter     string `mapstructure:"exporter" flag:"tracing-exporter" usage:"where spans are exported: otlp, stdout or none"`
+	OTLPEndpoint string `mapstructure:"otlp_endpoint" flag:"tracing-otlp-endpoint" usage:"host:port of OTLP/HTTP collector"`
+	OTLPInsecure bool   `mapstructure:"otlp_insecure" flag:"tracing-otlp-insecure" usage:"export spans to OTLP collector over plain HTTP"`
+	ServiceName  string `mapstructure:"service_name" flag:"tracing-service-name" usage:"service name of exported spans"`
+}
+
+// Proxies returns trusted proxies listed in TrustedProxies
+func (cfg HTTPConfig) Proxies() []string {
+	proxies := []string{}
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// EnvPrefix is the prefix of environment variables
+const EnvPrefix = "SIMBIRGO_"
+
+const redacted = "******"
+
+// Default returns config used when nothing is set
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:            ":80",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 15 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ReadyTimeout:    2 * time.Second,
+		},
+		DB: DBConfig{
+			User:            "postgres",
+			Password:        "postgres",
+			Host:            "localhost",
+			DBName:          "postgres",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+			ConnMaxIdleTime: 10 * time.Minute,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:     15 * time.Minute,
+			RefreshTokenTTL:    30 * 24 * time.Hour,
+			RevocationStore:    "postgres",
+			LockoutThreshold:   5,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+		Rent: RentConfig{
+			GeoSearch:                 "haversine",
+			ReservationGracePeriod:    15 * time.Minute,
+			MaxReservationDuration:    24 * time.Hour,
+			MaxReservationAdvance:     30 * 24 * time.Hour,
+			ReservationExpireInterval: time.Minute,
+			AutoEndInterval:           time.Minute,
+		},
+		Pricing: PricingConfig{
+			MinutesHoldPeriod: time.Hour,
+			DaysHoldPeriod:    24 * time.Hour,
+		},
+		Payment: PaymentConfig{
+			Gateway:    "none",
+			WebhookURL: "http://localhost:80/api/Payment/Webhook",
+		},
+		Idempotency: IdempotencyConfig{
+			Store:         "postgres",
+			TTL:           24 * time.Hour,
+			LockTimeout:   5 * time.Minute,
+			SweepInterval: 10 * time.Minute,
+		},
+		Migrations: MigrationsConfig{
+			Dir: "internal/database/migrations",
+		},
+		Log: LogConfig{
+			Level:              "info",
+			Format:             "text",
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			OTLPInsecure: true,
+			ServiceName:  "simbirgo",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:      true,
+			Period:       time.Minute,
+			IPRequests:   300,
+			UserRequests: 120,
+			AuthRequests: 10,
+		},
+	}
 }
 
+// Init loads config from the file set by -config flag or SIMBIRGO_CONFIG variable,
+// environment and command line flags. Remaining arguments are available with flag.Args.
+// It exits if config is invalid. With -print-config it prints the config and exits.
 func Init() *Config {
-	var cfg Config
-
-	var (
-		username string
-		password string
-		dbname   string
-		port     int
-		sslmode  string
-		host     string
-	)
-
-	flag.StringVar(&username, "username", "postgres", "if required username is not postgres, then use this flag")
-	flag.StringVar(&password, "password", "postgres", "if required password is not postgres, then use this flag")
-	flag.StringVar(&dbname, "dbname", "postgres", "if required database is not postgres, then use this flag")
-	flag.IntVar(&port, "port", 5432, "if required port is not 5432, then use this flag")
-	flag.StringVar(&sslmode, "sslmode", "disable", "if required sslmode is not 'disabled', then use this flag")
-	flag.StringVar(&host, "host", "localhost", "if required host is not localhost, then use this flag")
-
-	flag.Parse()
-
-	cfg.User = username
-	cfg.Password = password
-	cfg.DBName = dbname
-	cfg.Port = port
-	cfg.SSLMode = sslmode
-	cfg.Host = host
-	return &cfg
+	cfg, printConfig, err := load(flag.CommandLine, os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if printConfig {
+		out, err := cfg.Redacted()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Print(out)
+		os.Exit(0)
+	}
+	return cfg
+}
+
+func load(fs *flag.FlagSet, args []string, getenv func(string) string) (*Config, bool, error) {
+	op := "config.load()"
+	cfg := Default()
+	fields := configFields(&cfg)
+
+	// flags are applied after the file and environment, so they are collected first
+	flagValues := map[string]string{}
+	var configFile string
+	var printConfig bool
+	fs.StringVar(&configFile, "config", getenv(EnvPrefix+"CONFIG"), "path to config file in yaml or toml format")
+	fs.BoolVar(&printConfig, "print-config", false, "print config with redacted secrets and exit")
+	for _, field := range fields {
+		if field.flag == "" {
+			continue
+		}
+		fs.Var(flagValue{field: field, values: flagValues}, field.flag, field.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if configFile != "" {
+		values, err := readFile(configFile)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", op, err)
+		}
+		for key, value := range values {
+			field, ok := fields[key]
+			if !ok {
+				return nil, false, fmt.Errorf("%s: unknown key %s in %s", op, key, configFile)
+			}
+			if err := field.set(value); err != nil {
+				return nil, false, fmt.Errorf("%s: %s in %s: %w", op, key, configFile, err)
+			}
+		}
+	}
+
+	for key, field := range fields {
+		value := getenv(envName(key))
+		if value == "" {
+			continue
+		}
+		if err := field.set(value); err != nil {
+			return nil, false, fmt.Errorf("%s: %s: %w", op, envName(key), err)
+		}
+	}
+
+	for key, value := range flagValues {
+		if err := fields[key].set(value); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, false, fmt.Errorf("%s: invalid config: %w", op, err)
+	}
+	return &cfg, printConfig, nil
+}
+
+// Validate checks all values and returns all found problems
+func (cfg Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value))
+	}
+
+	check(cfg.HTTP.Addr != "", "http.addr must be set")
+	check(cfg.HTTP.ReadTimeout >= 0, "http.read_timeout must not be negative")
+	check(cfg.HTTP.WriteTimeout >= 0, "http.write_timeout must not be negative")
+	check(cfg.HTTP.IdleTimeout >= 0, "http.idle_timeout must not be negative")
+	check(cfg.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(cfg.HTTP.DrainDelay >= 0, "http.drain_delay must not be negative")
+	check(cfg.HTTP.ReadyTimeout > 0, "http.ready_timeout must be positive")
+	for _, proxy := range cfg.HTTP.Proxies() {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, fmt.Sprintf("http.trusted_proxies has invalid ip or cidr %q", proxy))
+	}
+
+	check(cfg.DB.Host != "", "db.host must be set")
+	check(cfg.DB.User != "", "db.user must be set")
+	check(cfg.DB.DBName != "", "db.dbname must be set")
+	check(cfg.DB.Port > 0 && cfg.DB.Port <= 65535, "db.port must be between 1 and 65535")
+	oneOf("db.sslmode", cfg.DB.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	check(cfg.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(cfg.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(cfg.DB.MaxOpenConns == 0 || cfg.DB.MaxIdleConns <= cfg.DB.MaxOpenConns, "db.max_idle_conns must not exceed db.max_open_conns")
+	check(cfg.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+	check(cfg.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time must not be negative")
+
+	check(cfg.Auth.AccessTokenTTL > 0, "auth.access_token_ttl must be positive")
+	check(cfg.Auth.RefreshTokenTTL > cfg.Auth.AccessTokenTTL, "auth.refresh_token_ttl must be longer than auth.access_token_ttl")
+	check(cfg.Auth.JWTSigningKid == "" || cfg.Auth.JWTKeysDir != "" || cfg.Auth.JWTSecret != "",
+		"auth.jwt_signing_kid requires auth.jwt_keys_dir or auth.jwt_secret")
+	check(!cfg.Auth.JWTDevKey || (cfg.Auth.JWTKeysDir == "" && cfg.Auth.JWTSecret == ""),
+		"auth.jwt_dev_key can't be used with auth.jwt_keys_dir or auth.jwt_secret")
+	oneOf("auth.revocation_store", cfg.Auth.RevocationStore, "postgres", "memory")
+	check(cfg.Auth.LockoutThreshold >= 0, "auth.lockout_threshold must not be negative")
+	check(cfg.Auth.LockoutDuration > 0, "auth.lockout_duration must be positive")
+	check(cfg.Auth.MaxLockoutDuration >= cfg.Auth.LockoutDuration, "auth.lockout_max_duration must not be shorter than auth.lockout_duration")
+
+	oneOf("rent.geo_search", cfg.Rent.GeoSearch, "haversine", "postgis")
+	check(cfg.Rent.ReservationGracePeriod > 0, "rent.reservation_grace_period must be positive")
+	check(cfg.Rent.MaxReservationDuration > 0, "rent.max_reservation_duration must be positive")
+	check(cfg.Rent.MaxReservationAdvance > 0, "rent.max_reservation_advance must be positive")
+	check(cfg.Rent.ReservationExpireInterval > 0, "rent.reservation_expire_interval must be positive")
+	check(cfg.Rent.AutoEndInterval > 0, "rent.auto_end_interval must be positive")
+
+	check(cfg.Pricing.MinutesHoldPeriod >= 0, "pricing.minutes_hold_period must not be negative")
+	check(cfg.Pricing.DaysHoldPeriod >= 0, "pricing.days_hold_period must not be negative")
+
+	oneOf("payment.gateway", cfg.Payment.Gateway, "none", "fake")
+	check(cfg.Payment.Gateway != "fake" || cfg.Payment.DevMode, "payment.gateway fake requires payment.dev_mode")
+	if u, err := url.Parse(cfg.Payment.WebhookURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("payment.webhook_url must be absolute url, got %q", cfg.Payment.WebhookURL))
+	}
+
+	oneOf("idempotency.store", cfg.Idempotency.Store, "postgres", "memory")
+	check(cfg.Idempotency.TTL > 0, "idempotency.ttl must be positive")
+	check(cfg.Idempotency.LockTimeout > cfg.HTTP.WriteTimeout, "idempotency.lock_timeout must be greater than http.write_timeout")
+	check(cfg.Idempotency.SweepInterval > 0, "idempotency.sweep_interval must be positive")
+
+	check(cfg.Migrations.Dir != "", "migrations.dir must be set")
+
+	oneOf("log.level", cfg.Log.Level, "debug", "info", "warn", "error")
+	oneOf("log.format", cfg.Log.Format, "text", "json")
+	check(cfg.Log.SlowQueryThreshold >= 0, "log.slow_query_threshold must not be negative")
+
+	check(strings.HasPrefix(cfg.Metrics.Path, "/"), "metrics.path must start with /")
+
+	check(cfg.RateLimit.Period > 0, "rate_limit.period must be positive")
+	check(cfg.RateLimit.IPRequests > 0, "rate_limit.ip_requests must be positive")
+	check(cfg.RateLimit.UserRequests > 0, "rate_limit.user_requests must be positive")
+	check(cfg.RateLimit.AuthRequests > 0, "rate_limit.auth_requests must be positive")
+
+	oneOf("tracing.exporter", cfg.Tracing.Exporter, "otlp", "stdout", "none")
+	check(cfg.Tracing.Exporter != "otlp" || cfg.Tracing.OTLPEndpoint != "", "tracing.otlp_endpoint must be set for otlp exporter")
+	check(cfg.Tracing.ServiceName != "", "tracing.service_name must be set")
+
+	return errors.Join(errs...)
+}
+
+// Redacted returns config in yaml format with secrets replaced
+func (cfg Config) Redacted() (string, error) {
+	op := "config.Redacted()"
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+	for _, field := range orderedFields(&cfg) {
+		sectionKey, key, _ := strings.Cut(field.key, ".")
+		section, ok := sections[sectionKey]
+		if !ok {
+			section = &yaml.Node{Kind: yaml.MappingNode}
+			sections[sectionKey] = section
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: sectionKey}, section)
+		}
+		value := field.String()
+		if field.secret && value != "" {
+			value = redacted
+		}
+		section.Content = append(section.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value, Style: scalarStyle(field.value)})
+	}
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return string(out), nil
+}
+
+// scalarStyle quotes strings, so values like "off" are not read as booleans
+func scalarStyle(v reflect.Value) yaml.Style {
+	if v.Kind() == reflect.String {
+		return yaml.DoubleQuotedStyle
+	}
+	return 0
+}
+
+// field is a settable value of the config with its dotted key
+type field struct {
+	key    string
+	flag   string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+func (f field) set(value any) error {
+	raw, ok := value.(string)
+	if !ok {
+		raw = fmt.Sprint(value)
+	}
+	switch {
+	case f.value.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(raw)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		f.value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+func (f field) String() string {
+	if f.value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(f.value.Int()).String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// configFields returns fields of cfg by their keys
+func configFields(cfg *Config) map[string]field {
+	fields := map[string]field{}
+	for _, f := range orderedFields(cfg) {
+		fields[f.key] = f
+	}
+	return fields
+}
+
+// orderedFields returns fields of cfg in order of declaration
+func orderedFields(cfg *Config) []field {
+	var fields []field
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionKey := root.Type().Field(i).Tag.Get("mapstructure")
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j).Tag
+			fields = append(fields, field{
+				key:    sectionKey + "." + tag.Get("mapstructure"),
+				flag:   tag.Get("flag"),
+				usage:  tag.Get("usage"),
+				secret: tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readFile reads yaml or toml file, chosen by the extension, into dotted keys
+func readFile(path string) (map[string]any, error) {
+	op := "config.readFile()"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format %s, use yaml or toml", op, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse %s: %w", op, path, err)
+	}
+
+	values := map[string]any{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]any) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flatten(key, nested, values)
+			continue
+		}
+		values[key] = value
+	}
+}
+
+// flagValue keeps raw value of the flag, so it is applied over the file and environment
+type flagValue struct {
+	field  field
+	values map[string]string
+}
+
+func (v flagValue) String() string {
+	if v.field.value.IsValid() {
+		return v.field.String()
+	}
+	return ""
+}
+
+func (v flagValue) Set(raw string) error {
+	probe := field{value: reflect.New(v.field.value.Type()).Elem()}
+	if err := probe.set(raw); err != nil {
+		return err
+	}
+	v.values[v.field.key] = raw
+	return nil
 }
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		return path
+	}
+	yamlFile := write("config.yaml", `
+http:
+  addr: ":8080"
+db:
+  host: db.local
+  port: 6432
+  password: from-file
+auth:
+  access_token_ttl: 5m
+`)
+	tomlFile := write("config.toml", `
+[db]
+host = "toml.local"
+max_open_conns = 10
+
+[log]
+format = "json"
+`)
+	unknownKey := write("unknown.yaml", "db:\n  hots: db.local\n")
+	invalidValue := write("invalid.yaml", "db:\n  port: many\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, Default(), *cfg)
+			},
+		},
+		{
+			name: "yaml file",
+			args: []string{"-config", yamlFile},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":8080", cfg.HTTP.Addr)
+				assert.Equal(t, "db.local", cfg.DB.Host)
+				assert.Equal(t, 6432, cfg.DB.Port)
+				assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL)
+				assert.Equal(t, "postgres", cfg.DB.User)
+			},
+		},
+		{
+			name: "toml file from environment",
+			env:  map[string]string{"SIMBIRGO_CONFIG": tomlFile},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "toml.local", cfg.DB.Host)
+				assert.Equal(t, 10, cfg.DB.MaxOpenConns)
+				assert.Equal(t, "json", cfg.Log.Format)
+			},
+		},
+		{
+			name: "environment overrides file",
+			args: []string{"-config", yamlFile},
+			env:  map[string]string{"SIMBIRGO_DB_HOST": "env.local", "SIMBIRGO_AUTH_ACCESS_TOKEN_TTL": "1m"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "env.local", cfg.DB.Host)
+				assert.Equal(t, 6432, cfg.DB.Port)
+				assert.Equal(t, time.Minute, cfg.Auth.AccessTokenTTL)
+			},
+		},
+		{
+			name: "flags override environment and file",
+			args: []string{"-config", yamlFile, "-host", "flag.local", "-http-addr", ":9090", "migrate", "up"},
+			env:  map[string]string{"SIMBIRGO_DB_HOST": "env.local"},
+			check: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, "flag.local", cfg.DB.Host)
+				assert.Equal(t, ":9090", cfg.HTTP.Addr)
+				assert.Equal(t, "from-file", cfg.DB.Password)
+			},
+		},
+		{
+			name:    "unknown key in file",
+			args:    []string{"-config", unknownKey},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value in file",
+			args:    []string{"-config", invalidValue},
+			wantErr: true,
+		},
+		{
+			name:    "invalid flag value",
+			args:    []string{"-access-token-ttl", "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid environment value",
+			env:     map[string]string{"SIMBIRGO_DB_PORT": "many"},
+			wantErr: true,
+		},
+		{
+			name:    "validation failed",
+			args:    []string{"-revocation-store", "redis", "-port", "0"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			getenv := func(key string) string { return tt.env[key] }
+
+			cfg, _, err := load(fs, tt.args, getenv)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.RefreshTokenTTL = cfg.Auth.AccessTokenTTL
+	cfg.DB.MaxIdleConns = cfg.DB.MaxOpenConns + 1
+	cfg.Log.Level = "verbose"
+	cfg.Payment.WebhookURL = "/api/Payment/Webhook"
+	cfg.Payment.Gateway = "fake"
+	cfg.Auth.JWTSecret = "secret"
+	cfg.Auth.JWTDevKey = true
+	cfg.HTTP.TrustedProxies = "10.0.0.0/8, 192.168.1.1, proxy.local"
+
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "auth.refresh_token_ttl")
+	assert.ErrorContains(t, err, "db.max_idle_conns")
+	assert.ErrorContains(t, err, "log.level")
+	assert.ErrorContains(t, err, "payment.webhook_url")
+	assert.ErrorContains(t, err, "payment.gateway fake requires payment.dev_mode")
+	assert.ErrorContains(t, err, "auth.jwt_dev_key can't be used with auth.jwt_keys_dir or auth.jwt_secret")
+	assert.ErrorContains(t, err, `http.trusted_proxies has invalid ip or cidr "proxy.local"`)
+	assert.NotContains(t, err.Error(), "192.168.1.1")
+
+	assert.NoError(t, Default().Validate())
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.DB.Password = "db-password"
+	cfg.Auth.JWTSecret = "jwt-secret"
+
+	out, err := cfg.Redacted()
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "db-password")
+	assert.NotContains(t, out, "jwt-secret")
+	assert.Contains(t, out, `password: "******"`)
+	assert.Contains(t, out, `webhook_secret: ""`)
+
+	// printed config can be loaded back
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(out), 0o600))
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loaded, _, err := load(fs, []string{"-config", path}, func(string) string { return "" })
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.HTTP, loaded.HTTP)
+	assert.Equal(t, cfg.Rent, loaded.Rent)
+}
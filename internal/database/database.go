@@ -1,249 +1,859 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"simbirGo/internal/config"
 	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Database struct {
 	db *gorm.DB
 }
 
-func Connect(cfg *config.Config) (Database, error) {
-	op := "database.Connect()"
+// WithTx runs fn in a transaction, Database passed to fn is bound to it.
+// The transaction is rolled back if fn returns error or panics.
+func (db Database) WithTx(ctx context.Context, fn func(tx Database) error) error {
+	return db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(Database{db: tx})
+	})
+}
+
+// NewTransactor adapts WithTx to the repository interface R of a usecase.
+// Database must implement R.
+func NewTransactor[R any](db Database) func(ctx context.Context, fn func(r R) error) error {
+	return func(ctx context.Context, fn func(r R) error) error {
+		return db.WithTx(ctx, func(tx Database) error {
+			r, ok := any(tx).(R)
+			if !ok {
+				return fmt.Errorf("database.NewTransactor(): %T does not implement repository", tx)
+			}
+			return fn(r)
+		})
+	}
+}
+
+// wrapError converts gorm errors to entities.ErrNotFound, entities.ErrConflict or entities.ErrInternal
+func wrapError(op string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%s: %w", op, entities.ErrNotFound)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fmt.Errorf("%s: %w: %w", op, entities.ErrConflict, err)
+	default:
+		return fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+}
+
+// Open connects to the database without checking the schema, it is used to run migrations
+func Open(cfg *config.Config, log *slog.Logger) (Database, error) {
+	op := "database.Open()"
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s ",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
+		cfg.DB.Host, cfg.DB.User, cfg.DB.Password, cfg.DB.DBName, cfg.DB.Port, cfg.DB.SSLMode)
 
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN: dsn,
-	}), &gorm.Config{})
+	}), &gorm.Config{
+		TranslateError: true,
+		Logger:         newLogger(log, cfg.Log.SlowQueryThreshold),
+	})
 
 	if err != nil {
 		return Database{}, fmt.Errorf("%s: failed to connect to postgres: %w", op, err)
 	}
 
-	if err := db.AutoMigrate(&models.Rent{}, &models.RentType{}, &models.User{},
-		&models.Transport{}, models.TransportType{}); err != nil {
-		return Database{}, fmt.Errorf("%s: failed to migrate database: %w", op, err)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return Database{}, fmt.Errorf("%s: %w", op, err)
 	}
-	//fill transport type [Car, Bike, Scooter]
-	var tType models.TransportType
-	db.Find(&tType, "type = 'Car'")
-	if tType.Id == 0 {
-		db.Create(&models.TransportType{Type: "Car"})
+	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+	return Database{db: db}, nil
+}
+
+// Connect connects to the database and fails if the schema is behind migrations of this build
+func Connect(cfg *config.Config, log *slog.Logger) (Database, error) {
+	op := "database.Connect()"
+	db, err := Open(cfg, log)
+	if err != nil {
+		return Database{}, fmt.Errorf("%s: %w", op, err)
 	}
-	db.Find(&tType, "type = 'Scooter'")
-	if tType.Id == 0 {
-		db.Create(&models.TransportType{Type: "Scooter"})
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return Database{}, fmt.Errorf("%s: %w", op, err)
 	}
-	db.Find(&tType, "type = 'Bike'")
-	if tType.Id == 0 {
-		db.Create(&models.TransportType{Type: "Bike"})
+	if err := migrator.CheckSchema(); err != nil {
+		return Database{}, fmt.Errorf("%s: %w", op, err)
 	}
+	return db, nil
+}
 
-	// var transpotType []models.TransportType
-	// db.Find(&transpotType)
-	// if len(transpotType) != 3 {
-	// 	db.Create(&models.TransportType{Type: "Car"})
-	// 	db.Create(&models.TransportType{Type: "Bike"})
-	// 	db.Create(&models.TransportType{Type: "Scooter"})
-	// }
+// auth repository
+func (db Database) FindUserByUsername(ctx context.Context, username string) (models.User, error) {
+	op := "database.FindUserByUsername()"
+	var user models.User
+	if err := db.db.WithContext(ctx).Take(&user, "username=?", username).Error; err != nil {
+		return models.User{}, wrapError(op, err)
+	}
+	return user, nil
+}
 
-	var rentType models.RentType
-	db.Find(&rentType, "type = 'Minutes'")
-	if rentType.Id == 0 {
-		db.Create(&models.RentType{Type: "Minutes"})
+func (db Database) FindUserById(ctx context.Context, id uint) (models.User, error) {
+	op := "database.FindUserById()"
+	var user models.User
+	if err := db.db.WithContext(ctx).Take(&user, "id=?", id).Error; err != nil {
+		return models.User{}, wrapError(op, err)
 	}
-	db.Find(&rentType, "type = 'Days'")
-	if rentType.Id == 0 {
-		db.Create(&models.RentType{Type: "Days"})
+	return user, nil
+}
+
+func (db Database) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	op := "database.CreateUser()"
+	if err := db.db.WithContext(ctx).Create(&user).Error; err != nil {
+		return models.User{}, wrapError(op, err)
 	}
+	return user, nil
+}
 
-	log.Println("succesfully migrate database")
-	return Database{db: db}, nil
+// SaveUser saves user without balance, balance is changed by journal entries only.
+// Failed sign-ins are changed by their own methods, so they are not overwritten either.
+func (db Database) SaveUser(ctx context.Context, user models.User) error {
+	op := "database.SaveUser()"
+	if err := db.db.WithContext(ctx).Omit("Balance", "DebtSince", "FailedSignIns", "LockedUntil").Save(&user).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
 }
 
-// auth repository
-func (db Database) FindUserByUsername(username string) models.User {
+// RecordFailedSignIn increments failed sign-ins of the user and returns the new number
+func (db Database) RecordFailedSignIn(ctx context.Context, userId uint) (int, error) {
+	op := "database.RecordFailedSignIn()"
 	var user models.User
-	db.db.Find(&user, "username=?", username)
-	return user
+	res := db.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_sign_ins"}}}).
+		Where("id = ?", userId).
+		UpdateColumn("failed_sign_ins", gorm.Expr("failed_sign_ins + 1"))
+	if res.Error != nil {
+		return 0, wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return 0, fmt.Errorf("%s: %w", op, entities.ErrNotFound)
+	}
+	return user.FailedSignIns, nil
 }
 
-func (db Database) FindUserById(id uint) models.User {
-	var user models.User
-	db.db.Find(&user, "id=?", id)
-	return user
+func (db Database) LockUser(ctx context.Context, userId uint, until time.Time) error {
+	op := "database.LockUser()"
+	err := db.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userId).
+		UpdateColumn("locked_until", until).Error
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
 }
 
-func (db Database) CreateUser(user models.User) models.User {
-	db.db.Create(&user)
-	return user
+// ResetFailedSignIns forgets failed sign-ins of the user and unlocks it
+func (db Database) ResetFailedSignIns(ctx context.Context, userId uint) error {
+	op := "database.ResetFailedSignIns()"
+	err := db.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userId).
+		UpdateColumns(map[string]any{"failed_sign_ins": 0, "locked_until": nil}).Error
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
 }
 
-func (db Database) SaveUser(user models.User) {
-	db.db.Save(&user)
+func (db Database) GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]models.User, pagination.Meta, error) {
+	op := "database.GetUsers()"
+	query := db.db.WithContext(ctx).Model(&models.User{})
+	if filter.UsernamePrefix != "" {
+		query = query.Where("username LIKE ?", escapeLike(filter.UsernamePrefix)+"%")
+	}
+	if filter.IsAdmin != nil {
+		query = query.Where("is_admin = ?", *filter.IsAdmin)
+	}
+	users, meta, err := paginate(query, page, userSortKeys, func(u models.User) uint { return u.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return users, meta, nil
 }
 
-func (db Database) GetUsers(start uint, count int) []models.User {
-	var users []models.User
-	db.db.Limit(int(count)).Order("id").Find(&users, "id>=?", start)
-	return users
+func (db Database) DeleteUser(ctx context.Context, id uint) error {
+	op := "database.DeleteUser()"
+	res := db.db.WithContext(ctx).Delete(&models.User{}, "id=?", id)
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
-func (db Database) DeleteUser(id uint) {
-	db.db.Delete(&models.User{}, "id=?", id)
+func (db Database) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	op := "database.CreateRefreshToken()"
+	if err := db.db.WithContext(ctx).Create(&token).Error; err != nil {
+		return models.RefreshToken{}, wrapError(op, err)
+	}
+	return token, nil
+}
+
+func (db Database) FindRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error) {
+	op := "database.FindRefreshToken()"
+	var token models.RefreshToken
+	if err := db.db.WithContext(ctx).Take(&token, "token_hash=?", hash).Error; err != nil {
+		return models.RefreshToken{}, wrapError(op, err)
+	}
+	return token, nil
+}
+
+// MarkRefreshTokenUsed marks token as used, returns false if it has been already used
+func (db Database) MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error) {
+	op := "database.MarkRefreshTokenUsed()"
+	res := db.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, wrapError(op, res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (db Database) RevokeRefreshFamily(ctx context.Context, familyId string) error {
+	op := "database.RevokeRefreshFamily()"
+	err := db.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) RevokeUserRefreshTokens(ctx context.Context, userId uint) error {
+	op := "database.RevokeUserRefreshTokens()"
+	err := db.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+// role repository
+func (db Database) FindRoles(ctx context.Context, page pagination.Request) ([]models.Role, pagination.Meta, error) {
+	op := "database.FindRoles()"
+	query := db.db.WithContext(ctx).Model(&models.Role{})
+	roles, meta, err := paginate(query, page, roleSortKeys, func(r models.Role) uint { return r.Id }, "Permissions")
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return roles, meta, nil
+}
+
+func (db Database) FindRoleById(ctx context.Context, id uint) (models.Role, error) {
+	op := "database.FindRoleById()"
+	var role models.Role
+	if err := db.db.WithContext(ctx).Preload("Permissions").Take(&role, "id=?", id).Error; err != nil {
+		return models.Role{}, wrapError(op, err)
+	}
+	return role, nil
+}
+
+func (db Database) FindRoleByName(ctx context.Context, name string) (models.Role, error) {
+	op := "database.FindRoleByName()"
+	var role models.Role
+	if err := db.db.WithContext(ctx).Preload("Permissions").Take(&role, "name=?", name).Error; err != nil {
+		return models.Role{}, wrapError(op, err)
+	}
+	return role, nil
+}
+
+func (db Database) CreateRole(ctx context.Context, role models.Role) (models.Role, error) {
+	op := "database.CreateRole()"
+	if err := db.db.WithContext(ctx).Create(&role).Error; err != nil {
+		return models.Role{}, wrapError(op, err)
+	}
+	return role, nil
+}
+
+// SaveRole saves role and replaces its permissions
+func (db Database) SaveRole(ctx context.Context, role models.Role) error {
+	op := "database.SaveRole()"
+	err := db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(&role).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.RolePermission{}, "role_id = ?", role.Id).Error; err != nil {
+			return err
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) DeleteRole(ctx context.Context, id uint) error {
+	op := "database.DeleteRole()"
+	if err := db.db.WithContext(ctx).Delete(&models.Role{}, "id=?", id).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) FindUserRoles(ctx context.Context, userId uint) ([]models.UserRole, error) {
+	op := "database.FindUserRoles()"
+	var userRoles []models.UserRole
+	err := db.db.WithContext(ctx).Preload("Role.Permissions").Order("role_id").Find(&userRoles, "user_id = ?", userId).Error
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	return userRoles, nil
+}
+
+// AssignRole grants role to user, granting admin role also sets users.is_admin
+func (db Database) AssignRole(ctx context.Context, userRole models.UserRole) error {
+	op := "database.AssignRole()"
+	err := db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("User", "Role").Create(&userRole).Error
+		if err != nil {
+			return err
+		}
+		return db.syncAdminFlag(tx, userRole.UserId)
+	})
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) UnassignRole(ctx context.Context, userId, roleId uint) error {
+	op := "database.UnassignRole()"
+	err := db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.UserRole{}, "user_id = ? AND role_id = ?", userId, roleId).Error; err != nil {
+			return err
+		}
+		return db.syncAdminFlag(tx, userId)
+	})
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) syncAdminFlag(tx *gorm.DB, userId uint) error {
+	return tx.Exec(`UPDATE users SET is_admin = EXISTS (
+		SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+		WHERE user_roles.user_id = users.id AND roles.name = ? AND user_roles.operator_id = 0
+	) WHERE id = ?`, entities.AdminRole, userId).Error
 }
 
 // transport repository
-func (db Database) FindTypeById(id uint) string {
+func (db Database) FindTypeById(ctx context.Context, id uint) (string, error) {
+	op := "database.FindTypeById()"
 	var trType models.TransportType
-	db.db.Find(&trType, "id=?", id)
-	return trType.Type
+	if err := db.db.WithContext(ctx).Take(&trType, "id=?", id).Error; err != nil {
+		return "", wrapError(op, err)
+	}
+	return trType.Type, nil
 }
 
-func (db Database) FindTypeByName(typeName string) uint {
+func (db Database) FindTypeByName(ctx context.Context, typeName string) (uint, error) {
+	op := "database.FindTypeByName()"
 	var trType models.TransportType
-	db.db.Find(&trType, "type=?", typeName)
-	return trType.Id
+	if err := db.db.WithContext(ctx).Take(&trType, "type=?", typeName).Error; err != nil {
+		return 0, wrapError(op, err)
+	}
+	return trType.Id, nil
 }
 
-func (db Database) FindTranspot(id uint) models.Transport {
+func (db Database) FindTranspot(ctx context.Context, id uint) (models.Transport, error) {
+	op := "database.FindTranspot()"
 	var transport models.Transport
-	db.db.Find(&transport, "id=?", id)
-	return transport
+	if err := db.db.WithContext(ctx).Take(&transport, "id=?", id).Error; err != nil {
+		return models.Transport{}, wrapError(op, err)
+	}
+	return transport, nil
 }
 
-func (db Database) CreateTransport(transport models.Transport) models.Transport {
-	db.db.Create(&transport)
-	return transport
+func (db Database) CreateTransport(ctx context.Context, transport models.Transport) (models.Transport, error) {
+	op := "database.CreateTransport()"
+	if err := db.db.WithContext(ctx).Create(&transport).Error; err != nil {
+		return models.Transport{}, wrapError(op, err)
+	}
+	return transport, nil
 }
 
-func (db Database) FindUserTransport(userId, transportId uint) models.Transport {
+func (db Database) FindUserTransport(ctx context.Context, userId, transportId uint) (models.Transport, error) {
+	op := "database.FindUserTransport()"
 	var transport models.Transport
-	db.db.Where("id = ? AND owner_id = ?", transportId, userId).Find(&transport)
-	return transport
+	if err := db.db.WithContext(ctx).Where("id = ? AND owner_id = ?", transportId, userId).Take(&transport).Error; err != nil {
+		return models.Transport{}, wrapError(op, err)
+	}
+	return transport, nil
 }
 
-func (db Database) SaveTransport(transport models.Transport) {
-	db.db.Save(&transport)
+func (db Database) SaveTransport(ctx context.Context, transport models.Transport) error {
+	op := "database.SaveTransport()"
+	if err := db.db.WithContext(ctx).Save(&transport).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
 }
 
-func (db Database) DeleteUserTransport(ownerId, transportId uint) {
-	db.db.Where("owner_id = ? AND id = ?", ownerId, transportId).Delete(&models.Transport{})
+func (db Database) DeleteUserTransport(ctx context.Context, ownerId, transportId uint) error {
+	op := "database.DeleteUserTransport()"
+	res := db.db.WithContext(ctx).Where("owner_id = ? AND id = ?", ownerId, transportId).Delete(&models.Transport{})
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
-func (db Database) FindTranspots(start, count int, transportId uint) []models.Transport {
-	var transports []models.Transport
-	db.db.Where("id >= ? AND type_id = ?", start, transportId).Limit(int(count)).Find(&transports)
-	return transports
+// FindTranspots returns transports of any owner when ownerIds is nil
+func (db Database) FindTranspots(ctx context.Context, typeId uint, ownerIds []uint, page pagination.Request) ([]models.Transport, pagination.Meta, error) {
+	op := "database.FindTranspots()"
+	query := db.db.WithContext(ctx).Model(&models.Transport{}).Where("type_id = ?", typeId)
+	if ownerIds != nil {
+		query = query.Where("owner_id IN ?", ownerIds)
+	}
+	transports, meta, err := paginate(query, page, transportSortKeys, func(t models.Transport) uint { return t.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return transports, meta, nil
 }
 
-func (db Database) DeleteTransport(id uint) {
-	db.db.Delete(&models.Transport{}, "id=?", id)
+func (db Database) DeleteTransport(ctx context.Context, id uint) error {
+	op := "database.DeleteTransport()"
+	res := db.db.WithContext(ctx).Delete(&models.Transport{}, "id=?", id)
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
 // rent repository
-func (db Database) FindAvalibleTransports(lat, long, radius float64, typeId uint) []models.Transport {
-	var query string
-	if typeId == 0 {
-		query = fmt.Sprintf("SELECT * FROM transports WHERE SQRT(power((latitude - %f), 2) + power((longitude - %f), 2)) <= %f AND can_be_rented = true", lat, long, radius)
-	} else {
-		query = fmt.Sprintf("SELECT * FROM transports WHERE SQRT(power((latitude - %f), 2) + power((longitude - %f), 2)) <= %f AND type_id = %d AND can_be_rented = true", lat, long, radius, typeId)
-	}
-
-	rows, err := db.db.Raw(query).Rows()
-	if err != nil {
-		log.Printf("err : %s", err.Error())
-		return []models.Transport{}
-	}
-	defer rows.Close()
-	var transports []models.Transport
-
-	for rows.Next() {
-		var (
-			id          uint
-			ownerId     uint
-			typeId      uint
-			canBeRented bool
-			model       string
-			color       string
-			identifier  string
-			description string
-			latitude    float64
-			longitude   float64
-			minutePrice float64
-			dayPrice    float64
-		)
-
-		err := rows.Scan(&id, &ownerId, &typeId, &model, &color, &identifier, &description,
-			&latitude, &longitude, &minutePrice, &dayPrice, &canBeRented)
-		if err != nil {
-			log.Println("database.FindAvalibleTransports(): ", err.Error())
-		}
-
-		transports = append(transports, models.Transport{
-			Id:          id,
-			OwnerId:     ownerId,
-			TypeId:      typeId,
-			CanBeRented: canBeRented,
-			Model:       model,
-			Color:       color,
-			Identifier:  identifier,
-			Description: description,
-			Latitude:    latitude,
-			Longitude:   longitude,
-			MinutePrice: minutePrice,
-			DayPrice:    dayPrice,
-		})
+func (db Database) FindRentById(ctx context.Context, id int) (models.Rent, error) {
+	op := "database.FindRentById()"
+	var rent models.Rent
+	if err := db.db.WithContext(ctx).Take(&rent, "id = ?", id).Error; err != nil {
+		return models.Rent{}, wrapError(op, err)
 	}
-	return transports
+	return rent, nil
 }
 
-func (db Database) FindRentById(id int) models.Rent {
+// FindRentForUpdate locks the rent row until the end of transaction
+func (db Database) FindRentForUpdate(ctx context.Context, id int) (models.Rent, error) {
+	op := "database.FindRentForUpdate()"
 	var rent models.Rent
-	db.db.Find(&rent, "id = ?", id)
-	return rent
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&rent, "id = ?", id).Error
+	if err != nil {
+		return models.Rent{}, wrapError(op, err)
+	}
+	return rent, nil
 }
 
-func (db Database) FindUserRents(id int) []models.Rent {
+// FindRunningRentsWithHold returns rents which are not ended and have money held for them
+func (db Database) FindRunningRentsWithHold(ctx context.Context) ([]models.Rent, error) {
+	op := "database.FindRunningRentsWithHold()"
 	var rents []models.Rent
-	db.db.Order("time_start").Find(&rents, "user_id = ?", id)
-	return rents
+	if err := db.db.WithContext(ctx).Order("id").Find(&rents, "time_end IS NULL AND hold > 0").Error; err != nil {
+		return nil, wrapError(op, err)
+	}
+	return rents, nil
 }
 
-func (db Database) FindTransportRents(id int) []models.Rent {
-	var rents []models.Rent
-	db.db.Order("time_start").Find(&rents, "transport_id = ?", id)
-	return rents
+// CountActiveRents returns number of rents which are not ended
+func (db Database) CountActiveRents(ctx context.Context) (int64, error) {
+	op := "database.CountActiveRents()"
+	var count int64
+	if err := db.db.WithContext(ctx).Model(&models.Rent{}).Where("time_end IS NULL").Count(&count).Error; err != nil {
+		return 0, wrapError(op, err)
+	}
+	return count, nil
+}
+
+// CountAvailableTransports returns number of transports which can be rented by type name,
+// types without such transports have zero count
+func (db Database) CountAvailableTransports(ctx context.Context) (map[string]int64, error) {
+	op := "database.CountAvailableTransports()"
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	err := db.db.WithContext(ctx).Model(&models.TransportType{}).
+		Select("transport_types.type, count(transports.id) AS count").
+		Joins("LEFT JOIN transports ON transports.type_id = transport_types.id AND transports.can_be_rented").
+		Group("transport_types.type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// FindTranspotForUpdate locks the transport row until the end of transaction
+func (db Database) FindTranspotForUpdate(ctx context.Context, id uint) (models.Transport, error) {
+	op := "database.FindTranspotForUpdate()"
+	var transport models.Transport
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&transport, "id=?", id).Error
+	if err != nil {
+		return models.Transport{}, wrapError(op, err)
+	}
+	return transport, nil
+}
+
+// FindUserForUpdate locks the user row until the end of transaction
+func (db Database) FindUserForUpdate(ctx context.Context, id uint) (models.User, error) {
+	op := "database.FindUserForUpdate()"
+	var user models.User
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id=?", id).Error
+	if err != nil {
+		return models.User{}, wrapError(op, err)
+	}
+	return user, nil
+}
+
+func (db Database) FindUserRents(ctx context.Context, id int, filter entities.RentFilter, page pagination.Request) ([]models.Rent, pagination.Meta, error) {
+	op := "database.FindUserRents()"
+	query := filterRents(db.db.WithContext(ctx).Model(&models.Rent{}).Where("user_id = ?", id), filter)
+	rents, meta, err := paginate(query, page, rentSortKeys, func(r models.Rent) uint { return r.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return rents, meta, nil
+}
+
+func (db Database) FindTransportRents(ctx context.Context, id int, filter entities.RentFilter, page pagination.Request) ([]models.Rent, pagination.Meta, error) {
+	op := "database.FindTransportRents()"
+	query := filterRents(db.db.WithContext(ctx).Model(&models.Rent{}).Where("transport_id = ?", id), filter)
+	rents, meta, err := paginate(query, page, rentSortKeys, func(r models.Rent) uint { return r.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return rents, meta, nil
+}
+
+// filterRents applies filters of rents history to the query
+func filterRents(query *gorm.DB, filter entities.RentFilter) *gorm.DB {
+	if filter.From != nil {
+		query = query.Where("time_start >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("time_start < ?", *filter.To)
+	}
+	switch filter.Status {
+	case entities.RentStatusActive:
+		query = query.Where("time_end IS NULL")
+	case entities.RentStatusEnded:
+		query = query.Where("time_end IS NOT NULL")
+	}
+	if filter.TransportType != "" {
+		query = query.Where("transport_id IN (?)", query.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Transport{}).Select("transports.id").
+			Joins("JOIN transport_types ON transport_types.id = transports.type_id").
+			Where("transport_types.type = ?", filter.TransportType))
+	}
+	return query
+}
+
+func (db Database) CreateRent(ctx context.Context, rent models.Rent) (models.Rent, error) {
+	op := "database.CreateRent()"
+	if err := db.db.WithContext(ctx).Create(&rent).Error; err != nil {
+		return models.Rent{}, wrapError(op, err)
+	}
+	return rent, nil
+}
+
+func (db Database) SaveRent(ctx context.Context, rent models.Rent) error {
+	op := "database.SaveRent()"
+	if err := db.db.WithContext(ctx).Save(&rent).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) DeleteRent(ctx context.Context, id int) error {
+	op := "database.DeleteRent()"
+	res := db.db.WithContext(ctx).Delete(&models.Rent{}, "id = ?", id)
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// reservation repository
+func (db Database) FindReservationById(ctx context.Context, id uint) (models.Reservation, error) {
+	op := "database.FindReservationById()"
+	var reservation models.Reservation
+	if err := db.db.WithContext(ctx).Take(&reservation, "id = ?", id).Error; err != nil {
+		return models.Reservation{}, wrapError(op, err)
+	}
+	return reservation, nil
+}
+
+// FindReservationForUpdate locks the reservation row until the end of transaction
+func (db Database) FindReservationForUpdate(ctx context.Context, id uint) (models.Reservation, error) {
+	op := "database.FindReservationForUpdate()"
+	var reservation models.Reservation
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&reservation, "id = ?", id).Error
+	if err != nil {
+		return models.Reservation{}, wrapError(op, err)
+	}
+	return reservation, nil
+}
+
+func (db Database) FindUserReservations(ctx context.Context, userId uint, page pagination.Request) ([]models.Reservation, pagination.Meta, error) {
+	op := "database.FindUserReservations()"
+	query := db.db.WithContext(ctx).Model(&models.Reservation{}).Where("user_id = ?", userId)
+	reservations, meta, err := paginate(query, page, reservationSortKeys, func(r models.Reservation) uint { return r.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return reservations, meta, nil
+}
+
+// FindActiveReservations returns active reservations of transports intersecting with [from, to)
+func (db Database) FindActiveReservations(ctx context.Context, transportIds []uint, from, to time.Time) ([]models.Reservation, error) {
+	op := "database.FindActiveReservations()"
+	var reservations []models.Reservation
+	err := db.db.WithContext(ctx).Order("time_start").
+		Where("transport_id IN ? AND status = ?", transportIds, entities.ReservationActive).
+		Where("time_start < ? AND time_end > ?", to, from).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	return reservations, nil
+}
+
+func (db Database) CreateReservation(ctx context.Context, reservation models.Reservation) (models.Reservation, error) {
+	op := "database.CreateReservation()"
+	if err := db.db.WithContext(ctx).Create(&reservation).Error; err != nil {
+		return models.Reservation{}, wrapError(op, err)
+	}
+	return reservation, nil
+}
+
+func (db Database) SaveReservation(ctx context.Context, reservation models.Reservation) error {
+	op := "database.SaveReservation()"
+	if err := db.db.WithContext(ctx).Save(&reservation).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+// ExpireReservations marks active reservations started before the time as expired
+// and returns number of expired reservations
+func (db Database) ExpireReservations(ctx context.Context, startedBefore time.Time) (int64, error) {
+	op := "database.ExpireReservations()"
+	res := db.db.WithContext(ctx).Model(&models.Reservation{}).
+		Where("status = ? AND time_start < ?", entities.ReservationActive, startedBefore).
+		Update("status", entities.ReservationExpired)
+	if res.Error != nil {
+		return 0, wrapError(op, res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// pricing repository
+func (db Database) FindPricingPolicies(ctx context.Context, page pagination.Request) ([]models.PricingPolicy, pagination.Meta, error) {
+	op := "database.FindPricingPolicies()"
+	query := db.db.WithContext(ctx).Model(&models.PricingPolicy{})
+	policies, meta, err := paginate(query, page, pricingPolicySortKeys, func(p models.PricingPolicy) uint { return p.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return policies, meta, nil
+}
+
+func (db Database) FindPricingPolicyById(ctx context.Context, id uint) (models.PricingPolicy, error) {
+	op := "database.FindPricingPolicyById()"
+	var policy models.PricingPolicy
+	if err := db.db.WithContext(ctx).Take(&policy, "id = ?", id).Error; err != nil {
+		return models.PricingPolicy{}, wrapError(op, err)
+	}
+	return policy, nil
+}
+
+// FindTransportPricingPolicy returns policy of the transport or, if there is none, of its type
+func (db Database) FindTransportPricingPolicy(ctx context.Context, transportId, typeId uint) (models.PricingPolicy, error) {
+	op := "database.FindTransportPricingPolicy()"
+	var policy models.PricingPolicy
+	err := db.db.WithContext(ctx).Where("transport_id = ? OR transport_type_id = ?", transportId, typeId).
+		Order("transport_id IS NULL").Take(&policy).Error
+	if err != nil {
+		return models.PricingPolicy{}, wrapError(op, err)
+	}
+	return policy, nil
+}
+
+func (db Database) CreatePricingPolicy(ctx context.Context, policy models.PricingPolicy) (models.PricingPolicy, error) {
+	op := "database.CreatePricingPolicy()"
+	if err := db.db.WithContext(ctx).Create(&policy).Error; err != nil {
+		return models.PricingPolicy{}, wrapError(op, err)
+	}
+	return policy, nil
+}
+
+func (db Database) SavePricingPolicy(ctx context.Context, policy models.PricingPolicy) error {
+	op := "database.SavePricingPolicy()"
+	if err := db.db.WithContext(ctx).Save(&policy).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
 }
 
-func (db Database) CreateRent(rent models.Rent) models.Rent {
-	db.db.Create(&rent)
-	return rent
+func (db Database) DeletePricingPolicy(ctx context.Context, id uint) error {
+	op := "database.DeletePricingPolicy()"
+	res := db.db.WithContext(ctx).Delete(&models.PricingPolicy{}, "id = ?", id)
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
-func (db Database) SaveRent(rent models.Rent) {
-	db.db.Save(&rent)
+// promo code repository
+func (db Database) FindPromoCodes(ctx context.Context, page pagination.Request) ([]models.PromoCode, pagination.Meta, error) {
+	op := "database.FindPromoCodes()"
+	query := db.db.WithContext(ctx).Model(&models.PromoCode{})
+	promoCodes, meta, err := paginate(query, page, promoCodeSortKeys, func(p models.PromoCode) uint { return p.Id }, "TransportTypes")
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return promoCodes, meta, nil
 }
 
-func (db Database) DeleteRent(id int) {
-	db.db.Delete(&models.Rent{}, "id = ?", id)
+func (db Database) FindPromoCodeById(ctx context.Context, id uint) (models.PromoCode, error) {
+	op := "database.FindPromoCodeById()"
+	var promoCode models.PromoCode
+	if err := db.db.WithContext(ctx).Preload("TransportTypes").Take(&promoCode, "id = ?", id).Error; err != nil {
+		return models.PromoCode{}, wrapError(op, err)
+	}
+	return promoCode, nil
 }
 
-func (db Database) FindRentTypeById(id uint) string {
+// FindPromoCodeForUpdate locks the promo code row until the end of transaction,
+// so its uses are counted by one transaction at a time
+func (db Database) FindPromoCodeForUpdate(ctx context.Context, code string) (models.PromoCode, error) {
+	op := "database.FindPromoCodeForUpdate()"
+	var promoCode models.PromoCode
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("TransportTypes").
+		Take(&promoCode, "code = ?", code).Error
+	if err != nil {
+		return models.PromoCode{}, wrapError(op, err)
+	}
+	return promoCode, nil
+}
+
+// CountPromoCodeUses returns number of rents with the promo code, userId = 0 means rents of all users
+func (db Database) CountPromoCodeUses(ctx context.Context, promoCodeId, userId uint) (int64, error) {
+	op := "database.CountPromoCodeUses()"
+	query := db.db.WithContext(ctx).Model(&models.Rent{}).Where("promo_code_id = ?", promoCodeId)
+	if userId != 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, wrapError(op, err)
+	}
+	return count, nil
+}
+
+func (db Database) CreatePromoCode(ctx context.Context, promoCode models.PromoCode) (models.PromoCode, error) {
+	op := "database.CreatePromoCode()"
+	if err := db.db.WithContext(ctx).Create(&promoCode).Error; err != nil {
+		return models.PromoCode{}, wrapError(op, err)
+	}
+	return promoCode, nil
+}
+
+// SavePromoCode saves promo code and replaces its transport types
+func (db Database) SavePromoCode(ctx context.Context, promoCode models.PromoCode) error {
+	op := "database.SavePromoCode()"
+	err := db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("TransportTypes").Save(&promoCode).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.PromoCodeTransportType{}, "promo_code_id = ?", promoCode.Id).Error; err != nil {
+			return err
+		}
+		if len(promoCode.TransportTypes) == 0 {
+			return nil
+		}
+		return tx.Create(&promoCode.TransportTypes).Error
+	})
+	if err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) DeletePromoCode(ctx context.Context, id uint) error {
+	op := "database.DeletePromoCode()"
+	res := db.db.WithContext(ctx).Delete(&models.PromoCode{}, "id = ?", id)
+	if res.Error != nil {
+		return wrapError(op, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return wrapError(op, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (db Database) FindRentTypeById(ctx context.Context, id uint) (string, error) {
+	op := "database.FindRentTypeById()"
 	var rentType models.RentType
-	db.db.Find(&rentType, "id=?", id)
-	return rentType.Type
+	if err := db.db.WithContext(ctx).Take(&rentType, "id=?", id).Error; err != nil {
+		return "", wrapError(op, err)
+	}
+	return rentType.Type, nil
 }
 
-func (db Database) FindRentTypeByName(typeName string) uint {
+func (db Database) FindRentTypeByName(ctx context.Context, typeName string) (uint, error) {
+	op := "database.FindRentTypeByName()"
 	var rentType models.RentType
-	db.db.Find(&rentType, "type=?", typeName)
-	return rentType.Id
+	if err := db.db.WithContext(ctx).Take(&rentType, "type=?", typeName).Error; err != nil {
+		return 0, wrapError(op, err)
+	}
+	return rentType.Id, nil
 }
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks that the database accepts connections
+func (db Database) Ping(ctx context.Context) error {
+	op := "database.Ping()"
+	sqlDB, err := db.db.DB()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CheckMigrations returns error if some migrations of this build are not applied
+func (db Database) CheckMigrations(ctx context.Context) error {
+	op := "database.CheckMigrations()"
+	migrator, err := NewMigrator(Database{db: db.db.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := migrator.CheckSchema(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/idempotency"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyStore keeps idempotency keys in postgres, so retries are replayed
+// by any instance of the server and after restart
+type IdempotencyStore struct {
+	db          *gorm.DB
+	lockTimeout time.Duration
+}
+
+// NewIdempotencyStore creates the store, key which is not completed in lockTimeout
+// can be taken again, so the request is not blocked until expiration if the server crashed
+func NewIdempotencyStore(db Database, lockTimeout time.Duration) IdempotencyStore {
+	return IdempotencyStore{db: db.db, lockTimeout: lockTimeout}
+}
+
+func (is IdempotencyStore) Begin(key, fingerprint string, expiresAt time.Time) (idempotency.Record, bool, error) {
+	op := "database.IdempotencyStore.Begin()"
+	now := time.Now()
+	// expired key and stale lock of the same request are taken over in place
+	res := is.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"fingerprint":  fingerprint,
+			"completed":    false,
+			"status_code":  0,
+			"content_type": "",
+			"body":         nil,
+			"created_at":   now,
+			"locked_at":    now,
+			"expires_at":   expiresAt,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr(
+			"idempotency_keys.expires_at <= ? OR (NOT idempotency_keys.completed AND idempotency_keys.locked_at <= ? AND idempotency_keys.fingerprint = ?)",
+			now, now.Add(-is.lockTimeout), fingerprint,
+		)}},
+	}).Create(&models.IdempotencyKey{Key: key, Fingerprint: fingerprint, CreatedAt: now, LockedAt: now, ExpiresAt: expiresAt})
+	if res.Error != nil {
+		return idempotency.Record{}, false, fmt.Errorf("%s: %w", op, res.Error)
+	}
+	if res.RowsAffected > 0 {
+		return idempotency.Record{}, true, nil
+	}
+
+	var keyModel models.IdempotencyKey
+	if err := is.db.First(&keyModel, "key = ?", key).Error; err != nil {
+		return idempotency.Record{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return idempotency.Record{
+		Fingerprint: keyModel.Fingerprint,
+		Completed:   keyModel.Completed,
+		Response: idempotency.Response{
+			Status:      keyModel.StatusCode,
+			ContentType: keyModel.ContentType,
+			Body:        keyModel.Body,
+		},
+		LockedAt:  keyModel.LockedAt,
+		ExpiresAt: keyModel.ExpiresAt,
+	}, false, nil
+}
+
+func (is IdempotencyStore) Complete(key string, response idempotency.Response) error {
+	op := "database.IdempotencyStore.Complete()"
+	err := is.db.Model(&models.IdempotencyKey{}).Where("key = ?", key).Updates(map[string]any{
+		"completed":    true,
+		"status_code":  response.Status,
+		"content_type": response.ContentType,
+		"body":         response.Body,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (is IdempotencyStore) Release(key string) error {
+	op := "database.IdempotencyStore.Release()"
+	err := is.db.Delete(&models.IdempotencyKey{}, "key = ? AND completed = false", key).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired keys, it returns number of removed keys
+func (is IdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	op := "database.IdempotencyStore.DeleteExpired()"
+	res := is.db.WithContext(ctx).Delete(&models.IdempotencyKey{}, "expires_at <= ?", now)
+	if res.Error != nil {
+		return 0, fmt.Errorf("%s: %w", op, res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	// dry run builds statements without connecting to the database
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	require.NoError(t, gormDB.Callback().Create().After("gorm:create").Register("test:statements", record))
+	require.NoError(t, gormDB.Callback().Delete().After("gorm:delete").Register("test:statements", record))
+	store := NewIdempotencyStore(Database{db: gormDB}, time.Minute)
+
+	_, _, err = store.Begin("1:key", "fingerprint", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = store.DeleteExpired(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`INSERT INTO "idempotency_keys" ("key","fingerprint","completed","status_code","content_type","body","created_at","locked_at","expires_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) ` +
+			`ON CONFLICT ("key") DO UPDATE SET "body"=$10,"completed"=$11,"content_type"=$12,"created_at"=$13,"expires_at"=$14,"fingerprint"=$15,"locked_at"=$16,"status_code"=$17 ` +
+			// expired key or stale lock of the same request is taken over
+			`WHERE idempotency_keys.expires_at <= $18 OR (NOT idempotency_keys.completed AND idempotency_keys.locked_at <= $19 AND idempotency_keys.fingerprint = $20) `,
+		`DELETE FROM "idempotency_keys" WHERE expires_at <= $1`,
+	}, statements)
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func walletCode(userId uint) string {
+	return fmt.Sprintf("wallet:%d", userId)
+}
+
+// ledger repository
+// FindWalletAccount returns wallet of the user, it is created on first use
+func (db Database) FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error) {
+	op := "database.FindWalletAccount()"
+	wallet := models.LedgerAccount{Code: walletCode(userId), UserId: &userId}
+	if err := db.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&wallet).Error; err != nil {
+		return models.LedgerAccount{}, wrapError(op, err)
+	}
+	if err := db.db.WithContext(ctx).Take(&wallet, "user_id = ?", userId).Error; err != nil {
+		return models.LedgerAccount{}, wrapError(op, err)
+	}
+	return wallet, nil
+}
+
+func (db Database) FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error) {
+	op := "database.FindSystemAccount()"
+	var account models.LedgerAccount
+	if err := db.db.WithContext(ctx).Take(&account, "code = ? AND user_id IS NULL", code).Error; err != nil {
+		return models.LedgerAccount{}, wrapError(op, err)
+	}
+	return account, nil
+}
+
+// LockAccount locks the account row until the end of transaction
+func (db Database) LockAccount(ctx context.Context, id uint) error {
+	op := "database.LockAccount()"
+	var account models.LedgerAccount
+	if err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&account, "id = ?", id).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) FindAccountBalance(ctx context.Context, id uint) (float64, error) {
+	op := "database.FindAccountBalance()"
+	var balance float64
+	err := db.db.WithContext(ctx).Model(&models.Posting{}).Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ?", id).Scan(&balance).Error
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+	return balance, nil
+}
+
+// CreateJournalEntry saves the entry with its postings and updates cached balances of users.
+// Entries are never changed or deleted.
+func (db Database) CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	op := "database.CreateJournalEntry()"
+	var sum float64
+	for _, posting := range entry.Postings {
+		sum += posting.Amount
+	}
+	if math.Abs(sum) > 1e-6 {
+		return models.JournalEntry{}, fmt.Errorf("%s: %w: entry is not balanced: %f", op, entities.ErrInternal, sum)
+	}
+
+	err := db.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		for _, posting := range entry.Postings {
+			var account models.LedgerAccount
+			if err := tx.Take(&account, "id = ?", posting.AccountId).Error; err != nil {
+				return err
+			}
+			if account.UserId == nil {
+				continue
+			}
+			err := tx.Model(&models.User{}).Where("id = ?", *account.UserId).
+				UpdateColumn("balance", gorm.Expr("balance + ?", posting.Amount)).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.User{}).Where("id = ?", *account.UserId).
+				UpdateColumn("debt_since", gorm.Expr("CASE WHEN balance < 0 THEN COALESCE(debt_since, ?) END", entry.CreatedAt)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.JournalEntry{}, wrapError(op, err)
+	}
+	return entry, nil
+}
+
+// FindUserPostings returns postings of the user's wallet with their entries, newest first
+func (db Database) FindUserPostings(ctx context.Context, userId uint, page pagination.Request) ([]models.Posting, pagination.Meta, error) {
+	op := "database.FindUserPostings()"
+	query := db.db.WithContext(ctx).Model(&models.Posting{}).
+		Where("account_id IN (SELECT id FROM ledger_accounts WHERE user_id = ?)", userId)
+	postings, meta, err := paginate(query, page, postingSortKeys, func(p models.Posting) uint { return p.Id }, "Entry")
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return postings, meta, nil
+}
+
+func (db Database) HasRentEntry(ctx context.Context, rentId uint, kind string) (bool, error) {
+	op := "database.HasRentEntry()"
+	var count int64
+	err := db.db.WithContext(ctx).Model(&models.JournalEntry{}).Where("rent_id = ? AND kind = ?", rentId, kind).Count(&count).Error
+	if err != nil {
+		return false, wrapError(op, err)
+	}
+	return count > 0, nil
+}
+
+func (db Database) HasRentEntries(ctx context.Context, rentId uint) (bool, error) {
+	op := "database.HasRentEntries()"
+	var count int64
+	err := db.db.WithContext(ctx).Model(&models.JournalEntry{}).Where("rent_id = ?", rentId).Count(&count).Error
+	if err != nil {
+		return false, wrapError(op, err)
+	}
+	return count > 0, nil
+}
+
+func (db Database) FindAccountBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	op := "database.FindAccountBalances()"
+	var balances []models.AccountBalance
+	err := db.db.WithContext(ctx).Model(&models.LedgerAccount{}).
+		Select("ledger_accounts.id AS account_id, ledger_accounts.code, ledger_accounts.user_id, COALESCE(SUM(postings.amount), 0) AS balance").
+		Joins("LEFT JOIN postings ON postings.account_id = ledger_accounts.id").
+		Group("ledger_accounts.id").Order("ledger_accounts.id").
+		Scan(&balances).Error
+	if err != nil {
+		return nil, wrapError(op, err)
+	}
+	return balances, nil
+}
+
+// FindUserBalances returns all users with cached balances
+func (db Database) FindUserBalances(ctx context.Context) ([]models.User, error) {
+	op := "database.FindUserBalances()"
+	var users []models.User
+	if err := db.db.WithContext(ctx).Select("id", "username", "balance").Order("id").Find(&users).Error; err != nil {
+		return nil, wrapError(op, err)
+	}
+	return users, nil
+}
+
+// FindDebtors returns users with negative balance, the biggest debts first
+func (db Database) FindDebtors(ctx context.Context, page pagination.Request) ([]models.User, pagination.Meta, error) {
+	op := "database.FindDebtors()"
+	query := db.db.WithContext(ctx).Model(&models.User{}).Where("balance < 0")
+	users, meta, err := paginate(query, page, debtorSortKeys, func(u models.User) uint { return u.Id })
+	if err != nil {
+		return nil, pagination.Meta{}, wrapPageError(op, err)
+	}
+	return users, meta, nil
+}
+
+// FindTotalDebt sums debts of all users
+func (db Database) FindTotalDebt(ctx context.Context) (float64, error) {
+	op := "database.FindTotalDebt()"
+	var total float64
+	err := db.db.WithContext(ctx).Model(&models.User{}).Where("balance < 0").
+		Select("COALESCE(SUM(-balance), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, wrapError(op, err)
+	}
+	return total, nil
+}
+
+// top-up repository
+func (db Database) CreateTopUp(ctx context.Context, topUp models.TopUp) (models.TopUp, error) {
+	op := "database.CreateTopUp()"
+	if err := db.db.WithContext(ctx).Create(&topUp).Error; err != nil {
+		return models.TopUp{}, wrapError(op, err)
+	}
+	return topUp, nil
+}
+
+func (db Database) SaveTopUp(ctx context.Context, topUp models.TopUp) error {
+	op := "database.SaveTopUp()"
+	if err := db.db.WithContext(ctx).Save(&topUp).Error; err != nil {
+		return wrapError(op, err)
+	}
+	return nil
+}
+
+func (db Database) FindTopUpById(ctx context.Context, id uint) (models.TopUp, error) {
+	op := "database.FindTopUpById()"
+	var topUp models.TopUp
+	if err := db.db.WithContext(ctx).Take(&topUp, "id = ?", id).Error; err != nil {
+		return models.TopUp{}, wrapError(op, err)
+	}
+	return topUp, nil
+}
+
+// FindTopUpByIntentForUpdate locks the top-up created for the intent of the gateway
+func (db Database) FindTopUpByIntentForUpdate(ctx context.Context, gateway, intentId string) (models.TopUp, error) {
+	op := "database.FindTopUpByIntentForUpdate()"
+	var topUp models.TopUp
+	err := db.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Take(&topUp, "gateway = ? AND intent_id = ?", gateway, intentId).Error
+	if err != nil {
+		return models.TopUp{}, wrapError(op, err)
+	}
+	return topUp, nil
+}
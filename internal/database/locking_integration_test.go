@@ -0,0 +1,189 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/logging"
+	"simbirGo/internal/metrics"
+	"simbirGo/internal/usecase/rentUsecase"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// lockWait is how long the second transaction waits for the row locked by the first one
+const lockWait = 500 * time.Millisecond
+
+// openTestDB connects to the database from SIMBIRGO_TEST_DSN and applies migrations
+func openTestDB(t *testing.T) Database {
+	t.Helper()
+	dsn := os.Getenv("SIMBIRGO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("SIMBIRGO_TEST_DSN is not set")
+	}
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	db := Database{db: gormDB}
+
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return db
+}
+
+func TestForUpdate_Locks(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	user, err := db.CreateUser(ctx, models.User{Username: fmt.Sprintf("lock_%d", time.Now().UnixNano()), Password: "secret"})
+	require.NoError(t, err)
+	var transportType models.TransportType
+	require.NoError(t, db.db.Take(&transportType, "type = ?", "Car").Error)
+	transport, err := db.CreateTransport(ctx, models.Transport{
+		OwnerId:     user.Id,
+		TypeId:      transportType.Id,
+		CanBeRented: true,
+		Model:       "Lock",
+		Color:       "Black",
+		Identifier:  user.Username,
+		Description: "row locking test",
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.db.Delete(&models.Transport{}, transport.Id)
+		db.db.Delete(&models.User{}, user.Id)
+	})
+
+	tests := []struct {
+		name string
+		lock func(ctx context.Context, tx Database) error
+	}{
+		{
+			name: "transport",
+			lock: func(ctx context.Context, tx Database) error {
+				_, err := tx.FindTranspotForUpdate(ctx, transport.Id)
+				return err
+			},
+		},
+		{
+			name: "user",
+			lock: func(ctx context.Context, tx Database) error {
+				_, err := tx.FindUserForUpdate(ctx, user.Id)
+				return err
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			locked := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				done <- db.WithTx(ctx, func(tx Database) error {
+					if err := testCase.lock(ctx, tx); err != nil {
+						close(locked)
+						return err
+					}
+					close(locked)
+					<-release
+					return nil
+				})
+			}()
+			<-locked
+
+			// the row is held by the first transaction, so the second one waits until its deadline
+			waitCtx, cancel := context.WithTimeout(ctx, lockWait)
+			start := time.Now()
+			err := db.WithTx(waitCtx, func(tx Database) error { return testCase.lock(waitCtx, tx) })
+			cancel()
+			assert.Error(t, err, "row must be locked by the first transaction")
+			assert.GreaterOrEqual(t, time.Since(start), lockWait)
+
+			close(release)
+			require.NoError(t, <-done)
+
+			// after commit the lock is released
+			err = db.WithTx(ctx, func(tx Database) error { return testCase.lock(ctx, tx) })
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateNewRent_NoDoubleBooking(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	prefix := fmt.Sprintf("booking_%d", time.Now().UnixNano())
+
+	owner, err := db.CreateUser(ctx, models.User{Username: prefix + "_owner", Password: "secret"})
+	require.NoError(t, err)
+	var transportType models.TransportType
+	require.NoError(t, db.db.Take(&transportType, "type = ?", "Car").Error)
+	transport, err := db.CreateTransport(ctx, models.Transport{
+		OwnerId:     owner.Id,
+		TypeId:      transportType.Id,
+		CanBeRented: true,
+		Model:       "Booking",
+		Color:       "White",
+		Identifier:  prefix,
+		Description: "double booking test",
+		MinutePrice: 10,
+	})
+	require.NoError(t, err)
+
+	external, err := db.FindSystemAccount(ctx, entities.AccountExternal)
+	require.NoError(t, err)
+	const renters = 10
+	renterIds := make([]uint, renters)
+	for i := range renterIds {
+		renter, err := db.CreateUser(ctx, models.User{Username: fmt.Sprintf("%s_renter_%d", prefix, i), Password: "secret"})
+		require.NoError(t, err)
+		wallet, err := db.FindWalletAccount(ctx, renter.Id)
+		require.NoError(t, err)
+		_, err = db.CreateJournalEntry(ctx, models.NewTransfer(entities.EntryTopUp, external.Id, wallet.Id, 10000, "double booking test"))
+		require.NoError(t, err)
+		renterIds[i] = renter.Id
+	}
+
+	ru := rentUsecase.New(db, NewTransactor[rentUsecase.RentRepository](db), nil, logging.Discard(), metrics.New(), rentUsecase.DefaultOptions())
+
+	// all renters take the same transport at once, row lock lets only the first one in
+	start := make(chan struct{})
+	errs := make([]error, renters)
+	var wg sync.WaitGroup
+	for i, renterId := range renterIds {
+		wg.Add(1)
+		go func(i int, renterId uint) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = ru.CreateNewRent(ctx, renterId, int(transport.Id), "Minutes", "")
+		}(i, renterId)
+	}
+	close(start)
+	wg.Wait()
+
+	started := 0
+	for _, err := range errs {
+		if err == nil {
+			started++
+			continue
+		}
+		assert.ErrorIs(t, err, entities.ErrConflict)
+	}
+	assert.Equal(t, 1, started)
+
+	var rents int64
+	require.NoError(t, db.db.Model(&models.Rent{}).Where("transport_id = ?", transport.Id).Count(&rents).Error)
+	assert.Equal(t, int64(1), rents)
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// logger writes gorm messages to slog. Queries are traced on debug level,
+// queries slower than slowThreshold on warn and failed queries on error.
+// Parameters are never interpolated into queries, so passwords and tokens do not get to logs.
+type logger struct {
+	log           *slog.Logger
+	slowThreshold time.Duration
+}
+
+func newLogger(log *slog.Logger, slowThreshold time.Duration) logger {
+	return logger{log: log, slowThreshold: slowThreshold}
+}
+
+// LogMode is ignored, level of logs is controlled by slog
+func (l logger) LogMode(gormLogger.LogLevel) gormLogger.Interface {
+	return l
+}
+
+func (l logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.log.InfoContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.log.WarnContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.log.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+
+	level := slog.LevelDebug
+	switch {
+	case failed:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	}
+	if !l.log.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("elapsed", elapsed),
+	}
+	msg := "query"
+	switch {
+	case failed:
+		msg = "query failed"
+		attrs = append(attrs, slog.Any("error", err))
+	case slow:
+		msg = "slow query"
+	}
+	l.log.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// ParamsFilter drops parameters of queries before they are logged
+func (l logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"simbirGo/internal/database/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockId is the key of advisory lock held while migration is applied or reverted
+const migrationLockId = 7303412
+
+var (
+	migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationName     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// ErrNoMigrations is returned by Down when there is no applied migration to revert
+var ErrNoMigrations = errors.New("no applied migrations")
+
+// Migration is a versioned change of the schema, Down reverts changes made by Up
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus is the migration with time it was applied, nil if it is pending
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads migrations named <version>_<name>.up.sql and <version>_<name>.down.sql
+// from fsys, sorted by version. Every migration must have both scripts.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	op := "database.LoadMigrations()"
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, file := range files {
+		match := migrationFileName.FindStringSubmatch(file)
+		if match == nil {
+			return nil, fmt.Errorf("%s: invalid migration file name %s", op, file)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%s: invalid version of migration %s", op, file)
+		}
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("%s: migrations %s and %s have the same version", op, migration, file)
+		}
+		if match[3] == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%s: migration %s must have up and down scripts", op, migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// CreateMigration writes empty up and down scripts of the next version to dir
+// and returns their paths
+func CreateMigration(dir, name string) ([]string, error) {
+	op := "database.CreateMigration()"
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("%s: name must contain only lowercase letters, digits and underscores", op)
+	}
+	migrations, err := LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	migration := Migration{Version: 1, Name: name}
+	if len(migrations) > 0 {
+		migration.Version = migrations[len(migrations)-1].Version + 1
+	}
+
+	paths := make([]string, 0, 2)
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s.sql", migration, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		_, err = fmt.Fprintf(file, "-- %s %s\n", migration, direction)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Migrator applies embedded migrations and keeps applied versions in schema_migrations
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db Database) (Migrator, error) {
+	op := "database.NewMigrator()"
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return Migrator{}, fmt.Errorf("%s: %w", op, err)
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return Migrator{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return Migrator{db: db.db, migrations: migrations}, nil
+}
+
+// Status returns all known migrations, applied and pending
+func (m Migrator) Status() ([]MigrationStatus, error) {
+	op := "database.Migrator.Status()"
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns migrations which are not applied yet
+func (m Migrator) Pending() ([]Migration, error) {
+	op := "database.Migrator.Pending()"
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	pending := []Migration{}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies pending migrations in order of versions, each in its own transaction
+func (m Migrator) Up() ([]Migration, error) {
+	op := "database.Migrator.Up()"
+	if err := m.createTable(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	done := []Migration{}
+	for _, migration := range m.migrations {
+		applied := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockId).Error; err != nil {
+				return err
+			}
+			// another instance may have applied it while we waited for the lock
+			versions, err := m.applied(tx)
+			if err != nil {
+				return err
+			}
+			if _, ok := versions[migration.Version]; ok {
+				return nil
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			applied = true
+			return tx.Create(&models.SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("%s: failed to apply %s: %w", op, migration, err)
+		}
+		if applied {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down reverts the last applied migration
+func (m Migrator) Down() (Migration, error) {
+	op := "database.Migrator.Down()"
+	if err := m.createTable(); err != nil {
+		return Migration{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var reverted Migration
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockId).Error; err != nil {
+			return err
+		}
+		var last models.SchemaMigration
+		err := tx.Order("version DESC").Take(&last).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoMigrations
+		}
+		if err != nil {
+			return err
+		}
+
+		migration, ok := m.find(last.Version)
+		if !ok {
+			return fmt.Errorf("migration %04d_%s is unknown to this build", last.Version, last.Name)
+		}
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		reverted = migration
+		return tx.Delete(&models.SchemaMigration{}, "version = ?", migration.Version).Error
+	})
+	if err != nil {
+		return Migration{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return reverted, nil
+}
+
+// CheckSchema returns error if some migrations are not applied
+func (m Migrator) CheckSchema() error {
+	op := "database.Migrator.CheckSchema()"
+	pending, err := m.Pending()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%s: database schema is behind, %d migrations are pending starting from %s, run migrate up",
+			op, len(pending), pending[0])
+	}
+	return nil
+}
+
+func (m Migrator) find(version uint) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m Migrator) createTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`).Error
+}
+
+// applied returns applied versions, schema_migrations may not exist yet
+func (m Migrator) applied(db *gorm.DB) (map[uint]time.Time, error) {
+	var exists bool
+	if err := db.Raw("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	versions := map[uint]time.Time{}
+	if !exists {
+		return versions, nil
+	}
+	var applied []models.SchemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	for _, migration := range applied {
+		versions[migration.Version] = migration.AppliedAt
+	}
+	return versions, nil
+}
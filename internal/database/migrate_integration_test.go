@@ -0,0 +1,143 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"simbirGo/internal/database/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// baseline models are the schema which AutoMigrate created in the first release
+
+type baselineUser struct {
+	Id       uint   `gorm:"primaryKey"`
+	Username string `gorm:"not null; unique"`
+	Password string `gorm:"not null"`
+	IsAdmin  bool   `gorm:"not null"`
+	Balance  float64
+}
+
+func (baselineUser) TableName() string { return "users" }
+
+type baselineTransportType struct {
+	Id   uint   `gorm:"primaryKey"`
+	Type string `gorm:"not null"`
+}
+
+func (baselineTransportType) TableName() string { return "transport_types" }
+
+type baselineRentType struct {
+	Id   uint   `gorm:"primaryKey"`
+	Type string `gorm:"not null"`
+}
+
+func (baselineRentType) TableName() string { return "rent_types" }
+
+type baselineTransport struct {
+	Id            uint         `gorm:"primaryKey"`
+	OwnerId       uint         `gorm:"not null"`
+	Owner         baselineUser `gorm:"foreignKey:OwnerId"`
+	TypeId        uint
+	TransportType baselineTransportType `gorm:"foreignKey:TypeId"`
+	CanBeRented   bool                  `gorm:"not null; type:boolean"`
+	Model         string                `gorm:"not null"`
+	Color         string                `gorm:"not null"`
+	Identifier    string                `gorm:"not null"`
+	Description   string                `gorm:"not null"`
+	Latitude      float64               `gorm:"not null; type: numeric"`
+	Longitude     float64               `gorm:"not null; type: numeric"`
+	MinutePrice   float64
+	DayPrice      float64
+}
+
+func (baselineTransport) TableName() string { return "transports" }
+
+type baselineRent struct {
+	Id          uint              `gorm:"primaryKey"`
+	TransportId uint              `gorm:"not null"`
+	Transport   baselineTransport `gorm:"foreignKey:TransportId"`
+	UserId      uint              `gorm:"not null"`
+	User        baselineUser      `gorm:"foreignKey:UserId; not null"`
+	TimeStart   time.Time         `gorm:"not null; type: timestamptz"`
+	TimeEnd     *time.Time        `gorm:"default:null"`
+	PriceOfUnit float64           `gorm:"not null"`
+	RentTypeId  uint
+	RentType    baselineRentType `gorm:"foreignKey:RentTypeId"`
+	FinalPrice  float64          `gorm:"default:null"`
+}
+
+func (baselineRent) TableName() string { return "rents" }
+
+func TestMigrator_UpgradesBaseline(t *testing.T) {
+	dsn := os.Getenv("SIMBIRGO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("SIMBIRGO_TEST_DSN is not set")
+	}
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	schema := fmt.Sprintf("baseline_%d", time.Now().UnixNano())
+	require.NoError(t, gormDB.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() {
+		gormDB.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	// one connection keeps search_path of the baseline schema for all statements,
+	// public stays in the path for extensions
+	err = gormDB.Connection(func(conn *gorm.DB) error {
+		require.NoError(t, conn.Exec("SET search_path TO "+schema+", public").Error)
+		defer conn.Exec("RESET search_path")
+
+		require.NoError(t, conn.AutoMigrate(&baselineUser{}, &baselineTransportType{}, &baselineRentType{}, &baselineTransport{}, &baselineRent{}))
+		carType := baselineTransportType{Type: "Car"}
+		require.NoError(t, conn.Create(&carType).Error)
+		minutesType := baselineRentType{Type: "Minutes"}
+		require.NoError(t, conn.Create(&minutesType).Error)
+		user := baselineUser{Username: "admin", Password: "secret", IsAdmin: true, Balance: 100}
+		require.NoError(t, conn.Create(&user).Error)
+		transport := baselineTransport{OwnerId: user.Id, TypeId: carType.Id, Model: "Old", Color: "Black", Identifier: "A001AA", Description: "baseline"}
+		require.NoError(t, conn.Create(&transport).Error)
+		end := time.Now()
+		rent := baselineRent{TransportId: transport.Id, UserId: user.Id, TimeStart: end.Add(-time.Hour), TimeEnd: &end, PriceOfUnit: 10, RentTypeId: minutesType.Id, FinalPrice: 600}
+		require.NoError(t, conn.Create(&rent).Error)
+
+		db := Database{db: conn}
+		migrator, err := NewMigrator(db)
+		require.NoError(t, err)
+		_, err = migrator.Up()
+		require.NoError(t, err)
+		assert.NoError(t, migrator.CheckSchema())
+
+		var columns []string
+		err = conn.Raw("SELECT table_name || '.' || column_name FROM information_schema.columns WHERE table_schema = ?", schema).
+			Scan(&columns).Error
+		require.NoError(t, err)
+		assert.Subset(t, columns, []string{
+			"users.debt_since", "users.failed_sign_ins", "users.locked_until",
+			"rents.original_price", "rents.discount", "rents.promo_code_id", "rents.debt", "rents.hold", "rents.tariff",
+		})
+
+		// rows of the baseline are read and written by the current models
+		ctx := context.Background()
+		upgradedUser, err := db.FindUserById(ctx, user.Id)
+		require.NoError(t, err)
+		assert.Equal(t, float64(100), upgradedUser.Balance)
+		assert.Nil(t, upgradedUser.DebtSince)
+		upgradedRent, err := db.FindRentById(ctx, int(rent.Id))
+		require.NoError(t, err)
+		assert.Zero(t, upgradedRent.Hold)
+		assert.Nil(t, upgradedRent.PromoCodeId)
+		_, err = db.CreateRent(ctx, models.Rent{TransportId: transport.Id, UserId: user.Id, TimeStart: time.Now(), PriceOfUnit: 10, RentTypeId: minutesType.Id, Hold: 600})
+		assert.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(data string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(data)} }
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []uint
+		wantErr  bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"0010_add_index.up.sql":   file("CREATE INDEX"),
+				"0010_add_index.down.sql": file("DROP INDEX"),
+				"0002_seed.up.sql":        file("INSERT"),
+				"0002_seed.down.sql":      file("DELETE"),
+				"README.md":               file("not a migration"),
+			},
+			versions: []uint{2, 10},
+		},
+		{
+			name:     "empty directory",
+			fsys:     fstest.MapFS{},
+			versions: []uint{},
+		},
+		{
+			name:    "down script is missing",
+			fsys:    fstest.MapFS{"0001_init.up.sql": file("CREATE TABLE")},
+			wantErr: true,
+		},
+		{
+			name: "versions are duplicated",
+			fsys: fstest.MapFS{
+				"0001_init.up.sql":    file("CREATE TABLE"),
+				"0001_init.down.sql":  file("DROP TABLE"),
+				"0001_other.up.sql":   file("CREATE TABLE"),
+				"0001_other.down.sql": file("DROP TABLE"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			fsys:    fstest.MapFS{"init.sql": file("CREATE TABLE")},
+			wantErr: true,
+		},
+		{
+			name: "zero version",
+			fsys: fstest.MapFS{
+				"0000_init.up.sql":   file("CREATE TABLE"),
+				"0000_init.down.sql": file("DROP TABLE"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.fsys)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			versions := make([]uint, len(migrations))
+			for i, migration := range migrations {
+				versions[i] = migration.Version
+				assert.NotEmpty(t, migration.Up)
+				assert.NotEmpty(t, migration.Down)
+			}
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	fsys, err := fs.Sub(migrationFiles, "migrations")
+	assert.NoError(t, err)
+	migrations, err := LoadMigrations(fsys)
+	assert.NoError(t, err)
+	// versions go one by one, so the order of review is the order of applying
+	for i, migration := range migrations {
+		assert.Equal(t, uint(i+1), migration.Version, migration.String())
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_init.up.sql"), []byte("CREATE TABLE"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_init.down.sql"), []byte("DROP TABLE"), 0o644))
+
+	paths, err := CreateMigration(dir, "add_index")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "0002_add_index.up.sql"), filepath.Join(dir, "0002_add_index.down.sql")}, paths)
+
+	migrations, err := LoadMigrations(os.DirFS(dir))
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+
+	_, err = CreateMigration(dir, "Add Index")
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS top_ups;
+DROP TABLE IF EXISTS postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP TABLE IF EXISTS pricing_policies;
+DROP TABLE IF EXISTS reservations;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS revoked_users;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS rents;
+DROP TABLE IF EXISTS promo_code_transport_types;
+DROP TABLE IF EXISTS promo_codes;
+DROP TABLE IF EXISTS transports;
+DROP TABLE IF EXISTS rent_types;
+DROP TABLE IF EXISTS transport_types;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,226 @@
+-- Initial schema. Tables are created only if they do not exist,
+-- so databases created by AutoMigrate are adopted as is.
+-- Columns added after that schema are added to adopted tables by 0010_upgrade_baseline.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    username text NOT NULL UNIQUE,
+    password text NOT NULL,
+    is_admin boolean NOT NULL,
+    balance decimal,
+    debt_since timestamptz DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transport_types (
+    id bigserial PRIMARY KEY,
+    type text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rent_types (
+    id bigserial PRIMARY KEY,
+    type text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transports (
+    id bigserial PRIMARY KEY,
+    owner_id bigint NOT NULL,
+    type_id bigint,
+    can_be_rented boolean NOT NULL,
+    model text NOT NULL,
+    color text NOT NULL,
+    identifier text NOT NULL,
+    description text NOT NULL,
+    latitude numeric NOT NULL,
+    longitude numeric NOT NULL,
+    minute_price decimal,
+    day_price decimal,
+    CONSTRAINT fk_transports_owner FOREIGN KEY (owner_id) REFERENCES users (id),
+    CONSTRAINT fk_transports_transport_type FOREIGN KEY (type_id) REFERENCES transport_types (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transports_location ON transports (latitude, longitude);
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id bigserial PRIMARY KEY,
+    code text NOT NULL,
+    kind text NOT NULL,
+    value decimal NOT NULL,
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz NOT NULL,
+    max_uses bigint NOT NULL,
+    max_uses_per_user bigint NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_codes_code ON promo_codes (code);
+
+CREATE TABLE IF NOT EXISTS promo_code_transport_types (
+    promo_code_id bigint,
+    transport_type_id bigint,
+    PRIMARY KEY (promo_code_id, transport_type_id),
+    CONSTRAINT fk_promo_codes_transport_types FOREIGN KEY (promo_code_id) REFERENCES promo_codes (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS rents (
+    id bigserial PRIMARY KEY,
+    transport_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    time_start timestamptz NOT NULL,
+    time_end timestamptz DEFAULT NULL,
+    price_of_unit decimal NOT NULL,
+    rent_type_id bigint,
+    final_price decimal DEFAULT NULL,
+    original_price decimal DEFAULT NULL,
+    discount decimal DEFAULT NULL,
+    promo_code_id bigint,
+    debt decimal NOT NULL DEFAULT 0,
+    hold decimal NOT NULL DEFAULT 0,
+    tariff jsonb,
+    CONSTRAINT fk_rents_transport FOREIGN KEY (transport_id) REFERENCES transports (id),
+    CONSTRAINT fk_rents_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_rents_rent_type FOREIGN KEY (rent_type_id) REFERENCES rent_types (id),
+    CONSTRAINT fk_rents_promo_code FOREIGN KEY (promo_code_id) REFERENCES promo_codes (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    family_id text NOT NULL,
+    token_hash text NOT NULL,
+    created_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz DEFAULT NULL,
+    revoked_at timestamptz DEFAULT NULL,
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti text PRIMARY KEY,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS revoked_users (
+    user_id bigint PRIMARY KEY,
+    revoked_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_users_expires_at ON revoked_users (expires_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL UNIQUE,
+    description text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint,
+    permission text,
+    PRIMARY KEY (role_id, permission),
+    CONSTRAINT fk_roles_permissions FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id bigint,
+    role_id bigint,
+    operator_id bigint,
+    PRIMARY KEY (user_id, role_id, operator_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    transport_id bigint NOT NULL,
+    time_start timestamptz NOT NULL,
+    time_end timestamptz NOT NULL,
+    status text NOT NULL,
+    rent_id bigint DEFAULT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_reservations_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_reservations_transport FOREIGN KEY (transport_id) REFERENCES transports (id)
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_user_id ON reservations (user_id);
+CREATE INDEX IF NOT EXISTS idx_reservations_transport_time ON reservations (transport_id, time_start);
+CREATE INDEX IF NOT EXISTS idx_reservations_status ON reservations (status);
+
+CREATE TABLE IF NOT EXISTS pricing_policies (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    transport_type_id bigint,
+    transport_id bigint,
+    unlock_fee decimal,
+    minimum_charge decimal,
+    free_minutes bigint,
+    day_rate_switch boolean,
+    daily_cap decimal,
+    night_multiplier decimal,
+    night_start bigint,
+    night_end bigint,
+    weekend_multiplier decimal,
+    timezone text,
+    minutes_hold decimal,
+    days_hold decimal,
+    CONSTRAINT fk_pricing_policies_transport_type FOREIGN KEY (transport_type_id) REFERENCES transport_types (id),
+    CONSTRAINT fk_pricing_policies_transport FOREIGN KEY (transport_id) REFERENCES transports (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_policies_transport_type_id ON pricing_policies (transport_type_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_policies_transport_id ON pricing_policies (transport_id);
+
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id bigserial PRIMARY KEY,
+    code text NOT NULL,
+    user_id bigint
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_code ON ledger_accounts (code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_user_id ON ledger_accounts (user_id);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id bigserial PRIMARY KEY,
+    kind text NOT NULL,
+    description text NOT NULL,
+    rent_id bigint,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_kind ON journal_entries (kind);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_rent_id ON journal_entries (rent_id);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id bigserial PRIMARY KEY,
+    entry_id bigint NOT NULL,
+    account_id bigint NOT NULL,
+    amount decimal NOT NULL,
+    CONSTRAINT fk_journal_entries_postings FOREIGN KEY (entry_id) REFERENCES journal_entries (id),
+    CONSTRAINT fk_postings_account FOREIGN KEY (account_id) REFERENCES ledger_accounts (id)
+);
+CREATE INDEX IF NOT EXISTS idx_postings_entry_id ON postings (entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account_id ON postings (account_id);
+
+CREATE TABLE IF NOT EXISTS top_ups (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    amount decimal NOT NULL,
+    status text NOT NULL,
+    gateway text NOT NULL,
+    intent_id text,
+    failure_reason text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_top_ups_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_top_ups_user_id ON top_ups (user_id);
+CREATE INDEX IF NOT EXISTS idx_top_ups_status ON top_ups (status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_top_ups_intent ON top_ups (gateway, intent_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key text PRIMARY KEY,
+    fingerprint text NOT NULL,
+    completed boolean NOT NULL DEFAULT false,
+    status_code bigint NOT NULL DEFAULT 0,
+    content_type text NOT NULL DEFAULT '',
+    body bytea,
+    created_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,9 @@
+-- types which are used by transports and rents are kept
+DELETE FROM transport_types
+WHERE type IN ('Car', 'Scooter', 'Bike')
+    AND NOT EXISTS (SELECT 1 FROM transports WHERE transports.type_id = transport_types.id)
+    AND NOT EXISTS (SELECT 1 FROM pricing_policies WHERE pricing_policies.transport_type_id = transport_types.id);
+
+DELETE FROM rent_types
+WHERE type IN ('Minutes', 'Days')
+    AND NOT EXISTS (SELECT 1 FROM rents WHERE rents.rent_type_id = rent_types.id);
@@ -0,0 +1,9 @@
+INSERT INTO transport_types (type)
+SELECT t.type FROM (VALUES (1, 'Car'), (2, 'Scooter'), (3, 'Bike')) AS t (ord, type)
+WHERE NOT EXISTS (SELECT 1 FROM transport_types WHERE transport_types.type = t.type)
+ORDER BY t.ord;
+
+INSERT INTO rent_types (type)
+SELECT t.type FROM (VALUES (1, 'Minutes'), (2, 'Days')) AS t (ord, type)
+WHERE NOT EXISTS (SELECT 1 FROM rent_types WHERE rent_types.type = t.type)
+ORDER BY t.ord;
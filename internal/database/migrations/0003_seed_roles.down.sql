@@ -0,0 +1,2 @@
+-- permissions and assignments are removed with the roles
+DELETE FROM roles WHERE name IN ('admin', 'support', 'fleet_manager', 'finance');
@@ -0,0 +1,35 @@
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'full access'),
+    ('support', 'support agent'),
+    ('fleet_manager', 'manages transports of an operator'),
+    ('finance', 'adjusts balances')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, p.permission FROM roles
+JOIN (VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:manage'),
+    ('admin', 'rents:read'),
+    ('admin', 'rents:end'),
+    ('admin', 'rents:manage'),
+    ('admin', 'transports:manage'),
+    ('admin', 'balances:adjust'),
+    ('admin', 'roles:manage'),
+    ('admin', 'pricing:manage'),
+    ('admin', 'promo:manage'),
+    ('support', 'users:read'),
+    ('support', 'rents:read'),
+    ('support', 'rents:end'),
+    ('fleet_manager', 'transports:manage'),
+    ('finance', 'users:read'),
+    ('finance', 'balances:adjust')
+) AS p (role, permission) ON p.role = roles.name
+ON CONFLICT DO NOTHING;
+
+-- accounts created as admins before roles were introduced get global admin role
+INSERT INTO user_roles (user_id, role_id, operator_id)
+SELECT users.id, roles.id, 0 FROM users
+JOIN roles ON roles.name = 'admin'
+WHERE users.is_admin
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+-- journal entries are immutable, only system accounts without postings are removed
+DELETE FROM ledger_accounts
+WHERE code IN ('external', 'revenue', 'promo', 'holds')
+    AND NOT EXISTS (SELECT 1 FROM postings WHERE postings.account_id = ledger_accounts.id);
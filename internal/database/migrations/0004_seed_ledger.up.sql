@@ -0,0 +1,27 @@
+INSERT INTO ledger_accounts (code) VALUES ('external'), ('revenue'), ('promo'), ('holds')
+ON CONFLICT DO NOTHING;
+
+-- balances accumulated before the ledger was introduced get opening entries,
+-- cached balance is already up to date
+DO $$
+DECLARE
+    u record;
+    v_wallet_id bigint;
+    v_entry_id bigint;
+    v_external_id bigint;
+BEGIN
+    SELECT id INTO v_external_id FROM ledger_accounts WHERE code = 'external';
+    FOR u IN
+        SELECT id, balance FROM users
+        WHERE balance <> 0
+            AND NOT EXISTS (SELECT 1 FROM ledger_accounts WHERE ledger_accounts.user_id = users.id)
+    LOOP
+        INSERT INTO ledger_accounts (code, user_id) VALUES ('wallet:' || u.id, u.id) RETURNING id INTO v_wallet_id;
+        INSERT INTO journal_entries (kind, description, created_at)
+        VALUES ('opening_balance', 'balance before ledger', now()) RETURNING id INTO v_entry_id;
+        INSERT INTO postings (entry_id, account_id, amount) VALUES
+            (v_entry_id, v_external_id, -u.balance),
+            (v_entry_id, v_wallet_id, u.balance);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rent_types_type;
+DROP INDEX IF EXISTS idx_transport_types_type;
@@ -0,0 +1,3 @@
+-- types are looked up by name, so names must be unique
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transport_types_type ON transport_types (type);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rent_types_type ON rent_types (type);
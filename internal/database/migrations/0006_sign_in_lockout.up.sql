@@ -0,0 +1,3 @@
+-- failed sign-ins in a row, the account is locked until locked_until after too many of them
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_sign_ins integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until timestamptz DEFAULT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_username_pattern;
+DROP INDEX IF EXISTS idx_rents_transport_time_start;
+DROP INDEX IF EXISTS idx_rents_user_time_start;
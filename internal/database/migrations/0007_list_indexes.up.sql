@@ -0,0 +1,4 @@
+-- keyset pagination of rents history and users reads the index in sort order
+CREATE INDEX IF NOT EXISTS idx_rents_user_time_start ON rents (user_id, time_start, id);
+CREATE INDEX IF NOT EXISTS idx_rents_transport_time_start ON rents (transport_id, time_start, id);
+CREATE INDEX IF NOT EXISTS idx_users_username_pattern ON users (username text_pattern_ops);
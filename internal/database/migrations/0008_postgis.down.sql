@@ -0,0 +1,2 @@
+-- the extension is kept, other objects of the database may depend on it
+DROP INDEX IF EXISTS idx_transports_geography;
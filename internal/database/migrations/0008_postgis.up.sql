@@ -0,0 +1,20 @@
+-- PostGIS is optional: the extension and the index for geo_search=postgis are created
+-- only where the extension is installed on the server and the role is allowed to create it.
+-- Otherwise they can be created later by a superuser, the server checks them on start.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        RAISE NOTICE 'postgis is not available, skipping';
+        RETURN;
+    END IF;
+    BEGIN
+        CREATE EXTENSION IF NOT EXISTS postgis;
+    EXCEPTION WHEN insufficient_privilege THEN
+        RAISE NOTICE 'not allowed to create postgis extension, skipping';
+        RETURN;
+    END;
+    -- the expression must match transportGeography in transportLocator.go
+    CREATE INDEX IF NOT EXISTS idx_transports_geography ON transports
+        USING GIST ((ST_SetSRID(ST_MakePoint(longitude::float8, latitude::float8), 4326)::geography));
+END
+$$;
@@ -0,0 +1,3 @@
+-- request which took the key holds it until locked_at + lock timeout,
+-- after that a retry can take the key which was not completed
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_at timestamptz NOT NULL DEFAULT now();
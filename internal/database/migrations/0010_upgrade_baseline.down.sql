@@ -0,0 +1,2 @@
+-- columns are part of 0001_init for new databases, so only the index is dropped
+DROP INDEX IF EXISTS idx_rents_promo_code_id;
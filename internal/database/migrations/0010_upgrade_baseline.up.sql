@@ -0,0 +1,20 @@
+-- Tables created by AutoMigrate of the first release are adopted by 0001_init as is,
+-- so columns which were added after it are added here. New databases already have them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS debt_since timestamptz DEFAULT NULL;
+
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS original_price decimal DEFAULT NULL;
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS discount decimal DEFAULT NULL;
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS promo_code_id bigint;
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS debt decimal NOT NULL DEFAULT 0;
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS hold decimal NOT NULL DEFAULT 0;
+ALTER TABLE rents ADD COLUMN IF NOT EXISTS tariff jsonb;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = 'rents'::regclass AND conname = 'fk_rents_promo_code') THEN
+        ALTER TABLE rents ADD CONSTRAINT fk_rents_promo_code
+            FOREIGN KEY (promo_code_id) REFERENCES promo_codes (id) ON DELETE SET NULL;
+    END IF;
+END
+$$;
+CREATE INDEX IF NOT EXISTS idx_rents_promo_code_id ON rents (promo_code_id);
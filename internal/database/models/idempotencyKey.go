@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	Key         string    `gorm:"primaryKey"`
+	Fingerprint string    `gorm:"not null"`
+	Completed   bool      `gorm:"not null; default:false"`
+	StatusCode  int       `gorm:"not null; default:0"`
+	ContentType string    `gorm:"not null; default:''"`
+	Body        []byte    `gorm:"type: bytea"`
+	CreatedAt   time.Time `gorm:"not null; type: timestamptz"`
+	LockedAt    time.Time `gorm:"not null; type: timestamptz"`
+	ExpiresAt   time.Time `gorm:"not null; index; type: timestamptz"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// LedgerAccount is wallet of a user or system account
+type LedgerAccount struct {
+	Id     uint   `gorm:"primaryKey"`
+	Code   string `gorm:"not null; uniqueIndex"`
+	UserId *uint  `gorm:"uniqueIndex"`
+}
+
+// JournalEntry is immutable record of money movement, amounts of its postings sum up to zero
+type JournalEntry struct {
+	Id          uint   `gorm:"primaryKey"`
+	Kind        string `gorm:"not null; index"`
+	Description string `gorm:"not null"`
+	RentId      *uint  `gorm:"index"`
+	CreatedAt   time.Time
+	Postings    []Posting `gorm:"foreignKey:EntryId"`
+}
+
+// Posting changes balance of the account by the amount,
+// positive amount increases the balance
+type Posting struct {
+	Id        uint           `gorm:"primaryKey"`
+	EntryId   uint           `gorm:"not null; index"`
+	Entry     *JournalEntry  `gorm:"foreignKey:EntryId"`
+	AccountId uint           `gorm:"not null; index"`
+	Account   *LedgerAccount `gorm:"foreignKey:AccountId"`
+	Amount    float64        `gorm:"not null"`
+}
+
+// NewTransfer creates entry moving amount of money from one account to another
+func NewTransfer(kind string, fromAccountId, toAccountId uint, amount float64, description string) JournalEntry {
+	return JournalEntry{
+		Kind:        kind,
+		Description: description,
+		Postings: []Posting{
+			{AccountId: fromAccountId, Amount: -amount},
+			{AccountId: toAccountId, Amount: amount},
+		},
+	}
+}
+
+// AccountBalance is sum of postings of the account
+type AccountBalance struct {
+	AccountId uint
+	Code      string
+	UserId    *uint
+	Balance   float64
+}
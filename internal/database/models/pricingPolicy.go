@@ -0,0 +1,15 @@
+package models
+
+import "simbirGo/internal/pricing"
+
+// PricingPolicy is attached either to transport type or to transport,
+// policy of the transport takes precedence
+type PricingPolicy struct {
+	Id              uint           `gorm:"primaryKey"`
+	Name            string         `gorm:"not null"`
+	TransportTypeId *uint          `gorm:"uniqueIndex"`
+	TransportType   *TransportType `gorm:"foreignKey:TransportTypeId"`
+	TransportId     *uint          `gorm:"uniqueIndex"`
+	Transport       *Transport     `gorm:"foreignKey:TransportId; constraint:OnDelete:CASCADE"`
+	Policy          pricing.Policy `gorm:"embedded"`
+}
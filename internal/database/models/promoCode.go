@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type PromoCode struct {
+	Id             uint      `gorm:"primaryKey"`
+	Code           string    `gorm:"not null; uniqueIndex"`
+	Kind           string    `gorm:"not null"`
+	Value          float64   `gorm:"not null"`
+	ValidFrom      time.Time `gorm:"not null; type: timestamptz"`
+	ValidTo        time.Time `gorm:"not null; type: timestamptz"`
+	MaxUses        int       `gorm:"not null"`
+	MaxUsesPerUser int       `gorm:"not null"`
+	// TransportTypes restricts the code to the types, empty means all types
+	TransportTypes []PromoCodeTransportType `gorm:"foreignKey:PromoCodeId; constraint:OnDelete:CASCADE"`
+}
+
+type PromoCodeTransportType struct {
+	PromoCodeId     uint `gorm:"primaryKey; autoIncrement:false"`
+	TransportTypeId uint `gorm:"primaryKey; autoIncrement:false"`
+}
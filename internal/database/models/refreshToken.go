@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	Id        uint       `gorm:"primaryKey"`
+	UserId    uint       `gorm:"not null; index"`
+	User      User       `gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	FamilyId  string     `gorm:"not null; index"`
+	TokenHash string     `gorm:"not null; uniqueIndex"`
+	CreatedAt time.Time  `gorm:"not null; type: timestamptz"`
+	ExpiresAt time.Time  `gorm:"not null; type: timestamptz"`
+	UsedAt    *time.Time `gorm:"default:null; type: timestamptz"`
+	RevokedAt *time.Time `gorm:"default:null; type: timestamptz"`
+}
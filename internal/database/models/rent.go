@@ -1,6 +1,7 @@
 package models
 
 import (
+	"simbirGo/internal/pricing"
 	"time"
 )
 
@@ -16,4 +17,15 @@ type Rent struct {
 	RentTypeId  uint
 	RentType    RentType `gorm:"foreignKey:RentTypeId"`
 	FinalPrice  float64  `gorm:"default:null"`
+	// OriginalPrice is the price before promo code discount
+	OriginalPrice float64    `gorm:"default:null"`
+	Discount      float64    `gorm:"default:null"`
+	PromoCodeId   *uint      `gorm:"index"`
+	PromoCode     *PromoCode `gorm:"foreignKey:PromoCodeId; constraint:OnDelete:SET NULL"`
+	// Debt is part of the final price which was not covered by the balance
+	Debt float64 `gorm:"not null; default:0"`
+	// Hold is money reserved from the balance at the start of the rent
+	Hold float64 `gorm:"not null; default:0"`
+	// Tariff is pricing conditions at the start of the rent
+	Tariff pricing.Tariff `gorm:"serializer:json; type:jsonb"`
 }
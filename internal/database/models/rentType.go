@@ -2,5 +2,5 @@ package models
 
 type RentType struct {
 	Id   uint   `gorm:"primaryKey"`
-	Type string `gorm:"not null"`
+	Type string `gorm:"not null; uniqueIndex"`
 }
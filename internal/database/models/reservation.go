@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Reservation struct {
+	Id          uint      `gorm:"primaryKey"`
+	UserId      uint      `gorm:"not null; index"`
+	User        User      `gorm:"foreignKey:UserId"`
+	TransportId uint      `gorm:"not null; index:idx_reservations_transport_time,priority:1"`
+	Transport   Transport `gorm:"foreignKey:TransportId"`
+	TimeStart   time.Time `gorm:"not null; type: timestamptz; index:idx_reservations_transport_time,priority:2"`
+	TimeEnd     time.Time `gorm:"not null; type: timestamptz"`
+	Status      string    `gorm:"not null; index"`
+	RentId      *uint     `gorm:"default:null"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type RevokedToken struct {
+	Jti       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null; index; type: timestamptz"`
+}
+
+type RevokedUser struct {
+	UserId    uint      `gorm:"primaryKey; autoIncrement:false"`
+	RevokedAt time.Time `gorm:"not null; type: timestamptz"`
+	ExpiresAt time.Time `gorm:"not null; index; type: timestamptz"`
+}
@@ -0,0 +1,21 @@
+package models
+
+type Role struct {
+	Id          uint             `gorm:"primaryKey"`
+	Name        string           `gorm:"not null; unique"`
+	Description string           `gorm:"not null"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleId; constraint:OnDelete:CASCADE"`
+}
+
+type RolePermission struct {
+	RoleId     uint   `gorm:"primaryKey; autoIncrement:false"`
+	Permission string `gorm:"primaryKey"`
+}
+
+type UserRole struct {
+	UserId     uint `gorm:"primaryKey; autoIncrement:false"`
+	User       User `gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	RoleId     uint `gorm:"primaryKey; autoIncrement:false"`
+	Role       Role `gorm:"foreignKey:RoleId; constraint:OnDelete:CASCADE"`
+	OperatorId uint `gorm:"primaryKey; autoIncrement:false"`
+}
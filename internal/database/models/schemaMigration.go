@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// SchemaMigration is a migration applied to the database
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey; autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null; type: timestamptz"`
+}
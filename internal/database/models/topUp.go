@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TopUp is payment of the user at payment gateway, balance is credited when it succeeds
+type TopUp struct {
+	Id            uint    `gorm:"primaryKey"`
+	UserId        uint    `gorm:"not null; index"`
+	User          User    `gorm:"foreignKey:UserId; constraint:OnDelete:CASCADE"`
+	Amount        float64 `gorm:"not null"`
+	Status        string  `gorm:"not null; index"`
+	Gateway       string  `gorm:"not null; uniqueIndex:idx_top_ups_intent,priority:1"`
+	IntentId      *string `gorm:"uniqueIndex:idx_top_ups_intent,priority:2"`
+	FailureReason string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -11,8 +11,14 @@ type Transport struct {
 	Color         string        `gorm:"not null"`
 	Identifier    string        `gorm:"not null"`
 	Description   string        `gorm:"not null"`
-	Latitude      float64       `gorm:"not null; type: numeric"`
-	Longitude     float64       `gorm:"not null; type: numeric"`
+	Latitude      float64       `gorm:"not null; type: numeric; index:idx_transports_location,priority:1"`
+	Longitude     float64       `gorm:"not null; type: numeric; index:idx_transports_location,priority:2"`
 	MinutePrice   float64
 	DayPrice      float64
 }
+
+// NearbyTransport is transport found by location with distance to the search center in meters
+type NearbyTransport struct {
+	Transport Transport
+	Distance  float64
+}
@@ -1,9 +1,17 @@
 package models
 
+import "time"
+
 type User struct {
 	Id       uint   `gorm:"primaryKey"`
 	Username string `gorm:"not null; unique" `
 	Password string `gorm:"not null"`
-	IsAdmin  bool   `gorm:"not null"`
+	IsAdmin  bool   `gorm:"not null"` // mirrors global admin role
 	Balance  float64
+	// DebtSince is when the balance became negative, it is kept by journal entries
+	DebtSince *time.Time `gorm:"default:null"`
+	// FailedSignIns is number of failed sign-ins since the last successful one
+	FailedSignIns int `gorm:"not null;default:0"`
+	// LockedUntil is when sign-in is allowed again after too many failures
+	LockedUntil *time.Time `gorm:"default:null"`
 }
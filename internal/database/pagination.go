@@ -0,0 +1,157 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/pagination"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sortKey is a column list of T can be sorted by
+type sortKey[T any] struct {
+	column string
+	// value is kept in the cursor, parse converts it back for the query
+	value func(T) string
+	parse func(string) (any, error)
+}
+
+func byId[T any]() sortKey[T] {
+	return sortKey[T]{column: "id"}
+}
+
+func byString[T any](column string, value func(T) string) sortKey[T] {
+	return sortKey[T]{column: column, value: value, parse: func(s string) (any, error) { return s, nil }}
+}
+
+func byFloat[T any](column string, value func(T) float64) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		value:  func(item T) string { return strconv.FormatFloat(value(item), 'f', -1, 64) },
+		parse:  func(s string) (any, error) { return strconv.ParseFloat(s, 64) },
+	}
+}
+
+func byTime[T any](column string, value func(T) time.Time) sortKey[T] {
+	return sortKey[T]{
+		column: column,
+		value:  func(item T) string { return value(item).Format(time.RFC3339Nano) },
+		parse:  func(s string) (any, error) { return time.Parse(time.RFC3339Nano, s) },
+	}
+}
+
+var userSortKeys = map[string]sortKey[models.User]{
+	"id":       byId[models.User](),
+	"username": byString("username", func(u models.User) string { return u.Username }),
+}
+
+var transportSortKeys = map[string]sortKey[models.Transport]{
+	"id":    byId[models.Transport](),
+	"model": byString("model", func(t models.Transport) string { return t.Model }),
+}
+
+var rentSortKeys = map[string]sortKey[models.Rent]{
+	"id":        byId[models.Rent](),
+	"timeStart": byTime("time_start", func(r models.Rent) time.Time { return r.TimeStart }),
+}
+
+var reservationSortKeys = map[string]sortKey[models.Reservation]{
+	"id":        byId[models.Reservation](),
+	"timeStart": byTime("time_start", func(r models.Reservation) time.Time { return r.TimeStart }),
+}
+
+var postingSortKeys = map[string]sortKey[models.Posting]{
+	"id": byId[models.Posting](),
+}
+
+// debtorSortKeys sort by debt, which is negative balance
+var debtorSortKeys = map[string]sortKey[models.User]{
+	"id":   byId[models.User](),
+	"debt": byFloat("-balance", func(u models.User) float64 { return -u.Balance }),
+}
+
+var roleSortKeys = map[string]sortKey[models.Role]{
+	"id":   byId[models.Role](),
+	"name": byString("name", func(r models.Role) string { return r.Name }),
+}
+
+var promoCodeSortKeys = map[string]sortKey[models.PromoCode]{
+	"id":   byId[models.PromoCode](),
+	"code": byString("code", func(p models.PromoCode) string { return p.Code }),
+}
+
+var pricingPolicySortKeys = map[string]sortKey[models.PricingPolicy]{
+	"id": byId[models.PricingPolicy](),
+}
+
+// paginate finds the page of query sorted by the key and id after the cursor,
+// total is counted without the cursor. Associations are preloaded for the page only.
+func paginate[T any](query *gorm.DB, req pagination.Request, keys map[string]sortKey[T], id func(T) uint, preloads ...string) ([]T, pagination.Meta, error) {
+	key, ok := keys[req.Sort.Field]
+	if !ok {
+		return nil, pagination.Meta{}, fmt.Errorf("unknown sort field %q", req.Sort.Field)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var meta pagination.Meta
+	if err := query.Count(&meta.Total).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if req.Sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	page := query
+	if req.After != nil {
+		if key.parse == nil {
+			page = page.Where("id "+cmp+" ?", req.After.Id)
+		} else {
+			value, err := key.parse(req.After.Value)
+			if err != nil {
+				return nil, pagination.Meta{}, pagination.ErrInvalidCursor
+			}
+			page = page.Where(fmt.Sprintf("(%s, id) %s (?, ?)", key.column, cmp), value, req.After.Id)
+		}
+	}
+	order := "id " + dir
+	if key.parse != nil {
+		order = key.column + " " + dir + ", " + order
+	}
+	for _, preload := range preloads {
+		page = page.Preload(preload)
+	}
+
+	// one more item shows that there is the next page
+	var items []T
+	if err := page.Order(order).Limit(req.Limit + 1).Find(&items).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	if len(items) > req.Limit {
+		items = items[:req.Limit]
+		last := items[req.Limit-1]
+		cursor := pagination.Cursor{Sort: req.Sort.String(), Id: id(last)}
+		if key.value != nil {
+			cursor.Value = key.value(last)
+		}
+		meta.NextCursor = cursor.Encode()
+	}
+	return items, meta, nil
+}
+
+// wrapPageError keeps validation errors of the cursor for the client
+func wrapPageError(op string, err error) error {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return wrapError(op, err)
+}
+
+// escapeLike escapes wildcards of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
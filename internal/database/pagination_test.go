@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestPaginate(t *testing.T) {
+	from := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	admin := true
+
+	tests := []struct {
+		name    string
+		find    func(db Database) error
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "first page of users",
+			find: func(db Database) error {
+				_, _, err := db.GetUsers(context.Background(), entities.UserFilter{UsernamePrefix: "ad_", IsAdmin: &admin},
+					pagination.Request{Limit: 10, Sort: pagination.Sort{Field: "id"}})
+				return err
+			},
+			want: []string{
+				`SELECT count(*) FROM "users" WHERE username LIKE $1 AND is_admin = $2`,
+				`SELECT * FROM "users" WHERE username LIKE $1 AND is_admin = $2 ORDER BY id ASC LIMIT 11`,
+			},
+		},
+		{
+			name: "next page of rents",
+			find: func(db Database) error {
+				cursor := pagination.Cursor{Sort: "-timeStart", Value: "2023-10-05T12:00:00Z", Id: 7}
+				_, _, err := db.FindUserRents(context.Background(), 1,
+					entities.RentFilter{From: &from, Status: entities.RentStatusEnded, TransportType: "Car"},
+					pagination.Request{Limit: 5, Sort: pagination.Sort{Field: "timeStart", Desc: true}, After: &cursor})
+				return err
+			},
+			want: []string{
+				`SELECT count(*) FROM "rents" WHERE user_id = $1 AND time_start >= $2 AND time_end IS NOT NULL AND transport_id IN (SELECT transports.id FROM "transports" JOIN transport_types ON transport_types.id = transports.type_id WHERE transport_types.type = $3)`,
+				`SELECT * FROM "rents" WHERE user_id = $1 AND time_start >= $2 AND time_end IS NOT NULL AND transport_id IN (SELECT transports.id FROM "transports" JOIN transport_types ON transport_types.id = transports.type_id WHERE transport_types.type = $3) AND (time_start, id) < ($4, $5) ORDER BY time_start DESC, id DESC LIMIT 6`,
+			},
+		},
+		{
+			name: "next page of debtors",
+			find: func(db Database) error {
+				cursor := pagination.Cursor{Sort: "-debt", Value: "150.5", Id: 2}
+				_, _, err := db.FindDebtors(context.Background(),
+					pagination.Request{Limit: 20, Sort: pagination.Sort{Field: "debt", Desc: true}, After: &cursor})
+				return err
+			},
+			want: []string{
+				`SELECT count(*) FROM "users" WHERE balance < 0`,
+				`SELECT * FROM "users" WHERE balance < 0 AND (-balance, id) < ($1, $2) ORDER BY -balance DESC, id DESC LIMIT 21`,
+			},
+		},
+		{
+			name: "first page of transactions",
+			find: func(db Database) error {
+				_, _, err := db.FindUserPostings(context.Background(), 1,
+					pagination.Request{Limit: 20, Sort: pagination.Sort{Field: "id", Desc: true}})
+				return err
+			},
+			want: []string{
+				`SELECT count(*) FROM "postings" WHERE account_id IN (SELECT id FROM ledger_accounts WHERE user_id = $1)`,
+				`SELECT * FROM "postings" WHERE account_id IN (SELECT id FROM ledger_accounts WHERE user_id = $1) ORDER BY id DESC LIMIT 21`,
+			},
+		},
+		{
+			name: "tampered cursor",
+			find: func(db Database) error {
+				cursor := pagination.Cursor{Sort: "timeStart", Value: "yesterday", Id: 7}
+				_, _, err := db.FindTransportRents(context.Background(), 1, entities.RentFilter{},
+					pagination.Request{Limit: 5, Sort: pagination.Sort{Field: "timeStart"}, After: &cursor})
+				return err
+			},
+			want:    []string{`SELECT count(*) FROM "rents" WHERE transport_id = $1`},
+			wantErr: pagination.ErrInvalidCursor,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// dry run builds statements without connecting to the database
+			gormDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+				DryRun:                 true,
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			var statements []string
+			err = gormDB.Callback().Query().After("gorm:query").Register("test:statements", func(tx *gorm.DB) {
+				statements = append(statements, tx.Statement.SQL.String())
+			})
+			require.NoError(t, err)
+
+			err = testCase.find(Database{db: gormDB})
+			assert.ErrorIs(t, err, testCase.wantErr)
+			// subqueries are built by the same callbacks, so only presence of the statements is checked
+			assert.Subset(t, statements, testCase.want)
+		})
+	}
+}
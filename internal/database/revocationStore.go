@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevocationStore keeps revoked tokens in postgres, so they stay revoked after restart
+type RevocationStore struct {
+	db *gorm.DB
+}
+
+func NewRevocationStore(db Database) RevocationStore {
+	return RevocationStore{db: db.db}
+}
+
+func (rs RevocationStore) Revoke(jti string, expiresAt time.Time) error {
+	op := "database.RevocationStore.Revoke()"
+	rs.cleanup()
+	err := rs.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{Jti: jti, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (rs RevocationStore) RevokeUser(userId uint, revokedAt, expiresAt time.Time) error {
+	op := "database.RevocationStore.RevokeUser()"
+	rs.cleanup()
+	err := rs.db.Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&models.RevokedUser{UserId: userId, RevokedAt: revokedAt, ExpiresAt: expiresAt}).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (rs RevocationStore) IsRevoked(token entities.Token) (bool, error) {
+	op := "database.RevocationStore.IsRevoked()"
+	now := time.Now()
+	var count int64
+	err := rs.db.Model(&models.RevokedToken{}).
+		Where("jti = ? AND expires_at > ?", token.Jti, now).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	err = rs.db.Model(&models.RevokedUser{}).
+		Where("user_id = ? AND expires_at > ? AND revoked_at >= ?", token.Id, now, token.IssuedAt).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return count > 0, nil
+}
+
+func (rs RevocationStore) cleanup() {
+	now := time.Now()
+	rs.db.Delete(&models.RevokedToken{}, "expires_at <= ?", now)
+	rs.db.Delete(&models.RevokedUser{}, "expires_at <= ?", now)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"simbirGo/internal/tracing"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTraceQueries(t *testing.T) {
+	// dry run builds statements without connecting to the database
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	db := Database{db: gormDB}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(sdktrace.WithSyncer(exporter))
+	require.NoError(t, db.TraceQueries(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "rentUsecase.EndRent")
+	_, err = db.FindUserById(ctx, 1)
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "query users", query.Name)
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID())
+	assert.Contains(t, query.Attributes, semconv.DBSystemPostgreSQL)
+	assert.Contains(t, query.Attributes, semconv.DBSQLTableKey.String("users"))
+	assert.Contains(t, query.Attributes, semconv.DBStatement(`SELECT * FROM "users" WHERE id=$1 LIMIT 1`))
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/geo"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// HaversineLocator searches transports in bounding boxes of the search area
+// using idx_transports_location and filters them by great-circle distance.
+type HaversineLocator struct {
+	db *gorm.DB
+}
+
+func NewHaversineLocator(db Database) HaversineLocator {
+	return HaversineLocator{db: db.db}
+}
+
+// FindTransportsNear returns rentable transports within radius meters from the center, nearest first.
+// typeId = 0 means transports of all types.
+func (l HaversineLocator) FindTransportsNear(ctx context.Context, center geo.Point, radius float64, typeId uint) ([]models.NearbyTransport, error) {
+	op := "database.HaversineLocator.FindTransportsNear()"
+	var area *gorm.DB
+	for i, box := range geo.BoundingBoxes(center, radius) {
+		inBox := "latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?"
+		if i == 0 {
+			area = l.db.Where(inBox, box.MinLat, box.MaxLat, box.MinLong, box.MaxLong)
+		} else {
+			area = area.Or(inBox, box.MinLat, box.MaxLat, box.MinLong, box.MaxLong)
+		}
+	}
+
+	query := l.db.WithContext(ctx).Where("can_be_rented = ?", true).Where(area)
+	if typeId != 0 {
+		query = query.Where("type_id = ?", typeId)
+	}
+	var transports []models.Transport
+	if err := query.Find(&transports).Error; err != nil {
+		return nil, wrapError(op, err)
+	}
+
+	nearby := make([]models.NearbyTransport, 0, len(transports))
+	for _, transport := range transports {
+		distance := geo.Distance(center, geo.Point{Lat: transport.Latitude, Long: transport.Longitude})
+		if distance <= radius {
+			nearby = append(nearby, models.NearbyTransport{Transport: transport, Distance: distance})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].Distance == nearby[j].Distance {
+			return nearby[i].Transport.Id < nearby[j].Transport.Id
+		}
+		return nearby[i].Distance < nearby[j].Distance
+	})
+	return nearby, nil
+}
+
+// transportGeography is the expression of idx_transports_geography,
+// queries must use the same expression for the index to be used
+const transportGeography = "(ST_SetSRID(ST_MakePoint(longitude::float8, latitude::float8), 4326)::geography)"
+
+// PostGISLocator searches transports with PostGIS geography functions
+// backed by GiST index. Requires postgis extension.
+type PostGISLocator struct {
+	db *gorm.DB
+}
+
+// NewPostGISLocator checks that postgis extension and the index are created by migration 0008_postgis
+func NewPostGISLocator(db Database) (PostGISLocator, error) {
+	op := "database.NewPostGISLocator()"
+	var ready struct {
+		Extension bool
+		Index     bool
+	}
+	err := db.db.Raw(`SELECT
+		EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis') AS extension,
+		to_regclass('idx_transports_geography') IS NOT NULL AS index`).Scan(&ready).Error
+	if err != nil {
+		return PostGISLocator{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ready.Extension {
+		return PostGISLocator{}, fmt.Errorf("%s: postgis extension is not installed, "+
+			"create it and idx_transports_geography as in migration 0008_postgis", op)
+	}
+	if !ready.Index {
+		return PostGISLocator{}, fmt.Errorf("%s: index idx_transports_geography is missing, create it as in migration 0008_postgis", op)
+	}
+	return PostGISLocator{db: db.db}, nil
+}
+
+// FindTransportsNear returns rentable transports within radius meters from the center, nearest first.
+// typeId = 0 means transports of all types.
+func (l PostGISLocator) FindTransportsNear(ctx context.Context, center geo.Point, radius float64, typeId uint) ([]models.NearbyTransport, error) {
+	op := "database.PostGISLocator.FindTransportsNear()"
+	origin := "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+	query := l.db.WithContext(ctx).Model(&models.Transport{}).
+		Select("id, ST_Distance("+transportGeography+", "+origin+") AS distance", center.Long, center.Lat).
+		Where("can_be_rented = ?", true).
+		Where("ST_DWithin("+transportGeography+", "+origin+", ?)", center.Long, center.Lat, radius)
+	if typeId != 0 {
+		query = query.Where("type_id = ?", typeId)
+	}
+
+	var found []struct {
+		Id       uint
+		Distance float64
+	}
+	if err := query.Order("distance, id").Scan(&found).Error; err != nil {
+		return nil, wrapError(op, err)
+	}
+	if len(found) == 0 {
+		return []models.NearbyTransport{}, nil
+	}
+
+	ids := make([]uint, 0, len(found))
+	for _, f := range found {
+		ids = append(ids, f.Id)
+	}
+	var transports []models.Transport
+	if err := l.db.WithContext(ctx).Find(&transports, ids).Error; err != nil {
+		return nil, wrapError(op, err)
+	}
+	byId := make(map[uint]models.Transport, len(transports))
+	for _, transport := range transports {
+		byId[transport.Id] = transport
+	}
+
+	nearby := make([]models.NearbyTransport, 0, len(found))
+	for _, f := range found {
+		// transport could be deleted between the queries
+		if transport, ok := byId[f.Id]; ok {
+			nearby = append(nearby, models.NearbyTransport{Transport: transport, Distance: f.Distance})
+		}
+	}
+	return nearby, nil
+}
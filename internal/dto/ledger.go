@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+)
+
+// PostingModelToTransaction converts posting of user's wallet with preloaded entry
+func PostingModelToTransaction(posting models.Posting) entities.Transaction {
+	return entities.Transaction{
+		Id:          posting.Entry.Id,
+		Kind:        posting.Entry.Kind,
+		Amount:      posting.Amount,
+		Description: posting.Entry.Description,
+		RentId:      posting.Entry.RentId,
+		CreatedAt:   posting.Entry.CreatedAt,
+	}
+}
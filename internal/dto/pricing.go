@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+)
+
+func PricingPolicyEntitieToModel(policy entities.PricingPolicy, typeId *uint) models.PricingPolicy {
+	return models.PricingPolicy{
+		Id:              policy.Id,
+		Name:            policy.Name,
+		TransportTypeId: typeId,
+		TransportId:     policy.TransportId,
+		Policy:          policy.Policy,
+	}
+}
+
+func PricingPolicyModelToEntitie(policy models.PricingPolicy, typeStr string) entities.PricingPolicy {
+	return entities.PricingPolicy{
+		Id:            policy.Id,
+		Name:          policy.Name,
+		TransportType: typeStr,
+		TransportId:   policy.TransportId,
+		Policy:        policy.Policy,
+	}
+}
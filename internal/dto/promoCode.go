@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pricing"
+)
+
+func PromoCodeEntitieToModel(promoCode entities.PromoCode, typeIds []uint) models.PromoCode {
+	transportTypes := make([]models.PromoCodeTransportType, 0, len(typeIds))
+	for _, typeId := range typeIds {
+		transportTypes = append(transportTypes, models.PromoCodeTransportType{
+			PromoCodeId:     promoCode.Id,
+			TransportTypeId: typeId,
+		})
+	}
+	return models.PromoCode{
+		Id:             promoCode.Id,
+		Code:           promoCode.Code,
+		Kind:           string(promoCode.Kind),
+		Value:          promoCode.Value,
+		ValidFrom:      promoCode.ValidFrom,
+		ValidTo:        promoCode.ValidTo,
+		MaxUses:        promoCode.MaxUses,
+		MaxUsesPerUser: promoCode.MaxUsesPerUser,
+		TransportTypes: transportTypes,
+	}
+}
+
+func PromoCodeModelToEntitie(promoCode models.PromoCode, typeStrs []string, uses int64) entities.PromoCode {
+	return entities.PromoCode{
+		Id:             promoCode.Id,
+		Code:           promoCode.Code,
+		Kind:           pricing.DiscountKind(promoCode.Kind),
+		Value:          promoCode.Value,
+		ValidFrom:      promoCode.ValidFrom,
+		ValidTo:        promoCode.ValidTo,
+		MaxUses:        promoCode.MaxUses,
+		MaxUsesPerUser: promoCode.MaxUsesPerUser,
+		TransportTypes: typeStrs,
+		Uses:           uses,
+	}
+}
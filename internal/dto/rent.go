@@ -7,26 +7,38 @@ import (
 
 func RentEntitieToModel(rent entities.Rent, rentType uint) models.Rent {
 	return models.Rent{
-		Id:          rent.Id,
-		TransportId: rent.TransportId,
-		UserId:      rent.UserId,
-		TimeStart:   rent.TimeStart,
-		TimeEnd:     rent.TimeEnd,
-		PriceOfUnit: rent.PriceOfUnit,
-		RentTypeId: rentType,
-		FinalPrice:  rent.FinalPrice,
+		Id:            rent.Id,
+		TransportId:   rent.TransportId,
+		UserId:        rent.UserId,
+		TimeStart:     rent.TimeStart,
+		TimeEnd:       rent.TimeEnd,
+		PriceOfUnit:   rent.PriceOfUnit,
+		RentTypeId:    rentType,
+		FinalPrice:    rent.FinalPrice,
+		Tariff:        rent.Tariff,
+		OriginalPrice: rent.OriginalPrice,
+		Discount:      rent.Discount,
+		PromoCodeId:   rent.PromoCodeId,
+		Hold:          rent.Hold,
+		Debt:          rent.Debt,
 	}
 }
 
 func RentModelToEntitie(rent models.Rent, rentType string) entities.Rent {
 	return entities.Rent{
-		Id:          rent.Id,
-		TransportId: rent.TransportId,
-		UserId:      rent.UserId,
-		TimeStart:   rent.TimeStart,
-		TimeEnd:     rent.TimeEnd,
-		PriceOfUnit: rent.PriceOfUnit,
-		PriceType:   rentType,
-		FinalPrice:  rent.FinalPrice,
+		Id:            rent.Id,
+		TransportId:   rent.TransportId,
+		UserId:        rent.UserId,
+		TimeStart:     rent.TimeStart,
+		TimeEnd:       rent.TimeEnd,
+		PriceOfUnit:   rent.PriceOfUnit,
+		PriceType:     rentType,
+		FinalPrice:    rent.FinalPrice,
+		Tariff:        rent.Tariff,
+		OriginalPrice: rent.OriginalPrice,
+		Discount:      rent.Discount,
+		PromoCodeId:   rent.PromoCodeId,
+		Hold:          rent.Hold,
+		Debt:          rent.Debt,
 	}
 }
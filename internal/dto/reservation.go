@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+)
+
+func ReservationModelToEntitie(reservation models.Reservation) entities.Reservation {
+	return entities.Reservation{
+		Id:          reservation.Id,
+		TransportId: reservation.TransportId,
+		UserId:      reservation.UserId,
+		TimeStart:   reservation.TimeStart,
+		TimeEnd:     reservation.TimeEnd,
+		Status:      reservation.Status,
+		RentId:      reservation.RentId,
+	}
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+)
+
+func RoleEntitieToModel(role entities.Role) models.Role {
+	permissions := make([]models.RolePermission, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, models.RolePermission{
+			RoleId:     role.Id,
+			Permission: string(permission),
+		})
+	}
+	return models.Role{
+		Id:          role.Id,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
+
+func RoleModelToEntitie(role models.Role) entities.Role {
+	permissions := make([]entities.Permission, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, entities.Permission(permission.Permission))
+	}
+	return entities.Role{
+		Id:          role.Id,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
+
+func UserRoleModelToEntitie(userRole models.UserRole) entities.UserRole {
+	return entities.UserRole{
+		UserId:     userRole.UserId,
+		RoleId:     userRole.RoleId,
+		RoleName:   userRole.Role.Name,
+		OperatorId: userRole.OperatorId,
+	}
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+)
+
+func TopUpModelToEntitie(topUp models.TopUp) entities.TopUp {
+	return entities.TopUp{
+		Id:            topUp.Id,
+		Amount:        topUp.Amount,
+		Status:        topUp.Status,
+		Gateway:       topUp.Gateway,
+		FailureReason: topUp.FailureReason,
+		CreatedAt:     topUp.CreatedAt,
+		UpdatedAt:     topUp.UpdatedAt,
+	}
+}
@@ -19,7 +19,6 @@ func UserModelToEntitie(user models.User) entities.User {
 	return entities.User{
 		Id:       user.Id,
 		Username: user.Username,
-		Password: user.Password,
 		IsAdmin:  user.IsAdmin,
 		Balance:  user.Balance,
 	}
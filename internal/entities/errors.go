@@ -0,0 +1,150 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of domain errors, checked with errors.Is to pick the response status
+var (
+	// ErrValidation means the request has invalid values
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized means the credentials or refresh token are not accepted
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means the user is not allowed to perform the operation
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound means the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the operation conflicts with current state, e.g. unique value is taken
+	ErrConflict = errors.New("conflict")
+	// ErrInsufficientFunds means the user's balance is too low for the operation
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrTooManyRequests means the client must wait before the next attempt
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrPaymentGateway means the payment gateway has failed or rejected the payment
+	ErrPaymentGateway = errors.New("payment gateway error")
+	// ErrInternal means storage or another dependency has failed
+	ErrInternal = errors.New("internal error")
+)
+
+// Error codes are returned to clients in the "code" field and must not be changed
+const (
+	CodeValidationFailed       = "validation_failed"
+	CodeInvalidParam           = "invalid_param"
+	CodeInvalidBody            = "invalid_body"
+	CodeUnauthorized           = "unauthorized"
+	CodeTokenExpired           = "token_expired"
+	CodeTokenInvalid           = "token_invalid"
+	CodeTokenRevoked           = "token_revoked"
+	CodeInvalidCredentials     = "invalid_credentials"
+	CodeRefreshTokenInvalid    = "refresh_token_invalid"
+	CodeRefreshTokenReused     = "refresh_token_reused"
+	CodeForbidden              = "forbidden"
+	CodePermissionRequired     = "permission_required"
+	CodeOutOfScope             = "out_of_scope"
+	CodeNotFound               = "not_found"
+	CodeUserNotFound           = "user_not_found"
+	CodeTransportNotFound      = "transport_not_found"
+	CodeRentNotFound           = "rent_not_found"
+	CodeRoleNotFound           = "role_not_found"
+	CodeConflict               = "conflict"
+	CodeUsernameTaken          = "username_taken"
+	CodeRoleNameTaken          = "role_name_taken"
+	CodeRoleImmutable          = "role_immutable"
+	CodeTransportNotRentable   = "transport_not_rentable"
+	CodeRentAlreadyEnded       = "rent_already_ended"
+	CodeReservationNotFound    = "reservation_not_found"
+	CodeReservationOverlap     = "reservation_overlap"
+	CodeReservationInactive    = "reservation_inactive"
+	CodeReservationNotBegun    = "reservation_not_begun"
+	CodeReservationExpired     = "reservation_expired"
+	CodeTransportReserved      = "transport_reserved"
+	CodePricingPolicyNotFound  = "pricing_policy_not_found"
+	CodePricingPolicyTaken     = "pricing_policy_taken"
+	CodePromoCodeNotFound      = "promo_code_not_found"
+	CodePromoCodeTaken         = "promo_code_taken"
+	CodePromoCodeExpired       = "promo_code_expired"
+	CodePromoCodeExhausted     = "promo_code_exhausted"
+	CodePromoCodeNotApplicable = "promo_code_not_applicable"
+	CodeRentNotEnded           = "rent_not_ended"
+	CodeRentAlreadyRefunded    = "rent_already_refunded"
+	CodeRentHasEntries         = "rent_has_entries"
+	CodeTopUpNotFound          = "top_up_not_found"
+	CodeWebhookInvalid         = "webhook_invalid"
+	CodeSimulationUnsupported  = "simulation_unsupported"
+	CodePaymentGatewayFailed   = "payment_gateway_failed"
+	CodeInsufficientFunds      = "insufficient_funds"
+	CodeOutstandingDebt        = "outstanding_debt"
+	CodeIdempotencyKeyReused   = "idempotency_key_reused"
+	CodeIdempotencyInProgress  = "idempotency_in_progress"
+	CodeRateLimited            = "rate_limited"
+	CodeAccountLocked          = "account_locked"
+	CodeInternal               = "internal_error"
+)
+
+// FieldError describes invalid value of a single request field
+type FieldError struct {
+	Field   string `json:"field" example:"username"`
+	Message string `json:"message" example:"field is required"`
+}
+
+// Error keeps the message for clients, stable code and the kind checked with errors.Is
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	// RetryAfter is how long the client must wait before retrying, it is sent in Retry-After header
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NewValidationError(code, message string, fields ...FieldError) error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+// NewInvalidParamError reports invalid path or query parameter
+func NewInvalidParamError(param, message string) error {
+	return NewValidationError(CodeInvalidParam, "invalid value of "+param+" param", FieldError{Field: param, Message: message})
+}
+
+func NewUnauthorizedError(code, message string) error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+func NewForbiddenError(code, message string) error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func NewNotFoundError(code, message string) error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// NewDebtError reports that the user must pay the debt first
+func NewDebtError(message string) error {
+	return &Error{Kind: ErrInsufficientFunds, Code: CodeOutstandingDebt, Message: message}
+}
+
+func NewPaymentGatewayError(message string) error {
+	return &Error{Kind: ErrPaymentGateway, Code: CodePaymentGatewayFailed, Message: message}
+}
+
+func NewInsufficientFundsError(message string) error {
+	return &Error{Kind: ErrInsufficientFunds, Code: CodeInsufficientFunds, Message: message}
+}
+
+// NewTooManyRequestsError reports that the request can be retried after retryAfter
+func NewTooManyRequestsError(code, message string, retryAfter time.Duration) error {
+	return &Error{Kind: ErrTooManyRequests, Code: code, Message: message, RetryAfter: retryAfter}
+}
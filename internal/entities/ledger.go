@@ -0,0 +1,74 @@
+package entities
+
+import "time"
+
+// system ledger accounts
+const (
+	// AccountExternal is money outside of the system: top-ups come from it
+	AccountExternal = "external"
+	// AccountRevenue receives payments for rents and pays refunds and owner payouts
+	AccountRevenue = "revenue"
+	// AccountPromo pays promo code discounts
+	AccountPromo = "promo"
+	// AccountHolds keeps money reserved for running rents
+	AccountHolds = "holds"
+)
+
+var SystemAccounts = []string{AccountExternal, AccountRevenue, AccountPromo, AccountHolds}
+
+// kinds of journal entries
+const (
+	EntryOpeningBalance = "opening_balance"
+	EntryTopUp          = "top_up"
+	EntryHold           = "hold"
+	EntryHoldCapture    = "hold_capture"
+	EntryHoldRelease    = "hold_release"
+	EntryRentCharge     = "rent_charge"
+	EntryPromoCredit    = "promo_credit"
+	EntryRefund         = "refund"
+	EntryOwnerPayout    = "owner_payout"
+	EntryAdjustment     = "adjustment"
+)
+
+// Transaction is change of user's balance by journal entry
+type Transaction struct {
+	Id          uint      `json:"id"`
+	Kind        string    `json:"kind" enums:"opening_balance, top_up, hold, hold_capture, hold_release, rent_charge, promo_credit, refund, owner_payout, adjustment"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description"`
+	RentId      *uint     `json:"rentId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// BalanceMismatch is user whose balance differs from the ledger
+type BalanceMismatch struct {
+	UserId        uint    `json:"userId"`
+	Username      string  `json:"username"`
+	Balance       float64 `json:"balance"`
+	LedgerBalance float64 `json:"ledgerBalance"`
+	Difference    float64 `json:"difference"`
+}
+
+// ReconciliationReport compares balances of users with the ledger
+type ReconciliationReport struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Users       int       `json:"users"`
+	// Imbalance is sum of all postings, it must be zero
+	Imbalance      float64            `json:"imbalance"`
+	SystemAccounts map[string]float64 `json:"systemAccounts"`
+	Mismatches     []BalanceMismatch  `json:"mismatches"`
+}
+
+// Debtor is user with negative balance
+type Debtor struct {
+	UserId    uint      `json:"userId"`
+	Username  string    `json:"username"`
+	Debt      float64   `json:"debt"`
+	DebtSince time.Time `json:"debtSince"`
+}
+
+type DebtorsReport struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	TotalDebt   float64   `json:"totalDebt"`
+	Debtors     []Debtor  `json:"debtors"`
+}
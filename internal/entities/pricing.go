@@ -0,0 +1,12 @@
+package entities
+
+import "simbirGo/internal/pricing"
+
+// PricingPolicy is attached either to transport type or to transport
+type PricingPolicy struct {
+	Id            uint   `json:"id"`
+	Name          string `json:"name"`
+	TransportType string `json:"transportType,omitempty" enums:"Car, Bike, Scooter"`
+	TransportId   *uint  `json:"transportId,omitempty"`
+	pricing.Policy
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"simbirGo/internal/pricing"
+	"strings"
+	"time"
+)
+
+type PromoCode struct {
+	Id             uint                 `json:"id"`
+	Code           string               `json:"code"`
+	Kind           pricing.DiscountKind `json:"kind" enums:"percent, fixed"`
+	Value          float64              `json:"value"`
+	ValidFrom      time.Time            `json:"validFrom"`
+	ValidTo        time.Time            `json:"validTo"`
+	MaxUses        int                  `json:"maxUses"`
+	MaxUsesPerUser int                  `json:"maxUsesPerUser"`
+	// TransportTypes restricts the code to the types, empty means all types
+	TransportTypes []string `json:"transportTypes"`
+	// Uses is the number of rents the code is attached to
+	Uses int64 `json:"uses"`
+}
+
+// NormalizePromoCode makes promo codes case insensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"simbirGo/internal/pricing"
 	"time"
 )
 
@@ -13,4 +14,35 @@ type Rent struct {
 	PriceOfUnit float64    `json:"priceOfUnit"`
 	PriceType   string     `json:"priceType" enums:"Minutes, Days"`
 	FinalPrice  float64    `json:"finalPrice"`
+	// OriginalPrice is the price before promo code discount
+	OriginalPrice float64 `json:"originalPrice"`
+	Discount      float64 `json:"discount"`
+	PromoCodeId   *uint   `json:"promoCodeId,omitempty"`
+	// Debt is part of the final price which was not covered by the balance
+	Debt float64 `json:"debt"`
+	// Hold is money reserved from the balance at the start of the rent
+	Hold   float64        `json:"hold"`
+	Tariff pricing.Tariff `json:"tariff"`
+}
+
+// RentStats is current state of the fleet for monitoring
+type RentStats struct {
+	ActiveRents int64
+	// AvailableTransports is number of transports which can be rented by transport type
+	AvailableTransports map[string]int64
+}
+
+const (
+	RentStatusActive = "active"
+	RentStatusEnded  = "ended"
+)
+
+// RentFilter narrows history of rents, zero fields are not applied
+type RentFilter struct {
+	// From and To limit start time of the rent to [From, To)
+	From *time.Time
+	To   *time.Time
+	// Status is RentStatusActive or RentStatusEnded
+	Status        string
+	TransportType string
 }
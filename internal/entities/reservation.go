@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Statuses of reservation
+const (
+	ReservationActive    = "Active"
+	ReservationConverted = "Converted"
+	ReservationCancelled = "Cancelled"
+	ReservationExpired   = "Expired"
+)
+
+type Reservation struct {
+	Id          uint      `json:"id"`
+	TransportId uint      `json:"transportId"`
+	UserId      uint      `json:"userId"`
+	TimeStart   time.Time `json:"timeStart"`
+	TimeEnd     time.Time `json:"timeEnd"`
+	Status      string    `json:"status" enums:"Active, Converted, Cancelled, Expired"`
+	// RentId is id of the rent the reservation was converted into
+	RentId *uint `json:"rentId,omitempty"`
+}
+
+// ReservationSlot is time window when transport is reserved
+type ReservationSlot struct {
+	TimeStart time.Time `json:"timeStart"`
+	TimeEnd   time.Time `json:"timeEnd"`
+}
@@ -0,0 +1,84 @@
+package entities
+
+type Permission string
+
+const (
+	PermissionUsersRead        Permission = "users:read"
+	PermissionUsersManage      Permission = "users:manage"
+	PermissionRentsRead        Permission = "rents:read"
+	PermissionRentsEnd         Permission = "rents:end"
+	PermissionRentsManage      Permission = "rents:manage"
+	PermissionTransportsManage Permission = "transports:manage"
+	PermissionBalancesAdjust   Permission = "balances:adjust"
+	PermissionRolesManage      Permission = "roles:manage"
+	PermissionPricingManage    Permission = "pricing:manage"
+	PermissionPromoManage      Permission = "promo:manage"
+)
+
+// Permissions lists all permissions, new ones must be granted to admin role by a migration
+var Permissions = []Permission{
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionRentsRead,
+	PermissionRentsEnd,
+	PermissionRentsManage,
+	PermissionTransportsManage,
+	PermissionBalancesAdjust,
+	PermissionRolesManage,
+	PermissionPricingManage,
+	PermissionPromoManage,
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+const AdminRole = "admin"
+
+type Role struct {
+	Id          uint         `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// UserRole is role granted to user. Non-zero OperatorId limits the role
+// to transports owned by the operator.
+type UserRole struct {
+	UserId     uint   `json:"userId"`
+	RoleId     uint   `json:"roleId"`
+	RoleName   string `json:"roleName"`
+	OperatorId uint   `json:"operatorId"`
+}
+
+// ErrOutOfScope is returned when the permission is scoped to operators
+// and the resource belongs to someone else.
+var ErrOutOfScope = NewForbiddenError(CodeOutOfScope, "resource is out of your operator scope")
+
+// Scope describes where the permission can be used:
+// everywhere or only for transports of listed operators.
+type Scope struct {
+	All       bool
+	Operators []uint
+}
+
+func (s Scope) IsEmpty() bool {
+	return !s.All && len(s.Operators) == 0
+}
+
+func (s Scope) Allows(ownerId uint) bool {
+	if s.All {
+		return true
+	}
+	for _, operator := range s.Operators {
+		if operator == ownerId {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,34 @@
 package entities
 
+import "time"
+
 type Token struct {
-	Id      uint
-	IsAdmin bool
+	Id        uint
+	IsAdmin   bool
+	SessionId string
+	Jti       string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"tokenExpiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
 }
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// Statuses of top-up
+const (
+	TopUpPending   = "pending"
+	TopUpSucceeded = "succeeded"
+	TopUpFailed    = "failed"
+)
+
+type TopUp struct {
+	Id      uint    `json:"id"`
+	Amount  float64 `json:"amount"`
+	Status  string  `json:"status" enums:"pending, succeeded, failed"`
+	Gateway string  `json:"gateway"`
+	// ConfirmationURL is the page of the gateway where the user pays
+	ConfirmationURL string    `json:"confirmationUrl,omitempty"`
+	FailureReason   string    `json:"failureReason,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
@@ -14,3 +14,10 @@ type Transport struct {
 	MinutePrice   float64 `json:"minutePrice"`
 	DayPrice      float64 `json:"dayPrice"`
 }
+
+// NearbyTransport is transport found by location search
+type NearbyTransport struct {
+	Transport
+	// Distance to the search center in meters
+	Distance float64 `json:"distance" example:"125.4"`
+}
@@ -3,7 +3,22 @@ package entities
 type User struct {
 	Id       uint    `json:"id"`
 	Username string  `json:"username" binding:"required"`
-	Password string  `json:"password" binding:"required"`
+	Password string  `json:"-"`
 	IsAdmin  bool    `json:"isAdmin"`
 	Balance  float64 `json:"balance"`
 }
+
+// UserUpdate is admin's change of the account, nil IsAdmin and Balance are left as is
+type UserUpdate struct {
+	Id       uint
+	Username string
+	Password string
+	IsAdmin  *bool
+	Balance  *float64
+}
+
+// UserFilter narrows list of users, zero fields are not applied
+type UserFilter struct {
+	UsernamePrefix string
+	IsAdmin        *bool
+}
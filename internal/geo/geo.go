@@ -0,0 +1,81 @@
+package geo
+
+import "math"
+
+// EarthRadius is mean radius of the Earth in meters
+const EarthRadius = 6371008.8
+
+type Point struct {
+	Lat  float64
+	Long float64
+}
+
+// Box is a rectangle in degrees, MinLong <= MaxLong
+type Box struct {
+	MinLat  float64
+	MaxLat  float64
+	MinLong float64
+	MaxLong float64
+}
+
+func (b Box) Contains(p Point) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Long >= b.MinLong && p.Long <= b.MaxLong
+}
+
+// Distance returns great-circle distance between points in meters
+func Distance(a, b Point) float64 {
+	lat1 := radians(a.Lat)
+	lat2 := radians(b.Lat)
+	dLat := lat2 - lat1
+	dLong := radians(b.Long - a.Long)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLong/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// BoundingBoxes returns boxes covering all points within radius meters from the center.
+// The area is split in two boxes when it crosses the antimeridian,
+// near the poles the box covers all longitudes.
+func BoundingBoxes(center Point, radius float64) []Box {
+	angular := radius / EarthRadius
+	lat := radians(center.Lat)
+	minLat := lat - angular
+	maxLat := lat + angular
+
+	if minLat <= -math.Pi/2 || maxLat >= math.Pi/2 {
+		return []Box{{
+			MinLat:  degrees(math.Max(minLat, -math.Pi/2)),
+			MaxLat:  degrees(math.Min(maxLat, math.Pi/2)),
+			MinLong: -180,
+			MaxLong: 180,
+		}}
+	}
+
+	dLong := math.Asin(math.Sin(angular) / math.Cos(lat))
+	minLong := radians(center.Long) - dLong
+	maxLong := radians(center.Long) + dLong
+	box := Box{MinLat: degrees(minLat), MaxLat: degrees(maxLat)}
+
+	switch {
+	case minLong < -math.Pi:
+		west, east := box, box
+		west.MinLong, west.MaxLong = degrees(minLong+2*math.Pi), 180
+		east.MinLong, east.MaxLong = -180, degrees(maxLong)
+		return []Box{west, east}
+	case maxLong > math.Pi:
+		west, east := box, box
+		west.MinLong, west.MaxLong = degrees(minLong), 180
+		east.MinLong, east.MaxLong = -180, degrees(maxLong-2*math.Pi)
+		return []Box{west, east}
+	}
+	box.MinLong, box.MaxLong = degrees(minLong), degrees(maxLong)
+	return []Box{box}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	testTable := []struct {
+		name     string
+		a        Point
+		b        Point
+		expected float64
+	}{
+		{
+			name:     "Same point",
+			a:        Point{Lat: 54.3187, Long: 48.3978},
+			b:        Point{Lat: 54.3187, Long: 48.3978},
+			expected: 0,
+		},
+		{
+			name:     "Moscow - Saint Petersburg",
+			a:        Point{Lat: 55.7558, Long: 37.6173},
+			b:        Point{Lat: 59.9343, Long: 30.3351},
+			expected: 634_000,
+		},
+		{
+			name:     "Across the antimeridian",
+			a:        Point{Lat: 0, Long: 179.999},
+			b:        Point{Lat: 0, Long: -179.999},
+			expected: 222,
+		},
+		{
+			name:     "Near the pole",
+			a:        Point{Lat: 89.999, Long: 0},
+			b:        Point{Lat: 89.999, Long: 180},
+			expected: 222,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// 0.5% error is allowed, distances are compared with the ones from the map
+			assert.InDelta(t, testCase.expected, Distance(testCase.a, testCase.b), testCase.expected*0.005+1)
+		})
+	}
+}
+
+func TestBoundingBoxes(t *testing.T) {
+	testTable := []struct {
+		name   string
+		center Point
+		radius float64
+		boxes  int
+		inside []Point
+	}{
+		{
+			name:   "Single box",
+			center: Point{Lat: 54.3187, Long: 48.3978},
+			radius: 1000,
+			boxes:  1,
+			inside: []Point{{Lat: 54.3187, Long: 48.4100}, {Lat: 54.3100, Long: 48.3978}},
+		},
+		{
+			name:   "Across the antimeridian",
+			center: Point{Lat: 0, Long: 179.999},
+			radius: 1000,
+			boxes:  2,
+			inside: []Point{{Lat: 0, Long: 179.995}, {Lat: 0, Long: -179.995}},
+		},
+		{
+			name:   "Near the pole",
+			center: Point{Lat: 89.999, Long: 0},
+			radius: 1000,
+			boxes:  1,
+			inside: []Point{{Lat: 89.999, Long: 180}, {Lat: 89.998, Long: -90}},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			boxes := BoundingBoxes(testCase.center, testCase.radius)
+			assert.Len(t, boxes, testCase.boxes)
+			for _, p := range testCase.inside {
+				assert.LessOrEqual(t, Distance(testCase.center, p), testCase.radius)
+				assert.True(t, containedInAny(boxes, p), "point %v is outside of %v", p, boxes)
+			}
+		})
+	}
+}
+
+func containedInAny(boxes []Box, p Point) bool {
+	for _, b := range boxes {
+		if b.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failed := func(ctx context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		checks   map[string]Check
+		drain    bool
+		want     bool
+		statuses map[string]string
+	}{
+		{
+			name:     "all checks pass",
+			checks:   map[string]Check{"database": ok, "migrations": ok},
+			want:     true,
+			statuses: map[string]string{"database": StatusOk, "migrations": StatusOk},
+		},
+		{
+			name:     "check fails",
+			checks:   map[string]Check{"database": failed, "migrations": ok},
+			want:     false,
+			statuses: map[string]string{"database": StatusFail, "migrations": StatusOk},
+		},
+		{
+			name:     "check times out",
+			checks:   map[string]Check{"database": slow},
+			want:     false,
+			statuses: map[string]string{"database": StatusFail},
+		},
+		{
+			name:     "draining",
+			checks:   map[string]Check{"database": ok},
+			drain:    true,
+			want:     false,
+			statuses: map[string]string{"shutdown": StatusFail},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			checker := NewChecker(10 * time.Millisecond)
+			for name, check := range testCase.checks {
+				checker.Add(name, check)
+			}
+			if testCase.drain {
+				checker.Drain()
+			}
+
+			report, ready := checker.Ready(context.Background())
+			assert.Equal(t, testCase.want, ready)
+			statuses := map[string]string{}
+			for name, result := range report.Checks {
+				statuses[name] = result.Status
+				assert.Equal(t, result.Status == StatusFail, result.Error != "")
+			}
+			assert.Equal(t, testCase.statuses, statuses)
+			if ready {
+				assert.Equal(t, StatusOk, report.Status)
+			} else {
+				assert.Equal(t, StatusFail, report.Status)
+			}
+		})
+	}
+}
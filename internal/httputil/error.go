@@ -1,12 +1,134 @@
 package httpUtil
 
-import "github.com/gin-gonic/gin"
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/logging"
+	"strconv"
+	"strings"
 
-type ResponseError struct {
-	Error string `json:"err" example:"error occures"`
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is RFC 7807 problem details document.
+// Clients should switch on code, detail is human readable and may change.
+type Problem struct {
+	Type     string                `json:"type" example:"about:blank"`
+	Title    string                `json:"title" example:"Not Found"`
+	Status   int                   `json:"status" example:"404"`
+	Detail   string                `json:"detail,omitempty" example:"rent is not exist"`
+	Instance string                `json:"instance,omitempty" example:"/api/Rent/1"`
+	Code     string                `json:"code" example:"rent_not_found"`
+	Errors   []entities.FieldError `json:"errors,omitempty"`
+	// RequestId is the same as X-Request-ID response header, it helps to find the request in logs
+	RequestId string `json:"requestId,omitempty" example:"3f2a9c1b7d4e4f0a8b6c5d2e1f0a9b8c"`
+}
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          entities.CodeValidationFailed,
+	http.StatusUnauthorized:        entities.CodeUnauthorized,
+	http.StatusForbidden:           entities.CodeForbidden,
+	http.StatusNotFound:            entities.CodeNotFound,
+	http.StatusConflict:            entities.CodeConflict,
+	http.StatusTooManyRequests:     entities.CodeRateLimited,
+	http.StatusInternalServerError: entities.CodeInternal,
 }
 
 func NewResponseError(ctx *gin.Context, code int, msg string) {
-	responseErr := ResponseError{Error: msg}
-	ctx.AbortWithStatusJSON(code, responseErr)
+	NewResponseErrorWithCode(ctx, code, statusCodes[code], msg)
+}
+
+// NewResponseErrorWithCode adds machine readable errCode to the response
+func NewResponseErrorWithCode(ctx *gin.Context, code int, errCode, msg string) {
+	writeProblem(ctx, Problem{Status: code, Code: errCode, Detail: msg})
+}
+
+// NewResponseErrorFrom writes usecase error with status depending on its kind.
+// Errors without kind are treated as internal: clients get only generic message,
+// details are logged by the logger middleware from ctx.Errors.
+func NewResponseErrorFrom(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, entities.ErrValidation):
+		status = http.StatusBadRequest
+	case errors.Is(err, entities.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, entities.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, entities.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, entities.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, entities.ErrInsufficientFunds):
+		status = http.StatusPaymentRequired
+	case errors.Is(err, entities.ErrTooManyRequests):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, entities.ErrPaymentGateway):
+		status = http.StatusBadGateway
+	}
+
+	var domainErr *entities.Error
+	if status == http.StatusInternalServerError || !errors.As(err, &domainErr) {
+		NewResponseError(ctx, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if domainErr.RetryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(domainErr.RetryAfter.Seconds()))))
+	}
+	code := domainErr.Code
+	if code == "" {
+		code = statusCodes[status]
+	}
+	writeProblem(ctx, Problem{Status: status, Code: code, Detail: domainErr.Message, Errors: domainErr.Fields})
+}
+
+// NewBindingError converts error of request body binding to validation error
+// with details for every invalid field
+func NewBindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]entities.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, entities.FieldError{
+				Field:   jsonFieldName(fe.Field()),
+				Message: "failed on the '" + fe.Tag() + "' rule",
+			})
+		}
+		return entities.NewValidationError(entities.CodeValidationFailed, "request body has invalid fields", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return entities.NewValidationError(entities.CodeValidationFailed, "request body has invalid fields", entities.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be " + typeErr.Type.String(),
+		})
+	}
+	if errors.Is(err, io.EOF) {
+		return entities.NewValidationError(entities.CodeInvalidBody, "request body is empty")
+	}
+	return entities.NewValidationError(entities.CodeInvalidBody, "request body is not valid json")
+}
+
+func jsonFieldName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+func writeProblem(ctx *gin.Context, problem Problem) {
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = ctx.Request.URL.Path
+	problem.RequestId = logging.RequestId(ctx.Request.Context())
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
 }
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Header is sent by clients to make retries of mutating requests safe
+const Header = "Idempotency-Key"
+
+// MaxKeyLength limits length of the client's key
+const MaxKeyLength = 255
+
+// Response is the stored response which is replayed on retries
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Record is the state of the key, it is completed when the response is stored.
+// Key which is not completed is locked by the request since LockedAt.
+type Record struct {
+	Fingerprint string
+	Completed   bool
+	Response    Response
+	LockedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// Store keeps idempotency keys with responses until they expire.
+type Store interface {
+	// Begin reserves the key for the request with fingerprint.
+	// If the key is already taken, its record is returned and ok is false.
+	// Key which is not completed within the lock timeout of the store can be taken by the same request again.
+	Begin(key, fingerprint string, expiresAt time.Time) (record Record, ok bool, err error)
+	// Complete stores the response of the request which reserved the key
+	Complete(key string, response Response) error
+	// Release removes the key without response, so the request can be retried
+	Release(key string) error
+}
+
+// Fingerprint identifies the request, the same key with other fingerprint is rejected
+func Fingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(uri))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type MemoryStore struct {
+	mu          sync.Mutex
+	records     map[string]Record
+	lockTimeout time.Duration
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+func NewMemoryStore(lockTimeout time.Duration) *MemoryStore {
+	return &MemoryStore{
+		records:     make(map[string]Record),
+		lockTimeout: lockTimeout,
+		now:         time.Now,
+	}
+}
+
+func (s *MemoryStore) Begin(key, fingerprint string, expiresAt time.Time) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	now := s.now()
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) && !s.stale(record, fingerprint, now) {
+		return record, false, nil
+	}
+	s.records[key] = Record{Fingerprint: fingerprint, LockedAt: now, ExpiresAt: expiresAt}
+	return Record{}, true, nil
+}
+
+func (s *MemoryStore) Complete(key string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	record.Completed = true
+	record.Response = response
+	s.records[key] = record
+	return nil
+}
+
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && !record.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// stale reports whether the lock of the request with the fingerprint is over
+func (s *MemoryStore) stale(record Record, fingerprint string, now time.Time) bool {
+	return !record.Completed && record.Fingerprint == fingerprint && !now.Before(record.LockedAt.Add(s.lockTimeout))
+}
+
+// sweep removes expired records, it runs at most once a minute
+func (s *MemoryStore) sweep() {
+	now := s.now()
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore(30 * time.Second)
+	store.now = func() time.Time { return now }
+
+	_, ok, err := store.Begin("1:key", "fingerprint", now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// key is in progress until response is stored
+	record, ok, _ := store.Begin("1:key", "fingerprint", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.False(t, record.Completed)
+
+	response := Response{Status: 200, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+	assert.NoError(t, store.Complete("1:key", response))
+	record, ok, _ = store.Begin("1:key", "other", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.True(t, record.Completed)
+	assert.Equal(t, "fingerprint", record.Fingerprint)
+	assert.Equal(t, response, record.Response)
+
+	// completed key is not released
+	assert.NoError(t, store.Release("1:key"))
+	_, ok, _ = store.Begin("1:key", "fingerprint", now.Add(time.Minute))
+	assert.False(t, ok)
+
+	// released key can be taken again
+	_, ok, _ = store.Begin("2:key", "fingerprint", now.Add(time.Minute))
+	assert.True(t, ok)
+	assert.NoError(t, store.Release("2:key"))
+	_, ok, _ = store.Begin("2:key", "fingerprint", now.Add(time.Minute))
+	assert.True(t, ok)
+
+	// lock of the request which is not completed in time is taken over by its retry
+	_, ok, _ = store.Begin("3:key", "fingerprint", now.Add(time.Minute))
+	assert.True(t, ok)
+	now = now.Add(30 * time.Second)
+	record, ok, _ = store.Begin("3:key", "other", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, "fingerprint", record.Fingerprint)
+	_, ok, _ = store.Begin("3:key", "fingerprint", now.Add(time.Minute))
+	assert.True(t, ok)
+
+	// records are removed after expiration
+	now = now.Add(2 * time.Minute)
+	_, ok, _ = store.Begin("1:key", "fingerprint", now.Add(time.Minute))
+	assert.True(t, ok)
+	assert.Len(t, store.records, 1)
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	expiresAt := time.Now().Add(time.Minute)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	taken := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, _ := store.Begin("key", "fingerprint", expiresAt); ok {
+				mu.Lock()
+				taken++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, taken)
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("POST", "/api/Rent/New/1?rentType=Minutes", nil)
+	assert.Equal(t, fingerprint, Fingerprint("POST", "/api/Rent/New/1?rentType=Minutes", nil))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/api/Rent/New/2?rentType=Minutes", nil))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/api/Rent/New/1?rentType=Minutes", []byte("{}")))
+}
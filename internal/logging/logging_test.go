@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"simbirGo/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId, SpanID: spanId, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = WithRequestId(ctx, "req-1")
+	log.DebugContext(ctx, "hidden")
+	log.InfoContext(ctx, "signed in",
+		slog.String("username", "alex"),
+		slog.String("password", "qwerty"),
+		slog.Group("headers", slog.String("Authorization", "Bearer abc"), slog.String("User-Agent", "curl")),
+		slog.String("refresh_token", "abc"),
+	)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "signed in", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+	assert.Equal(t, "alex", record["username"])
+	assert.Equal(t, Redacted, record["password"])
+	assert.Equal(t, Redacted, record["refresh_token"])
+	assert.Equal(t, map[string]any{"Authorization": Redacted, "User-Agent": "curl"}, record["headers"])
+}
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "Authorization", want: true},
+		{key: "password", want: true},
+		{key: "old_password", want: true},
+		{key: "jwt_secret", want: true},
+		{key: "refreshToken", want: true},
+		{key: "X-Fake-Signature", want: true},
+		{key: "Set-Cookie", want: true},
+		{key: "username", want: false},
+		{key: "token_ttl", want: false},
+		{key: "request_id", want: false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.key, func(t *testing.T) {
+			assert.Equal(t, testCase.want, IsSensitive(testCase.key))
+		})
+	}
+}
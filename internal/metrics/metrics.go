@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"simbirGo/internal/entities"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "simbirgo"
+
+// statsTimeout limits queries of gauges made on every scrape
+const statsTimeout = 5 * time.Second
+
+// StatsProvider returns current state of rents, it is queried on every scrape
+type StatsProvider interface {
+	GetStats(ctx context.Context) (entities.RentStats, error)
+}
+
+// Metrics keeps collectors of the service in its own registry.
+// Counters are incremented by usecases when business events happen,
+// gauges are read from StatsProvider when metrics are scraped.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+
+	rentsStarted    *prometheus.CounterVec
+	rentsEnded      *prometheus.CounterVec
+	revenueCharged  prometheus.Counter
+	revenueRefunded prometheus.Counter
+	signInsFailed   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duration of database queries by operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table", "status"}),
+		rentsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rents_started_total",
+			Help:      "Number of started rents by rent type.",
+		}, []string{"rent_type"}),
+		rentsEnded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rents_ended_total",
+			Help:      "Number of ended rents by rent type.",
+		}, []string{"rent_type"}),
+		revenueCharged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "revenue_charged_total",
+			Help:      "Money charged for ended rents.",
+		}),
+		revenueRefunded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "revenue_refunded_total",
+			Help:      "Money returned to users for refunded rents.",
+		}),
+		signInsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_ins_failed_total",
+			Help:      "Number of failed sign-ins by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestDuration,
+		m.queryDuration,
+		m.rentsStarted,
+		m.rentsEnded,
+		m.revenueCharged,
+		m.revenueRefunded,
+		m.signInsFailed,
+	)
+	return m
+}
+
+// Handler serves metrics in prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterStats adds gauges of active rents and available transports read from sp.
+// Failed reads are logged and the gauges are skipped in the scrape.
+func (m *Metrics) RegisterStats(sp StatsProvider, log *slog.Logger) error {
+	return m.registry.Register(statsCollector{sp: sp, log: log})
+}
+
+// ObserveRequest records the finished request, route is the path template like /api/Rent/:rentId
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(operation, table string, duration time.Duration, failed bool) {
+	status := "ok"
+	if failed {
+		status = "error"
+	}
+	m.queryDuration.WithLabelValues(operation, table, status).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RentStarted(rentType string) {
+	m.rentsStarted.WithLabelValues(rentType).Inc()
+}
+
+// RentEnded counts the ended rent and money charged for it
+func (m *Metrics) RentEnded(rentType string, charged float64) {
+	m.rentsEnded.WithLabelValues(rentType).Inc()
+	if charged > 0 {
+		m.revenueCharged.Add(charged)
+	}
+}
+
+func (m *Metrics) RentRefunded(amount float64) {
+	if amount > 0 {
+		m.revenueRefunded.Add(amount)
+	}
+}
+
+func (m *Metrics) SignInFailed(reason string) {
+	m.signInsFailed.WithLabelValues(reason).Inc()
+}
+
+var (
+	activeRentsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "active_rents"),
+		"Number of rents which are not ended.", nil, nil)
+	availableTransportsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "available_transports"),
+		"Number of transports which can be rented by transport type.", []string{"transport_type"}, nil)
+)
+
+// statsCollector reads gauges from the database on scrape, so they are right after restarts
+type statsCollector struct {
+	sp  StatsProvider
+	log *slog.Logger
+}
+
+func (c statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeRentsDesc
+	ch <- availableTransportsDesc
+}
+
+func (c statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	stats, err := c.sp.GetStats(ctx)
+	if err != nil {
+		c.log.ErrorContext(ctx, "failed to collect rent stats", slog.Any("error", err))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(activeRentsDesc, prometheus.GaugeValue, float64(stats.ActiveRents))
+	for transportType, count := range stats.AvailableTransports {
+		ch <- prometheus.MustNewConstMetric(availableTransportsDesc, prometheus.GaugeValue, float64(count), transportType)
+	}
+}
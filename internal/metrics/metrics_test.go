@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/logging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStats struct {
+	stats entities.RentStats
+	err   error
+}
+
+func (f fakeStats) GetStats(ctx context.Context) (entities.RentStats, error) {
+	return f.stats, f.err
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterStats(fakeStats{stats: entities.RentStats{
+		ActiveRents:         3,
+		AvailableTransports: map[string]int64{"Car": 2, "Bike": 0},
+	}}, logging.Discard()))
+
+	m.ObserveRequest("GET", "/api/Rent/:rentId", 200, 20*time.Millisecond)
+	m.ObserveQuery("query", "rents", time.Millisecond, false)
+	m.RentStarted("Minutes")
+	m.RentEnded("Minutes", 15.5)
+	m.RentRefunded(5)
+	m.SignInFailed("invalid_password")
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`simbirgo_http_request_duration_seconds_count{method="GET",route="/api/Rent/:rentId",status="200"} 1`,
+		`simbirgo_db_query_duration_seconds_count{operation="query",status="ok",table="rents"} 1`,
+		`simbirgo_rents_started_total{rent_type="Minutes"} 1`,
+		`simbirgo_rents_ended_total{rent_type="Minutes"} 1`,
+		`simbirgo_revenue_charged_total 15.5`,
+		`simbirgo_revenue_refunded_total 5`,
+		`simbirgo_sign_ins_failed_total{reason="invalid_password"} 1`,
+		`simbirgo_active_rents 3`,
+		`simbirgo_available_transports{transport_type="Bike"} 0`,
+		`simbirgo_available_transports{transport_type="Car"} 2`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
+
+func TestMetrics_StatsError(t *testing.T) {
+	m := New()
+	require.NoError(t, m.RegisterStats(fakeStats{err: errors.New("connection refused")}, logging.Discard()))
+	m.RentStarted("Days")
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `simbirgo_rents_started_total{rent_type="Days"} 1`)
+	assert.NotContains(t, body, "simbirgo_active_rents")
+}
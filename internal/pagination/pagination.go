@@ -0,0 +1,132 @@
+// Package pagination parses cursor pagination params of list endpoints.
+// Cursor points to the last item of the previous page, so deleted rows
+// do not shift the pages like offsets do.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"simbirGo/internal/entities"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned for cursors which were not issued for the requested sort
+var ErrInvalidCursor = entities.NewInvalidParamError("cursor", "must be nextCursor of the previous page with the same sort")
+
+// Sort is a field the list is sorted by, ties are broken by id
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses "field" for ascending and "-field" for descending order
+func ParseSort(s string) Sort {
+	if field, ok := strings.CutPrefix(s, "-"); ok {
+		return Sort{Field: field, Desc: true}
+	}
+	return Sort{Field: s}
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor is position of the last item of the page
+type Cursor struct {
+	Sort string `json:"s"`
+	// Value is the sort field of the item, it is empty when the list is sorted by id
+	Value string `json:"v,omitempty"`
+	Id    uint   `json:"id"`
+}
+
+// Encode returns opaque string for the client
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Request is a page requested by the client
+type Request struct {
+	Limit int
+	Sort  Sort
+	// After is the cursor of the previous page, nil for the first page
+	After *Cursor
+}
+
+// Parse reads limit, sort and cursor query params.
+// Sort must be one of fields, defaultSort is used when it is not set.
+func Parse(query url.Values, defaultSort string, fields ...string) (Request, error) {
+	req := Request{Limit: DefaultLimit, Sort: ParseSort(defaultSort)}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return Request{}, entities.NewInvalidParamError("limit", "must be an integer from 1 to "+strconv.Itoa(MaxLimit))
+		}
+		req.Limit = limit
+	}
+
+	if sortStr := query.Get("sort"); sortStr != "" {
+		req.Sort = ParseSort(sortStr)
+		if !slices.Contains(fields, req.Sort.Field) {
+			return Request{}, entities.NewInvalidParamError("sort", "must be one of "+strings.Join(fields, ", ")+" with optional - prefix for descending order")
+		}
+	}
+
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := DecodeCursor(cursorStr)
+		if err != nil {
+			return Request{}, err
+		}
+		if cursor.Sort != req.Sort.String() {
+			return Request{}, ErrInvalidCursor
+		}
+		req.After = &cursor
+	}
+
+	return req, nil
+}
+
+// Meta is position of the page in the list, NextCursor is empty on the last page
+type Meta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Total is number of items matching the filters on all pages
+	Total int64 `json:"total"`
+}
+
+// Page is the response of list endpoints,
+// swagger annotations set type of items like pagination.Page{items=[]entities.Rent}
+type Page struct {
+	Items any `json:"items"`
+	Meta
+}
+
+func NewPage[T any](items []T, meta Meta) Page {
+	if items == nil {
+		items = []T{}
+	}
+	return Page{Items: items, Meta: meta}
+}
@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"net/url"
+	"simbirGo/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cursor := Cursor{Sort: "-timeStart", Value: "2023-10-01T10:00:00Z", Id: 7}
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    Request
+		wantErr string
+	}{
+		{
+			name:  "defaults",
+			query: url.Values{},
+			want:  Request{Limit: DefaultLimit, Sort: Sort{Field: "timeStart"}},
+		},
+		{
+			name:  "next page",
+			query: url.Values{"limit": {"5"}, "sort": {"-timeStart"}, "cursor": {cursor.Encode()}},
+			want:  Request{Limit: 5, Sort: Sort{Field: "timeStart", Desc: true}, After: &cursor},
+		},
+		{
+			name:    "limit too big",
+			query:   url.Values{"limit": {"101"}},
+			wantErr: "limit",
+		},
+		{
+			name:    "limit is not a number",
+			query:   url.Values{"limit": {"ten"}},
+			wantErr: "limit",
+		},
+		{
+			name:    "unknown sort",
+			query:   url.Values{"sort": {"password"}},
+			wantErr: "sort",
+		},
+		{
+			name:    "cursor of other sort",
+			query:   url.Values{"sort": {"timeStart"}, "cursor": {cursor.Encode()}},
+			wantErr: "cursor",
+		},
+		{
+			name:    "malformed cursor",
+			query:   url.Values{"cursor": {"not a cursor"}},
+			wantErr: "cursor",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			req, err := Parse(testCase.query, "timeStart", "id", "timeStart")
+			if testCase.wantErr != "" {
+				var e *entities.Error
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, entities.CodeInvalidParam, e.Code)
+				assert.Equal(t, testCase.wantErr, e.Fields[0].Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.want, req)
+		})
+	}
+}
+
+func TestCursor_Encode(t *testing.T) {
+	cursor := Cursor{Sort: "username", Value: "alice", Id: 42}
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestNewPage(t *testing.T) {
+	page := NewPage[int](nil, Meta{Total: 0})
+	assert.Equal(t, []int{}, page.Items, "empty list is encoded as [] for clients")
+}
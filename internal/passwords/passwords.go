@@ -0,0 +1,59 @@
+package passwords
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const cost = bcrypt.DefaultCost
+
+// Hash returns bcrypt hash of the password.
+func Hash(password string) (string, error) {
+	op := "passwords.Hash()"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", fmt.Errorf("password is too long")
+		}
+		return "", fmt.Errorf("%s: failed to hash password: %w", op, err)
+	}
+	return string(hash), nil
+}
+
+// Compare checks password against the stored value.
+// Accounts created before hashing was introduced keep their password in plaintext,
+// for them (and for hashes with outdated cost) needRehash is true,
+// so the caller can replace the stored value after successful sign in.
+func Compare(stored, password string) (ok bool, needRehash bool) {
+	if !isHash(stored) {
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)); err != nil {
+		return false, false
+	}
+	hashCost, err := bcrypt.Cost([]byte(stored))
+	return true, err != nil || hashCost < cost
+}
+
+// dummyHash is compared with passwords of unknown users
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), cost)
+	return hash
+})
+
+// CompareDummy takes as long as Compare of a hashed password,
+// so unknown users can not be told apart by response time.
+func CompareDummy(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+}
+
+func isHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") ||
+		strings.HasPrefix(stored, "$2y$")
+}
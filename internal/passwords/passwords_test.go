@@ -0,0 +1,63 @@
+package passwords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCompare(t *testing.T) {
+	hash, err := Hash("secret")
+	require.NoError(t, err)
+	lowCost, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		stored     string
+		password   string
+		wantOk     bool
+		wantRehash bool
+	}{
+		{name: "legacy plaintext", stored: "secret", password: "secret", wantOk: true, wantRehash: true},
+		{name: "legacy plaintext mismatch", stored: "secret", password: "wrong", wantOk: false, wantRehash: false},
+		{name: "bcrypt match", stored: hash, password: "secret", wantOk: true, wantRehash: false},
+		{name: "bcrypt mismatch", stored: hash, password: "wrong", wantOk: false, wantRehash: false},
+		{name: "low cost hash", stored: string(lowCost), password: "secret", wantOk: true, wantRehash: true},
+		{name: "low cost hash mismatch", stored: string(lowCost), password: "wrong", wantOk: false, wantRehash: false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, needRehash := Compare(testCase.stored, testCase.password)
+			assert.Equal(t, testCase.wantOk, ok)
+			assert.Equal(t, testCase.wantRehash, needRehash)
+		})
+	}
+}
+
+func TestIsHash(t *testing.T) {
+	tests := []struct {
+		stored string
+		want   bool
+	}{
+		{stored: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", want: true},
+		{stored: "$2b$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", want: true},
+		{stored: "$2y$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", want: true},
+		{stored: "password", want: false},
+		{stored: "$1$md5crypt", want: false},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.stored, func(t *testing.T) {
+			assert.Equal(t, testCase.want, isHash(testCase.stored))
+		})
+	}
+}
+
+func TestHash_TooLong(t *testing.T) {
+	_, err := Hash(string(make([]byte, 73)))
+	assert.EqualError(t, err, "password is too long")
+}
@@ -0,0 +1,172 @@
+package payments
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FakeSignatureHeader keeps "t=<unix time>,v1=<hex HMAC-SHA256 of time.body>"
+	FakeSignatureHeader = "X-Fake-Signature"
+	// signatureTolerance limits age of webhooks to prevent replays
+	signatureTolerance = 5 * time.Minute
+)
+
+type fakeIntent struct {
+	amount    float64
+	reference string
+}
+
+// FakeGateway is local payment provider: intents are kept in memory
+// and completed by Complete, which sends signed webhook to webhookURL.
+type FakeGateway struct {
+	secret     []byte
+	webhookURL string
+	client     *http.Client
+
+	mu      sync.Mutex
+	intents map[string]fakeIntent
+	now     func() time.Time
+}
+
+// NewFakeGateway creates gateway signing webhooks with secret,
+// random secret is used if it is empty, so only this process can verify them
+func NewFakeGateway(secret, webhookURL string) *FakeGateway {
+	if secret == "" {
+		random := make([]byte, 32)
+		rand.Read(random)
+		secret = hex.EncodeToString(random)
+	}
+	return &FakeGateway{
+		secret:     []byte(secret),
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		intents:    make(map[string]fakeIntent),
+		now:        time.Now,
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateIntent(amount float64, reference string) (Intent, error) {
+	id, err := randomId("pi_")
+	if err != nil {
+		return Intent{}, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.intents[id] = fakeIntent{amount: amount, reference: reference}
+	return Intent{Id: id}, nil
+}
+
+// Complete finishes the intent and delivers its webhook
+func (g *FakeGateway) Complete(intentId string, status Status, failureReason string) error {
+	op := "payments.FakeGateway.Complete()"
+	header, body, err := g.SignedEvent(intentId, status, failureReason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header = header
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: webhook is rejected with status %d", op, resp.StatusCode)
+	}
+	return nil
+}
+
+// SignedEvent builds webhook of the intent without sending it
+func (g *FakeGateway) SignedEvent(intentId string, status Status, failureReason string) (http.Header, []byte, error) {
+	g.mu.Lock()
+	intent, ok := g.intents[intentId]
+	g.mu.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("intent %s is not exist", intentId)
+	}
+	eventId, err := randomId("evt_")
+	if err != nil {
+		return nil, nil, err
+	}
+	event := Event{Id: eventId, IntentId: intentId, Status: status, Amount: intent.amount}
+	if status == Failed {
+		event.FailureReason = failureReason
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timestamp := strconv.FormatInt(g.now().Unix(), 10)
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(FakeSignatureHeader, "t="+timestamp+",v1="+g.sign(timestamp, body))
+	return header, body, nil
+}
+
+func (g *FakeGateway) ParseWebhook(header http.Header, body []byte) (Event, error) {
+	var timestamp, signature string
+	for _, part := range strings.Split(header.Get(FakeSignatureHeader), ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Event{}, ErrInvalidSignature
+	}
+	if age := g.now().Sub(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return Event{}, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(g.sign(timestamp, body))) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+	if event.IntentId == "" || (event.Status != Succeeded && event.Status != Failed) {
+		return Event{}, ErrInvalidEvent
+	}
+	return event, nil
+}
+
+func (g *FakeGateway) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomId(prefix string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,76 @@
+package payments
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGateway_ParseWebhook(t *testing.T) {
+	now := time.Now()
+	gateway := NewFakeGateway("secret", "")
+	gateway.now = func() time.Time { return now }
+	intent, err := gateway.CreateIntent(500, "1")
+	require.NoError(t, err)
+
+	header, body, err := gateway.SignedEvent(intent.Id, Succeeded, "")
+	require.NoError(t, err)
+	event, err := gateway.ParseWebhook(header, body)
+	require.NoError(t, err)
+	assert.Equal(t, intent.Id, event.IntentId)
+	assert.Equal(t, Succeeded, event.Status)
+	assert.Equal(t, float64(500), event.Amount)
+
+	// body is changed
+	_, err = gateway.ParseWebhook(header, append(body, ' '))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// signed by other secret
+	other := NewFakeGateway("other", "")
+	_, err = other.ParseWebhook(header, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// replayed later
+	now = now.Add(10 * time.Minute)
+	_, err = gateway.ParseWebhook(header, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = gateway.ParseWebhook(http.Header{}, body)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestFakeGateway_Complete(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event, err := NewFakeGateway("secret", "").ParseWebhook(r.Header, body)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = event
+	}))
+	defer server.Close()
+
+	gateway := NewFakeGateway("secret", server.URL)
+	intent, err := gateway.CreateIntent(100, "2")
+	require.NoError(t, err)
+
+	require.NoError(t, gateway.Complete(intent.Id, Failed, "card declined"))
+	assert.Equal(t, intent.Id, received.IntentId)
+	assert.Equal(t, Failed, received.Status)
+	assert.Equal(t, "card declined", received.FailureReason)
+
+	assert.Error(t, gateway.Complete("unknown", Succeeded, ""))
+
+	// webhook signed by other secret is rejected
+	other := NewFakeGateway("other", server.URL)
+	otherIntent, err := other.CreateIntent(100, "3")
+	require.NoError(t, err)
+	assert.Error(t, other.Complete(otherIntent.Id, Succeeded, ""))
+}
@@ -0,0 +1,52 @@
+package payments
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Status is final status of payment reported by webhook
+type Status string
+
+const (
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+var (
+	// ErrInvalidSignature means the webhook is not signed by the gateway or is too old
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidEvent means the webhook body can not be parsed
+	ErrInvalidEvent = errors.New("invalid webhook event")
+)
+
+// Intent is payment registered at the provider and waiting for the user
+type Intent struct {
+	Id string
+	// ConfirmationURL is the page where the user pays, it is empty if the provider has none
+	ConfirmationURL string
+}
+
+// Event is result of the payment reported by webhook
+type Event struct {
+	Id            string  `json:"id"`
+	IntentId      string  `json:"intentId"`
+	Status        Status  `json:"status"`
+	Amount        float64 `json:"amount"`
+	FailureReason string  `json:"failureReason,omitempty"`
+}
+
+// Gateway creates top-up payments at payment provider and verifies its webhooks.
+type Gateway interface {
+	// Name identifies the provider, intent ids are unique within it
+	Name() string
+	// CreateIntent registers payment of amount, reference is id of the top-up in this service
+	CreateIntent(amount float64, reference string) (Intent, error)
+	// ParseWebhook verifies signature of the callback and returns its event
+	ParseWebhook(header http.Header, body []byte) (Event, error)
+}
+
+// Simulator is implemented by gateways which can complete payments without the provider
+type Simulator interface {
+	Complete(intentId string, status Status, failureReason string) error
+}
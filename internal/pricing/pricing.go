@@ -0,0 +1,173 @@
+package pricing
+
+import (
+	"math"
+	"time"
+)
+
+// Unit is the unit rent is charged for
+type Unit int
+
+const (
+	Minute Unit = iota + 1
+	Day
+)
+
+const minutesPerDay = 24 * 60
+
+// Policy describes pricing options applied on top of the transport prices.
+// Zero value charges every started unit by its price.
+type Policy struct {
+	// UnlockFee is charged once for every rent
+	UnlockFee float64 `json:"unlockFee"`
+	// MinimumCharge is the least price of the rent, unlock fee included
+	MinimumCharge float64 `json:"minimumCharge"`
+	// FreeMinutes at the beginning of per-minute rent are not charged
+	FreeMinutes int `json:"freeMinutes"`
+	// DayRateSwitch charges per-minute rent by the day price for days where it is cheaper
+	DayRateSwitch bool `json:"dayRateSwitch"`
+	// DailyCap limits the charge for every 24 hours of the rent
+	DailyCap float64 `json:"dailyCap"`
+	// NightMultiplier applies to minutes started in [NightStart, NightEnd) hours, 0 means no multiplier
+	NightMultiplier float64 `json:"nightMultiplier"`
+	NightStart      int     `json:"nightStart" example:"23"`
+	NightEnd        int     `json:"nightEnd" example:"6"`
+	// WeekendMultiplier applies to minutes started on Saturday and Sunday, 0 means no multiplier
+	WeekendMultiplier float64 `json:"weekendMultiplier"`
+	// Timezone of night hours and weekends in IANA format, server timezone if empty
+	Timezone string `json:"timezone" example:"Europe/Ulyanovsk"`
+	// MinutesHold and DaysHold are reserved from the balance when per-minute and per-day rents start,
+	// 0 means the price of default hold period
+	MinutesHold float64 `json:"minutesHold"`
+	DaysHold    float64 `json:"daysHold"`
+}
+
+// Tariff is the policy with transport prices, it is saved with the rent
+// so the price can be calculated the same way later.
+type Tariff struct {
+	Policy
+	MinutePrice float64 `json:"minutePrice"`
+	DayPrice    float64 `json:"dayPrice"`
+	// Discount of promo code attached to the rent
+	Discount *Discount `json:"discount,omitempty"`
+}
+
+type DiscountKind string
+
+const (
+	Percent DiscountKind = "percent"
+	Fixed   DiscountKind = "fixed"
+)
+
+// Discount is percentage of the price or fixed amount of money
+type Discount struct {
+	Kind  DiscountKind `json:"kind" enums:"percent, fixed"`
+	Value float64      `json:"value"`
+}
+
+// Apply returns amount of the discount for the price, it does not exceed the price
+func (d Discount) Apply(price float64) float64 {
+	var discount float64
+	switch d.Kind {
+	case Percent:
+		discount = price * d.Value / 100
+	case Fixed:
+		discount = d.Value
+	}
+	if discount > price {
+		discount = price
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return math.Round(discount*100) / 100
+}
+
+// Price returns the price of the rent from start to end.
+// Per-minute rent is charged for every started minute with night and weekend multipliers,
+// per-day rent is charged for every started day.
+// Day rate switch and daily cap are applied to every 24 hours since the start of the rent.
+func (t Tariff) Price(unit Unit, start, end time.Time) float64 {
+	var charge float64
+	switch unit {
+	case Minute:
+		charge = t.minutesCharge(start, end)
+	case Day:
+		charge = t.daysCharge(start, end)
+	}
+
+	price := t.UnlockFee + charge
+	if price < t.MinimumCharge {
+		price = t.MinimumCharge
+	}
+	return math.Round(price*100) / 100
+}
+
+func (t Tariff) minutesCharge(start, end time.Time) float64 {
+	minutes := int(math.Ceil(float64(end.Sub(start)) / float64(time.Minute)))
+	loc := t.location()
+
+	var charge float64
+	for day := 0; day*minutesPerDay < minutes; day++ {
+		var dayCharge float64
+		for m := day * minutesPerDay; m < minutes && m < (day+1)*minutesPerDay; m++ {
+			if m < t.FreeMinutes {
+				continue
+			}
+			dayCharge += t.MinutePrice * t.multiplier(start.Add(time.Duration(m)*time.Minute).In(loc))
+		}
+		if t.DayRateSwitch && t.DayPrice > 0 && t.DayPrice < dayCharge {
+			dayCharge = t.DayPrice
+		}
+		if t.DailyCap > 0 && t.DailyCap < dayCharge {
+			dayCharge = t.DailyCap
+		}
+		charge += dayCharge
+	}
+	return charge
+}
+
+func (t Tariff) daysCharge(start, end time.Time) float64 {
+	days := math.Ceil(float64(end.Sub(start)) / float64(24*time.Hour))
+	if days <= 0 {
+		return 0
+	}
+	dayPrice := t.DayPrice
+	if t.DailyCap > 0 && t.DailyCap < dayPrice {
+		dayPrice = t.DailyCap
+	}
+	return days * dayPrice
+}
+
+func (t Tariff) multiplier(at time.Time) float64 {
+	m := 1.0
+	if t.NightMultiplier > 0 && t.isNight(at.Hour()) {
+		m *= t.NightMultiplier
+	}
+	if t.WeekendMultiplier > 0 && (at.Weekday() == time.Saturday || at.Weekday() == time.Sunday) {
+		m *= t.WeekendMultiplier
+	}
+	return m
+}
+
+// isNight reports whether the hour is in [NightStart, NightEnd), the interval can cross midnight
+func (p Policy) isNight(hour int) bool {
+	switch {
+	case p.NightStart < p.NightEnd:
+		return hour >= p.NightStart && hour < p.NightEnd
+	case p.NightStart > p.NightEnd:
+		return hour >= p.NightStart || hour < p.NightEnd
+	}
+	return false
+}
+
+func (p Policy) location() *time.Location {
+	if p.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
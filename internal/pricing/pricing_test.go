@@ -0,0 +1,168 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTariff_Price(t *testing.T) {
+	// Wednesday
+	start := time.Date(2023, 5, 3, 10, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name     string
+		tariff   Tariff
+		unit     Unit
+		start    time.Time
+		duration time.Duration
+		expected float64
+	}{
+		{
+			name:     "Every started minute",
+			tariff:   Tariff{MinutePrice: 10},
+			unit:     Minute,
+			start:    start,
+			duration: 90 * time.Second,
+			expected: 20,
+		},
+		{
+			name:     "Every started day",
+			tariff:   Tariff{DayPrice: 1000},
+			unit:     Day,
+			start:    start,
+			duration: 25 * time.Hour,
+			expected: 2000,
+		},
+		{
+			name:     "Unlock fee",
+			tariff:   Tariff{Policy: Policy{UnlockFee: 50}, MinutePrice: 10},
+			unit:     Minute,
+			start:    start,
+			duration: 5 * time.Minute,
+			expected: 100,
+		},
+		{
+			name:     "Minimum charge",
+			tariff:   Tariff{Policy: Policy{UnlockFee: 50, MinimumCharge: 150}, MinutePrice: 10},
+			unit:     Minute,
+			start:    start,
+			duration: 5 * time.Minute,
+			expected: 150,
+		},
+		{
+			name:     "Free minutes",
+			tariff:   Tariff{Policy: Policy{FreeMinutes: 3}, MinutePrice: 10},
+			unit:     Minute,
+			start:    start,
+			duration: 5 * time.Minute,
+			expected: 20,
+		},
+		{
+			name:     "Switch to the day rate",
+			tariff:   Tariff{Policy: Policy{DayRateSwitch: true}, MinutePrice: 10, DayPrice: 1000},
+			unit:     Minute,
+			start:    start,
+			duration: 24*time.Hour + 10*time.Minute,
+			expected: 1100,
+		},
+		{
+			name:     "Day rate is more expensive",
+			tariff:   Tariff{Policy: Policy{DayRateSwitch: true}, MinutePrice: 10, DayPrice: 1000},
+			unit:     Minute,
+			start:    start,
+			duration: 30 * time.Minute,
+			expected: 300,
+		},
+		{
+			name:     "Daily cap",
+			tariff:   Tariff{Policy: Policy{DailyCap: 500}, MinutePrice: 10},
+			unit:     Minute,
+			start:    start,
+			duration: 48*time.Hour + time.Minute,
+			expected: 1010,
+		},
+		{
+			name:     "Daily cap of day rent",
+			tariff:   Tariff{Policy: Policy{DailyCap: 500}, DayPrice: 1000},
+			unit:     Day,
+			start:    start,
+			duration: 2 * time.Hour,
+			expected: 500,
+		},
+		{
+			name:     "Night multiplier across midnight",
+			tariff:   Tariff{Policy: Policy{NightMultiplier: 1.5, NightStart: 23, NightEnd: 6, Timezone: "UTC"}, MinutePrice: 10},
+			unit:     Minute,
+			start:    time.Date(2023, 5, 3, 22, 58, 0, 0, time.UTC),
+			duration: 4 * time.Minute,
+			expected: 10 + 10 + 15 + 15,
+		},
+		{
+			name:     "Night multiplier in the policy timezone",
+			tariff:   Tariff{Policy: Policy{NightMultiplier: 2, NightStart: 0, NightEnd: 6, Timezone: "Europe/Moscow"}, MinutePrice: 10},
+			unit:     Minute,
+			start:    time.Date(2023, 5, 3, 22, 0, 0, 0, time.UTC),
+			duration: time.Minute,
+			expected: 20,
+		},
+		{
+			name:     "Weekend and night multipliers",
+			tariff:   Tariff{Policy: Policy{NightMultiplier: 2, NightStart: 0, NightEnd: 6, WeekendMultiplier: 1.5, Timezone: "UTC"}, MinutePrice: 10},
+			unit:     Minute,
+			start:    time.Date(2023, 5, 6, 5, 59, 0, 0, time.UTC),
+			duration: 2 * time.Minute,
+			expected: 30 + 15,
+		},
+		{
+			name:     "Rounded to kopecks",
+			tariff:   Tariff{MinutePrice: 0.1},
+			unit:     Minute,
+			start:    start,
+			duration: 3 * time.Minute,
+			expected: 0.3,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			price := testCase.tariff.Price(testCase.unit, testCase.start, testCase.start.Add(testCase.duration))
+			assert.Equal(t, testCase.expected, price)
+		})
+	}
+}
+
+func TestDiscount_Apply(t *testing.T) {
+	testTable := []struct {
+		name     string
+		discount Discount
+		price    float64
+		expected float64
+	}{
+		{
+			name:     "Percent",
+			discount: Discount{Kind: Percent, Value: 15},
+			price:    333,
+			expected: 49.95,
+		},
+		{
+			name:     "Fixed",
+			discount: Discount{Kind: Fixed, Value: 100},
+			price:    333,
+			expected: 100,
+		},
+		{
+			name:     "Fixed above the price",
+			discount: Discount{Kind: Fixed, Value: 500},
+			price:    333,
+			expected: 333,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.discount.Apply(testCase.price))
+		})
+	}
+}
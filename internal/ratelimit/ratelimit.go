@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests in Period, all of them can be made at once.
+// Tokens are refilled evenly, one every Period/Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter is a token bucket limiter keeping a bucket per key in memory.
+// Limits are not shared between instances of the server.
+type Limiter struct {
+	limit     Limit
+	mu        sync.Mutex
+	buckets   map[string]bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New(limit Limit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		buckets: make(map[string]bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty
+// it returns false and how long to wait for the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b := l.refill(key, now)
+	if b.tokens < 1 {
+		l.buckets[key] = b
+		return false, time.Duration(math.Ceil((1 - b.tokens) * float64(l.interval())))
+	}
+	b.tokens--
+	l.buckets[key] = b
+	return true, 0
+}
+
+// refill adds tokens accumulated since the last request, new bucket is full
+func (l *Limiter) refill(key string, now time.Time) bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		return bucket{tokens: float64(l.limit.Requests), updated: now}
+	}
+	b.tokens = math.Min(float64(l.limit.Requests), b.tokens+float64(now.Sub(b.updated))/float64(l.interval()))
+	b.updated = now
+	return b
+}
+
+// interval is time to refill one token
+func (l *Limiter) interval() time.Duration {
+	return l.limit.Period / time.Duration(l.limit.Requests)
+}
+
+// sweep removes buckets which are full again, it runs at most once a period
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.limit.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := New(Limit{Requests: 3, Period: time.Minute})
+	limiter.now = func() time.Time { return now }
+
+	// full bucket allows a burst of requests
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("ip:1.2.3.4")
+		assert.True(t, ok)
+	}
+	ok, retryAfter := limiter.Allow("ip:1.2.3.4")
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, retryAfter)
+
+	// other keys have own buckets
+	ok, _ = limiter.Allow("ip:5.6.7.8")
+	assert.True(t, ok)
+
+	// one token is refilled every period/requests
+	now = now.Add(15 * time.Second)
+	ok, retryAfter = limiter.Allow("ip:1.2.3.4")
+	assert.False(t, ok)
+	assert.Equal(t, 5*time.Second, retryAfter)
+	now = now.Add(5 * time.Second)
+	ok, _ = limiter.Allow("ip:1.2.3.4")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("ip:1.2.3.4")
+	assert.False(t, ok)
+
+	// idle buckets are removed once they are full
+	now = now.Add(2 * time.Minute)
+	ok, _ = limiter.Allow("ip:1.2.3.4")
+	assert.True(t, ok)
+	assert.Len(t, limiter.buckets, 1)
+}
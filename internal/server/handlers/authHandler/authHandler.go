@@ -1,11 +1,16 @@
 package authHandler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"simbirGo/internal/entities"
 	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	middleware "simbirGo/internal/server/middlewares"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,17 +18,21 @@ import (
 //go:generate mockgen -source=authHandler.go -destination=mock/mock.go
 type AuthUsecase interface {
 	//user's cases
-	MyAccount(id uint) (entities.User, error)
-	SignIn(user entities.User) (string, error)
-	SignUp(user entities.User) (entities.User, string, error)
-	SignOut(token string)
-	Update(user entities.User) (entities.User, error)
+	MyAccount(ctx context.Context, id uint) (entities.User, error)
+	SignIn(ctx context.Context, user entities.User) (entities.TokenPair, error)
+	SignUp(ctx context.Context, user entities.User) (entities.User, entities.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (entities.TokenPair, error)
+	SignOut(ctx context.Context, token string) error
+	JWKS() entities.JSONWebKeySet
+	Update(ctx context.Context, user entities.User) (entities.User, error)
 
 	//admin's cases
-	GetUsers(start, end uint) []entities.User
-	CreateUser(user entities.User) (entities.User, error)
-	UpdateUser(user entities.User) (entities.User, error)
-	DeleteUser(id uint) error
+	GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]entities.User, pagination.Meta, error)
+	CreateUser(ctx context.Context, user entities.User) (entities.User, error)
+	UpdateUser(ctx context.Context, update entities.UserUpdate) (entities.User, error)
+	DeleteUser(ctx context.Context, id uint) error
+	RevokeSessions(ctx context.Context, userId uint) error
+	UnlockUser(ctx context.Context, userId uint) error
 }
 
 type AuthHandlers struct {
@@ -42,14 +51,16 @@ func New(uc AuthUsecase) AuthHandlers {
 // @Produce  json
 // @Security ApiKeyAuth
 // @Success 200 {object} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Account/Me [get]
 func (ah AuthHandlers) UserMyAccount(ctx *gin.Context) {
 	id := ctx.GetUint("id")
-	user, err := ah.uc.MyAccount(id)
+	user, err := ah.uc.MyAccount(ctx.Request.Context(), id)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, user)
@@ -61,8 +72,11 @@ func (ah AuthHandlers) UserMyAccount(ctx *gin.Context) {
 // @Accept json
 // @Produce  json
 // @Param request body authHandler.UserSignIn.userCreadentials true "User credentials"
-// @Success 201 {object} string
-// @Failure 400 {object} httpUtil.ResponseError
+// @Success 201 {object} entities.TokenPair
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 429 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Account/SignIn [post]
 func (ah AuthHandlers) UserSignIn(ctx *gin.Context) {
 	type userCreadentials struct {
@@ -70,19 +84,61 @@ func (ah AuthHandlers) UserSignIn(ctx *gin.Context) {
 		Password string `json:"password" binding:"required"`
 	}
 	userCred := userCreadentials{}
-	if err := ctx.BindJSON(&userCred); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&userCred); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	user := entities.User{Username: userCred.Username, Password: userCred.Password}
-	token, err := ah.uc.SignIn(user)
+	tokenPair, err := ah.uc.SignIn(ctx.Request.Context(), user)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
-	ctx.SetCookie("access_token", token, 3600, "/", "localhost", false, true)
-	ctx.JSON(201, gin.H{"token": token})
+	setTokenCookies(ctx, tokenPair)
+	ctx.JSON(201, tokenPair)
+}
+
+// @Summary Обновление токена доступа
+// @Tags AccountController
+// @Description Обмен refresh токена на новую пару токенов. Каждый refresh токен можно использовать только один раз.
+// @Description Повторное использование refresh токена отзывает все токены текущей сессии.
+// @Description Если refreshToken не указан в теле запроса, используется cookie refresh_token.
+// @Accept json
+// @Produce  json
+// @Param request body authHandler.UserRefresh.refreshData false "Refresh token"
+// @Success 201 {object} entities.TokenPair
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 429 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Account/Refresh [post]
+func (ah AuthHandlers) UserRefresh(ctx *gin.Context) {
+	type refreshData struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	var data refreshData
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&data); err != nil {
+			ctx.Error(httpUtil.NewBindingError(err))
+			return
+		}
+	}
+	if data.RefreshToken == "" {
+		data.RefreshToken, _ = ctx.Cookie("refresh_token")
+	}
+	if data.RefreshToken == "" {
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "refresh token is required", entities.FieldError{Field: "refreshToken", Message: "is required"}))
+		return
+	}
+
+	tokenPair, err := ah.uc.Refresh(ctx.Request.Context(), data.RefreshToken)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	setTokenCookies(ctx, tokenPair)
+	ctx.JSON(201, tokenPair)
 }
 
 // @Summary Регистрация
@@ -92,48 +148,65 @@ func (ah AuthHandlers) UserSignIn(ctx *gin.Context) {
 // @Produce  json
 // @Param request body authHandler.UserSignUp.userData true "User data"
 // @Success 201 {object} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 429 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Account/SignUp [post]
 func (ah AuthHandlers) UserSignUp(ctx *gin.Context) {
 	type userData struct {
 		Username string `json:"username" binding:"required"`
 		Password string `json:"password" binding:"required"`
-		IsAdmin  bool   `json:"isAdmin"`
 	}
 	var usData userData
-	if err := ctx.BindJSON(&usData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&usData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 	user := entities.User{
 		Username: usData.Username,
 		Password: usData.Password,
-		IsAdmin:  usData.IsAdmin,
 	}
 
-	user, token, err := ah.uc.SignUp(user)
+	user, tokenPair, err := ah.uc.SignUp(ctx.Request.Context(), user)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
-	ctx.SetCookie("access_token", token, 360000, "/", "localhost", false, true)
+	setTokenCookies(ctx, tokenPair)
 	ctx.JSON(201, user)
 }
 
 // @Summary Выход из аккаунта
 // @Tags AccountController
-// @Description Внесение текущего используемого токена доступа в черный список токенов
+// @Description Отзыв текущего используемого токена доступа и refresh токенов текущей сессии
 // @Security ApiKeyAuth
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Account/SignOut [post]
 func (ah AuthHandlers) UserSignOut(ctx *gin.Context) {
 	token := strings.Split(ctx.GetHeader("Authorization"), " ")[1]
-	ah.uc.SignOut(token)
+	if err := ah.uc.SignOut(ctx.Request.Context(), token); err != nil {
+		ctx.Error(err)
+		return
+	}
 	ctx.Status(200)
 }
 
+// @Summary Публичные ключи
+// @Tags AccountController
+// @Description Публичные ключи в формате JWKS для проверки подписи jwt другими сервисами
+// @Produce json
+// @Success 200 {object} entities.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (ah AuthHandlers) JWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, ah.uc.JWKS())
+}
+
 // @Summary Обновление данных аккаунта
 // @Tags AccountController
 // @Description Обновление данных аккаунта username и password.
@@ -142,9 +215,13 @@ func (ah AuthHandlers) UserSignOut(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body authHandler.UserUpdate.userData true "User data"
-// @Success 200 {object} authHandler.UserUpdate.userData
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} authHandler.UserUpdate.responseData
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Account/Update [put]
 func (ah AuthHandlers) UserUpdate(ctx *gin.Context) {
 	type userData struct {
@@ -153,8 +230,8 @@ func (ah AuthHandlers) UserUpdate(ctx *gin.Context) {
 	}
 	var data userData
 
-	if err := ctx.BindJSON(&data); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 	id := ctx.GetUint("id")
@@ -163,14 +240,16 @@ func (ah AuthHandlers) UserUpdate(ctx *gin.Context) {
 		Username: data.Username,
 		Password: data.Password,
 	}
-	user, err := ah.uc.Update(user)
+	user, err := ah.uc.Update(ctx.Request.Context(), user)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, userData{
+	type responseData struct {
+		Username string `json:"username"`
+	}
+	ctx.JSON(http.StatusOK, responseData{
 		Username: user.Username,
-		Password: user.Password,
 	})
 }
 
@@ -178,34 +257,45 @@ func (ah AuthHandlers) UserUpdate(ctx *gin.Context) {
 
 // @Summary Получение данных пользователей
 // @Tags AdminAccountController
-// @Description Возращает информацию о count аккаунтах пользователей начиная с id = start
+// @Description Возвращает страницу аккаунтов пользователей.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
 // @Security ApiKeyAuth
 // @Produce json
-// @Param start query uint true "start"
-// @Param count query uint true "count"
-// @Success 200 {array} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id, username, -username) default(id)
+// @Param username query string false "Начало имени пользователя"
+// @Param isAdmin query bool false "Только администраторы или только не администраторы"
+// @Success 200 {object} pagination.Page{items=[]entities.User}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Account [get]
 func (ah AuthHandlers) AdminGetUsers(ctx *gin.Context) {
-	startStr := ctx.Query("start")
-	countStr := ctx.Query("count")
-	start, err := strconv.Atoi(startStr)
-	if err != nil || start < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of start query param")
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "id", "id", "username")
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	count, err := strconv.Atoi(countStr)
-	if err != nil || count < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of count query param")
-		return
+	filter := entities.UserFilter{UsernamePrefix: ctx.Query("username")}
+	if isAdminStr, ok := ctx.GetQuery("isAdmin"); ok {
+		isAdmin, err := strconv.ParseBool(isAdminStr)
+		if err != nil {
+			ctx.Error(entities.NewInvalidParamError("isAdmin", "must be true or false"))
+			return
+		}
+		filter.IsAdmin = &isAdmin
 	}
 
-	users := ah.uc.GetUsers(uint(start), uint(count))
+	users, meta, err := ah.uc.GetUsers(ctx.Request.Context(), filter, page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-	ctx.JSON(http.StatusOK, users)
+	ctx.JSON(http.StatusOK, pagination.NewPage(users, meta))
 }
 
 // @Summary Получение информации о пользователе
@@ -215,20 +305,22 @@ func (ah AuthHandlers) AdminGetUsers(ctx *gin.Context) {
 // @Produce json
 // @Param id path uint true "Account id"
 // @Success 200 {object} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Account/{id} [get]
 func (ah AuthHandlers) AdminGetUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	user, err := ah.uc.MyAccount(uint(id))
+	user, err := ah.uc.MyAccount(ctx.Request.Context(), uint(id))
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, user)
@@ -236,15 +328,20 @@ func (ah AuthHandlers) AdminGetUser(ctx *gin.Context) {
 
 // @Summary Создание нового пользователя
 // @Tags AdminAccountController
-// @Description Создание нового пользователя с указанными данными
+// @Description Создание нового пользователя с указанными данными.
+// @Description Для isAdmin = true требуется разрешение roles:manage, для ненулевого balance - balances:adjust.
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param request body authHandler.AdminCreateUser.userData true "User data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Account [post]
 func (ah AuthHandlers) AdminCreateUser(ctx *gin.Context) {
 	type userData struct {
@@ -255,10 +352,22 @@ func (ah AuthHandlers) AdminCreateUser(ctx *gin.Context) {
 	}
 
 	var usrData userData
-	if err := ctx.BindJSON(&usrData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&usrData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
+	if usrData.IsAdmin {
+		if err := checkGlobalPermission(ctx, entities.PermissionRolesManage, "isAdmin"); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+	if usrData.Balance != 0 {
+		if err := checkGlobalPermission(ctx, entities.PermissionBalancesAdjust, "balance"); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
 
 	user := entities.User{
 		Username: usrData.Username,
@@ -267,9 +376,9 @@ func (ah AuthHandlers) AdminCreateUser(ctx *gin.Context) {
 		Balance:  usrData.Balance,
 	}
 
-	user, err := ah.uc.CreateUser(user)
+	user, err := ah.uc.CreateUser(ctx.Request.Context(), user)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -278,37 +387,54 @@ func (ah AuthHandlers) AdminCreateUser(ctx *gin.Context) {
 
 // @Summary Обновление данных пользователя
 // @Tags AdminAccountController
-// @Description Обновление данных пользователя с id={id}
+// @Description Обновление данных пользователя с id={id}.
+// @Description isAdmin и balance не меняются, если не указаны. Для изменения isAdmin требуется разрешение roles:manage, для изменения balance - balances:adjust.
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path uint true "Account id"
 // @Param requset body authHandler.AdminUpdateUser.userData true "User data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200 {object} entities.User
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Account/{id} [put]
 func (ah AuthHandlers) AdminUpdateUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 	type userData struct {
-		Username string  `json:"username" binding:"required"`
-		Password string  `json:"password" binding:"required"`
-		IsAdmin  bool    `json:"isAdmin"`
-		Balance  float64 `json:"balance"`
+		Username string   `json:"username" binding:"required"`
+		Password string   `json:"password" binding:"required"`
+		IsAdmin  *bool    `json:"isAdmin"`
+		Balance  *float64 `json:"balance"`
 	}
 	var usrData userData
-	if err := ctx.BindJSON(&usrData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, "invalid data")
+	if err := ctx.ShouldBindJSON(&usrData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
+	if usrData.IsAdmin != nil {
+		if err := checkGlobalPermission(ctx, entities.PermissionRolesManage, "isAdmin"); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+	if usrData.Balance != nil {
+		if err := checkGlobalPermission(ctx, entities.PermissionBalancesAdjust, "balance"); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
 
-	user := entities.User{
+	update := entities.UserUpdate{
 		Id:       uint(id),
 		Username: usrData.Username,
 		Password: usrData.Password,
@@ -316,9 +442,9 @@ func (ah AuthHandlers) AdminUpdateUser(ctx *gin.Context) {
 		Balance:  usrData.Balance,
 	}
 
-	user, err = ah.uc.UpdateUser(user)
+	user, err := ah.uc.UpdateUser(ctx.Request.Context(), update)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, user)
@@ -329,22 +455,101 @@ func (ah AuthHandlers) AdminUpdateUser(ctx *gin.Context) {
 // @Description Удаление данных пользователя с id={id}
 // @Security ApiKeyAuth
 // @Param id path uint true "Account id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Account/{id} [delete]
 func (ah AuthHandlers) AdminDeleteUser(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
-	err = ah.uc.DeleteUser(uint(id))
+	err = ah.uc.DeleteUser(ctx.Request.Context(), uint(id))
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Завершение всех сессий пользователя
+// @Tags AdminAccountController
+// @Description Отзыв всех выданных токенов пользователя с id={id}
+// @Security ApiKeyAuth
+// @Param id path uint true "Account id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Account/{id}/RevokeSessions [post]
+func (ah AuthHandlers) AdminRevokeSessions(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	if err := ah.uc.RevokeSessions(ctx.Request.Context(), uint(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// checkGlobalPermission rejects change of the field by user without the permission for all operators
+func checkGlobalPermission(ctx *gin.Context, permission entities.Permission, field string) error {
+	if middleware.GetScope(ctx, permission).All {
+		return nil
+	}
+	return entities.NewForbiddenError(entities.CodePermissionRequired, fmt.Sprintf("permission %s is required to change %s", permission, field))
+}
+
+func setTokenCookies(ctx *gin.Context, tokenPair entities.TokenPair) {
+	ctx.SetCookie("access_token", tokenPair.AccessToken,
+		int(time.Until(tokenPair.AccessExpiresAt).Seconds()), "/", "localhost", false, true)
+	ctx.SetCookie("refresh_token", tokenPair.RefreshToken,
+		int(time.Until(tokenPair.RefreshExpiresAt).Seconds()), "/api/Account/Refresh", "localhost", false, true)
+}
+
+// @Summary Разблокировка аккаунта
+// @Tags AdminAccountController
+// @Description Снятие блокировки входа после неудачных попыток и сброс счетчика неудачных попыток пользователя с id={id}
+// @Security ApiKeyAuth
+// @Param id path uint true "Account id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Account/{id}/Unlock [post]
+func (ah AuthHandlers) AdminUnlockUser(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	if err := ah.uc.UnlockUser(ctx.Request.Context(), uint(id)); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -2,10 +2,14 @@ package authHandler
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"net/http/httptest"
 	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
 	mock_authHandler "simbirGo/internal/server/handlers/authHandler/mock"
+	middleware "simbirGo/internal/server/middlewares"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -14,12 +18,6 @@ import (
 )
 
 func TestAuthHandler_SignUp(t *testing.T) {
-	type userData struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-		IsAdmin  bool   `json:"isAdmin"`
-	}
-
 	type mockBehavior func(s *mock_authHandler.MockAuthUsecase, user entities.User)
 
 	testTable := []struct {
@@ -31,33 +29,31 @@ func TestAuthHandler_SignUp(t *testing.T) {
 		expectedRequestBody string
 	}{
 		{
-			name:      "OK",
+			name:      "Admin flag is ignored",
 			inputBody: `{"username":"foo","password":"bar","isAdmin":true}`,
 			inputUser: entities.User{
 				Id:       0,
 				Username: "foo",
 				Password: "bar",
-				IsAdmin:  true,
 				Balance:  0,
 			},
 			mockBehavior: func(s *mock_authHandler.MockAuthUsecase, user entities.User) {
-				s.EXPECT().SignUp(user).Return(entities.User{
+				s.EXPECT().SignUp(gomock.Any(), user).Return(entities.User{
 					Id:       1,
 					Username: "foo",
-					Password: "bar",
-					IsAdmin:  true,
+					Password: "$2a$10$hash",
 					Balance:  0,
-				}, "token", nil)
+				}, entities.TokenPair{AccessToken: "token"}, nil)
 			},
 			expectedStatusCode:  201,
-			expectedRequestBody: `{"id":1,"username":"foo","password":"bar","isAdmin":true,"balance":0}`,
+			expectedRequestBody: `{"id":1,"username":"foo","isAdmin":false,"balance":0}`,
 		},
 		{
 			name:                "Empty fields",
 			inputBody:           `{"password":"bar","isAdmin":true}`,
 			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase, user entities.User) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"err":"Key: 'userData.Username' Error:Field validation for 'Username' failed on the 'required' tag"}`,
+			expectedRequestBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"request body has invalid fields","instance":"/signUp","code":"validation_failed","errors":[{"field":"username","message":"failed on the 'required' rule"}]}`,
 		},
 		{
 			name:      "Usecase error",
@@ -66,13 +62,49 @@ func TestAuthHandler_SignUp(t *testing.T) {
 				Id:       0,
 				Username: "foo",
 				Password: "bar",
-				IsAdmin:  true,
 				Balance:  0,
 			},
 			mockBehavior: func(s *mock_authHandler.MockAuthUsecase, user entities.User) {
-				s.EXPECT().SignUp(user).Return(entities.User{}, "token", errors.New("something went wrong"))
+				s.EXPECT().SignUp(gomock.Any(), user).Return(entities.User{}, entities.TokenPair{}, errors.New("something went wrong"))
+			}, expectedStatusCode: 500,
+			expectedRequestBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error","instance":"/signUp","code":"internal_error"}`,
+		},
+		{
+			name:      "Username is taken",
+			inputBody: `{"username":"foo","password":"bar","isAdmin":true}`,
+			inputUser: entities.User{
+				Username: "foo",
+				Password: "bar",
+			},
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase, user entities.User) {
+				s.EXPECT().SignUp(gomock.Any(), user).Return(entities.User{}, entities.TokenPair{}, entities.NewConflictError(entities.CodeUsernameTaken, "user is already exist"))
+			}, expectedStatusCode: 409,
+			expectedRequestBody: `{"type":"about:blank","title":"Conflict","status":409,"detail":"user is already exist","instance":"/signUp","code":"username_taken"}`,
+		},
+		{
+			name:      "Validation error",
+			inputBody: `{"username":"foo","password":"bar","isAdmin":true}`,
+			inputUser: entities.User{
+				Username: "foo",
+				Password: "bar",
+			},
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase, user entities.User) {
+				s.EXPECT().SignUp(gomock.Any(), user).Return(entities.User{}, entities.TokenPair{}, entities.NewValidationError(entities.CodeValidationFailed, "username is too short",
+					entities.FieldError{Field: "username", Message: "must contain at least 4 characters"}))
 			}, expectedStatusCode: 400,
-			expectedRequestBody: `{"err":"something went wrong"}`,
+			expectedRequestBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"username is too short","instance":"/signUp","code":"validation_failed","errors":[{"field":"username","message":"must contain at least 4 characters"}]}`,
+		},
+		{
+			name:      "Database error",
+			inputBody: `{"username":"foo","password":"bar","isAdmin":true}`,
+			inputUser: entities.User{
+				Username: "foo",
+				Password: "bar",
+			},
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase, user entities.User) {
+				s.EXPECT().SignUp(gomock.Any(), user).Return(entities.User{}, entities.TokenPair{}, fmt.Errorf("%w: connection refused", entities.ErrInternal))
+			}, expectedStatusCode: 500,
+			expectedRequestBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error","instance":"/signUp","code":"internal_error"}`,
 		},
 	}
 
@@ -88,6 +120,7 @@ func TestAuthHandler_SignUp(t *testing.T) {
 
 			// Test server
 			r := gin.New()
+			r.Use(middleware.HandleErrors())
 			r.POST("/signUp", handler.UserSignUp)
 
 			w := httptest.NewRecorder()
@@ -98,8 +131,180 @@ func TestAuthHandler_SignUp(t *testing.T) {
 
 			assert.Equal(t, w.Code, testCase.expectedStatusCode, "invalid status code, expected %d have %d", testCase.expectedStatusCode, w.Code)
 
+			if w.Code >= 400 {
+				assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			}
 			assert.Equal(t, testCase.expectedRequestBody, w.Body.String(), "invalid requset body, expected %s have %s", testCase.expectedRequestBody, w.Body.String())
 		})
 
 	}
 }
+
+func TestAuthHandler_AdminGetUsers(t *testing.T) {
+	admin := true
+	cursor := pagination.Cursor{Sort: "username", Value: "bob", Id: 2}
+
+	type mockBehavior func(s *mock_authHandler.MockAuthUsecase)
+
+	testTable := []struct {
+		name                string
+		query               string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "First page",
+			query: "?limit=1&sort=username&username=b&isAdmin=true",
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase) {
+				s.EXPECT().GetUsers(gomock.Any(), entities.UserFilter{UsernamePrefix: "b", IsAdmin: &admin},
+					pagination.Request{Limit: 1, Sort: pagination.Sort{Field: "username"}}).
+					Return([]entities.User{{Id: 2, Username: "bob", IsAdmin: true}}, pagination.Meta{NextCursor: "next", Total: 2}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"items":[{"id":2,"username":"bob","isAdmin":true,"balance":0}],"nextCursor":"next","total":2}`,
+		},
+		{
+			name:  "Last page",
+			query: "?sort=username&cursor=" + cursor.Encode(),
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase) {
+				s.EXPECT().GetUsers(gomock.Any(), entities.UserFilter{},
+					pagination.Request{Limit: pagination.DefaultLimit, Sort: pagination.Sort{Field: "username"}, After: &cursor}).
+					Return(nil, pagination.Meta{Total: 2}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"items":[],"total":2}`,
+		},
+		{
+			name:                "Cursor of other sort",
+			query:               "?cursor=" + cursor.Encode(),
+			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid value of cursor param","instance":"/api/Admin/Account","code":"invalid_param","errors":[{"field":"cursor","message":"must be nextCursor of the previous page with the same sort"}]}`,
+		},
+		{
+			name:                "Invalid admin flag",
+			query:               "?isAdmin=yes",
+			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid value of isAdmin param","instance":"/api/Admin/Account","code":"invalid_param","errors":[{"field":"isAdmin","message":"must be true or false"}]}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_authHandler.NewMockAuthUsecase(c)
+			testCase.mockBehavior(auth)
+			handler := New(auth)
+
+			r := gin.New()
+			r.Use(middleware.HandleErrors())
+			r.GET("/api/Admin/Account", handler.AdminGetUsers)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/Admin/Account"+testCase.query, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+// permissions is the checker granting fixed scopes to every user
+type permissions map[entities.Permission]entities.Scope
+
+func (p permissions) Scope(ctx context.Context, userId uint, permission entities.Permission) (entities.Scope, error) {
+	return p[permission], nil
+}
+
+func TestAuthHandler_AdminUpdateUser(t *testing.T) {
+	isAdmin := true
+	balance := 100.0
+
+	type mockBehavior func(s *mock_authHandler.MockAuthUsecase)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		permissions         permissions
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "Admin role and balance are kept",
+			inputBody:   `{"username":"foo","password":"bar"}`,
+			permissions: permissions{},
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase) {
+				s.EXPECT().UpdateUser(gomock.Any(), entities.UserUpdate{Id: 1, Username: "foo", Password: "bar"}).
+					Return(entities.User{Id: 1, Username: "foo", IsAdmin: true, Balance: 30}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"username":"foo","isAdmin":true,"balance":30}`,
+		},
+		{
+			name:                "Admin flag without roles:manage",
+			inputBody:           `{"username":"foo","password":"bar","isAdmin":true}`,
+			permissions:         permissions{entities.PermissionBalancesAdjust: {All: true}},
+			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"permission roles:manage is required to change isAdmin","instance":"/api/Admin/Account/1","code":"permission_required"}`,
+		},
+		{
+			name:                "Admin flag with operator scoped roles:manage",
+			inputBody:           `{"username":"foo","password":"bar","isAdmin":false}`,
+			permissions:         permissions{entities.PermissionRolesManage: {Operators: []uint{2}}},
+			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"permission roles:manage is required to change isAdmin","instance":"/api/Admin/Account/1","code":"permission_required"}`,
+		},
+		{
+			name:                "Balance without balances:adjust",
+			inputBody:           `{"username":"foo","password":"bar","balance":100}`,
+			permissions:         permissions{entities.PermissionRolesManage: {All: true}},
+			mockBehavior:        func(s *mock_authHandler.MockAuthUsecase) {},
+			expectedStatusCode:  403,
+			expectedRequestBody: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"permission balances:adjust is required to change balance","instance":"/api/Admin/Account/1","code":"permission_required"}`,
+		},
+		{
+			name:        "All fields with permissions",
+			inputBody:   `{"username":"foo","password":"bar","isAdmin":true,"balance":100}`,
+			permissions: permissions{entities.PermissionRolesManage: {All: true}, entities.PermissionBalancesAdjust: {All: true}},
+			mockBehavior: func(s *mock_authHandler.MockAuthUsecase) {
+				s.EXPECT().UpdateUser(gomock.Any(), entities.UserUpdate{Id: 1, Username: "foo", Password: "bar", IsAdmin: &isAdmin, Balance: &balance}).
+					Return(entities.User{Id: 1, Username: "foo", IsAdmin: true, Balance: 100}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"id":1,"username":"foo","isAdmin":true,"balance":100}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_authHandler.NewMockAuthUsecase(c)
+			testCase.mockBehavior(auth)
+			handler := New(auth)
+
+			r := gin.New()
+			r.Use(middleware.HandleErrors())
+			r.PUT("/api/Admin/Account/:id",
+				middleware.LoadPermission(testCase.permissions, entities.PermissionRolesManage),
+				middleware.LoadPermission(testCase.permissions, entities.PermissionBalancesAdjust),
+				handler.AdminUpdateUser)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/Admin/Account/1", bytes.NewBufferString(testCase.inputBody))
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedRequestBody, w.Body.String())
+		})
+	}
+}
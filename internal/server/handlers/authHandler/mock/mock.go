@@ -5,8 +5,10 @@
 package mock_authHandler
 
 import (
+	context "context"
 	reflect "reflect"
 	entities "simbirGo/internal/entities"
+	pagination "simbirGo/internal/pagination"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,132 +37,193 @@ func (m *MockAuthUsecase) EXPECT() *MockAuthUsecaseMockRecorder {
 }
 
 // CreateUser mocks base method.
-func (m *MockAuthUsecase) CreateUser(user entities.User) (entities.User, error) {
+func (m *MockAuthUsecase) CreateUser(ctx context.Context, user entities.User) (entities.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(entities.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockAuthUsecaseMockRecorder) CreateUser(user interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthUsecase)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthUsecase)(nil).CreateUser), ctx, user)
 }
 
 // DeleteUser mocks base method.
-func (m *MockAuthUsecase) DeleteUser(id uint) error {
+func (m *MockAuthUsecase) DeleteUser(ctx context.Context, id uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", id)
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockAuthUsecaseMockRecorder) DeleteUser(id interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthUsecase)(nil).DeleteUser), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthUsecase)(nil).DeleteUser), ctx, id)
 }
 
 // GetUsers mocks base method.
-func (m *MockAuthUsecase) GetUsers(start, end uint) []entities.User {
+func (m *MockAuthUsecase) GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]entities.User, pagination.Meta, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", start, end)
+	ret := m.ctrl.Call(m, "GetUsers", ctx, filter, page)
 	ret0, _ := ret[0].([]entities.User)
-	return ret0
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetUsers indicates an expected call of GetUsers.
-func (mr *MockAuthUsecaseMockRecorder) GetUsers(start, end interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) GetUsers(ctx, filter, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAuthUsecase)(nil).GetUsers), ctx, filter, page)
+}
+
+// JWKS mocks base method.
+func (m *MockAuthUsecase) JWKS() entities.JSONWebKeySet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(entities.JSONWebKeySet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthUsecaseMockRecorder) JWKS() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAuthUsecase)(nil).GetUsers), start, end)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthUsecase)(nil).JWKS))
 }
 
 // MyAccount mocks base method.
-func (m *MockAuthUsecase) MyAccount(id uint) (entities.User, error) {
+func (m *MockAuthUsecase) MyAccount(ctx context.Context, id uint) (entities.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MyAccount", id)
+	ret := m.ctrl.Call(m, "MyAccount", ctx, id)
 	ret0, _ := ret[0].(entities.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // MyAccount indicates an expected call of MyAccount.
-func (mr *MockAuthUsecaseMockRecorder) MyAccount(id interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) MyAccount(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MyAccount", reflect.TypeOf((*MockAuthUsecase)(nil).MyAccount), ctx, id)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthUsecase) Refresh(ctx context.Context, refreshToken string) (entities.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(entities.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthUsecaseMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MyAccount", reflect.TypeOf((*MockAuthUsecase)(nil).MyAccount), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthUsecase)(nil).Refresh), ctx, refreshToken)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockAuthUsecase) RevokeSessions(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockAuthUsecaseMockRecorder) RevokeSessions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockAuthUsecase)(nil).RevokeSessions), ctx, userId)
 }
 
 // SignIn mocks base method.
-func (m *MockAuthUsecase) SignIn(user entities.User) (string, error) {
+func (m *MockAuthUsecase) SignIn(ctx context.Context, user entities.User) (entities.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", user)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "SignIn", ctx, user)
+	ret0, _ := ret[0].(entities.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignIn indicates an expected call of SignIn.
-func (mr *MockAuthUsecaseMockRecorder) SignIn(user interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) SignIn(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthUsecase)(nil).SignIn), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthUsecase)(nil).SignIn), ctx, user)
 }
 
 // SignOut mocks base method.
-func (m *MockAuthUsecase) SignOut(token string) {
+func (m *MockAuthUsecase) SignOut(ctx context.Context, token string) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SignOut", token)
+	ret := m.ctrl.Call(m, "SignOut", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // SignOut indicates an expected call of SignOut.
-func (mr *MockAuthUsecaseMockRecorder) SignOut(token interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) SignOut(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOut", reflect.TypeOf((*MockAuthUsecase)(nil).SignOut), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOut", reflect.TypeOf((*MockAuthUsecase)(nil).SignOut), ctx, token)
 }
 
 // SignUp mocks base method.
-func (m *MockAuthUsecase) SignUp(user entities.User) (entities.User, string, error) {
+func (m *MockAuthUsecase) SignUp(ctx context.Context, user entities.User) (entities.User, entities.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", user)
+	ret := m.ctrl.Call(m, "SignUp", ctx, user)
 	ret0, _ := ret[0].(entities.User)
-	ret1, _ := ret[1].(string)
+	ret1, _ := ret[1].(entities.TokenPair)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockAuthUsecaseMockRecorder) SignUp(user interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) SignUp(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthUsecase)(nil).SignUp), ctx, user)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAuthUsecase) UnlockUser(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAuthUsecaseMockRecorder) UnlockUser(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthUsecase)(nil).SignUp), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAuthUsecase)(nil).UnlockUser), ctx, userId)
 }
 
 // Update mocks base method.
-func (m *MockAuthUsecase) Update(user entities.User) (entities.User, error) {
+func (m *MockAuthUsecase) Update(ctx context.Context, user entities.User) (entities.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", user)
+	ret := m.ctrl.Call(m, "Update", ctx, user)
 	ret0, _ := ret[0].(entities.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockAuthUsecaseMockRecorder) Update(user interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) Update(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAuthUsecase)(nil).Update), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAuthUsecase)(nil).Update), ctx, user)
 }
 
 // UpdateUser mocks base method.
-func (m *MockAuthUsecase) UpdateUser(user entities.User) (entities.User, error) {
+func (m *MockAuthUsecase) UpdateUser(ctx context.Context, update entities.UserUpdate) (entities.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", user)
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, update)
 	ret0, _ := ret[0].(entities.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockAuthUsecaseMockRecorder) UpdateUser(user interface{}) *gomock.Call {
+func (mr *MockAuthUsecaseMockRecorder) UpdateUser(ctx, update interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAuthUsecase)(nil).UpdateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAuthUsecase)(nil).UpdateUser), ctx, update)
 }
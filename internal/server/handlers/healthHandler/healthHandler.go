@@ -0,0 +1,52 @@
+package healthHandler
+
+import (
+	"context"
+	"net/http"
+	"simbirGo/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadinessChecker interface {
+	Ready(ctx context.Context) (health.Report, bool)
+}
+
+type HealthHandler struct {
+	rc ReadinessChecker
+}
+
+func New(rc ReadinessChecker) HealthHandler {
+	return HealthHandler{rc: rc}
+}
+
+type liveness struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// @Summary Проверка жизни
+// @Tags HealthController
+// @Description Процесс запущен и отвечает на запросы, зависимости не проверяются
+// @Produce json
+// @Success 200 {object} liveness
+// @Router /healthz [get]
+func (hh HealthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, liveness{Status: health.StatusOk})
+}
+
+// @Summary Проверка готовности
+// @Tags HealthController
+// @Description Проверка подключения к базе данных и применённых миграций.
+// @Description После получения SIGTERM сервис сразу сообщает о неготовности, чтобы балансировщик перестал отправлять запросы
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (hh HealthHandler) Readiness(ctx *gin.Context) {
+	report, ready := hh.rc.Ready(ctx.Request.Context())
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
@@ -1,15 +1,27 @@
 package paymentHandler
 
 import (
+	"context"
 	"net/http"
+	"simbirGo/internal/entities"
 	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/payments"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PaymentUsecase interface {
-	IncreaseBalance(balanceId, userId uint, isAdmin bool) (int, error)
+	GetTransactions(ctx context.Context, userId uint, page pagination.Request) ([]entities.Transaction, pagination.Meta, error)
+	Payout(ctx context.Context, ownerId uint, amount float64) error
+	Reconcile(ctx context.Context) (entities.ReconciliationReport, error)
+	GetDebtors(ctx context.Context, page pagination.Request) (entities.DebtorsReport, pagination.Meta, error)
+	CreateTopUp(ctx context.Context, userId uint, amount float64) (entities.TopUp, error)
+	GetTopUp(ctx context.Context, userId, id uint) (entities.TopUp, error)
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+	SimulateTopUp(ctx context.Context, userId, id uint, status payments.Status, failureReason string) (entities.TopUp, error)
 }
 
 type PaymentHandler struct {
@@ -20,31 +32,269 @@ func New(pu PaymentUsecase) PaymentHandler {
 	return PaymentHandler{pu: pu}
 }
 
-// @Summary Пополнение баланса
+type topUpData struct {
+	Amount float64 `json:"amount" binding:"required" example:"1000"`
+}
+
+// @Summary Создание пополнения
+// @Tags PaymentController
+// @Description Создает платеж на сумму amount в платежном шлюзе. Баланс пополняется после подтверждения платежа шлюзом через webhook.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body paymentHandler.topUpData true "Top-up data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.TopUp
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Failure 502 {object} httpUtil.Problem
+// @Router /api/Payment/TopUp [post]
+func (ph PaymentHandler) CreateTopUp(ctx *gin.Context) {
+	var tData topUpData
+	if err := ctx.ShouldBindJSON(&tData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	topUp, err := ph.pu.CreateTopUp(ctx.Request.Context(), ctx.GetUint("id"), tData.Amount)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, topUp)
+}
+
+// @Summary Статус пополнения
+// @Tags PaymentController
+// @Description Получение пополнения текущего пользователя с id = {id}
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Top-up id"
+// @Success 200 {object} entities.TopUp
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Payment/TopUp/{id} [get]
+func (ph PaymentHandler) GetTopUp(ctx *gin.Context) {
+	topUpIdStr := ctx.Param("id")
+	topUpId, err := strconv.Atoi(topUpIdStr)
+	if err != nil || topUpId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	topUp, err := ph.pu.GetTopUp(ctx.Request.Context(), ctx.GetUint("id"), uint(topUpId))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, topUp)
+}
+
+type simulationData struct {
+	Status        payments.Status `json:"status" binding:"required" enums:"succeeded, failed"`
+	FailureReason string          `json:"failureReason" example:"card declined"`
+}
+
+// @Summary Имитация оплаты
+// @Tags PaymentController
+// @Description Завершает пополнение с id = {id} через встроенный тестовый шлюз (fake), который отправляет подписанный webhook.
+// @Description Доступно только при запуске с флагом payment-dev-mode, для других шлюзов недоступно.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Top-up id"
+// @Param request body paymentHandler.simulationData true "Payment result"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.TopUp
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Failure 502 {object} httpUtil.Problem
+// @Router /api/Payment/TopUp/{id}/Simulate [post]
+func (ph PaymentHandler) SimulateTopUp(ctx *gin.Context) {
+	topUpIdStr := ctx.Param("id")
+	topUpId, err := strconv.Atoi(topUpIdStr)
+	if err != nil || topUpId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	var sData simulationData
+	if err := ctx.ShouldBindJSON(&sData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	topUp, err := ph.pu.SimulateTopUp(ctx.Request.Context(), ctx.GetUint("id"), uint(topUpId), sData.Status, sData.FailureReason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, topUp)
+}
+
+// @Summary Webhook платежного шлюза
+// @Tags PaymentController
+// @Description Принимает подписанное уведомление шлюза о результате платежа. Повторные уведомления по завершенному пополнению игнорируются.
+// @Accept json
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Payment/Webhook [post]
+func (ph PaymentHandler) Webhook(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.Error(entities.NewValidationError(entities.CodeInvalidBody, "failed to read body"))
+		return
+	}
+
+	if err := ph.pu.HandleWebhook(ctx.Request.Context(), ctx.Request.Header, body); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary История операций
 // @Tags PaymentController
-// @Description Пополняет баланс пользователся с id = {id} на 250 000.
-// @Description Администраторы могут пополнять баланс любому пользователю, обычные пользователи только себе.
+// @Description Получение страницы проводок по кошельку текущего пользователя, по умолчанию новые первыми.
+// @Description Положительная сумма увеличивает баланс, отрицательная уменьшает.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id) default(-id)
+// @Success 200 {object} pagination.Page{items=[]entities.Transaction}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Payment/Transactions [get]
+func (ph PaymentHandler) GetTransactions(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "-id", "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	transactions, meta, err := ph.pu.GetTransactions(ctx.Request.Context(), ctx.GetUint("id"), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, pagination.NewPage(transactions, meta))
+}
+
+type payoutData struct {
+	Amount float64 `json:"amount" binding:"required" example:"1500"`
+}
+
+// @Summary Выплата владельцу
+// @Tags AdminPaymentController
+// @Description Перевод суммы amount со счета выручки на баланс пользователя с id = {id}
 // @Security ApiKeyAuth
-// @Param id path uint true "Account id"
+// @Accept json
+// @Param id path uint true "Owner id"
+// @Param request body paymentHandler.payoutData true "Payout data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
-// @Router /api/Payment/Hesoyam/{id} [post]
-func (ph PaymentHandler) IncreaseBalance(ctx *gin.Context) {
-	balanceIdStr := ctx.Param("id")
-	balanceId, err := strconv.Atoi(balanceIdStr)
-	if err != nil || balanceId < 0 {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": "invalid value of id param"})
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Payment/Payout/{id} [post]
+func (ph PaymentHandler) Payout(ctx *gin.Context) {
+	ownerIdStr := ctx.Param("id")
+	ownerId, err := strconv.Atoi(ownerIdStr)
+	if err != nil || ownerId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	userId := ctx.GetUint("id")
-	isAdmin := ctx.GetBool("isAdmin")
-	code, err := ph.pu.IncreaseBalance(uint(balanceId), userId, isAdmin)
+	var pData payoutData
+	if err := ctx.ShouldBindJSON(&pData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	if err := ph.pu.Payout(ctx.Request.Context(), uint(ownerId), pData.Amount); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Сверка балансов
+// @Tags AdminPaymentController
+// @Description Сравнение балансов пользователей с суммами проводок по их кошелькам.
+// @Description imbalance - сумма всех проводок, должна быть равна 0.
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} entities.ReconciliationReport
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Payment/Reconciliation [get]
+func (ph PaymentHandler) Reconcile(ctx *gin.Context) {
+	report, err := ph.pu.Reconcile(ctx.Request.Context())
 	if err != nil {
-		httpUtil.NewResponseError(ctx, code, err.Error())
+		ctx.Error(err)
 		return
 	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// debtorsPage is page of debtors with total debt of all of them
+type debtorsPage struct {
+	Items []entities.Debtor `json:"items"`
+	pagination.Meta
+	GeneratedAt time.Time `json:"generatedAt"`
+	TotalDebt   float64   `json:"totalDebt"`
+}
 
-	ctx.Status(code)
+// @Summary Должники
+// @Tags AdminPaymentController
+// @Description Страница пользователей с отрицательным балансом, по умолчанию начиная с наибольшего долга.
+// @Description debtSince - время, когда баланс стал отрицательным. Пользователи с долгом не могут начинать аренды и бронировать транспорт.
+// @Description totalDebt - сумма долгов всех должников, а не только текущей страницы.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(debt, -debt, id, -id) default(-debt)
+// @Success 200 {object} debtorsPage
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Payment/Debtors [get]
+func (ph PaymentHandler) GetDebtors(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "-debt", "debt", "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	report, meta, err := ph.pu.GetDebtors(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	items := report.Debtors
+	if items == nil {
+		items = []entities.Debtor{}
+	}
+	ctx.JSON(http.StatusOK, debtorsPage{Items: items, Meta: meta, GeneratedAt: report.GeneratedAt, TotalDebt: report.TotalDebt})
 }
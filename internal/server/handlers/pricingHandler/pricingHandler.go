@@ -0,0 +1,199 @@
+package pricingHandler
+
+import (
+	"context"
+	"net/http"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/pricing"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingUsecase interface {
+	GetPolicies(ctx context.Context, page pagination.Request) ([]entities.PricingPolicy, pagination.Meta, error)
+	GetPolicy(ctx context.Context, id uint) (entities.PricingPolicy, error)
+	CreatePolicy(ctx context.Context, policy entities.PricingPolicy) (entities.PricingPolicy, error)
+	UpdatePolicy(ctx context.Context, policy entities.PricingPolicy) (entities.PricingPolicy, error)
+	DeletePolicy(ctx context.Context, id uint) error
+}
+
+type PricingHandler struct {
+	pu PricingUsecase
+}
+
+func New(pu PricingUsecase) PricingHandler {
+	return PricingHandler{pu: pu}
+}
+
+type policyData struct {
+	Name          string `json:"name" binding:"required"`
+	TransportType string `json:"transportType" enums:"Car, Bike, Scooter"`
+	TransportId   *uint  `json:"transportId"`
+	pricing.Policy
+}
+
+func (p policyData) toEntitie(id uint) entities.PricingPolicy {
+	return entities.PricingPolicy{
+		Id:            id,
+		Name:          p.Name,
+		TransportType: p.TransportType,
+		TransportId:   p.TransportId,
+		Policy:        p.Policy,
+	}
+}
+
+// @Summary Список тарифов
+// @Tags AdminPricingController
+// @Description Получение страницы тарифных политик.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id) default(id)
+// @Success 200 {object} pagination.Page{items=[]entities.PricingPolicy}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Pricing [get]
+func (ph PricingHandler) GetPolicies(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "id", "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	policies, meta, err := ph.pu.GetPolicies(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, pagination.NewPage(policies, meta))
+}
+
+// @Summary Информация о тарифе
+// @Tags AdminPricingController
+// @Description Получение тарифной политики с id = {id}
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Policy id"
+// @Success 200 {object} entities.PricingPolicy
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Pricing/{id} [get]
+func (ph PricingHandler) GetPolicy(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	policy, err := ph.pu.GetPolicy(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// @Summary Создание тарифа
+// @Tags AdminPricingController
+// @Description Создание тарифной политики для типа транспорта (transportType) или конкретного транспорта (transportId).
+// @Description Политика транспорта имеет приоритет над политикой его типа. Условия тарифа сохраняются в аренде при ее начале.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body pricingHandler.policyData true "Policy data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.PricingPolicy
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Pricing [post]
+func (ph PricingHandler) CreatePolicy(ctx *gin.Context) {
+	var pData policyData
+	if err := ctx.ShouldBindJSON(&pData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	policy, err := ph.pu.CreatePolicy(ctx.Request.Context(), pData.toEntitie(0))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, policy)
+}
+
+// @Summary Обновление тарифа
+// @Tags AdminPricingController
+// @Description Обновление тарифной политики с id = {id}. Уже начатые аренды рассчитываются по прежним условиям.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Policy id"
+// @Param request body pricingHandler.policyData true "Policy data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.PricingPolicy
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Pricing/{id} [put]
+func (ph PricingHandler) UpdatePolicy(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	var pData policyData
+	if err := ctx.ShouldBindJSON(&pData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	policy, err := ph.pu.UpdatePolicy(ctx.Request.Context(), pData.toEntitie(uint(id)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// @Summary Удаление тарифа
+// @Tags AdminPricingController
+// @Description Удаление тарифной политики с id = {id}
+// @Security ApiKeyAuth
+// @Param id path uint true "Policy id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Pricing/{id} [delete]
+func (ph PricingHandler) DeletePolicy(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	if err := ph.pu.DeletePolicy(ctx.Request.Context(), uint(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
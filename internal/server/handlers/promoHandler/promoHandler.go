@@ -0,0 +1,208 @@
+package promoHandler
+
+import (
+	"context"
+	"net/http"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/pricing"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoUsecase interface {
+	GetPromoCodes(ctx context.Context, page pagination.Request) ([]entities.PromoCode, pagination.Meta, error)
+	GetPromoCode(ctx context.Context, id uint) (entities.PromoCode, error)
+	CreatePromoCode(ctx context.Context, promoCode entities.PromoCode) (entities.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, promoCode entities.PromoCode) (entities.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id uint) error
+}
+
+type PromoHandler struct {
+	pu PromoUsecase
+}
+
+func New(pu PromoUsecase) PromoHandler {
+	return PromoHandler{pu: pu}
+}
+
+type promoCodeData struct {
+	Code           string               `json:"code" binding:"required" example:"SUMMER23"`
+	Kind           pricing.DiscountKind `json:"kind" binding:"required" enums:"percent, fixed"`
+	Value          float64              `json:"value" binding:"required" example:"15"`
+	ValidFrom      time.Time            `json:"validFrom" binding:"required" example:"2023-06-01T00:00:00+04:00"`
+	ValidTo        time.Time            `json:"validTo" binding:"required" example:"2023-09-01T00:00:00+04:00"`
+	MaxUses        int                  `json:"maxUses"`
+	MaxUsesPerUser int                  `json:"maxUsesPerUser"`
+	TransportTypes []string             `json:"transportTypes"`
+}
+
+func (p promoCodeData) toEntitie(id uint) entities.PromoCode {
+	return entities.PromoCode{
+		Id:             id,
+		Code:           p.Code,
+		Kind:           p.Kind,
+		Value:          p.Value,
+		ValidFrom:      p.ValidFrom,
+		ValidTo:        p.ValidTo,
+		MaxUses:        p.MaxUses,
+		MaxUsesPerUser: p.MaxUsesPerUser,
+		TransportTypes: p.TransportTypes,
+	}
+}
+
+// @Summary Список промокодов
+// @Tags AdminPromoCodeController
+// @Description Получение страницы промокодов с числом использований.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id, code, -code) default(id)
+// @Success 200 {object} pagination.Page{items=[]entities.PromoCode}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/PromoCodes [get]
+func (ph PromoHandler) GetPromoCodes(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "id", "id", "code")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	promoCodes, meta, err := ph.pu.GetPromoCodes(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, pagination.NewPage(promoCodes, meta))
+}
+
+// @Summary Информация о промокоде
+// @Tags AdminPromoCodeController
+// @Description Получение промокода с id = {id}
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Promo code id"
+// @Success 200 {object} entities.PromoCode
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/PromoCodes/{id} [get]
+func (ph PromoHandler) GetPromoCode(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	promoCode, err := ph.pu.GetPromoCode(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, promoCode)
+}
+
+// @Summary Создание промокода
+// @Tags AdminPromoCodeController
+// @Description Создание промокода со скидкой в процентах (percent) или фиксированной суммой (fixed).
+// @Description Промокод действует в промежутке [validFrom, validTo). maxUses и maxUsesPerUser ограничивают число аренд с промокодом, 0 - без ограничений.
+// @Description Если transportTypes не указаны, промокод действует для всех типов транспорта. Регистр кода не учитывается.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body promoHandler.promoCodeData true "Promo code data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.PromoCode
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/PromoCodes [post]
+func (ph PromoHandler) CreatePromoCode(ctx *gin.Context) {
+	var pData promoCodeData
+	if err := ctx.ShouldBindJSON(&pData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	promoCode, err := ph.pu.CreatePromoCode(ctx.Request.Context(), pData.toEntitie(0))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, promoCode)
+}
+
+// @Summary Обновление промокода
+// @Tags AdminPromoCodeController
+// @Description Обновление промокода с id = {id}. Скидка уже начатых аренд не меняется.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Promo code id"
+// @Param request body promoHandler.promoCodeData true "Promo code data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.PromoCode
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/PromoCodes/{id} [put]
+func (ph PromoHandler) UpdatePromoCode(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	var pData promoCodeData
+	if err := ctx.ShouldBindJSON(&pData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	promoCode, err := ph.pu.UpdatePromoCode(ctx.Request.Context(), pData.toEntitie(uint(id)))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, promoCode)
+}
+
+// @Summary Удаление промокода
+// @Tags AdminPromoCodeController
+// @Description Удаление промокода с id = {id}. Скидка уже начатых аренд сохраняется.
+// @Security ApiKeyAuth
+// @Param id path uint true "Promo code id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/PromoCodes/{id} [delete]
+func (ph PromoHandler) DeletePromoCode(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	if err := ph.pu.DeletePromoCode(ctx.Request.Context(), uint(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
@@ -1,10 +1,12 @@
 package rentHandler
 
 import (
+	"context"
+	"fmt"
 	"math"
-	"net/http"
 	"simbirGo/internal/entities"
 	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
 	"strconv"
 	"time"
 
@@ -13,23 +15,35 @@ import (
 
 type RentUsecase interface {
 	//user
-	GetAvalibleTransport(lat, long, radius float64, transportType string) ([]entities.Transport, error)
-	GetRent(rentId int, userId uint) (entities.Rent, error)
-	GetUserHistory(userId uint) []entities.Rent
-	GetTransportHistory(userId, transportId int) ([]entities.Rent, error)
-	CreateNewRent(userId uint, transportId int, rentType string) (entities.Rent, error)
-	UserEndRent(userId uint, rentId int, lat, long float64) (entities.Rent, error)
+	GetAvalibleTransport(ctx context.Context, lat, long, radius float64, transportType string) ([]entities.NearbyTransport, error)
+	GetRent(ctx context.Context, rentId int, userId uint) (entities.Rent, error)
+	GetUserHistory(ctx context.Context, userId uint, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error)
+	GetTransportHistory(ctx context.Context, userId, transportId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error)
+	CreateNewRent(ctx context.Context, userId uint, transportId int, rentType, promoCode string) (entities.Rent, error)
+	UserEndRent(ctx context.Context, userId uint, rentId int, lat, long float64) (entities.Rent, error)
+
+	//reservations
+	GetReservations(ctx context.Context, userId uint, page pagination.Request) ([]entities.Reservation, pagination.Meta, error)
+	GetReservation(ctx context.Context, userId, id uint) (entities.Reservation, error)
+	CreateReservation(ctx context.Context, userId uint, transportId int, timeStart, timeEnd time.Time) (entities.Reservation, error)
+	CancelReservation(ctx context.Context, userId, id uint) (entities.Reservation, error)
+	StartReservation(ctx context.Context, userId, id uint, rentType, promoCode string) (entities.Rent, error)
+	GetTransportCalendar(ctx context.Context, transportId int, from, to time.Time) ([]entities.ReservationSlot, error)
 
 	//admin usecase
-	AdminGetRent(id int) (entities.Rent, error)
-	AdminGetUserHistory(userId int) ([]entities.Rent, error)
-	AdminGetTransportHistory(transportId int) ([]entities.Rent, error)
-	AdminCreateRent(rent entities.Rent) (entities.Rent, error)
-	AdminEndRent(id int, lat, long float64) (entities.Rent, error)
-	AdminUpdateRent(rent entities.Rent) (entities.Rent, error)
-	AdminDeleteRent(id int) error
+	AdminGetRent(ctx context.Context, id int) (entities.Rent, error)
+	AdminGetUserHistory(ctx context.Context, userId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error)
+	AdminGetTransportHistory(ctx context.Context, transportId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error)
+	AdminCreateRent(ctx context.Context, rent entities.Rent) (entities.Rent, error)
+	AdminEndRent(ctx context.Context, id int, lat, long float64) (entities.Rent, error)
+	AdminUpdateRent(ctx context.Context, rent entities.Rent) (entities.Rent, error)
+	AdminDeleteRent(ctx context.Context, id int) error
+	AdminRefundRent(ctx context.Context, id int) (entities.Rent, error)
 }
 
+// maxSearchRadius limits radius of the transport search in meters
+const maxSearchRadius float64 = 50000
+
 type RentHandler struct {
 	ru RentUsecase
 }
@@ -43,40 +57,45 @@ func New(ru RentUsecase) RentHandler {
 // @Summary Доступный транспорт для аренды
 // @Tags RentController
 // @Description Получение информации о транспорте, доступного для аренды по месту его расположения и типу.
+// @Description Транспорт отсортирован по расстоянию до точки поиска, расстояние в метрах указывается в поле distance.
 // @Produce json
 // @Param lat query float64 true "географическая широта"
-// @Param radius query float64 true "радиус поиска"
+// @Param radius query float64 true "радиус поиска в метрах"
 // @Param long query float64 true "географическая долгота"
 // @Param transportType query string true "transportType" Enums(All, Car, Bike, Scooter)
-// @Success 200 {array} entities.Transport
-// @Failure 400 {object} httpUtil.ResponseError
+// @Success 200 {array} entities.NearbyTransport
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/Transport [get]
 func (rh RentHandler) GetAvalibleTransport(ctx *gin.Context) {
 	latStr := ctx.Query("lat")
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil || math.Abs(lat) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of lat query param")
+		ctx.Error(entities.NewInvalidParamError("lat", "must be a number between -90 and 90"))
+		return
 	}
 
 	longStr := ctx.Query("long")
 	long, err := strconv.ParseFloat(longStr, 64)
 	if err != nil || math.Abs(long) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of long query param")
+		ctx.Error(entities.NewInvalidParamError("long", "must be a number between -180 and 180"))
+		return
 	}
 
 	radiusStr := ctx.Query("radius")
 	radius, err := strconv.ParseFloat(radiusStr, 64)
-	if err != nil || radius < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of long radius query param")
+	if err != nil || radius < 0 || radius > maxSearchRadius {
+		ctx.Error(entities.NewInvalidParamError("radius", fmt.Sprintf("must be a number between 0 and %.0f meters", maxSearchRadius)))
+		return
 	}
 	transportType, ok := ctx.GetQuery("transportType")
 	if !ok {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of transport type query param")
+		ctx.Error(entities.NewInvalidParamError("transportType", "is required"))
 	}
 
-	transports, err := rh.ru.GetAvalibleTransport(lat, long, radius, transportType)
+	transports, err := rh.ru.GetAvalibleTransport(ctx.Request.Context(), lat, long, radius, transportType)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -120,20 +139,22 @@ func (rh RentHandler) GetAvalibleTransport(ctx *gin.Context) {
 // @Produce json
 // @Param rentid path uint true "Rent id"
 // @Success 200 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/{rentid} [get]
 func (rh RentHandler) UserGetRent(ctx *gin.Context) {
 	rentIdStr := ctx.Param("id")
 	rentId, err := strconv.Atoi(rentIdStr)
 	if err != nil || rentId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 	userId := ctx.GetUint("id")
-	rent, err := rh.ru.GetRent(rentId, userId)
+	rent, err := rh.ru.GetRent(ctx.Request.Context(), rentId, userId)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -142,19 +163,36 @@ func (rh RentHandler) UserGetRent(ctx *gin.Context) {
 
 // @Summary Истории аренды пользователя
 // @Tags RentController
-// @Description Получение данных всех аренд текущего авторизованного пользователя
+// @Description Получение страницы аренд текущего авторизованного пользователя
 // @Security ApiKeyAuth
 // @Produce json
-// @Success 200 {array} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(timeStart, -timeStart, id, -id) default(timeStart)
+// @Param from query string false "Аренды, начатые не раньше, в формате RFC 3339"
+// @Param to query string false "Аренды, начатые раньше, в формате RFC 3339"
+// @Param status query string false "Статус аренды" Enums(active, ended)
+// @Param transportType query string false "Тип транспорта" Enums(Car, Bike, Scooter)
+// @Success 200 {object} pagination.Page{items=[]entities.Rent}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/MyHistory [get]
 func (rh RentHandler) UserGetHistory(ctx *gin.Context) {
 	userId := ctx.GetUint("id")
+	filter, page, err := parseHistoryQuery(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-	rent := rh.ru.GetUserHistory(userId)
+	rent, meta, err := rh.ru.GetUserHistory(ctx.Request.Context(), userId, filter, page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-	ctx.JSON(200, rent)
+	ctx.JSON(200, pagination.NewPage(rent, meta))
 }
 
 // @Summary Истории аренды транспорта
@@ -164,60 +202,84 @@ func (rh RentHandler) UserGetHistory(ctx *gin.Context) {
 // @Security ApiKeyAuth
 // @Produce json
 // @Param transportId path uint true "Transport id"
-// @Success 200 {array} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(timeStart, -timeStart, id, -id) default(timeStart)
+// @Param from query string false "Аренды, начатые не раньше, в формате RFC 3339"
+// @Param to query string false "Аренды, начатые раньше, в формате RFC 3339"
+// @Param status query string false "Статус аренды" Enums(active, ended)
+// @Param transportType query string false "Тип транспорта" Enums(Car, Bike, Scooter)
+// @Success 200 {object} pagination.Page{items=[]entities.Rent}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/TransportHistory/{transportId} [get]
 func (rh RentHandler) UserGetTransportHistory(ctx *gin.Context) {
 	userId := ctx.GetInt("id")
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	filter, page, err := parseHistoryQuery(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	rents, err := rh.ru.GetTransportHistory(userId, transportId)
+	rents, meta, err := rh.ru.GetTransportHistory(ctx.Request.Context(), userId, transportId, filter, page)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, rents)
+	ctx.JSON(200, pagination.NewPage(rents, meta))
 }
 
 // @Summary Создание новой аренды транспорта
 // @Tags RentController
 // @Description Создание новой аредны транспорта с id = {transportid}.
 // @Description В параметра rentType указывается тип аренды: [Minutes, Days].
+// @Description В параметре promoCode можно указать промокод, скидка применяется при завершении аренды.
+// @Description При начале аренды с баланса резервируется залог (hold), при недостатке средств или наличии долга аренда не создается.
 // @Security ApiKeyAuth
 // @Produce json
 // @Param transportId path uint true "Transport id"
 // @Param rentType query string true "Rent type: [Minutes, Days]" Enums(Minutes, Days)
+// @Param promoCode query string false "Promo code"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/New/{transportId} [post]
 func (rh RentHandler) UserCreateNewRent(ctx *gin.Context) {
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
 	rentType, ok := ctx.GetQuery("rentType")
 	if !ok || (rentType != "Minutes" && rentType != "Days") {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of transport type query param")
+		ctx.Error(entities.NewInvalidParamError("rentType", "must be Minutes or Days"))
 		return
 	}
 
 	userId := ctx.GetUint("id")
 
-	rent, err := rh.ru.CreateNewRent(userId, transportId, rentType)
+	rent, err := rh.ru.CreateNewRent(ctx.Request.Context(), userId, transportId, rentType, ctx.Query("promoCode"))
 
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(201, rent)
@@ -226,43 +288,47 @@ func (rh RentHandler) UserCreateNewRent(ctx *gin.Context) {
 // @Summary Окончание аренды
 // @Tags RentController
 // @Description Окончание аренды транспорта с id = {transportid}.
-// @Description Происходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то в завершить аренду нельзя.
+// @Description Происходит рассчет итоговой суммы аренды, залог списывается в счет стоимости. Если средств недостаточно, аренда все равно завершается, а баланс пользователя становится отрицательным (долг).
 // @Security ApiKeyAuth
 // @Produce json
 // @Param rentId path uint true "Transport id"
 // @Param lat query float64 true "lat"
 // @Param long query float64 true "long"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Rent/End/{rentId} [post]
 func (rh RentHandler) UserEndRent(ctx *gin.Context) {
 	rentIdStr := ctx.Param("id")
 	rentId, err := strconv.Atoi(rentIdStr)
 	if err != nil || rentId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
 	latStr := ctx.Query("lat")
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil || math.Abs(lat) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of lat query param")
+		ctx.Error(entities.NewInvalidParamError("lat", "must be a number between -90 and 90"))
 		return
 	}
 
 	longStr := ctx.Query("long")
 	long, err := strconv.ParseFloat(longStr, 64)
 	if err != nil || math.Abs(long) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of long query param")
+		ctx.Error(entities.NewInvalidParamError("long", "must be a number between -180 and 180"))
 		return
 	}
 
 	userId := ctx.GetUint("id")
 
-	rent, err := rh.ru.UserEndRent(userId, rentId, lat, long)
+	rent, err := rh.ru.UserEndRent(ctx.Request.Context(), userId, rentId, lat, long)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -278,20 +344,22 @@ func (rh RentHandler) UserEndRent(ctx *gin.Context) {
 // @Produce  json
 // @Param rentId path uint true "Rent id"
 // @Success 200 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Rent/{rentId} [get]
 func (rh RentHandler) AdminGetRent(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	rent, err := rh.ru.AdminGetRent(id)
+	rent, err := rh.ru.AdminGetRent(ctx.Request.Context(), id)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -304,25 +372,39 @@ func (rh RentHandler) AdminGetRent(ctx *gin.Context) {
 // @Security ApiKeyAuth
 // @Produce  json
 // @Param userId path uint true "User id"
-// @Success 200 {array} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(timeStart, -timeStart, id, -id) default(timeStart)
+// @Param from query string false "Аренды, начатые не раньше, в формате RFC 3339"
+// @Param to query string false "Аренды, начатые раньше, в формате RFC 3339"
+// @Param status query string false "Статус аренды" Enums(active, ended)
+// @Param transportType query string false "Тип транспорта" Enums(Car, Bike, Scooter)
+// @Success 200 {object} pagination.Page{items=[]entities.Rent}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/UserHistory/{userId} [get]
 func (rh RentHandler) AdminGetUserHistory(ctx *gin.Context) {
 	userIdStr := ctx.Param("id")
 	userId, err := strconv.Atoi(userIdStr)
 	if err != nil || userId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	rents, err := rh.ru.AdminGetUserHistory(userId)
+	filter, page, err := parseHistoryQuery(ctx)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
+		return
+	}
+	rents, meta, err := rh.ru.AdminGetUserHistory(ctx.Request.Context(), userId, filter, page)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, rents)
+	ctx.JSON(200, pagination.NewPage(rents, meta))
 }
 
 // @Summary История аренды транспорта
@@ -331,40 +413,61 @@ func (rh RentHandler) AdminGetUserHistory(ctx *gin.Context) {
 // @Security ApiKeyAuth
 // @Produce  json
 // @Param transportId path uint true "Transport id"
-// @Success 200 {array} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(timeStart, -timeStart, id, -id) default(timeStart)
+// @Param from query string false "Аренды, начатые не раньше, в формате RFC 3339"
+// @Param to query string false "Аренды, начатые раньше, в формате RFC 3339"
+// @Param status query string false "Статус аренды" Enums(active, ended)
+// @Param transportType query string false "Тип транспорта" Enums(Car, Bike, Scooter)
+// @Success 200 {object} pagination.Page{items=[]entities.Rent}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/TransportHistory/{transportId} [get]
 func (rh RentHandler) AdminGetTransportHistory(ctx *gin.Context) {
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
-	rents, err := rh.ru.AdminGetTransportHistory(transportId)
+	filter, page, err := parseHistoryQuery(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
+	rents, meta, err := rh.ru.AdminGetTransportHistory(ctx.Request.Context(), transportId, filter, page)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, rents)
+	ctx.JSON(200, pagination.NewPage(rents, meta))
 }
 
 // @Summary Создание новой аренды
 // @Tags AdminRentController
 // @Description Создание аренды транспорта с id = transportId пользователем с id = userId
+// @Description Если указана дата окончания, стоимость аренды списывается с баланса пользователя,
+// @Description иначе с баланса резервируется залог, как при начале аренды пользователем, и транспорт становится недоступным для аренды.
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce  json
 // @Param request body rentHandler.AdminCreateRent.rentData true "Rent data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Rent [post]
 func (rh RentHandler) AdminCreateRent(ctx *gin.Context) {
 	type rentData struct {
@@ -376,19 +479,19 @@ func (rh RentHandler) AdminCreateRent(ctx *gin.Context) {
 		PriceType   string  `json:"priceType" binding:"required" enum:"Days, Minutes"`
 	}
 	var rData rentData
-	if err := ctx.BindJSON(&rData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&rData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if rData.PriceType != "Days" && rData.PriceType != "Minutes" {
-		httpUtil.NewResponseError(ctx, 400, "invalid priceType")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid priceType", entities.FieldError{Field: "priceType", Message: "must be Minutes or Days"}))
 		return
 	}
 
 	timeStart, err := time.Parse(time.RFC3339, rData.TimeStart)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, "invalid end time value, should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid start time value", entities.FieldError{Field: "timeStart", Message: "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"}))
 		return
 	}
 
@@ -396,12 +499,12 @@ func (rh RentHandler) AdminCreateRent(ctx *gin.Context) {
 	if rData.TimeEnd != "" {
 		tEnd, err := time.Parse(time.RFC3339, rData.TimeEnd)
 		if err != nil && rData.TimeEnd != "" {
-			httpUtil.NewResponseError(ctx, 400, "invalid end time value, should be : yyyy-mm-ddThh:mm:ss±hh:mm")
+			ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value", entities.FieldError{Field: "timeEnd", Message: "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"}))
 			return
 		}
 
 		if tEnd.Unix()-timeStart.Unix() <= 0 {
-			httpUtil.NewResponseError(ctx, 400, "invalid end time value: end time must be after start time")
+			ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value", entities.FieldError{Field: "timeEnd", Message: "must be after start time"}))
 			return
 		}
 
@@ -417,9 +520,9 @@ func (rh RentHandler) AdminCreateRent(ctx *gin.Context) {
 		PriceType:   rData.PriceType,
 	}
 
-	rent, err = rh.ru.AdminCreateRent(rent)
+	rent, err = rh.ru.AdminCreateRent(ctx.Request.Context(), rent)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -429,40 +532,44 @@ func (rh RentHandler) AdminCreateRent(ctx *gin.Context) {
 // @Summary Завершение аренды
 // @Tags AdminRentController
 // @Description Завершение аренды с id = {rentId}.
-// @Description Происходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то в завершить аренду нельзя.
+// @Description Происходит рассчет итоговой суммы аренды, залог списывается в счет стоимости. Если средств недостаточно, аренда все равно завершается, а баланс пользователя становится отрицательным (долг).
 // @Security ApiKeyAuth
 // @Produce  json
 // @Param lat query float64 true "lat"
 // @Param long query float64 true "long"
 // @Param rentId path uint true "Rent id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Rent/End/{rentId} [post]
 func (rh RentHandler) AdminEndRent(ctx *gin.Context) {
 	latStr := ctx.Query("lat")
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil || math.Abs(lat) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of lat query param")
+		ctx.Error(entities.NewInvalidParamError("lat", "must be a number between -90 and 90"))
 	}
 
 	longStr := ctx.Query("long")
 	long, err := strconv.ParseFloat(longStr, 64)
 	if err != nil || math.Abs(long) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of long query param")
+		ctx.Error(entities.NewInvalidParamError("long", "must be a number between -180 and 180"))
 	}
 
 	rentIdStr := ctx.Param("id")
 	rentId, err := strconv.Atoi(rentIdStr)
 	if err != nil || rentId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
-	rent, err := rh.ru.AdminEndRent(rentId, lat, long)
+	rent, err := rh.ru.AdminEndRent(ctx.Request.Context(), rentId, lat, long)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -473,22 +580,28 @@ func (rh RentHandler) AdminEndRent(ctx *gin.Context) {
 // @Tags AdminRentController
 // @Description Обновление информации об аренде с id = {rentId}
 // @Description Если в обновлении аренды указывается дата ее окончания, то аренда считается завершенной.
-// @Description Происходит рассчет итоговой суммы аренды и если она оказывается больше, чем сумма на счете пользователя, то обновить аренду нельзя.
+// @Description Происходит рассчет итоговой суммы аренды, она списывается с баланса пользователя так же, как при завершении аренды.
+// @Description При изменении завершенной аренды с баланса списывается или возвращается разница в стоимости, возобновить завершенную аренду нельзя.
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce  json
 // @Param request body rentHandler.AdminUpdateRent.rentData true "Rent data"
 // @Param rentId path uint true "Rent id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Rent
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Rent/{rentId} [put]
 func (rh RentHandler) AdminUpdateRent(ctx *gin.Context) {
 	rentIdStr := ctx.Param("id")
 	rentId, err := strconv.Atoi(rentIdStr)
 	if err != nil || rentId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
@@ -502,19 +615,19 @@ func (rh RentHandler) AdminUpdateRent(ctx *gin.Context) {
 	}
 
 	var rData rentData
-	if err := ctx.BindJSON(&rData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&rData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if rData.PriceType != "Days" && rData.PriceType != "Minutes" {
-		httpUtil.NewResponseError(ctx, 400, "invalid priceType")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid priceType", entities.FieldError{Field: "priceType", Message: "must be Minutes or Days"}))
 		return
 	}
 
 	timeStart, err := time.Parse(time.RFC3339, rData.TimeStart)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, "invalid end time value, should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid start time value", entities.FieldError{Field: "timeStart", Message: "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"}))
 		return
 	}
 
@@ -522,12 +635,12 @@ func (rh RentHandler) AdminUpdateRent(ctx *gin.Context) {
 	if rData.TimeEnd != "" {
 		tEnd, err := time.Parse(time.RFC3339, rData.TimeEnd)
 		if err != nil && rData.TimeEnd != "" {
-			httpUtil.NewResponseError(ctx, 400, "invalid end time value, should be : yyyy-mm-ddThh:mm:ss±hh:mm")
+			ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value", entities.FieldError{Field: "timeEnd", Message: "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"}))
 			return
 		}
 
 		if tEnd.Unix()-timeStart.Unix() <= 0 {
-			httpUtil.NewResponseError(ctx, 400, "invalid end time value: end time must be after start time")
+			ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value", entities.FieldError{Field: "timeEnd", Message: "must be after start time"}))
 			return
 		}
 
@@ -543,9 +656,9 @@ func (rh RentHandler) AdminUpdateRent(ctx *gin.Context) {
 		PriceType:   rData.PriceType,
 	}
 
-	rent, err = rh.ru.AdminUpdateRent(rent)
+	rent, err = rh.ru.AdminUpdateRent(ctx.Request.Context(), rent)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -554,28 +667,97 @@ func (rh RentHandler) AdminUpdateRent(ctx *gin.Context) {
 
 // @Summary Удаление аренды
 // @Tags AdminRentController
-// @Description Удаление аренды с id = {rentId}
+// @Description Удаление завершенной аренды с id = {rentId}. Незавершенную аренду и аренду с проводками в журнале удалить нельзя, вместо этого используется возврат средств.
 // @Security ApiKeyAuth
 // @Produce json
 // @Param rentId path uint true "Rent id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Rent/{rentId} [delete]
 func (rh RentHandler) AdminDeleteRent(ctx *gin.Context) {
 	rentIdStr := ctx.Param("id")
 	rentId, err := strconv.Atoi(rentIdStr)
 	if err != nil || rentId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
-	err = rh.ru.AdminDeleteRent(rentId)
+	err = rh.ru.AdminDeleteRent(ctx.Request.Context(), rentId)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
 	ctx.Status(200)
 }
+
+// @Summary Возврат средств за аренду
+// @Tags AdminRentController
+// @Description Возврат итоговой суммы завершенной аренды с id = {rentId} на баланс арендатора. Аренду можно вернуть только один раз.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param rentId path uint true "Rent id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.Rent
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Rent/Refund/{rentId} [post]
+func (rh RentHandler) AdminRefundRent(ctx *gin.Context) {
+	rentIdStr := ctx.Param("id")
+	rentId, err := strconv.Atoi(rentIdStr)
+	if err != nil || rentId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	rent, err := rh.ru.AdminRefundRent(ctx.Request.Context(), rentId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, rent)
+}
+
+// parseHistoryQuery reads filters and pagination of rents history
+func parseHistoryQuery(ctx *gin.Context) (entities.RentFilter, pagination.Request, error) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "timeStart", "timeStart", "id")
+	if err != nil {
+		return entities.RentFilter{}, pagination.Request{}, err
+	}
+
+	filter := entities.RentFilter{TransportType: ctx.Query("transportType")}
+	if fromStr, ok := ctx.GetQuery("from"); ok {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return entities.RentFilter{}, pagination.Request{}, entities.NewInvalidParamError("from", "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm")
+		}
+		filter.From = &from
+	}
+	if toStr, ok := ctx.GetQuery("to"); ok {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return entities.RentFilter{}, pagination.Request{}, entities.NewInvalidParamError("to", "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm")
+		}
+		filter.To = &to
+	}
+
+	switch status := ctx.Query("status"); status {
+	case "", entities.RentStatusActive, entities.RentStatusEnded:
+		filter.Status = status
+	default:
+		return entities.RentFilter{}, pagination.Request{}, entities.NewInvalidParamError("status", "must be active or ended")
+	}
+
+	return filter, page, nil
+}
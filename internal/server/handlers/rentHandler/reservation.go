@@ -0,0 +1,232 @@
+package rentHandler
+
+import (
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarPeriod is default period of the transport calendar
+const calendarPeriod = 7 * 24 * time.Hour
+
+// @Summary Мои бронирования
+// @Tags ReservationController
+// @Description Получение страницы бронирований текущего пользователя, по умолчанию по времени начала.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(timeStart, -timeStart, id, -id) default(timeStart)
+// @Success 200 {object} pagination.Page{items=[]entities.Reservation}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations [get]
+func (rh RentHandler) UserGetReservations(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "timeStart", "timeStart", "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	userId := ctx.GetUint("id")
+	reservations, meta, err := rh.ru.GetReservations(ctx.Request.Context(), userId, page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, pagination.NewPage(reservations, meta))
+}
+
+// @Summary Получение бронирования
+// @Tags ReservationController
+// @Description Получение информации о бронировании по id. Доступно только пользователю, создавшему бронирование.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Reservation id"
+// @Success 200 {object} entities.Reservation
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations/{id} [get]
+func (rh RentHandler) UserGetReservation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	userId := ctx.GetUint("id")
+	reservation, err := rh.ru.GetReservation(ctx.Request.Context(), userId, uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, reservation)
+}
+
+// @Summary Бронирование транспорта
+// @Tags ReservationController
+// @Description Бронирование транспорта на промежуток времени [timeStart, timeEnd) в формате RFC 3339.
+// @Description Бронирования одного транспорта не могут пересекаться. Если аренда не начата в течение льготного периода после timeStart, бронирование истекает.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body rentHandler.UserCreateReservation.reservationData true "Reservation data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.Reservation
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations [post]
+func (rh RentHandler) UserCreateReservation(ctx *gin.Context) {
+	type reservationData struct {
+		TransportId uint      `json:"transportId" binding:"required"`
+		TimeStart   time.Time `json:"timeStart" binding:"required" example:"2023-05-01T10:00:00+04:00"`
+		TimeEnd     time.Time `json:"timeEnd" binding:"required" example:"2023-05-01T12:00:00+04:00"`
+	}
+	var rData reservationData
+	if err := ctx.ShouldBindJSON(&rData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	userId := ctx.GetUint("id")
+	reservation, err := rh.ru.CreateReservation(ctx.Request.Context(), userId, int(rData.TransportId), rData.TimeStart, rData.TimeEnd)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, reservation)
+}
+
+// @Summary Отмена бронирования
+// @Tags ReservationController
+// @Description Отмена активного бронирования.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Reservation id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.Reservation
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations/{id} [delete]
+func (rh RentHandler) UserCancelReservation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	userId := ctx.GetUint("id")
+	reservation, err := rh.ru.CancelReservation(ctx.Request.Context(), userId, uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, reservation)
+}
+
+// @Summary Начало аренды по бронированию
+// @Tags ReservationController
+// @Description Начинает аренду забронированного транспорта. Аренду можно начать в течение льготного периода до и после начала бронирования.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Reservation id"
+// @Param rentType query string true "Rent type: [Minutes, Days]" Enums(Minutes, Days)
+// @Param promoCode query string false "Promo code"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.Rent
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 402 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations/{id}/Start [post]
+func (rh RentHandler) UserStartReservation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	rentType, ok := ctx.GetQuery("rentType")
+	if !ok || (rentType != "Minutes" && rentType != "Days") {
+		ctx.Error(entities.NewInvalidParamError("rentType", "must be Minutes or Days"))
+		return
+	}
+
+	userId := ctx.GetUint("id")
+	rent, err := rh.ru.StartReservation(ctx.Request.Context(), userId, uint(id), rentType, ctx.Query("promoCode"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, rent)
+}
+
+// @Summary Календарь бронирований транспорта
+// @Tags ReservationController
+// @Description Промежутки времени, на которые забронирован транспорт с id = {id}.
+// @Description По умолчанию возвращаются бронирования на неделю вперед.
+// @Produce json
+// @Param id path uint true "Transport id"
+// @Param from query string false "Начало периода в формате RFC 3339"
+// @Param to query string false "Конец периода в формате RFC 3339"
+// @Success 200 {array} entities.ReservationSlot
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Rent/Reservations/Transport/{id} [get]
+func (rh RentHandler) GetTransportCalendar(ctx *gin.Context) {
+	transportId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || transportId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+
+	from := time.Now()
+	if fromStr, ok := ctx.GetQuery("from"); ok {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			ctx.Error(entities.NewInvalidParamError("from", "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"))
+			return
+		}
+	}
+	to := from.Add(calendarPeriod)
+	if toStr, ok := ctx.GetQuery("to"); ok {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			ctx.Error(entities.NewInvalidParamError("to", "should be : yyyy-mm-ddThh:mm:ssZ or yyyy-mm-ddThh:mm:ss±hh:mm"))
+			return
+		}
+	}
+
+	slots, err := rh.ru.GetTransportCalendar(ctx.Request.Context(), transportId, from, to)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, slots)
+}
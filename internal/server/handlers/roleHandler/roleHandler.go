@@ -0,0 +1,328 @@
+package roleHandler
+
+import (
+	"context"
+	"net/http"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleUsecase interface {
+	Scope(ctx context.Context, userId uint, permission entities.Permission) (entities.Scope, error)
+	GetPermissions() []entities.Permission
+	GetRoles(ctx context.Context, page pagination.Request) ([]entities.Role, pagination.Meta, error)
+	GetRole(ctx context.Context, id uint) (entities.Role, error)
+	CreateRole(ctx context.Context, role entities.Role) (entities.Role, error)
+	UpdateRole(ctx context.Context, role entities.Role) (entities.Role, error)
+	DeleteRole(ctx context.Context, id uint) error
+	GetUserRoles(ctx context.Context, userId uint) ([]entities.UserRole, error)
+	AssignRole(ctx context.Context, userRole entities.UserRole) error
+	UnassignRole(ctx context.Context, userId, roleId uint) error
+}
+
+type RoleHandler struct {
+	ru RoleUsecase
+}
+
+func New(ru RoleUsecase) RoleHandler {
+	return RoleHandler{ru: ru}
+}
+
+// @Summary Список разрешений
+// @Tags AdminRoleController
+// @Description Получение списка всех разрешений, которые можно назначить роли
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {array} string
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/Permissions [get]
+func (rh RoleHandler) GetPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, rh.ru.GetPermissions())
+}
+
+// @Summary Список ролей
+// @Tags AdminRoleController
+// @Description Получение страницы ролей с их разрешениями.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
+// @Security ApiKeyAuth
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id, name, -name) default(id)
+// @Success 200 {object} pagination.Page{items=[]entities.Role}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles [get]
+func (rh RoleHandler) GetRoles(ctx *gin.Context) {
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "id", "id", "name")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	roles, meta, err := rh.ru.GetRoles(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, pagination.NewPage(roles, meta))
+}
+
+// @Summary Информация о роли
+// @Tags AdminRoleController
+// @Description Получение информации о роли с id = {id}
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path uint true "Role id"
+// @Success 200 {object} entities.Role
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/{id} [get]
+func (rh RoleHandler) GetRole(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	role, err := rh.ru.GetRole(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, role)
+}
+
+// @Summary Создание роли
+// @Tags AdminRoleController
+// @Description Создание роли с указанным набором разрешений
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body roleHandler.CreateRole.roleData true "Role data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 201 {object} entities.Role
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles [post]
+func (rh RoleHandler) CreateRole(ctx *gin.Context) {
+	type roleData struct {
+		Name        string                `json:"name" binding:"required"`
+		Description string                `json:"description"`
+		Permissions []entities.Permission `json:"permissions"`
+	}
+	var rData roleData
+	if err := ctx.ShouldBindJSON(&rData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	role, err := rh.ru.CreateRole(ctx.Request.Context(), entities.Role{
+		Name:        rData.Name,
+		Description: rData.Description,
+		Permissions: rData.Permissions,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, role)
+}
+
+// @Summary Обновление роли
+// @Tags AdminRoleController
+// @Description Обновление названия и разрешений роли с id = {id}. Роль admin изменить нельзя.
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path uint true "Role id"
+// @Param request body roleHandler.UpdateRole.roleData true "Role data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200 {object} entities.Role
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/{id} [put]
+func (rh RoleHandler) UpdateRole(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	type roleData struct {
+		Name        string                `json:"name" binding:"required"`
+		Description string                `json:"description"`
+		Permissions []entities.Permission `json:"permissions"`
+	}
+	var rData roleData
+	if err := ctx.ShouldBindJSON(&rData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	role, err := rh.ru.UpdateRole(ctx.Request.Context(), entities.Role{
+		Id:          uint(id),
+		Name:        rData.Name,
+		Description: rData.Description,
+		Permissions: rData.Permissions,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, role)
+}
+
+// @Summary Удаление роли
+// @Tags AdminRoleController
+// @Description Удаление роли с id = {id}. Роль admin удалить нельзя.
+// @Security ApiKeyAuth
+// @Param id path uint true "Role id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/{id} [delete]
+func (rh RoleHandler) DeleteRole(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	if err := rh.ru.DeleteRole(ctx.Request.Context(), uint(id)); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Роли пользователя
+// @Tags AdminRoleController
+// @Description Получение ролей пользователя с id = {userId}
+// @Security ApiKeyAuth
+// @Produce json
+// @Param userId path uint true "User id"
+// @Success 200 {array} entities.UserRole
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/User/{userId} [get]
+func (rh RoleHandler) GetUserRoles(ctx *gin.Context) {
+	userIdStr := ctx.Param("id")
+	userId, err := strconv.Atoi(userIdStr)
+	if err != nil || userId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	userRoles, err := rh.ru.GetUserRoles(ctx.Request.Context(), uint(userId))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, userRoles)
+}
+
+// @Summary Назначение роли
+// @Tags AdminRoleController
+// @Description Назначение роли пользователю с id = {userId}.
+// @Description Если указан operatorId, разрешения роли на управление транспортом действуют только для транспорта этого владельца.
+// @Security ApiKeyAuth
+// @Accept json
+// @Param userId path uint true "User id"
+// @Param request body roleHandler.AssignRole.assignData true "Role data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/User/{userId} [post]
+func (rh RoleHandler) AssignRole(ctx *gin.Context) {
+	userIdStr := ctx.Param("id")
+	userId, err := strconv.Atoi(userIdStr)
+	if err != nil || userId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	type assignData struct {
+		RoleId     uint `json:"roleId" binding:"required"`
+		OperatorId uint `json:"operatorId"`
+	}
+	var aData assignData
+	if err := ctx.ShouldBindJSON(&aData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
+		return
+	}
+
+	err = rh.ru.AssignRole(ctx.Request.Context(), entities.UserRole{
+		UserId:     uint(userId),
+		RoleId:     aData.RoleId,
+		OperatorId: aData.OperatorId,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Отзыв роли
+// @Tags AdminRoleController
+// @Description Отзыв роли с id = {roleId} у пользователя с id = {userId}
+// @Security ApiKeyAuth
+// @Param userId path uint true "User id"
+// @Param roleId path uint true "Role id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
+// @Success 200
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
+// @Router /api/Admin/Roles/User/{userId}/{roleId} [delete]
+func (rh RoleHandler) UnassignRole(ctx *gin.Context) {
+	userIdStr := ctx.Param("id")
+	userId, err := strconv.Atoi(userIdStr)
+	if err != nil || userId < 0 {
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
+		return
+	}
+	roleIdStr := ctx.Param("roleId")
+	roleId, err := strconv.Atoi(roleIdStr)
+	if err != nil || roleId < 0 {
+		ctx.Error(entities.NewInvalidParamError("roleId", "must be a non-negative integer"))
+		return
+	}
+	if err := rh.ru.UnassignRole(ctx.Request.Context(), uint(userId), uint(roleId)); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
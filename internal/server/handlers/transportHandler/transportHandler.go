@@ -1,10 +1,12 @@
 package transportHandler
 
 import (
+	"context"
 	"math"
-	"net/http"
 	"simbirGo/internal/entities"
 	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/pagination"
+	middleware "simbirGo/internal/server/middlewares"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -12,16 +14,17 @@ import (
 
 type TransportUsecase interface {
 	//user's cases
-	GetTransport(id uint) (entities.Transport, error)
-	CreateTransport(transport entities.Transport) (entities.Transport, error)
-	UpdateUserTransport(transport entities.Transport) (entities.Transport, error)
-	DeleteUserTransport(userId, transportId uint) error
-	GetTransports(start, count int, transportType string) ([]entities.Transport, error)
+	GetTransport(ctx context.Context, id uint) (entities.Transport, error)
+	CreateTransport(ctx context.Context, transport entities.Transport) (entities.Transport, error)
+	UpdateUserTransport(ctx context.Context, transport entities.Transport) (entities.Transport, error)
+	DeleteUserTransport(ctx context.Context, userId, transportId uint) error
 
 	// admin's cases
-	AdminCreateTransport(transport entities.Transport) (entities.Transport, error)
-	AdminUpdateTransport(transport entities.Transport) (entities.Transport, error)
-	AdminDeleteTransport(id uint) error
+	GetTransports(ctx context.Context, transportType string, scope entities.Scope, page pagination.Request) ([]entities.Transport, pagination.Meta, error)
+	AdminGetTransport(ctx context.Context, id uint, scope entities.Scope) (entities.Transport, error)
+	AdminCreateTransport(ctx context.Context, transport entities.Transport, scope entities.Scope) (entities.Transport, error)
+	AdminUpdateTransport(ctx context.Context, transport entities.Transport, scope entities.Scope) (entities.Transport, error)
+	AdminDeleteTransport(ctx context.Context, id uint, scope entities.Scope) error
 }
 
 type TransportHandler struct {
@@ -40,18 +43,20 @@ func New(tu TransportUsecase) TransportHandler {
 // @Produce json
 // @Param id path uint true "Transport id"
 // @Success 200 {object} transportHandler.UserGetTransport.transportData
-// @Failure 400 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Transport/{id} [get]
 func (th TransportHandler) UserGetTransport(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	transport, err := th.tu.GetTransport(uint(id))
+	transport, err := th.tu.GetTransport(ctx.Request.Context(), uint(id))
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -89,9 +94,12 @@ func (th TransportHandler) UserGetTransport(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body transportHandler.UserCreateTransport.transportData true "Transport data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} transportHandler.UserCreateTransport.responseData
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Transport [post]
 func (th TransportHandler) UserCreateTransport(ctx *gin.Context) {
 	type transportData struct {
@@ -108,25 +116,25 @@ func (th TransportHandler) UserCreateTransport(ctx *gin.Context) {
 	}
 
 	var tData transportData
-	if err := ctx.BindJSON(&tData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&tData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if tData.DayPrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of dayPrice", entities.FieldError{Field: "dayPrice", Message: "must be non-negative"}))
 		return
 	}
 	if tData.MinutePrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of minutePrice", entities.FieldError{Field: "minutePrice", Message: "must be non-negative"}))
 		return
 	}
 	if math.Abs(tData.Longitude) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of longitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of longitude", entities.FieldError{Field: "longitude", Message: "must be between -180 and 180"}))
 		return
 	}
 	if math.Abs(tData.Latitude) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of latitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of latitude", entities.FieldError{Field: "latitude", Message: "must be between -90 and 90"}))
 		return
 	}
 
@@ -145,9 +153,9 @@ func (th TransportHandler) UserCreateTransport(ctx *gin.Context) {
 		DayPrice:      tData.DayPrice,
 	}
 
-	transport, err := th.tu.CreateTransport(transport)
+	transport, err := th.tu.CreateTransport(ctx.Request.Context(), transport)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -188,9 +196,13 @@ func (th TransportHandler) UserCreateTransport(ctx *gin.Context) {
 // @Produce  json
 // @Param id path uint true "Transport id"
 // @Param request body transportHandler.UserUpdateTransport.transportData true "Transport data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200 {object} transportHandler.UserUpdateTransport.responseData
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Transport/{id} [put]
 func (th TransportHandler) UserUpdateTransport(ctx *gin.Context) {
 	type transportData struct {
@@ -207,32 +219,32 @@ func (th TransportHandler) UserUpdateTransport(ctx *gin.Context) {
 	}
 
 	var tData transportData
-	if err := ctx.BindJSON(&tData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&tData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if tData.DayPrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of dayPrice", entities.FieldError{Field: "dayPrice", Message: "must be non-negative"}))
 		return
 	}
 	if tData.MinutePrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of minutePrice", entities.FieldError{Field: "minutePrice", Message: "must be non-negative"}))
 		return
 	}
 	if math.Abs(tData.Longitude) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of longitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of longitude", entities.FieldError{Field: "longitude", Message: "must be between -180 and 180"}))
 		return
 	}
 	if math.Abs(tData.Latitude) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of latitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of latitude", entities.FieldError{Field: "latitude", Message: "must be between -90 and 90"}))
 		return
 	}
 
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 	userId := ctx.GetUint("id")
@@ -250,9 +262,9 @@ func (th TransportHandler) UserUpdateTransport(ctx *gin.Context) {
 		MinutePrice:   tData.MinutePrice,
 		DayPrice:      tData.DayPrice,
 	}
-	transport, err = th.tu.UpdateUserTransport(transport)
+	transport, err = th.tu.UpdateUserTransport(ctx.Request.Context(), transport)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -289,21 +301,25 @@ func (th TransportHandler) UserUpdateTransport(ctx *gin.Context) {
 // @Description Удаление транспорта с id = {id}. Удалить данные о транспорте может только владелец транспорта.
 // @Security ApiKeyAuth
 // @Param id path uint true "Transport id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Transport/{id} [delete]
 func (th TransportHandler) UserDeleteTransport(ctx *gin.Context) {
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 	userId := ctx.GetUint("id")
-	err = th.tu.DeleteUserTransport(userId, uint(transportId))
+	err = th.tu.DeleteUserTransport(ctx.Request.Context(), userId, uint(transportId))
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 	ctx.Status(200)
@@ -313,40 +329,35 @@ func (th TransportHandler) UserDeleteTransport(ctx *gin.Context) {
 
 // @Summary Информация о транспортных средствах
 // @Tags AdminTransportController
-// @Description Получение count транспортных средств с id >= start и с типом транспорта transportType
+// @Description Получение страницы транспортных средств с типом транспорта transportType.
+// @Description Следующая страница запрашивается с cursor = nextCursor из предыдущего ответа, nextCursor пуст на последней странице.
 // @Security ApiKeyAuth
 // @Produce  json
-// @Param start query uint true "start"
-// @Param count query uint true "count"
 // @Param transportType query string true "transportType" Enums(Car, Bike, Scooter)
-// @Success 200 {array} entities.Transport
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Param limit query int false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "nextCursor предыдущей страницы"
+// @Param sort query string false "Поле сортировки, - в начале для сортировки по убыванию" Enums(id, -id, model, -model) default(id)
+// @Success 200 {object} pagination.Page{items=[]entities.Transport}
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Transport [get]
 func (th TransportHandler) AdminGetTransports(ctx *gin.Context) {
-	startStr := ctx.Query("start")
-	countStr := ctx.Query("count")
-	transportType := ctx.Query("transportType")
-	start, err := strconv.Atoi(startStr)
-	if err != nil || start < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of start query param")
-		return
-	}
-
-	count, err := strconv.Atoi(countStr)
-	if err != nil || count < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of count query param")
+	page, err := pagination.Parse(ctx.Request.URL.Query(), "id", "id", "model")
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	transports, err := th.tu.GetTransports(start, count, transportType)
+	scope := middleware.GetScope(ctx, entities.PermissionTransportsManage)
+	transports, meta, err := th.tu.GetTransports(ctx.Request.Context(), ctx.Query("transportType"), scope, page)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(200, transports)
+	ctx.JSON(200, pagination.NewPage(transports, meta))
 }
 
 // @Summary Информация о транспортном средстве
@@ -356,20 +367,23 @@ func (th TransportHandler) AdminGetTransports(ctx *gin.Context) {
 // @Produce json
 // @Param id path uint true "Transport id"
 // @Success 200 {object} entities.Transport
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Transport/{id} [get]
 func (th TransportHandler) AdminGetTransport(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 0 {
-		httpUtil.NewResponseError(ctx, http.StatusBadRequest, err.Error())
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
-	transport, err := th.tu.GetTransport(uint(id))
+	scope := middleware.GetScope(ctx, entities.PermissionTransportsManage)
+	transport, err := th.tu.AdminGetTransport(ctx.Request.Context(), uint(id), scope)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -383,10 +397,14 @@ func (th TransportHandler) AdminGetTransport(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body transportHandler.AdminCreateTransport.transportData true "Transport data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 201 {object} entities.Transport
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Transport [post]
 func (th TransportHandler) AdminCreateTransport(ctx *gin.Context) {
 	type transportData struct {
@@ -404,25 +422,25 @@ func (th TransportHandler) AdminCreateTransport(ctx *gin.Context) {
 	}
 
 	var tData transportData
-	if err := ctx.BindJSON(&tData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&tData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if tData.DayPrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of dayPrice", entities.FieldError{Field: "dayPrice", Message: "must be non-negative"}))
 		return
 	}
 	if tData.MinutePrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of minutePrice", entities.FieldError{Field: "minutePrice", Message: "must be non-negative"}))
 		return
 	}
 	if math.Abs(tData.Longitude) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of longitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of longitude", entities.FieldError{Field: "longitude", Message: "must be between -180 and 180"}))
 		return
 	}
 	if math.Abs(tData.Latitude) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of latitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of latitude", entities.FieldError{Field: "latitude", Message: "must be between -90 and 90"}))
 		return
 	}
 
@@ -440,9 +458,10 @@ func (th TransportHandler) AdminCreateTransport(ctx *gin.Context) {
 		DayPrice:      tData.DayPrice,
 	}
 
-	transport, err := th.tu.AdminCreateTransport(transport)
+	scope := middleware.GetScope(ctx, entities.PermissionTransportsManage)
+	transport, err := th.tu.AdminCreateTransport(ctx.Request.Context(), transport, scope)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -457,10 +476,14 @@ func (th TransportHandler) AdminCreateTransport(ctx *gin.Context) {
 // @Produce json
 // @Param id path uint true "Transport id"
 // @Param request body transportHandler.AdminUpdateTransport.transportData true "Transport data"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200 {object} entities.Transport
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Transport/{id} [put]
 func (th TransportHandler) AdminUpdateTransport(ctx *gin.Context) {
 	type transportData struct {
@@ -478,32 +501,32 @@ func (th TransportHandler) AdminUpdateTransport(ctx *gin.Context) {
 	}
 
 	var tData transportData
-	if err := ctx.BindJSON(&tData); err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+	if err := ctx.ShouldBindJSON(&tData); err != nil {
+		ctx.Error(httpUtil.NewBindingError(err))
 		return
 	}
 
 	if tData.DayPrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of dayPrice", entities.FieldError{Field: "dayPrice", Message: "must be non-negative"}))
 		return
 	}
 	if tData.MinutePrice < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of dayPrice")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of minutePrice", entities.FieldError{Field: "minutePrice", Message: "must be non-negative"}))
 		return
 	}
 	if math.Abs(tData.Longitude) > 180 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of longitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of longitude", entities.FieldError{Field: "longitude", Message: "must be between -180 and 180"}))
 		return
 	}
 	if math.Abs(tData.Latitude) > 90 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of latitude")
+		ctx.Error(entities.NewValidationError(entities.CodeValidationFailed, "invalid value of latitude", entities.FieldError{Field: "latitude", Message: "must be between -90 and 90"}))
 		return
 	}
 
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
@@ -522,9 +545,10 @@ func (th TransportHandler) AdminUpdateTransport(ctx *gin.Context) {
 		DayPrice:      tData.DayPrice,
 	}
 
-	transport, err = th.tu.AdminUpdateTransport(transport)
+	scope := middleware.GetScope(ctx, entities.PermissionTransportsManage)
+	transport, err = th.tu.AdminUpdateTransport(ctx.Request.Context(), transport, scope)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(200, transport)
@@ -535,22 +559,27 @@ func (th TransportHandler) AdminUpdateTransport(ctx *gin.Context) {
 // @Description Удаление информации о транспортном средстве с id = {id}
 // @Security ApiKeyAuth
 // @Param id path uint true "Transport id"
+// @Param Idempotency-Key header string false "ключ идемпотентности для безопасного повтора запроса"
 // @Success 200
-// @Failure 400 {object} httpUtil.ResponseError
-// @Failure 401 {object} httpUtil.ResponseError
-// @Failure 403 {object} httpUtil.ResponseError
+// @Failure 400 {object} httpUtil.Problem
+// @Failure 401 {object} httpUtil.Problem
+// @Failure 403 {object} httpUtil.Problem
+// @Failure 404 {object} httpUtil.Problem
+// @Failure 409 {object} httpUtil.Problem
+// @Failure 500 {object} httpUtil.Problem
 // @Router /api/Admin/Transport/{id} [delete]
 func (th TransportHandler) AdminDeleteTransport(ctx *gin.Context) {
 	transportIdStr := ctx.Param("id")
 	transportId, err := strconv.Atoi(transportIdStr)
 	if err != nil || transportId < 0 {
-		httpUtil.NewResponseError(ctx, 400, "invalid value of id param")
+		ctx.Error(entities.NewInvalidParamError("id", "must be a non-negative integer"))
 		return
 	}
 
-	err = th.tu.AdminDeleteTransport(uint(transportId))
+	scope := middleware.GetScope(ctx, entities.PermissionTransportsManage)
+	err = th.tu.AdminDeleteTransport(ctx.Request.Context(), uint(transportId), scope)
 	if err != nil {
-		httpUtil.NewResponseError(ctx, 400, err.Error())
+		ctx.Error(err)
 		return
 	}
 
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"errors"
+	"simbirGo/internal/entities"
 	httpUtil "simbirGo/internal/httputil"
 	"simbirGo/internal/tokens"
 	"strings"
@@ -8,7 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func CheckAuthification() gin.HandlerFunc {
+func CheckAuthification(rs tokens.RevocationStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		authHeaderArray := strings.Split(authHeader, " ")
@@ -18,20 +20,32 @@ func CheckAuthification() gin.HandlerFunc {
 		}
 		if authHeaderArray[1] == "" {
 			httpUtil.NewResponseError(ctx, 401, "invalid jwt token")
+			return
 		}
 		token := authHeaderArray[1]
-		if tokens.IsInBlackList(token) {
-			httpUtil.NewResponseError(ctx, 401, "unauthorized")
+		tokenData, err := tokens.ParseToken(token)
+		if errors.Is(err, tokens.ErrTokenExpired) {
+			ctx.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="token is expired"`)
+			httpUtil.NewResponseErrorWithCode(ctx, 401, entities.CodeTokenExpired, err.Error())
 			return
 		}
-
-		tokenData, err := tokens.ParseToken(token)
 		if err != nil {
-			httpUtil.NewResponseError(ctx, 401, err.Error())
+			ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			httpUtil.NewResponseErrorWithCode(ctx, 401, entities.CodeTokenInvalid, err.Error())
+			return
+		}
+		revoked, err := rs.IsRevoked(tokenData)
+		if err != nil {
+			httpUtil.NewResponseError(ctx, 500, "failed to check token")
 			return
 		}
+		if revoked {
+			ctx.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="token is revoked"`)
+			httpUtil.NewResponseErrorWithCode(ctx, 401, entities.CodeTokenRevoked, "token is revoked")
+			return
+		}
+
 		ctx.Set("id", tokenData.Id)
-		ctx.Set("isAdmin", tokenData.IsAdmin)
 		ctx.Next()
 	}
 }
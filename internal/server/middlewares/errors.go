@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	httpUtil "simbirGo/internal/httputil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleErrors writes the last error added by handler with ctx.Error
+// as problem+json response. Status is chosen by the kind of domain error.
+func HandleErrors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		err := ctx.Errors.Last()
+		if err == nil || ctx.Writer.Written() {
+			return
+		}
+		httpUtil.NewResponseErrorFrom(ctx, err.Err)
+	}
+}
@@ -0,0 +1,126 @@
+package middlewares
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/idempotency"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency replays the stored response when mutating request is retried
+// with the same Idempotency-Key. Keys are scoped by the user, so it must go after CheckAuthification.
+// Responses with 5xx status are not stored, such requests can be retried with the same key.
+// Failures of the store are added to ctx.Errors to be logged.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotency.Header)
+		if key == "" || !isMutating(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			httpUtil.NewResponseErrorFrom(ctx, entities.NewValidationError(entities.CodeValidationFailed, "invalid idempotency key",
+				entities.FieldError{Field: idempotency.Header, Message: fmt.Sprintf("must not be longer than %d characters", idempotency.MaxKeyLength)}))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			httpUtil.NewResponseErrorWithCode(ctx, 400, entities.CodeInvalidBody, "failed to read request body")
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := fmt.Sprintf("%d:%s", ctx.GetUint("id"), key)
+		fingerprint := idempotency.Fingerprint(ctx.Request.Method, ctx.Request.URL.RequestURI(), body)
+		record, ok, err := store.Begin(storeKey, fingerprint, time.Now().Add(ttl))
+		if err != nil {
+			ctx.Error(err)
+			httpUtil.NewResponseError(ctx, 500, "failed to check idempotency key")
+			return
+		}
+		if !ok {
+			switch {
+			case record.Fingerprint != fingerprint:
+				httpUtil.NewResponseErrorWithCode(ctx, 409, entities.CodeIdempotencyKeyReused, "idempotency key is already used for another request")
+			case !record.Completed:
+				httpUtil.NewResponseErrorWithCode(ctx, 409, entities.CodeIdempotencyInProgress, "request with this idempotency key is in progress")
+			default:
+				replay(ctx, record.Response)
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Release(storeKey); err != nil {
+				ctx.Error(err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// errors are written here instead of HandleErrors to store them as the response
+		if err := ctx.Errors.Last(); err != nil && !ctx.Writer.Written() {
+			httpUtil.NewResponseErrorFrom(ctx, err.Err)
+		}
+		if recorder.Status() >= 500 {
+			return
+		}
+		response := idempotency.Response{
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := store.Complete(storeKey, response); err != nil {
+			ctx.Error(err)
+			return
+		}
+		completed = true
+	}
+}
+
+func replay(ctx *gin.Context, response idempotency.Response) {
+	ctx.Header("Idempotent-Replayed", "true")
+	if len(response.Body) == 0 {
+		ctx.AbortWithStatus(response.Status)
+		return
+	}
+	ctx.Data(response.Status, response.ContentType, response.Body)
+	ctx.Abort()
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder copies the response body to store it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
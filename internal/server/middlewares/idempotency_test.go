@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/idempotency"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		method string
+		path   string
+		key    string
+		body   string
+	}
+	type response struct {
+		status   int
+		code     string
+		replayed bool
+	}
+
+	tests := []struct {
+		name     string
+		requests []request
+		want     []response
+		calls    int
+	}{
+		{
+			name: "retry is replayed",
+			requests: []request{
+				{method: "POST", path: "/rent", key: "a", body: `{"id":1}`},
+				{method: "POST", path: "/rent", key: "a", body: `{"id":1}`},
+			},
+			want:  []response{{status: 200}, {status: 200, replayed: true}},
+			calls: 1,
+		},
+		{
+			name: "key with another payload",
+			requests: []request{
+				{method: "POST", path: "/rent", key: "a", body: `{"id":1}`},
+				{method: "POST", path: "/rent", key: "a", body: `{"id":2}`},
+			},
+			want:  []response{{status: 200}, {status: 409, code: entities.CodeIdempotencyKeyReused}},
+			calls: 1,
+		},
+		{
+			name: "key on another path",
+			requests: []request{
+				{method: "POST", path: "/rent", key: "a"},
+				{method: "POST", path: "/fail", key: "a"},
+			},
+			want:  []response{{status: 200}, {status: 409, code: entities.CodeIdempotencyKeyReused}},
+			calls: 1,
+		},
+		{
+			name: "domain error is replayed",
+			requests: []request{
+				{method: "POST", path: "/conflict", key: "a"},
+				{method: "POST", path: "/conflict", key: "a"},
+			},
+			want:  []response{{status: 409, code: entities.CodeConflict}, {status: 409, code: entities.CodeConflict, replayed: true}},
+			calls: 1,
+		},
+		{
+			name: "internal error is not stored",
+			requests: []request{
+				{method: "POST", path: "/fail", key: "a"},
+				{method: "POST", path: "/fail", key: "a"},
+			},
+			want:  []response{{status: 500, code: entities.CodeInternal}, {status: 500, code: entities.CodeInternal}},
+			calls: 2,
+		},
+		{
+			name: "requests without key",
+			requests: []request{
+				{method: "POST", path: "/rent"},
+				{method: "POST", path: "/rent"},
+			},
+			want:  []response{{status: 200}, {status: 200}},
+			calls: 2,
+		},
+		{
+			name: "safe methods are not stored",
+			requests: []request{
+				{method: "GET", path: "/rent", key: "a"},
+				{method: "GET", path: "/rent", key: "a"},
+			},
+			want:  []response{{status: 200}, {status: 200}},
+			calls: 2,
+		},
+		{
+			name:     "too long key",
+			requests: []request{{method: "POST", path: "/rent", key: strings.Repeat("a", idempotency.MaxKeyLength+1)}},
+			want:     []response{{status: 400, code: entities.CodeValidationFailed}},
+			calls:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			router := gin.New()
+			router.Use(HandleErrors(), func(ctx *gin.Context) { ctx.Set("id", uint(1)) },
+				Idempotency(idempotency.NewMemoryStore(time.Minute), time.Hour))
+			handler := func(ctx *gin.Context) {
+				calls++
+				ctx.JSON(200, gin.H{"calls": calls})
+			}
+			router.POST("/rent", handler)
+			router.GET("/rent", handler)
+			router.POST("/conflict", func(ctx *gin.Context) {
+				calls++
+				ctx.Error(entities.NewConflictError(entities.CodeConflict, "conflict"))
+			})
+			router.POST("/fail", func(ctx *gin.Context) {
+				calls++
+				ctx.Error(assert.AnError)
+			})
+
+			var first string
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+				if r.key != "" {
+					req.Header.Set(idempotency.Header, r.key)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.want[i].status, w.Code)
+				if tt.want[i].code != "" {
+					assert.Contains(t, w.Body.String(), `"code":"`+tt.want[i].code+`"`)
+				}
+				assert.Equal(t, tt.want[i].replayed, w.Header().Get("Idempotent-Replayed") == "true")
+				if i == 0 {
+					first = w.Body.String()
+				} else if tt.want[i].replayed {
+					assert.Equal(t, first, w.Body.String())
+					assert.NotEmpty(t, w.Header().Get("Content-Type"))
+				}
+			}
+			assert.Equal(t, tt.calls, calls)
+		})
+	}
+}
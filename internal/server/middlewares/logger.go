@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/logging"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+// requestIdPattern limits ids passed by clients, so they are safe to write to logs
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestId takes id of the request from X-Request-ID header or generates a new one.
+// The id is returned in the response header and stored in the request context for logs and errors.
+func RequestId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(id) {
+			id = newRequestId()
+		}
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestId(ctx.Request.Context(), id))
+		ctx.Header(RequestIdHeader, id)
+		ctx.Next()
+	}
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Logger writes a line for every request. Errors added by handlers with ctx.Error
+// are written with the line, so internal errors hidden from clients are logged here.
+// Headers are logged only on debug level with sensitive values redacted.
+func Logger(log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if id, ok := ctx.Get("id"); ok {
+			attrs = append(attrs, slog.Any("user_id", id))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", ctx.Errors.Errors()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		if log.Enabled(ctx.Request.Context(), slog.LevelDebug) {
+			headers := make([]any, 0, len(ctx.Request.Header))
+			for name, values := range ctx.Request.Header {
+				headers = append(headers, slog.Any(name, values))
+			}
+			attrs = append(attrs, slog.Group("headers", headers...))
+		}
+		log.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panic of the handler into 500 response,
+// the panic with its stack is logged by Logger as error of the request
+func Recovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				ctx.Error(fmt.Errorf("panic: %v\n%s", r, debug.Stack()))
+				httpUtil.NewResponseError(ctx, 500, "internal server error")
+			}
+		}()
+		ctx.Next()
+	}
+}
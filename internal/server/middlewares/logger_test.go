@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"simbirGo/internal/config"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/logging"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		header   string
+		generate bool
+	}{
+		{name: "id of the client", header: "abc-123"},
+		{name: "no id", header: "", generate: true},
+		{name: "unsafe id", header: "abc\n123", generate: true},
+		{name: "too long id", header: strings.Repeat("a", 129), generate: true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			router := gin.New()
+			router.Use(RequestId(), Logger(logging.New(config.LogConfig{Level: "info", Format: "json"}, &logs)), HandleErrors())
+			router.GET("/rent", func(ctx *gin.Context) {
+				ctx.Error(errors.New("connection refused"))
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/rent", nil)
+			req.Header.Set(RequestIdHeader, testCase.header)
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIdHeader)
+			if testCase.generate {
+				assert.Len(t, id, 32)
+			} else {
+				assert.Equal(t, testCase.header, id)
+			}
+
+			var problem httpUtil.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, id, problem.RequestId)
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+			assert.Equal(t, id, record["request_id"])
+			assert.Equal(t, "ERROR", record["level"])
+			assert.Equal(t, []any{"connection refused"}, record["errors"])
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	router := gin.New()
+	router.Use(RequestId(), Logger(logging.New(config.LogConfig{Level: "debug", Format: "json"}, &logs)), Recovery())
+	router.GET("/panic", func(ctx *gin.Context) {
+		panic("unexpected")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("User-Agent", "test")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 500, w.Code)
+	assert.NotContains(t, logs.String(), "secret-token")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, float64(500), record["status"])
+	require.Len(t, record["errors"], 1)
+	panicErr := record["errors"].([]any)[0].(string)
+	assert.True(t, strings.HasPrefix(panicErr, "panic: unexpected\n"), panicErr)
+	// stack points to the handler which panicked
+	assert.Contains(t, panicErr, "logger_test.go")
+	assert.Equal(t, map[string]any{"Authorization": logging.Redacted, "User-Agent": []any{"test"}}, record["headers"])
+}
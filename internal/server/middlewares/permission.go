@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionChecker interface {
+	Scope(ctx context.Context, userId uint, permission entities.Permission) (entities.Scope, error)
+}
+
+// LoadPermission stores scope of the permission for the current user,
+// handler decides what to do if the user has no permission
+func LoadPermission(pc PermissionChecker, permission entities.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope, err := pc.Scope(ctx.Request.Context(), ctx.GetUint("id"), permission)
+		if err != nil {
+			httpUtil.NewResponseError(ctx, 500, "failed to check permissions")
+			return
+		}
+		ctx.Set(scopeKey(permission), scope)
+		ctx.Next()
+	}
+}
+
+// RequirePermission rejects users without the permission
+func RequirePermission(pc PermissionChecker, permission entities.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope, err := pc.Scope(ctx.Request.Context(), ctx.GetUint("id"), permission)
+		if err != nil {
+			httpUtil.NewResponseError(ctx, 500, "failed to check permissions")
+			return
+		}
+		if scope.IsEmpty() {
+			httpUtil.NewResponseErrorWithCode(ctx, 403, entities.CodePermissionRequired, fmt.Sprintf("permission %s is required", permission))
+			return
+		}
+		ctx.Set(scopeKey(permission), scope)
+		ctx.Next()
+	}
+}
+
+// GetScope returns scope stored by LoadPermission or RequirePermission
+func GetScope(ctx *gin.Context, permission entities.Permission) entities.Scope {
+	scope, _ := ctx.Value(scopeKey(permission)).(entities.Scope)
+	return scope
+}
+
+func scopeKey(permission entities.Permission) string {
+	return "scope:" + string(permission)
+}
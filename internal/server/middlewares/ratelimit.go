@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"fmt"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter takes a token of the key, if there is none it returns how long to wait
+type RateLimiter interface {
+	Allow(key string) (bool, time.Duration)
+}
+
+// RateLimitByIP limits requests of every client ip to the route
+func RateLimitByIP(l RateLimiter) gin.HandlerFunc {
+	return rateLimit(l, func(ctx *gin.Context) string {
+		return "ip:" + ctx.ClientIP()
+	})
+}
+
+// RateLimitByUser limits requests of every user to the route, it must go after CheckAuthification
+func RateLimitByUser(l RateLimiter) gin.HandlerFunc {
+	return rateLimit(l, func(ctx *gin.Context) string {
+		return fmt.Sprintf("user:%d", ctx.GetUint("id"))
+	})
+}
+
+// rateLimit rejects requests with 429 and Retry-After header when the bucket of the client is empty
+func rateLimit(l RateLimiter, client func(ctx *gin.Context) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ok, retryAfter := l.Allow(ctx.Request.Method + " " + route + " " + client(ctx))
+		if !ok {
+			httpUtil.NewResponseErrorFrom(ctx, entities.NewTooManyRequestsError(entities.CodeRateLimited,
+				"too many requests, retry later", retryAfter))
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"simbirGo/internal/entities"
+	httpUtil "simbirGo/internal/httputil"
+	"simbirGo/internal/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	limiter := ratelimit.New(ratelimit.Limit{Requests: 2, Period: time.Minute})
+	router.Use(RateLimitByIP(limiter))
+	router.POST("/api/Account/SignIn", func(ctx *gin.Context) { ctx.Status(200) })
+	router.POST("/api/Account/SignUp", func(ctx *gin.Context) { ctx.Status(200) })
+	users := router.Group("/api/Rent", func(ctx *gin.Context) {
+		ctx.Set("id", uint(ctx.GetHeader("X-User")[0]-'0'))
+	}, RateLimitByUser(ratelimit.New(ratelimit.Limit{Requests: 1, Period: time.Minute})))
+	users.GET("/MyHistory", func(ctx *gin.Context) { ctx.Status(200) })
+
+	request := func(method, path, ip, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-User", user)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		ip         string
+		user       string
+		wantStatus int
+	}{
+		{name: "first request", method: "POST", path: "/api/Account/SignIn", ip: "10.0.0.1", wantStatus: 200},
+		{name: "second request", method: "POST", path: "/api/Account/SignIn", ip: "10.0.0.1", wantStatus: 200},
+		{name: "limit is reached", method: "POST", path: "/api/Account/SignIn", ip: "10.0.0.1", wantStatus: 429},
+		{name: "other route", method: "POST", path: "/api/Account/SignUp", ip: "10.0.0.1", wantStatus: 200},
+		{name: "other ip", method: "POST", path: "/api/Account/SignIn", ip: "10.0.0.2", wantStatus: 200},
+		{name: "user", method: "GET", path: "/api/Rent/MyHistory", ip: "10.0.0.3", user: "1", wantStatus: 200},
+		{name: "same user from other ip", method: "GET", path: "/api/Rent/MyHistory", ip: "10.0.0.4", user: "1", wantStatus: 429},
+		{name: "other user from same ip", method: "GET", path: "/api/Rent/MyHistory", ip: "10.0.0.4", user: "2", wantStatus: 200},
+	}
+
+	for _, testCase := range tests {
+		w := request(testCase.method, testCase.path, testCase.ip, testCase.user)
+		assert.Equal(t, testCase.wantStatus, w.Code, testCase.name)
+		if testCase.wantStatus != 429 {
+			continue
+		}
+		assert.NotEmpty(t, w.Header().Get("Retry-After"), testCase.name)
+		var problem httpUtil.Problem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, entities.CodeRateLimited, problem.Code)
+	}
+	assert.Equal(t, "30", request("POST", "/api/Account/SignIn", "10.0.0.1", "").Header().Get("Retry-After"))
+}
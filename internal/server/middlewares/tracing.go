@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"simbirGo/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "simbirGo/internal/server/middlewares"
+
+// requestIdAttr links the span with logs and problem responses of the request
+const requestIdAttr = attribute.Key("request.id")
+
+// Tracing starts server span of the request continuing trace of the client from
+// traceparent header. Span is named by route template and is failed on 5xx responses,
+// errors of the request are recorded as span events.
+func Tracing(tp trace.TracerProvider, p propagation.TextMapPropagator) gin.HandlerFunc {
+	tracer := tp.Tracer(tracerName)
+	return func(ctx *gin.Context) {
+		parent := p.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+
+		name := ctx.Request.Method
+		attrs := []attribute.KeyValue{
+			semconv.HTTPMethod(ctx.Request.Method),
+			semconv.URLPath(ctx.Request.URL.Path),
+		}
+		if route := ctx.FullPath(); route != "" {
+			name += " " + route
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+		if id := logging.RequestId(ctx.Request.Context()); id != "" {
+			attrs = append(attrs, requestIdAttr.String(id))
+		}
+
+		spanCtx, span := tracer.Start(parent, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
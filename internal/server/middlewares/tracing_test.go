@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http/httptest"
+	"simbirGo/internal/tracing"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantStatus  int
+		wantCode    codes.Code
+		wantTraceId string
+	}{
+		{
+			name:       "new trace",
+			path:       "/api/Rent/1",
+			wantName:   "GET /api/Rent/:id",
+			wantStatus: 200,
+			wantCode:   codes.Unset,
+		},
+		{
+			name:        "trace of the client",
+			path:        "/api/Rent/1",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantName:    "GET /api/Rent/:id",
+			wantStatus:  200,
+			wantCode:    codes.Unset,
+			wantTraceId: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:       "failed request",
+			path:       "/api/Rent/End/1",
+			wantName:   "GET /api/Rent/End/:id",
+			wantStatus: 500,
+			wantCode:   codes.Error,
+		},
+		{
+			name:       "unknown route",
+			path:       "/api/Unknown",
+			wantName:   "GET",
+			wantStatus: 404,
+			wantCode:   codes.Unset,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := tracing.NewProvider(sdktrace.WithSyncer(exporter))
+
+			var handlerSpan trace.SpanContext
+			router := gin.New()
+			router.Use(RequestId(), Tracing(tp, tracing.Propagator))
+			router.GET("/api/Rent/:id", func(ctx *gin.Context) {
+				handlerSpan = trace.SpanContextFromContext(ctx.Request.Context())
+				ctx.Status(200)
+			})
+			router.GET("/api/Rent/End/:id", func(ctx *gin.Context) {
+				ctx.Error(errors.New("connection refused"))
+				ctx.Status(500)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, nil)
+			if testCase.traceparent != "" {
+				req.Header.Set("traceparent", testCase.traceparent)
+			}
+			router.ServeHTTP(w, req)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, testCase.wantName, span.Name)
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+			assert.Equal(t, testCase.wantCode, span.Status.Code)
+			assert.Contains(t, span.Attributes, semconv.HTTPStatusCode(testCase.wantStatus))
+			assert.Contains(t, span.Attributes, attribute.String("request.id", w.Header().Get(RequestIdHeader)))
+			if testCase.wantTraceId != "" {
+				assert.Equal(t, testCase.wantTraceId, span.SpanContext.TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+			}
+			if testCase.wantStatus == 200 {
+				assert.Equal(t, span.SpanContext.SpanID(), handlerSpan.SpanID())
+			}
+			if testCase.wantCode == codes.Error {
+				require.Len(t, span.Events, 1)
+				assert.Equal(t, "exception", span.Events[0].Name)
+			}
+		})
+	}
+}
@@ -2,13 +2,22 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"simbirGo/internal/config"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/idempotency"
 	"simbirGo/internal/server/handlers/authHandler"
+	"simbirGo/internal/server/handlers/healthHandler"
 	"simbirGo/internal/server/handlers/paymentHandler"
+	"simbirGo/internal/server/handlers/pricingHandler"
+	"simbirGo/internal/server/handlers/promoHandler"
 	"simbirGo/internal/server/handlers/rentHandler"
+	"simbirGo/internal/server/handlers/roleHandler"
 	"simbirGo/internal/server/handlers/transportHandler"
 	middleware "simbirGo/internal/server/middlewares"
+	"simbirGo/internal/tokens"
 	"time"
 
 	_ "simbirGo/docs"
@@ -16,6 +25,8 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Usecase interface {
@@ -24,45 +35,181 @@ type Usecase interface {
 	transportHandler.TransportUsecase
 }
 
+// Metrics measures requests and serves collected metrics
+type Metrics interface {
+	middleware.RequestObserver
+	Handler() http.Handler
+}
+
+// Readiness checks dependencies of the server, after Drain it must report not ready
+type Readiness interface {
+	healthHandler.ReadinessChecker
+	Drain()
+}
+
+// RateLimits limit requests of every client to every route, nil limiter does not limit
+type RateLimits struct {
+	// IP limits all routes by client ip
+	IP middleware.RateLimiter
+	// User limits routes of authenticated users by user id
+	User middleware.RateLimiter
+	// Auth limits sign in, sign up and refresh by client ip
+	Auth middleware.RateLimiter
+}
+
 type Server struct {
-	addr   string
-	router *gin.Engine
+	cfg              config.HTTPConfig
+	log              *slog.Logger
+	router           *gin.Engine
+	rs               tokens.RevocationStore
+	is               idempotency.Store
+	idempotencyTTL   time.Duration
+	metrics          Metrics
+	metricsPath      string
+	readiness        Readiness
+	limits           RateLimits
+	tp               trace.TracerProvider
+	propagator       propagation.TextMapPropagator
+	simulatePayments bool
 }
 
-func New(addr string) Server {
+func New(cfg config.HTTPConfig, log *slog.Logger, rs tokens.RevocationStore, is idempotency.Store, idempotencyTTL time.Duration) Server {
 	return Server{
-		addr:   addr,
-		router: gin.Default(),
+		cfg:            cfg,
+		log:            log,
+		router:         gin.New(),
+		rs:             rs,
+		is:             is,
+		idempotencyTTL: idempotencyTTL,
 	}
 }
 
-func (s *Server) Run(ctx context.Context, uc authHandler.AuthUsecase, pu paymentHandler.PaymentUsecase, tu transportHandler.TransportUsecase, ru rentHandler.RentUsecase) {
+// ServeMetrics measures every request and serves metrics on path, it must be called before Run
+func (s *Server) ServeMetrics(path string, m Metrics) {
+	s.metrics = m
+	s.metricsPath = path
+}
+
+// ServeReadiness serves /readyz with checks of r, it must be called before Run.
+// On shutdown r is drained and requests are served for cfg.DrainDelay more.
+func (s *Server) ServeReadiness(r Readiness) {
+	s.readiness = r
+}
+
+// Trace starts span of every request continuing traces of clients propagated by p,
+// it must be called before Run
+func (s *Server) Trace(tp trace.TracerProvider, p propagation.TextMapPropagator) {
+	s.tp = tp
+	s.propagator = p
+}
+
+// SimulatePayments serves /api/Payment/TopUp/{id}/Simulate for the fake gateway,
+// it is for development and tests only and must be called before Run
+func (s *Server) SimulatePayments() {
+	s.simulatePayments = true
+}
+
+// LimitRates rejects requests over the limits with 429, it must be called before Run
+func (s *Server) LimitRates(l RateLimits) {
+	s.limits = l
+}
+
+func (s *Server) Run(ctx context.Context, uc authHandler.AuthUsecase, pu paymentHandler.PaymentUsecase, tu transportHandler.TransportUsecase, ru rentHandler.RentUsecase, rlu roleHandler.RoleUsecase, pru pricingHandler.PricingUsecase, pmu promoHandler.PromoUsecase) {
+	// client ip is taken from X-Forwarded-For of trusted proxies only, so clients can not change it
+	if err := s.router.SetTrustedProxies(s.cfg.Proxies()); err != nil {
+		s.log.Error("failed to set trusted proxies", slog.Any("error", err))
+		return
+	}
+
+	// metrics and logs go before HandleErrors to see status of error responses
+	s.router.Use(middleware.RequestId())
+
+	//probes are registered before metrics, traces and logs, so they are not measured and logged
+	hh := healthHandler.New(s.readiness)
+	s.router.GET("/healthz", hh.Liveness)
+	if s.readiness != nil {
+		s.router.GET("/readyz", hh.Readiness)
+	}
+
+	if s.metrics != nil {
+		s.router.Use(middleware.Metrics(s.metrics))
+		// registered before Logger, so scrapes are not written to the log
+		s.router.GET(s.metricsPath, gin.WrapH(s.metrics.Handler()))
+	}
+	if s.tp != nil {
+		// goes before Logger, so access logs have id of the trace
+		s.router.Use(middleware.Tracing(s.tp, s.propagator))
+	}
+	s.router.Use(middleware.Logger(s.log), middleware.Recovery(), middleware.HandleErrors())
+	s.router.Use(limit(s.limits.IP, middleware.RateLimitByIP))
+	userLimit := limit(s.limits.User, middleware.RateLimitByUser)
+	authLimit := limit(s.limits.Auth, middleware.RateLimitByIP)
+
 	//swagger route
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	//mutating requests of authenticated users can be retried with Idempotency-Key
+	idempotent := middleware.Idempotency(s.is, s.idempotencyTTL)
+
 	//auth routes
 	ah := authHandler.New(uc)
 
 	//user auth routes
-	authRouts := s.router.Group("/", middleware.CheckAuthification())
+	authRouts := s.router.Group("/", middleware.CheckAuthification(s.rs), userLimit, idempotent)
 	authRouts.GET("/api/Account/Me", ah.UserMyAccount)
-	s.router.POST("/api/Account/SignIn", ah.UserSignIn)
-	s.router.POST("/api/Account/SignUp", ah.UserSignUp)
+	s.router.POST("/api/Account/SignIn", authLimit, ah.UserSignIn)
+	s.router.POST("/api/Account/SignUp", authLimit, ah.UserSignUp)
+	s.router.POST("/api/Account/Refresh", authLimit, ah.UserRefresh)
 	authRouts.POST("/api/Account/SignOut", ah.UserSignOut)
 	authRouts.PUT("/api/Account/Update", ah.UserUpdate)
+	s.router.GET("/.well-known/jwks.json", ah.JWKS)
 
 	//admin auth routes
-	adminAuthRouts := s.router.Group("/api/Admin/Account", middleware.CheckAuthification(),
-		middleware.CheckAdminStatus())
-	adminAuthRouts.GET("/", ah.AdminGetUsers)
-	adminAuthRouts.GET("/:id", ah.AdminGetUser)
-	adminAuthRouts.POST("/", ah.AdminCreateUser)
-	adminAuthRouts.PUT("/:id", ah.AdminUpdateUser)
-	adminAuthRouts.DELETE("/:id", ah.AdminDeleteUser)
+	usersRead := middleware.RequirePermission(rlu, entities.PermissionUsersRead)
+	usersManage := middleware.RequirePermission(rlu, entities.PermissionUsersManage)
+	adminAuthRouts := s.router.Group("/api/Admin/Account", middleware.CheckAuthification(s.rs), userLimit, idempotent)
+	adminAuthRouts.GET("/", usersRead, ah.AdminGetUsers)
+	adminAuthRouts.GET("/:id", usersRead, ah.AdminGetUser)
+	// isAdmin and balance of the account are changed only with permissions for them
+	rolesLoad := middleware.LoadPermission(rlu, entities.PermissionRolesManage)
+	balancesLoad := middleware.LoadPermission(rlu, entities.PermissionBalancesAdjust)
+	adminAuthRouts.POST("/", usersManage, rolesLoad, balancesLoad, ah.AdminCreateUser)
+	adminAuthRouts.PUT("/:id", usersManage, rolesLoad, balancesLoad, ah.AdminUpdateUser)
+	adminAuthRouts.DELETE("/:id", usersManage, ah.AdminDeleteUser)
+	adminAuthRouts.POST("/:id/RevokeSessions", usersManage, ah.AdminRevokeSessions)
+	adminAuthRouts.POST("/:id/Unlock", usersManage, ah.AdminUnlockUser)
+
+	//admin role routes
+	rlh := roleHandler.New(rlu)
+	roleAdminRoutes := s.router.Group("/api/Admin/Roles", middleware.CheckAuthification(s.rs), userLimit, idempotent,
+		middleware.RequirePermission(rlu, entities.PermissionRolesManage))
+	roleAdminRoutes.GET("/", rlh.GetRoles)
+	roleAdminRoutes.GET("/Permissions", rlh.GetPermissions)
+	roleAdminRoutes.GET("/:id", rlh.GetRole)
+	roleAdminRoutes.POST("/", rlh.CreateRole)
+	roleAdminRoutes.PUT("/:id", rlh.UpdateRole)
+	roleAdminRoutes.DELETE("/:id", rlh.DeleteRole)
+	roleAdminRoutes.GET("/User/:id", rlh.GetUserRoles)
+	roleAdminRoutes.POST("/User/:id", rlh.AssignRole)
+	roleAdminRoutes.DELETE("/User/:id/:roleId", rlh.UnassignRole)
 
 	//payment rout
 	ph := paymentHandler.New(pu)
-	s.router.POST("/api/Payment/Hesoyam/:id", middleware.CheckAuthification(), ph.IncreaseBalance)
+	s.router.POST("/api/Payment/Webhook", ph.Webhook)
+	paymentRoutes := s.router.Group("/api/Payment", middleware.CheckAuthification(s.rs), userLimit, idempotent)
+	paymentRoutes.GET("/Transactions", ph.GetTransactions)
+	paymentRoutes.POST("/TopUp", ph.CreateTopUp)
+	paymentRoutes.GET("/TopUp/:id", ph.GetTopUp)
+	if s.simulatePayments {
+		paymentRoutes.POST("/TopUp/:id/Simulate", ph.SimulateTopUp)
+	}
+
+	//admin payment routes
+	paymentAdminRoutes := s.router.Group("/api/Admin/Payment", middleware.CheckAuthification(s.rs), userLimit, idempotent,
+		middleware.RequirePermission(rlu, entities.PermissionBalancesAdjust))
+	paymentAdminRoutes.POST("/Payout/:id", ph.Payout)
+	paymentAdminRoutes.GET("/Reconciliation", ph.Reconcile)
+	paymentAdminRoutes.GET("/Debtors", ph.GetDebtors)
 
 	//transport routes
 	th := transportHandler.New(tu)
@@ -70,14 +217,14 @@ func (s *Server) Run(ctx context.Context, uc authHandler.AuthUsecase, pu payment
 	//user transport routes
 	s.router.GET("/api/Transport/:id", th.UserGetTransport)
 	transportAuthRoutes := s.router.Group("/api/Transport",
-		middleware.CheckAuthification())
+		middleware.CheckAuthification(s.rs), userLimit, idempotent)
 	transportAuthRoutes.POST("/", th.UserCreateTransport)
 	transportAuthRoutes.PUT("/:id", th.UserUpdateTransport)
 	transportAuthRoutes.DELETE("/:id", th.UserDeleteTransport)
 
 	//admin transport routes
 	transportAdminRoutes := s.router.Group("/api/Admin/Transport",
-		middleware.CheckAuthification(), middleware.CheckAdminStatus())
+		middleware.CheckAuthification(s.rs), userLimit, idempotent, middleware.RequirePermission(rlu, entities.PermissionTransportsManage))
 	transportAdminRoutes.GET("/", th.AdminGetTransports)
 	transportAdminRoutes.GET("/:id", th.AdminGetTransport)
 	transportAdminRoutes.POST("/", th.AdminCreateTransport)
@@ -89,43 +236,92 @@ func (s *Server) Run(ctx context.Context, uc authHandler.AuthUsecase, pu payment
 
 	//user rent routes
 	s.router.GET("/api/Rent/Transport", rh.GetAvalibleTransport)
-	rentRouts := s.router.Group("/api/Rent", middleware.CheckAuthification())
+	rentRouts := s.router.Group("/api/Rent", middleware.CheckAuthification(s.rs), userLimit, idempotent)
 	rentRouts.GET("/:id", rh.UserGetRent)
 	rentRouts.GET("/MyHistory", rh.UserGetHistory)
 	rentRouts.GET("/TransportHistory/:id", rh.UserGetTransportHistory)
 	rentRouts.POST("/New/:id", rh.UserCreateNewRent)
 	rentRouts.POST("/End/:id", rh.UserEndRent)
 
+	//reservation routes
+	s.router.GET("/api/Rent/Reservations/Transport/:id", rh.GetTransportCalendar)
+	reservationRoutes := s.router.Group("/api/Rent/Reservations", middleware.CheckAuthification(s.rs), userLimit, idempotent)
+	reservationRoutes.GET("", rh.UserGetReservations)
+	reservationRoutes.POST("", rh.UserCreateReservation)
+	reservationRoutes.GET("/:id", rh.UserGetReservation)
+	reservationRoutes.DELETE("/:id", rh.UserCancelReservation)
+	reservationRoutes.POST("/:id/Start", rh.UserStartReservation)
+
 	//admin rent routes
-	rentsAdminRoutes := s.router.Group("/api/Admin", middleware.CheckAuthification(),
-		middleware.CheckAdminStatus())
-	rentsAdminRoutes.GET("/Rent/:id", rh.AdminGetRent)
-	rentsAdminRoutes.POST("/Rent", rh.AdminCreateRent)
-	rentsAdminRoutes.POST("/Rent/End/:id", rh.AdminEndRent)
-	rentsAdminRoutes.GET("/UserHistory/:id", rh.AdminGetUserHistory)
-	rentsAdminRoutes.GET("/TransportHistory/:id", rh.AdminGetTransportHistory)
-	rentsAdminRoutes.PUT("/Rent/:id", rh.AdminUpdateRent)
-	rentsAdminRoutes.DELETE("/Rent/:id", rh.AdminDeleteRent)
+	rentsRead := middleware.RequirePermission(rlu, entities.PermissionRentsRead)
+	rentsManage := middleware.RequirePermission(rlu, entities.PermissionRentsManage)
+	rentsAdminRoutes := s.router.Group("/api/Admin", middleware.CheckAuthification(s.rs), userLimit, idempotent)
+	rentsAdminRoutes.GET("/Rent/:id", rentsRead, rh.AdminGetRent)
+	rentsAdminRoutes.POST("/Rent", rentsManage, rh.AdminCreateRent)
+	rentsAdminRoutes.POST("/Rent/End/:id", middleware.RequirePermission(rlu, entities.PermissionRentsEnd), rh.AdminEndRent)
+	rentsAdminRoutes.GET("/UserHistory/:id", rentsRead, rh.AdminGetUserHistory)
+	rentsAdminRoutes.GET("/TransportHistory/:id", rentsRead, rh.AdminGetTransportHistory)
+	rentsAdminRoutes.PUT("/Rent/:id", rentsManage, rh.AdminUpdateRent)
+	rentsAdminRoutes.DELETE("/Rent/:id", rentsManage, rh.AdminDeleteRent)
+	rentsAdminRoutes.POST("/Rent/Refund/:id", rentsManage, rh.AdminRefundRent)
+
+	//admin pricing routes
+	prh := pricingHandler.New(pru)
+	pricingAdminRoutes := s.router.Group("/api/Admin/Pricing", middleware.CheckAuthification(s.rs), userLimit, idempotent,
+		middleware.RequirePermission(rlu, entities.PermissionPricingManage))
+	pricingAdminRoutes.GET("", prh.GetPolicies)
+	pricingAdminRoutes.GET("/:id", prh.GetPolicy)
+	pricingAdminRoutes.POST("", prh.CreatePolicy)
+	pricingAdminRoutes.PUT("/:id", prh.UpdatePolicy)
+	pricingAdminRoutes.DELETE("/:id", prh.DeletePolicy)
+
+	//admin promo code routes
+	pmh := promoHandler.New(pmu)
+	promoAdminRoutes := s.router.Group("/api/Admin/PromoCodes", middleware.CheckAuthification(s.rs), userLimit, idempotent,
+		middleware.RequirePermission(rlu, entities.PermissionPromoManage))
+	promoAdminRoutes.GET("", pmh.GetPromoCodes)
+	promoAdminRoutes.GET("/:id", pmh.GetPromoCode)
+	promoAdminRoutes.POST("", pmh.CreatePromoCode)
+	promoAdminRoutes.PUT("/:id", pmh.UpdatePromoCode)
+	promoAdminRoutes.DELETE("/:id", pmh.DeletePromoCode)
 
 	srv := http.Server{
-		Addr:    s.addr,
-		Handler: s.router,
+		Addr:         s.cfg.Addr,
+		Handler:      s.router,
+		ReadTimeout:  s.cfg.ReadTimeout,
+		WriteTimeout: s.cfg.WriteTimeout,
+		IdleTimeout:  s.cfg.IdleTimeout,
 	}
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Println("failed to listen server")
+		s.log.Info("server is listening", slog.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("failed to listen server", slog.Any("error", err))
 		}
 	}()
 
 	//gracefull shutdown
 	<-ctx.Done()
-	log.Println("closing server gracefully...")
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	if s.readiness != nil {
+		s.readiness.Drain()
+		s.log.Info("draining server", slog.Duration("delay", s.cfg.DrainDelay))
+		time.Sleep(s.cfg.DrainDelay)
+	}
+	s.log.Info("closing server gracefully...")
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctxTimeout); err != nil {
-		log.Println("failed to shutdown server gracefully")
+		s.log.Error("failed to shutdown server gracefully", slog.Any("error", err))
+		return
+	}
+	s.log.Info("server closed gracefully")
+}
+
+// limit returns middleware limiting requests with l, or the one passing all requests if l is not set
+func limit(l middleware.RateLimiter, by func(l middleware.RateLimiter) gin.HandlerFunc) gin.HandlerFunc {
+	if l == nil {
+		return func(ctx *gin.Context) {}
 	}
-	log.Println("server closed gracefully")
+	return by(l)
 }
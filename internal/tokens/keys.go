@@ -0,0 +1,201 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"simbirGo/internal/entities"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const hmacKeyId = "hs256"
+
+type key struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type keySet struct {
+	signing *key
+	keys    map[string]*key
+}
+
+var keys keySet
+
+// InitKeys loads signing and verification keys.
+// Every *.pem file in keysDir is a key with kid equal to the file name without extension.
+// Files with private keys (RSA or Ed25519) can be used for signing, files with public keys
+// only verify tokens, so retired keys can stay in the directory until their tokens die out.
+// Non-empty secret adds HS256 key with kid "hs256".
+// Tokens are signed with signingKid key, which can be omitted if there is only one private key.
+// At least one key must be configured, see InitDevKey for development.
+func InitKeys(keysDir, signingKid, secret string) error {
+	op := "tokens.InitKeys()"
+	set := keySet{keys: make(map[string]*key)}
+
+	if keysDir != "" {
+		files, err := filepath.Glob(filepath.Join(keysDir, "*.pem"))
+		if err != nil {
+			return fmt.Errorf("%s: failed to list keys dir: %w", op, err)
+		}
+		for _, file := range files {
+			k, err := loadKey(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			set.keys[k.id] = k
+		}
+	}
+
+	if secret != "" {
+		set.keys[hmacKeyId] = &key{
+			id:        hmacKeyId,
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+		}
+	}
+
+	switch {
+	case signingKid != "":
+		k, ok := set.keys[signingKid]
+		if !ok {
+			return fmt.Errorf("%s: signing key %q is not found", op, signingKid)
+		}
+		if k.signKey == nil {
+			return fmt.Errorf("%s: signing key %q has no private part", op, signingKid)
+		}
+		set.signing = k
+	case len(set.keys) == 0:
+		return fmt.Errorf("%s: no jwt keys configured", op)
+	default:
+		for _, k := range set.keys {
+			if k.signKey == nil {
+				continue
+			}
+			if set.signing != nil {
+				return fmt.Errorf("%s: several private keys found, signing key id must be set", op)
+			}
+			set.signing = k
+		}
+		if set.signing == nil {
+			return fmt.Errorf("%s: no private key for signing", op)
+		}
+	}
+
+	keys = set
+	return nil
+}
+
+// InitDevKey signs tokens with a random key generated on start. Tokens are invalid after restart
+// and on other instances, so it is used only in development.
+func InitDevKey() error {
+	op := "tokens.InitDevKey()"
+	k, err := ephemeralKey()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	slog.Warn("using ephemeral jwt key: tokens will be invalid after restart", slog.String("kid", k.id))
+	keys = keySet{signing: k, keys: map[string]*key{k.id: k}}
+	return nil
+}
+
+// JWKS returns public keys, which can be used to verify tokens.
+// HMAC secret is never published.
+func JWKS() entities.JSONWebKeySet {
+	ids := make([]string, 0, len(keys.keys))
+	for id := range keys.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := entities.JSONWebKeySet{Keys: make([]entities.JSONWebKey, 0, len(ids))}
+	for _, id := range ids {
+		k := keys.keys[id]
+		jwk := entities.JSONWebKey{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func loadKey(file string) (*key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", file, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode pem in %s", file)
+	}
+
+	k := &key{id: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block %q in %s", block.Type, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", file, err)
+	}
+
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.signKey, k.verifyKey = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.verifyKey = jwt.SigningMethodRS256, v
+	case ed25519.PrivateKey:
+		k.method, k.signKey, k.verifyKey = jwt.SigningMethodEdDSA, v, v.Public()
+	case ed25519.PublicKey:
+		k.method, k.verifyKey = jwt.SigningMethodEdDSA, v
+	default:
+		return nil, fmt.Errorf("unsupported key type %T in %s", parsed, file)
+	}
+	return k, nil
+}
+
+func ephemeralKey() (*key, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+	return &key{
+		id:        "ephemeral-" + hex.EncodeToString(pub[:4]),
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   priv,
+		verifyKey: pub,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package tokens
+
+import (
+	"simbirGo/internal/entities"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps revoked access tokens until they expire on their own.
+type RevocationStore interface {
+	// Revoke revokes single token with jti until expiresAt
+	Revoke(jti string, expiresAt time.Time) error
+	// RevokeUser revokes all tokens of the user issued not later than revokedAt,
+	// the record is kept until expiresAt, when all such tokens are expired.
+	RevokeUser(userId uint, revokedAt, expiresAt time.Time) error
+	IsRevoked(token entities.Token) (bool, error)
+}
+
+type revokedUser struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	tokens    map[string]time.Time
+	users     map[uint]revokedUser
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uint]revokedUser),
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryRevocationStore) Revoke(jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) RevokeUser(userId uint, revokedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	s.users[userId] = revokedUser{revokedAt: revokedAt, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(token entities.Token) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	if expiresAt, ok := s.tokens[token.Jti]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if user, ok := s.users[token.Id]; ok && now.Before(user.expiresAt) &&
+		!token.IssuedAt.After(user.revokedAt) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// sweep removes expired records, it runs at most once a minute
+func (s *MemoryRevocationStore) sweep() {
+	now := s.now()
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for jti, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for id, user := range s.users {
+		if !now.Before(user.expiresAt) {
+			delete(s.users, id)
+		}
+	}
+}
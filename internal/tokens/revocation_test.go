@@ -0,0 +1,61 @@
+package tokens
+
+import (
+	"simbirGo/internal/entities"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRevocationStore()
+	store.now = func() time.Time { return now }
+
+	token := entities.Token{Id: 1, Jti: "jti", IssuedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Minute)}
+	other := entities.Token{Id: 2, Jti: "other", IssuedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Minute)}
+
+	assert.NoError(t, store.Revoke(token.Jti, token.ExpiresAt))
+	revoked, _ := store.IsRevoked(token)
+	assert.True(t, revoked)
+	revoked, _ = store.IsRevoked(other)
+	assert.False(t, revoked)
+
+	// user revocation affects only tokens issued before it
+	assert.NoError(t, store.RevokeUser(2, now, now.Add(time.Minute)))
+	revoked, _ = store.IsRevoked(other)
+	assert.True(t, revoked)
+	fresh := entities.Token{Id: 2, Jti: "fresh", IssuedAt: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)}
+	revoked, _ = store.IsRevoked(fresh)
+	assert.False(t, revoked)
+
+	// records are removed after expiration
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, store.Revoke("new", now.Add(time.Minute)))
+	assert.Len(t, store.tokens, 1)
+	assert.Empty(t, store.users)
+	revoked, _ = store.IsRevoked(token)
+	assert.False(t, revoked)
+}
+
+func TestMemoryRevocationStore_Concurrent(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	expiresAt := time.Now().Add(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_ = store.Revoke(string(rune('a'+i)), expiresAt)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = store.IsRevoked(entities.Token{Id: uint(i), Jti: string(rune('a' + i))})
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, store.tokens, 50)
+}
@@ -1,62 +1,132 @@
 package tokens
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"simbirGo/internal/entities"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateNewJwt(user entities.User) (string, error) {
+const issuer = "simbirGo"
+
+var (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrTokenExpired = errors.New("token is expired")
+	ErrTokenInvalid = errors.New("token is invalid")
+)
+
+// GenerateNewJwt creates short-lived access token.
+// sessionId is id of the refresh token family the access token was issued with.
+func GenerateNewJwt(user entities.User, sessionId string) (string, time.Time, error) {
 	op := "usecase.token.GenerateNewJwt()"
-	key := []byte("boba")
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
+	if keys.signing == nil {
+		return "", time.Time{}, fmt.Errorf("%s: signing key is not initialized", op)
+	}
+	jti, err := NewId()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+	token := jwt.NewWithClaims(keys.signing.method,
 		jwt.MapClaims{
 			"id":      user.Id,
 			"isAdmin": user.IsAdmin,
+			"sid":     sessionId,
+			"jti":     jti,
+			"iss":     issuer,
+			"iat":     now.Unix(),
+			"exp":     expiresAt.Unix(),
 		})
+	token.Header["kid"] = keys.signing.id
 
-	strToken, err := token.SignedString(key)
+	strToken, err := token.SignedString(keys.signing.signKey)
 	if err != nil {
-		return "", fmt.Errorf("%s: failed to sign jwt: %w", op, err)
+		return "", time.Time{}, fmt.Errorf("%s: failed to sign jwt: %w", op, err)
 	}
-	return strToken, nil
+	return strToken, expiresAt, nil
 }
 
 func ParseToken(tokenString string) (entities.Token, error) {
 	op := "tokens.ParseToken()"
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown signing key: %q", op, kid)
+		}
+		if t.Method.Alg() != k.method.Alg() {
 			return nil, fmt.Errorf("%s: unexpected signing method: %v", op, t.Header["alg"])
 		}
-		return []byte("boba"), nil
-	})
+		return k.verifyKey, nil
+	}, jwt.WithIssuer(issuer), jwt.WithIssuedAt(), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return entities.Token{}, ErrTokenExpired
+	}
+	if err != nil {
+		return entities.Token{}, fmt.Errorf("%w: %s", ErrTokenInvalid, err.Error())
+	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		return entities.Token{
-			Id:      uint(claims["id"].(float64)),
-			IsAdmin: claims["isAdmin"].(bool),
-		}, nil
+	if !ok || !token.Valid {
+		return entities.Token{}, ErrTokenInvalid
+	}
+	// exp is required by the parser
+	exp, _ := claims.GetExpirationTime()
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return entities.Token{}, fmt.Errorf("%w: token has no issue time", ErrTokenInvalid)
+	}
+	id, _ := claims["id"].(float64)
+	isAdmin, _ := claims["isAdmin"].(bool)
+	sid, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return entities.Token{}, fmt.Errorf("%w: token has no jti", ErrTokenInvalid)
 	}
 
-	return entities.Token{}, fmt.Errorf("%s: failed to parse token: %w", op, err)
+	return entities.Token{
+		Id:        uint(id),
+		IsAdmin:   isAdmin,
+		SessionId: sid,
+		Jti:       jti,
+		IssuedAt:  iat.Time,
+		ExpiresAt: exp.Time,
+	}, nil
 }
 
-// black list
-var blackList map[string]bool
-
-func InitBlackList() {
-	blackList = make(map[string]bool)
+// GenerateRefreshToken returns opaque refresh token and its hash.
+// Only the hash is supposed to be stored.
+func GenerateRefreshToken() (string, string, error) {
+	op := "tokens.GenerateRefreshToken()"
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("%s: failed to generate refresh token: %w", op, err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashRefreshToken(token), nil
 }
 
-func RemoveToken(token string) {
-	blackList[token] = true
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func IsInBlackList(token string) bool {
-	if val, ok := blackList[token]; val && ok {
-		return true
+// NewId returns random identifier for jti and refresh token families.
+func NewId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
 	}
-	return false
+	return hex.EncodeToString(buf), nil
 }
@@ -0,0 +1,132 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"simbirGo/internal/entities"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, dir, kid string, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600))
+}
+
+func TestTokens_KeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writeKey(t, dir, "rsa-old", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	writeKey(t, dir, "ed-new", "PRIVATE KEY", der)
+
+	user := entities.User{Id: 7, IsAdmin: true}
+
+	require.NoError(t, InitKeys(dir, "rsa-old", ""))
+	oldToken, _, err := GenerateNewJwt(user, "session")
+	require.NoError(t, err)
+
+	// rotate: new key signs, old one only verifies
+	pubDer, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	writeKey(t, dir, "rsa-old", "PUBLIC KEY", pubDer)
+	require.NoError(t, InitKeys(dir, "ed-new", ""))
+
+	newToken, _, err := GenerateNewJwt(user, "session")
+	require.NoError(t, err)
+
+	for _, token := range []string{oldToken, newToken} {
+		data, err := ParseToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, uint(7), data.Id)
+		assert.True(t, data.IsAdmin)
+		assert.Equal(t, "session", data.SessionId)
+	}
+
+	jwks := JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "ed-new", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+	assert.Equal(t, "rsa-old", jwks.Keys[1].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[1].Alg)
+
+	// key removed from the set is not trusted anymore
+	require.NoError(t, os.Remove(filepath.Join(dir, "rsa-old.pem")))
+	require.NoError(t, InitKeys(dir, "", ""))
+	_, err = ParseToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestTokens_InitKeys(t *testing.T) {
+	t.Run("Secret is not published", func(t *testing.T) {
+		require.NoError(t, InitKeys("", "", "secret"))
+		token, _, err := GenerateNewJwt(entities.User{Id: 1}, "")
+		require.NoError(t, err)
+		_, err = ParseToken(token)
+		assert.NoError(t, err)
+		assert.Empty(t, JWKS().Keys)
+	})
+
+	t.Run("Unknown signing key", func(t *testing.T) {
+		assert.Error(t, InitKeys("", "missing", "secret"))
+	})
+
+	t.Run("No keys", func(t *testing.T) {
+		assert.Error(t, InitKeys("", "", ""))
+	})
+
+	t.Run("Dev key", func(t *testing.T) {
+		require.NoError(t, InitDevKey())
+		assert.Len(t, JWKS().Keys, 1)
+	})
+}
+
+func TestTokens_Expiration(t *testing.T) {
+	require.NoError(t, InitKeys("", "", "secret"))
+	defer func(ttl time.Duration) { AccessTokenTTL = ttl }(AccessTokenTTL)
+
+	AccessTokenTTL = -time.Minute
+	token, _, err := GenerateNewJwt(entities.User{Id: 1}, "")
+	require.NoError(t, err)
+
+	_, err = ParseToken(token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = ParseToken("not a token")
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+}
+
+func TestTokens_ExpirationRequired(t *testing.T) {
+	require.NoError(t, InitKeys("", "", "secret"))
+
+	token := jwt.NewWithClaims(keys.signing.method, jwt.MapClaims{
+		"id":  1,
+		"jti": "jti",
+		"iss": issuer,
+		"iat": time.Now().Unix(),
+	})
+	token.Header["kid"] = keys.signing.id
+	strToken, err := token.SignedString(keys.signing.signKey)
+	require.NoError(t, err)
+
+	_, err = ParseToken(strToken)
+	assert.ErrorIs(t, err, ErrTokenInvalid)
+}
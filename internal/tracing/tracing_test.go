@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"simbirGo/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		provider bool
+		wantErr  bool
+	}{
+		{name: "none", exporter: "none"},
+		{name: "stdout", exporter: "stdout", provider: true},
+		{name: "otlp", exporter: "otlp", provider: true},
+		{name: "unknown", exporter: "jaeger", wantErr: true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg := config.Default().Tracing
+			cfg.Exporter = testCase.exporter
+
+			tp, err := Setup(context.Background(), cfg)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if !testCase.provider {
+				assert.Nil(t, tp)
+				return
+			}
+			require.NotNil(t, tp)
+			assert.NoError(t, tp.Shutdown(context.Background()))
+		})
+	}
+}
@@ -1,140 +1,491 @@
 package authUsecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"simbirGo/internal/database/models"
 	"simbirGo/internal/dto"
 	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/passwords"
 	"simbirGo/internal/tokens"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("simbirGo/internal/usecase/authUsecase")
+
 //go:generate mockgen -source=authUsecase.go -destination=mock/mock.go
 
 type AuthRepository interface {
-	FindUserByUsername(username string) models.User
-	FindUserById(id uint) models.User
-	CreateUser(user models.User) models.User
-	SaveUser(user models.User)
-	GetUsers(start uint, count int) []models.User
-	DeleteUser(id uint)
+	FindUserByUsername(ctx context.Context, username string) (models.User, error)
+	FindUserById(ctx context.Context, id uint) (models.User, error)
+	FindUserForUpdate(ctx context.Context, id uint) (models.User, error)
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	SaveUser(ctx context.Context, user models.User) error
+	RecordFailedSignIn(ctx context.Context, userId uint) (int, error)
+	LockUser(ctx context.Context, userId uint, until time.Time) error
+	ResetFailedSignIns(ctx context.Context, userId uint) error
+	GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]models.User, pagination.Meta, error)
+	DeleteUser(ctx context.Context, id uint) error
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error)
+	FindRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error)
+	RevokeRefreshFamily(ctx context.Context, familyId string) error
+	RevokeUserRefreshTokens(ctx context.Context, userId uint) error
+	FindRoleByName(ctx context.Context, name string) (models.Role, error)
+	AssignRole(ctx context.Context, userRole models.UserRole) error
+	UnassignRole(ctx context.Context, userId, roleId uint) error
+	FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error)
+	FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error)
+	CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error)
+}
+
+// Transactor runs fn in a database transaction,
+// repository passed to fn is bound to the transaction
+type Transactor func(ctx context.Context, fn func(r AuthRepository) error) error
+
+// Metrics counts security events of authentication
+type Metrics interface {
+	SignInFailed(reason string)
 }
 
 type AuthUsecase struct {
-	r AuthRepository
+	r   AuthRepository
+	tx  Transactor
+	rs  tokens.RevocationStore
+	log *slog.Logger
+	m   Metrics
 }
 
-func New(r AuthRepository) AuthUsecase {
-	return AuthUsecase{r: r}
+func New(r AuthRepository, tx Transactor, rs tokens.RevocationStore, log *slog.Logger, m Metrics) AuthUsecase {
+	return AuthUsecase{r: r, tx: tx, rs: rs, log: log, m: m}
 }
 
-func (au AuthUsecase) MyAccount(id uint) (entities.User, error) {
-	user := au.r.FindUserById(id)
-	if user.Id == 0 {
-		return entities.User{}, fmt.Errorf("user is not exist")
+func (au AuthUsecase) MyAccount(ctx context.Context, id uint) (entities.User, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.MyAccount")
+	defer span.End()
+	op := "authUsecase.MyAccount()"
+	user, err := au.r.FindUserById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.User{}, entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return entities.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 	return dto.UserModelToEntitie(user), nil
 }
 
-func (au AuthUsecase) SignIn(user entities.User) (string, error) {
-	userModel := au.r.FindUserByUsername(user.Username)
-	if userModel.Id == 0 {
-		return "", fmt.Errorf("username is not exist")
+func (au AuthUsecase) SignIn(ctx context.Context, user entities.User) (entities.TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.SignIn")
+	defer span.End()
+	op := "authUsecase.SignIn()"
+	userModel, err := au.r.FindUserByUsername(ctx, user.Username)
+	if errors.Is(err, entities.ErrNotFound) {
+		au.m.SignInFailed("unknown_user")
+		passwords.CompareDummy(user.Password)
+		return entities.TokenPair{}, newInvalidCredentialsError()
+	}
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if user.Password != userModel.Password {
-		return "", fmt.Errorf("invalid password")
+	if userModel.LockedUntil != nil {
+		if wait := time.Until(*userModel.LockedUntil); wait > 0 {
+			au.m.SignInFailed("locked")
+			return entities.TokenPair{}, newLockedError(wait)
+		}
 	}
 
-	userEntite := dto.UserModelToEntitie(userModel)
-	token, err := tokens.GenerateNewJwt(userEntite)
-	if err != nil {
-		return "", err
+	ok, needRehash := passwords.Compare(userModel.Password, user.Password)
+	if !ok {
+		au.m.SignInFailed("invalid_password")
+		au.log.WarnContext(ctx, "sign in with invalid password", slog.Uint64("user_id", uint64(userModel.Id)))
+		return entities.TokenPair{}, au.recordFailedSignIn(ctx, userModel.Id)
+	}
+	if userModel.FailedSignIns > 0 {
+		if err := au.r.ResetFailedSignIns(ctx, userModel.Id); err != nil {
+			return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if needRehash {
+		hash, err := passwords.Hash(user.Password)
+		if err != nil {
+			return entities.TokenPair{}, err
+		}
+		userModel.Password = hash
+		if err := au.r.SaveUser(ctx, userModel); err != nil {
+			return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
 	}
-	return token, nil
+
+	userEntite := dto.UserModelToEntitie(userModel)
+	return au.newSession(ctx, userEntite)
 }
 
-func (au AuthUsecase) SignUp(user entities.User) (entities.User, string, error) {
-	candidate := au.r.FindUserByUsername(user.Username)
-	if candidate.Id != 0 {
-		return entities.User{}, "", fmt.Errorf("user is already exist")
+func (au AuthUsecase) SignUp(ctx context.Context, user entities.User) (entities.User, entities.TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.SignUp")
+	defer span.End()
+	op := "authUsecase.SignUp()"
+	if err := au.checkUsername(ctx, user.Username, 0); err != nil {
+		return entities.User{}, entities.TokenPair{}, err
 	}
 
+	hash, err := passwords.Hash(user.Password)
+	if err != nil {
+		return entities.User{}, entities.TokenPair{}, err
+	}
+	user.Password = hash
+	// only admins grant admin rights, self-registered accounts never get them
+	user.IsAdmin = false
+
 	userModel := dto.UserEntitieToModels(user)
-	userModel = au.r.CreateUser(userModel)
+	userModel, err = au.r.CreateUser(ctx, userModel)
+	if err != nil {
+		return entities.User{}, entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
 	userEntite := dto.UserModelToEntitie(userModel)
-	token, err := tokens.GenerateNewJwt(userEntite)
+	tokenPair, err := au.newSession(ctx, userEntite)
+	if err != nil {
+		return entities.User{}, entities.TokenPair{}, err
+	}
+	au.log.InfoContext(ctx, "user signed up", slog.Uint64("user_id", uint64(userModel.Id)))
+	return userEntite, tokenPair, nil
+}
+
+// Refresh exchanges refresh token for a new token pair. Every refresh token can be used once,
+// presenting already used token means it was stolen, so the whole family is revoked.
+func (au AuthUsecase) Refresh(ctx context.Context, refreshToken string) (entities.TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.Refresh")
+	defer span.End()
+	op := "authUsecase.Refresh()"
+	token, err := au.r.FindRefreshToken(ctx, tokens.HashRefreshToken(refreshToken))
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.TokenPair{}, entities.NewUnauthorizedError(entities.CodeRefreshTokenInvalid, "invalid refresh token")
+	}
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if token.RevokedAt != nil {
+		return entities.TokenPair{}, entities.NewUnauthorizedError(entities.CodeRefreshTokenInvalid, "refresh token is revoked")
+	}
+	marked := false
+	if token.UsedAt == nil {
+		marked, err = au.r.MarkRefreshTokenUsed(ctx, token.Id)
+		if err != nil {
+			return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if !marked {
+		if err := au.r.RevokeRefreshFamily(ctx, token.FamilyId); err != nil {
+			return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
+		au.log.WarnContext(ctx, "refresh token reuse detected, session is revoked", slog.Uint64("user_id", uint64(token.UserId)))
+		return entities.TokenPair{}, entities.NewUnauthorizedError(entities.CodeRefreshTokenReused, "refresh token reuse detected, session is revoked")
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return entities.TokenPair{}, entities.NewUnauthorizedError(entities.CodeRefreshTokenInvalid, "refresh token is expired")
+	}
+
+	user, err := au.r.FindUserById(ctx, token.UserId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.TokenPair{}, entities.NewUnauthorizedError(entities.CodeRefreshTokenInvalid, "user is not exist")
+	}
 	if err != nil {
-		return entities.User{}, "", err
+		return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return au.issueTokens(ctx, dto.UserModelToEntitie(user), token.FamilyId)
+}
+
+func (au AuthUsecase) SignOut(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "authUsecase.SignOut")
+	defer span.End()
+	op := "authUsecase.SignOut()"
+	tokenData, err := tokens.ParseToken(token)
+	if err != nil {
+		return err
+	}
+	if err := au.rs.Revoke(tokenData.Jti, tokenData.ExpiresAt); err != nil {
+		return fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+	if tokenData.SessionId != "" {
+		if err := au.r.RevokeRefreshFamily(ctx, tokenData.SessionId); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 	}
-	return userEntite, token, nil
+	return nil
 }
 
-func (au AuthUsecase) SignOut(token string) {
-	tokens.RemoveToken(token)
+func (au AuthUsecase) JWKS() entities.JSONWebKeySet {
+	return tokens.JWKS()
 }
 
-func (au AuthUsecase) Update(user entities.User) (entities.User, error) {
-	userModel := au.r.FindUserById(user.Id)
-	if userModel.Id == 0 {
-		return entities.User{}, fmt.Errorf("user is not exist")
+func (au AuthUsecase) Update(ctx context.Context, user entities.User) (entities.User, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.Update")
+	defer span.End()
+	op := "authUsecase.Update()"
+	userModel, err := au.r.FindUserById(ctx, user.Id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.User{}, entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return entities.User{}, fmt.Errorf("%s: %w", op, err)
 	}
-	candidate := au.r.FindUserByUsername(user.Username)
-	if candidate.Id != 0 && candidate.Id != userModel.Id {
-		return entities.User{}, fmt.Errorf("username is taken")
+	if err := au.checkUsername(ctx, user.Username, userModel.Id); err != nil {
+		return entities.User{}, err
+	}
+	hash, err := passwords.Hash(user.Password)
+	if err != nil {
+		return entities.User{}, err
 	}
 	userModel.Username = user.Username
-	userModel.Password = user.Password
-	au.r.SaveUser(userModel)
+	userModel.Password = hash
+	if err := au.r.SaveUser(ctx, userModel); err != nil {
+		return entities.User{}, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return dto.UserModelToEntitie(userModel), nil
 }
 
 //adminAuth
 
-func (au AuthUsecase) GetUsers(start, count uint) []entities.User {
-	usersModels := au.r.GetUsers(start, int(count))
+// GetUsers returns page of users matching the filter
+func (au AuthUsecase) GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]entities.User, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.GetUsers")
+	defer span.End()
+	op := "authUsecase.GetUsers()"
+	usersModels, meta, err := au.r.GetUsers(ctx, filter, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
 	usersEntities := make([]entities.User, 0, len(usersModels))
 	for _, user := range usersModels {
 		usersEntities = append(usersEntities, dto.UserModelToEntitie(user))
 	}
-	return usersEntities
+	return usersEntities, meta, nil
 }
 
-func (au AuthUsecase) CreateUser(user entities.User) (entities.User, error) {
-	candidate := au.r.FindUserByUsername(user.Username)
-	if candidate.Id != 0 {
-		return entities.User{}, fmt.Errorf("user is already exist")
+func (au AuthUsecase) CreateUser(ctx context.Context, user entities.User) (entities.User, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.CreateUser")
+	defer span.End()
+	op := "authUsecase.CreateUser()"
+	if err := au.checkUsername(ctx, user.Username, 0); err != nil {
+		return entities.User{}, err
+	}
+
+	hash, err := passwords.Hash(user.Password)
+	if err != nil {
+		return entities.User{}, err
 	}
+	user.Password = hash
 
 	userModel := dto.UserEntitieToModels(user)
-	userModel = au.r.CreateUser(userModel)
+	userModel.Balance = 0
+	err = au.tx(ctx, func(r AuthRepository) error {
+		var err error
+		userModel, err = r.CreateUser(ctx, userModel)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if userModel.IsAdmin {
+			if err := au.syncAdminRole(ctx, r, userModel.Id, true); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if err := au.adjustBalance(ctx, r, userModel.Id, user.Balance); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.User{}, err
+	}
+	userModel.Balance = user.Balance
 	userEntite := dto.UserModelToEntitie(userModel)
 	return userEntite, nil
 }
 
-func (au AuthUsecase) UpdateUser(user entities.User) (entities.User, error) {
-	userModel := au.r.FindUserById(user.Id)
-	if userModel.Id == 0 {
-		return entities.User{}, fmt.Errorf("user is not exist")
+// UpdateUser changes the account, admin role and balance together,
+// the user row is locked so balance delta is counted from the current balance
+func (au AuthUsecase) UpdateUser(ctx context.Context, update entities.UserUpdate) (entities.User, error) {
+	ctx, span := tracer.Start(ctx, "authUsecase.UpdateUser")
+	defer span.End()
+	op := "authUsecase.UpdateUser()"
+	hash, err := passwords.Hash(update.Password)
+	if err != nil {
+		return entities.User{}, err
 	}
-	candidate := au.r.FindUserByUsername(user.Username)
-	if candidate.Id != 0 && candidate.Id != userModel.Id {
-		return entities.User{}, fmt.Errorf("username is taken")
+
+	var userModel models.User
+	err = au.tx(ctx, func(r AuthRepository) error {
+		var err error
+		userModel, err = r.FindUserForUpdate(ctx, update.Id)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := au.checkUsername(ctx, update.Username, userModel.Id); err != nil {
+			return err
+		}
+		userModel.Username = update.Username
+		userModel.Password = hash
+		if update.IsAdmin != nil {
+			userModel.IsAdmin = *update.IsAdmin
+		}
+		if err := r.SaveUser(ctx, userModel); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if update.IsAdmin != nil {
+			if err := au.syncAdminRole(ctx, r, userModel.Id, userModel.IsAdmin); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if update.Balance != nil {
+			if err := au.adjustBalance(ctx, r, userModel.Id, *update.Balance-userModel.Balance); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			userModel.Balance = *update.Balance
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.User{}, err
 	}
-	userModel.Username = user.Username
-	userModel.Password = user.Password
-	userModel.IsAdmin = user.IsAdmin
-	userModel.Balance = user.Balance
-	au.r.SaveUser(userModel)
 
 	return dto.UserModelToEntitie(userModel), nil
 }
 
-func (au AuthUsecase) DeleteUser(id uint) error {
-	user := au.r.FindUserById(id)
-	if user.Id == 0 {
-		return fmt.Errorf("user is not exist")
+func (au AuthUsecase) DeleteUser(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "authUsecase.DeleteUser")
+	defer span.End()
+	op := "authUsecase.DeleteUser()"
+	err := au.r.DeleteUser(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RevokeSessions signs the user out from all devices
+func (au AuthUsecase) RevokeSessions(ctx context.Context, userId uint) error {
+	ctx, span := tracer.Start(ctx, "authUsecase.RevokeSessions")
+	defer span.End()
+	op := "authUsecase.RevokeSessions()"
+	_, err := au.r.FindUserById(ctx, userId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	now := time.Now()
+	if err := au.rs.RevokeUser(userId, now, now.Add(tokens.AccessTokenTTL)); err != nil {
+		return fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+	if err := au.r.RevokeUserRefreshTokens(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	au.log.InfoContext(ctx, "sessions of the user are revoked", slog.Uint64("user_id", uint64(userId)))
+	return nil
+}
+
+// adjustBalance posts adjustment entry changing balance of the user by amount
+func (au AuthUsecase) adjustBalance(ctx context.Context, r AuthRepository, userId uint, amount float64) error {
+	op := "authUsecase.adjustBalance()"
+	if amount == 0 {
+		return nil
+	}
+	external, err := r.FindSystemAccount(ctx, entities.AccountExternal)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	wallet, err := r.FindWalletAccount(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	entry := models.NewTransfer(entities.EntryAdjustment, external.Id, wallet.Id, amount, "balance changed by admin")
+	if _, err := r.CreateJournalEntry(ctx, entry); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	au.r.DeleteUser(id)
 	return nil
 }
+
+// checkUsername returns conflict error if username is taken by someone except userId
+func (au AuthUsecase) checkUsername(ctx context.Context, username string, userId uint) error {
+	op := "authUsecase.checkUsername()"
+	candidate, err := au.r.FindUserByUsername(ctx, username)
+	if errors.Is(err, entities.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if userId == 0 {
+		return entities.NewConflictError(entities.CodeUsernameTaken, "user is already exist")
+	}
+	if candidate.Id != userId {
+		return entities.NewConflictError(entities.CodeUsernameTaken, "username is taken")
+	}
+	return nil
+}
+
+// syncAdminRole keeps isAdmin flag and global admin role consistent
+func (au AuthUsecase) syncAdminRole(ctx context.Context, r AuthRepository, userId uint, isAdmin bool) error {
+	role, err := r.FindRoleByName(ctx, entities.AdminRole)
+	if errors.Is(err, entities.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isAdmin {
+		return r.AssignRole(ctx, models.UserRole{UserId: userId, RoleId: role.Id})
+	}
+	return r.UnassignRole(ctx, userId, role.Id)
+}
+
+func (au AuthUsecase) newSession(ctx context.Context, user entities.User) (entities.TokenPair, error) {
+	op := "authUsecase.newSession()"
+	familyId, err := tokens.NewId()
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+	return au.issueTokens(ctx, user, familyId)
+}
+
+func (au AuthUsecase) issueTokens(ctx context.Context, user entities.User, familyId string) (entities.TokenPair, error) {
+	op := "authUsecase.issueTokens()"
+	accessToken, accessExpiresAt, err := tokens.GenerateNewJwt(user, familyId)
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+	refreshToken, hash, err := tokens.GenerateRefreshToken()
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w: %w", op, entities.ErrInternal, err)
+	}
+	now := time.Now()
+	refreshModel, err := au.r.CreateRefreshToken(ctx, models.RefreshToken{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: hash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(tokens.RefreshTokenTTL),
+	})
+	if err != nil {
+		return entities.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return entities.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshModel.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,347 @@
+package authUsecase
+
+import (
+	"context"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/logging"
+	"simbirGo/internal/metrics"
+	"simbirGo/internal/passwords"
+	"simbirGo/internal/tokens"
+	mock_authUsecase "simbirGo/internal/usecase/authUsecase/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// inTx runs fn with the same repository, transactions are not checked by unit tests
+func inTx(r AuthRepository) Transactor {
+	return func(ctx context.Context, fn func(r AuthRepository) error) error {
+		return fn(r)
+	}
+}
+
+func TestAuthUsecase_SignIn(t *testing.T) {
+	assert.NoError(t, tokens.InitKeys("", "", "secret"))
+	hash, err := passwords.Hash("bar")
+	assert.NoError(t, err)
+	lockedUntil := time.Now().Add(time.Minute)
+	lockExpired := time.Now().Add(-time.Minute)
+
+	type mockBehavior func(r *mock_authUsecase.MockAuthRepository)
+
+	testTable := []struct {
+		name         string
+		inputUser    entities.User
+		mockBehavior mockBehavior
+		expectedErr  string
+	}{
+		{
+			name:      "Hashed password",
+			inputUser: entities.User{Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: hash}, nil)
+				r.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+					return token, nil
+				})
+			},
+		},
+		{
+			name:      "Legacy plaintext password is rehashed",
+			inputUser: entities.User{Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: "bar"}, nil)
+				r.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Do(func(_ context.Context, user models.User) {
+					assert.NotEqual(t, "bar", user.Password)
+					ok, needRehash := passwords.Compare(user.Password, "bar")
+					assert.True(t, ok)
+					assert.False(t, needRehash)
+				})
+				r.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+					return token, nil
+				})
+			},
+		},
+		{
+			name:      "Unknown user",
+			inputUser: entities.User{Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{}, entities.ErrNotFound)
+			},
+			expectedErr: "invalid username or password",
+		},
+		{
+			name:      "Invalid password",
+			inputUser: entities.User{Username: "foo", Password: "baz"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: hash}, nil)
+				r.EXPECT().RecordFailedSignIn(gomock.Any(), uint(1)).Return(1, nil)
+			},
+			expectedErr: "invalid username or password",
+		},
+		{
+			name:      "Invalid legacy password",
+			inputUser: entities.User{Username: "foo", Password: "baz"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: "bar"}, nil)
+				r.EXPECT().RecordFailedSignIn(gomock.Any(), uint(1)).Return(1, nil)
+			},
+			expectedErr: "invalid username or password",
+		},
+		{
+			name:      "Too many failures lock the account",
+			inputUser: entities.User{Username: "foo", Password: "baz"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: hash, FailedSignIns: 4}, nil)
+				r.EXPECT().RecordFailedSignIn(gomock.Any(), uint(1)).Return(5, nil)
+				r.EXPECT().LockUser(gomock.Any(), uint(1), gomock.Any()).Do(func(_ context.Context, _ uint, until time.Time) {
+					assert.WithinDuration(t, time.Now().Add(LockoutDuration), until, time.Second)
+				})
+			},
+			expectedErr: "account is locked after too many failed sign-ins, retry later",
+		},
+		{
+			name:      "Locked account",
+			inputUser: entities.User{Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: hash, FailedSignIns: 5, LockedUntil: &lockedUntil}, nil)
+			},
+			expectedErr: "account is locked after too many failed sign-ins, retry later",
+		},
+		{
+			name:      "Failures are forgotten after sign in",
+			inputUser: entities.User{Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo", Password: hash, FailedSignIns: 5, LockedUntil: &lockExpired}, nil)
+				r.EXPECT().ResetFailedSignIns(gomock.Any(), uint(1))
+				r.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+					return token, nil
+				})
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_authUsecase.NewMockAuthRepository(c)
+			testCase.mockBehavior(repo)
+			uc := New(repo, inTx(repo), tokens.NewMemoryRevocationStore(), logging.Discard(), metrics.New())
+
+			tokenPair, err := uc.SignIn(context.Background(), testCase.inputUser)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tokenPair.AccessToken)
+			assert.NotEmpty(t, tokenPair.RefreshToken)
+		})
+	}
+}
+
+func TestAuthUsecase_SignUp(t *testing.T) {
+	assert.NoError(t, tokens.InitKeys("", "", "secret"))
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_authUsecase.NewMockAuthRepository(c)
+	repo.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{}, entities.ErrNotFound)
+	repo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user models.User) (models.User, error) {
+		assert.False(t, user.IsAdmin)
+		user.Id = 1
+		return user, nil
+	})
+	repo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+		return token, nil
+	})
+	uc := New(repo, inTx(repo), tokens.NewMemoryRevocationStore(), logging.Discard(), metrics.New())
+
+	// no role is assigned, the mock fails on unexpected AssignRole
+	user, _, err := uc.SignUp(context.Background(), entities.User{Username: "foo", Password: "bar", IsAdmin: true})
+	assert.NoError(t, err)
+	assert.False(t, user.IsAdmin)
+}
+
+func TestAuthUsecase_UpdateUser(t *testing.T) {
+	isAdmin := false
+	balance := 100.0
+
+	type mockBehavior func(r *mock_authUsecase.MockAuthRepository)
+
+	testTable := []struct {
+		name         string
+		update       entities.UserUpdate
+		mockBehavior mockBehavior
+		expected     entities.User
+		expectedErr  string
+	}{
+		{
+			name:   "Admin role and balance are kept",
+			update: entities.UserUpdate{Id: 1, Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserForUpdate(gomock.Any(), uint(1)).Return(models.User{Id: 1, Username: "old", IsAdmin: true, Balance: 30}, nil)
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{}, entities.ErrNotFound)
+				r.EXPECT().SaveUser(gomock.Any(), gomock.Any())
+			},
+			expected: entities.User{Id: 1, Username: "foo", IsAdmin: true, Balance: 30},
+		},
+		{
+			name:   "Balance is adjusted from the locked row",
+			update: entities.UserUpdate{Id: 1, Username: "foo", Password: "bar", IsAdmin: &isAdmin, Balance: &balance},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserForUpdate(gomock.Any(), uint(1)).Return(models.User{Id: 1, Username: "foo", IsAdmin: true, Balance: 30}, nil)
+				r.EXPECT().FindUserByUsername(gomock.Any(), "foo").Return(models.User{Id: 1, Username: "foo"}, nil)
+				r.EXPECT().SaveUser(gomock.Any(), gomock.Any())
+				r.EXPECT().FindRoleByName(gomock.Any(), entities.AdminRole).Return(models.Role{Id: 1}, nil)
+				r.EXPECT().UnassignRole(gomock.Any(), uint(1), uint(1))
+				r.EXPECT().FindSystemAccount(gomock.Any(), entities.AccountExternal).Return(models.LedgerAccount{Id: 10}, nil)
+				r.EXPECT().FindWalletAccount(gomock.Any(), uint(1)).Return(models.LedgerAccount{Id: 11}, nil)
+				r.EXPECT().CreateJournalEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+					assert.Equal(t, models.NewTransfer(entities.EntryAdjustment, 10, 11, 70, "balance changed by admin").Postings, entry.Postings)
+					return entry, nil
+				})
+			},
+			expected: entities.User{Id: 1, Username: "foo", Balance: 100},
+		},
+		{
+			name:   "User is not found",
+			update: entities.UserUpdate{Id: 1, Username: "foo", Password: "bar"},
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindUserForUpdate(gomock.Any(), uint(1)).Return(models.User{}, entities.ErrNotFound)
+			},
+			expectedErr: "user is not exist",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_authUsecase.NewMockAuthRepository(c)
+			testCase.mockBehavior(repo)
+			uc := New(repo, inTx(repo), tokens.NewMemoryRevocationStore(), logging.Discard(), metrics.New())
+
+			user, err := uc.UpdateUser(context.Background(), testCase.update)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, user)
+		})
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: 0},
+		{failures: 4, expected: 0},
+		{failures: 5, expected: time.Minute},
+		{failures: 6, expected: 2 * time.Minute},
+		{failures: 8, expected: 8 * time.Minute},
+		{failures: 11, expected: time.Hour},
+		{failures: 100, expected: time.Hour},
+	}
+
+	for _, testCase := range tests {
+		assert.Equal(t, testCase.expected, lockoutDuration(testCase.failures), "failures: %d", testCase.failures)
+	}
+}
+
+func TestAuthUsecase_Refresh(t *testing.T) {
+	assert.NoError(t, tokens.InitKeys("", "", "secret"))
+	refreshToken := "refresh"
+	hash := tokens.HashRefreshToken(refreshToken)
+	usedAt := time.Now().Add(-time.Minute)
+
+	type mockBehavior func(r *mock_authUsecase.MockAuthRepository)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedErr  string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindRefreshToken(gomock.Any(), hash).Return(models.RefreshToken{
+					Id: 1, UserId: 2, FamilyId: "family", ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				r.EXPECT().MarkRefreshTokenUsed(gomock.Any(), uint(1)).Return(true, nil)
+				r.EXPECT().FindUserById(gomock.Any(), uint(2)).Return(models.User{Id: 2, Username: "foo"}, nil)
+				r.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+					assert.Equal(t, "family", token.FamilyId)
+					assert.NotEqual(t, hash, token.TokenHash)
+					return token, nil
+				})
+			},
+		},
+		{
+			name: "Unknown token",
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindRefreshToken(gomock.Any(), hash).Return(models.RefreshToken{}, entities.ErrNotFound)
+			},
+			expectedErr: "invalid refresh token",
+		},
+		{
+			name: "Reused token revokes family",
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindRefreshToken(gomock.Any(), hash).Return(models.RefreshToken{
+					Id: 1, UserId: 2, FamilyId: "family", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt,
+				}, nil)
+				r.EXPECT().RevokeRefreshFamily(gomock.Any(), "family")
+			},
+			expectedErr: "refresh token reuse detected, session is revoked",
+		},
+		{
+			name: "Concurrent reuse revokes family",
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindRefreshToken(gomock.Any(), hash).Return(models.RefreshToken{
+					Id: 1, UserId: 2, FamilyId: "family", ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				r.EXPECT().MarkRefreshTokenUsed(gomock.Any(), uint(1)).Return(false, nil)
+				r.EXPECT().RevokeRefreshFamily(gomock.Any(), "family")
+			},
+			expectedErr: "refresh token reuse detected, session is revoked",
+		},
+		{
+			name: "Expired token",
+			mockBehavior: func(r *mock_authUsecase.MockAuthRepository) {
+				r.EXPECT().FindRefreshToken(gomock.Any(), hash).Return(models.RefreshToken{
+					Id: 1, UserId: 2, FamilyId: "family", ExpiresAt: time.Now().Add(-time.Hour),
+				}, nil)
+				r.EXPECT().MarkRefreshTokenUsed(gomock.Any(), uint(1)).Return(true, nil)
+			},
+			expectedErr: "refresh token is expired",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_authUsecase.NewMockAuthRepository(c)
+			testCase.mockBehavior(repo)
+			uc := New(repo, inTx(repo), tokens.NewMemoryRevocationStore(), logging.Discard(), metrics.New())
+
+			tokenPair, err := uc.Refresh(context.Background(), refreshToken)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tokenPair.AccessToken)
+			assert.NotEqual(t, refreshToken, tokenPair.RefreshToken)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package authUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"simbirGo/internal/entities"
+	"time"
+)
+
+var (
+	// LockoutThreshold is number of failed sign-ins in a row after which the account is locked, 0 disables lockout
+	LockoutThreshold = 5
+	// LockoutDuration is the first lock, it doubles with every next failure up to MaxLockoutDuration
+	LockoutDuration    = time.Minute
+	MaxLockoutDuration = time.Hour
+)
+
+// lockoutDuration returns how long the account is locked after failures in a row, 0 if it is not locked
+func lockoutDuration(failures int) time.Duration {
+	if LockoutThreshold <= 0 || failures < LockoutThreshold {
+		return 0
+	}
+	lock := LockoutDuration
+	for i := LockoutThreshold; i < failures && lock < MaxLockoutDuration; i++ {
+		lock *= 2
+	}
+	return min(lock, MaxLockoutDuration)
+}
+
+// newInvalidCredentialsError is the same for unknown user and wrong password, so accounts can not be enumerated
+func newInvalidCredentialsError() error {
+	return entities.NewUnauthorizedError(entities.CodeInvalidCredentials, "invalid username or password")
+}
+
+func newLockedError(retryAfter time.Duration) error {
+	return entities.NewTooManyRequestsError(entities.CodeAccountLocked,
+		"account is locked after too many failed sign-ins, retry later", retryAfter)
+}
+
+// recordFailedSignIn counts the failure and locks the account if there are too many of them.
+// It returns error the client gets: invalid credentials or locked account.
+func (au AuthUsecase) recordFailedSignIn(ctx context.Context, userId uint) error {
+	op := "authUsecase.recordFailedSignIn()"
+	invalid := newInvalidCredentialsError()
+	if LockoutThreshold <= 0 {
+		return invalid
+	}
+	failures, err := au.r.RecordFailedSignIn(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	lock := lockoutDuration(failures)
+	if lock == 0 {
+		return invalid
+	}
+	if err := au.r.LockUser(ctx, userId, time.Now().Add(lock)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	au.log.WarnContext(ctx, "account is locked after failed sign-ins",
+		slog.Uint64("user_id", uint64(userId)), slog.Int("failures", failures), slog.Duration("lock", lock))
+	return newLockedError(lock)
+}
+
+// UnlockUser allows the user to sign in again and forgets failed sign-ins
+func (au AuthUsecase) UnlockUser(ctx context.Context, userId uint) error {
+	ctx, span := tracer.Start(ctx, "authUsecase.UnlockUser")
+	defer span.End()
+	op := "authUsecase.UnlockUser()"
+	_, err := au.r.FindUserById(ctx, userId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := au.r.ResetFailedSignIns(ctx, userId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	au.log.InfoContext(ctx, "account is unlocked", slog.Uint64("user_id", uint64(userId)))
+	return nil
+}
@@ -5,8 +5,12 @@
 package mock_authUsecase
 
 import (
+	context "context"
 	reflect "reflect"
 	models "simbirGo/internal/database/models"
+	entities "simbirGo/internal/entities"
+	pagination "simbirGo/internal/pagination"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,82 +38,345 @@ func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AssignRole mocks base method.
+func (m *MockAuthRepository) AssignRole(ctx context.Context, userRole models.UserRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, userRole)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockAuthRepositoryMockRecorder) AssignRole(ctx, userRole interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAuthRepository)(nil).AssignRole), ctx, userRole)
+}
+
+// CreateJournalEntry mocks base method.
+func (m *MockAuthRepository) CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournalEntry", ctx, entry)
+	ret0, _ := ret[0].(models.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournalEntry indicates an expected call of CreateJournalEntry.
+func (mr *MockAuthRepositoryMockRecorder) CreateJournalEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalEntry", reflect.TypeOf((*MockAuthRepository)(nil).CreateJournalEntry), ctx, entry)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockAuthRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockAuthRepositoryMockRecorder) CreateRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).CreateRefreshToken), ctx, token)
+}
+
 // CreateUser mocks base method.
-func (m *MockAuthRepository) CreateUser(user models.User) models.User {
+func (m *MockAuthRepository) CreateUser(ctx context.Context, user models.User) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(models.User)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockAuthRepositoryMockRecorder) CreateUser(user interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthRepository)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthRepository)(nil).CreateUser), ctx, user)
 }
 
 // DeleteUser mocks base method.
-func (m *MockAuthRepository) DeleteUser(id uint) {
+func (m *MockAuthRepository) DeleteUser(ctx context.Context, id uint) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "DeleteUser", id)
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockAuthRepositoryMockRecorder) DeleteUser(id interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthRepository)(nil).DeleteUser), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthRepository)(nil).DeleteUser), ctx, id)
+}
+
+// FindRefreshToken mocks base method.
+func (m *MockAuthRepository) FindRefreshToken(ctx context.Context, hash string) (models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRefreshToken", ctx, hash)
+	ret0, _ := ret[0].(models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRefreshToken indicates an expected call of FindRefreshToken.
+func (mr *MockAuthRepositoryMockRecorder) FindRefreshToken(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshToken", reflect.TypeOf((*MockAuthRepository)(nil).FindRefreshToken), ctx, hash)
+}
+
+// FindRoleByName mocks base method.
+func (m *MockAuthRepository) FindRoleByName(ctx context.Context, name string) (models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoleByName", ctx, name)
+	ret0, _ := ret[0].(models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoleByName indicates an expected call of FindRoleByName.
+func (mr *MockAuthRepositoryMockRecorder) FindRoleByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoleByName", reflect.TypeOf((*MockAuthRepository)(nil).FindRoleByName), ctx, name)
+}
+
+// FindSystemAccount mocks base method.
+func (m *MockAuthRepository) FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSystemAccount", ctx, code)
+	ret0, _ := ret[0].(models.LedgerAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSystemAccount indicates an expected call of FindSystemAccount.
+func (mr *MockAuthRepositoryMockRecorder) FindSystemAccount(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSystemAccount", reflect.TypeOf((*MockAuthRepository)(nil).FindSystemAccount), ctx, code)
 }
 
 // FindUserById mocks base method.
-func (m *MockAuthRepository) FindUserById(id uint) models.User {
+func (m *MockAuthRepository) FindUserById(ctx context.Context, id uint) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUserById", id)
+	ret := m.ctrl.Call(m, "FindUserById", ctx, id)
 	ret0, _ := ret[0].(models.User)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // FindUserById indicates an expected call of FindUserById.
-func (mr *MockAuthRepositoryMockRecorder) FindUserById(id interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) FindUserById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserById", reflect.TypeOf((*MockAuthRepository)(nil).FindUserById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserById", reflect.TypeOf((*MockAuthRepository)(nil).FindUserById), ctx, id)
 }
 
 // FindUserByUsername mocks base method.
-func (m *MockAuthRepository) FindUserByUsername(username string) models.User {
+func (m *MockAuthRepository) FindUserByUsername(ctx context.Context, username string) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUserByUsername", username)
+	ret := m.ctrl.Call(m, "FindUserByUsername", ctx, username)
 	ret0, _ := ret[0].(models.User)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // FindUserByUsername indicates an expected call of FindUserByUsername.
-func (mr *MockAuthRepositoryMockRecorder) FindUserByUsername(username interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) FindUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsername", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByUsername), ctx, username)
+}
+
+// FindUserForUpdate mocks base method.
+func (m *MockAuthRepository) FindUserForUpdate(ctx context.Context, id uint) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserForUpdate", ctx, id)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserForUpdate indicates an expected call of FindUserForUpdate.
+func (mr *MockAuthRepositoryMockRecorder) FindUserForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserForUpdate", reflect.TypeOf((*MockAuthRepository)(nil).FindUserForUpdate), ctx, id)
+}
+
+// FindWalletAccount mocks base method.
+func (m *MockAuthRepository) FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWalletAccount", ctx, userId)
+	ret0, _ := ret[0].(models.LedgerAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWalletAccount indicates an expected call of FindWalletAccount.
+func (mr *MockAuthRepositoryMockRecorder) FindWalletAccount(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsername", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWalletAccount", reflect.TypeOf((*MockAuthRepository)(nil).FindWalletAccount), ctx, userId)
 }
 
 // GetUsers mocks base method.
-func (m *MockAuthRepository) GetUsers(start uint, count int) []models.User {
+func (m *MockAuthRepository) GetUsers(ctx context.Context, filter entities.UserFilter, page pagination.Request) ([]models.User, pagination.Meta, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", start, count)
+	ret := m.ctrl.Call(m, "GetUsers", ctx, filter, page)
 	ret0, _ := ret[0].([]models.User)
-	return ret0
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetUsers indicates an expected call of GetUsers.
-func (mr *MockAuthRepositoryMockRecorder) GetUsers(start, count interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) GetUsers(ctx, filter, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAuthRepository)(nil).GetUsers), start, count)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAuthRepository)(nil).GetUsers), ctx, filter, page)
+}
+
+// LockUser mocks base method.
+func (m *MockAuthRepository) LockUser(ctx context.Context, userId uint, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUser", ctx, userId, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUser indicates an expected call of LockUser.
+func (mr *MockAuthRepositoryMockRecorder) LockUser(ctx, userId, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUser", reflect.TypeOf((*MockAuthRepository)(nil).LockUser), ctx, userId, until)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockAuthRepository) MarkRefreshTokenUsed(ctx context.Context, id uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockAuthRepositoryMockRecorder) MarkRefreshTokenUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockAuthRepository)(nil).MarkRefreshTokenUsed), ctx, id)
+}
+
+// RecordFailedSignIn mocks base method.
+func (m *MockAuthRepository) RecordFailedSignIn(ctx context.Context, userId uint) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedSignIn", ctx, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailedSignIn indicates an expected call of RecordFailedSignIn.
+func (mr *MockAuthRepositoryMockRecorder) RecordFailedSignIn(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedSignIn", reflect.TypeOf((*MockAuthRepository)(nil).RecordFailedSignIn), ctx, userId)
+}
+
+// ResetFailedSignIns mocks base method.
+func (m *MockAuthRepository) ResetFailedSignIns(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetFailedSignIns", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetFailedSignIns indicates an expected call of ResetFailedSignIns.
+func (mr *MockAuthRepositoryMockRecorder) ResetFailedSignIns(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetFailedSignIns", reflect.TypeOf((*MockAuthRepository)(nil).ResetFailedSignIns), ctx, userId)
+}
+
+// RevokeRefreshFamily mocks base method.
+func (m *MockAuthRepository) RevokeRefreshFamily(ctx context.Context, familyId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshFamily", ctx, familyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshFamily indicates an expected call of RevokeRefreshFamily.
+func (mr *MockAuthRepositoryMockRecorder) RevokeRefreshFamily(ctx, familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshFamily", reflect.TypeOf((*MockAuthRepository)(nil).RevokeRefreshFamily), ctx, familyId)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockAuthRepository) RevokeUserRefreshTokens(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockAuthRepositoryMockRecorder) RevokeUserRefreshTokens(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockAuthRepository)(nil).RevokeUserRefreshTokens), ctx, userId)
 }
 
 // SaveUser mocks base method.
-func (m *MockAuthRepository) SaveUser(user models.User) {
+func (m *MockAuthRepository) SaveUser(ctx context.Context, user models.User) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SaveUser", user)
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // SaveUser indicates an expected call of SaveUser.
-func (mr *MockAuthRepositoryMockRecorder) SaveUser(user interface{}) *gomock.Call {
+func (mr *MockAuthRepositoryMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockAuthRepository)(nil).SaveUser), ctx, user)
+}
+
+// UnassignRole mocks base method.
+func (m *MockAuthRepository) UnassignRole(ctx context.Context, userId, roleId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignRole", ctx, userId, roleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignRole indicates an expected call of UnassignRole.
+func (mr *MockAuthRepositoryMockRecorder) UnassignRole(ctx, userId, roleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignRole", reflect.TypeOf((*MockAuthRepository)(nil).UnassignRole), ctx, userId, roleId)
+}
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// SignInFailed mocks base method.
+func (m *MockMetrics) SignInFailed(reason string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignInFailed", reason)
+}
+
+// SignInFailed indicates an expected call of SignInFailed.
+func (mr *MockMetricsMockRecorder) SignInFailed(reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockAuthRepository)(nil).SaveUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInFailed", reflect.TypeOf((*MockMetrics)(nil).SignInFailed), reason)
 }
@@ -0,0 +1,262 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: paymentUsecase.go
+
+// Package mock_paymentUsecase is a generated GoMock package.
+package mock_paymentUsecase
+
+import (
+	context "context"
+	reflect "reflect"
+	models "simbirGo/internal/database/models"
+	pagination "simbirGo/internal/pagination"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateJournalEntry mocks base method.
+func (m *MockPaymentRepository) CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournalEntry", ctx, entry)
+	ret0, _ := ret[0].(models.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournalEntry indicates an expected call of CreateJournalEntry.
+func (mr *MockPaymentRepositoryMockRecorder) CreateJournalEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalEntry", reflect.TypeOf((*MockPaymentRepository)(nil).CreateJournalEntry), ctx, entry)
+}
+
+// CreateTopUp mocks base method.
+func (m *MockPaymentRepository) CreateTopUp(ctx context.Context, topUp models.TopUp) (models.TopUp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopUp", ctx, topUp)
+	ret0, _ := ret[0].(models.TopUp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTopUp indicates an expected call of CreateTopUp.
+func (mr *MockPaymentRepositoryMockRecorder) CreateTopUp(ctx, topUp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopUp", reflect.TypeOf((*MockPaymentRepository)(nil).CreateTopUp), ctx, topUp)
+}
+
+// FindAccountBalance mocks base method.
+func (m *MockPaymentRepository) FindAccountBalance(ctx context.Context, id uint) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAccountBalance", ctx, id)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAccountBalance indicates an expected call of FindAccountBalance.
+func (mr *MockPaymentRepositoryMockRecorder) FindAccountBalance(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAccountBalance", reflect.TypeOf((*MockPaymentRepository)(nil).FindAccountBalance), ctx, id)
+}
+
+// FindAccountBalances mocks base method.
+func (m *MockPaymentRepository) FindAccountBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAccountBalances", ctx)
+	ret0, _ := ret[0].([]models.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAccountBalances indicates an expected call of FindAccountBalances.
+func (mr *MockPaymentRepositoryMockRecorder) FindAccountBalances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAccountBalances", reflect.TypeOf((*MockPaymentRepository)(nil).FindAccountBalances), ctx)
+}
+
+// FindDebtors mocks base method.
+func (m *MockPaymentRepository) FindDebtors(ctx context.Context, page pagination.Request) ([]models.User, pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDebtors", ctx, page)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindDebtors indicates an expected call of FindDebtors.
+func (mr *MockPaymentRepositoryMockRecorder) FindDebtors(ctx, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDebtors", reflect.TypeOf((*MockPaymentRepository)(nil).FindDebtors), ctx, page)
+}
+
+// FindSystemAccount mocks base method.
+func (m *MockPaymentRepository) FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSystemAccount", ctx, code)
+	ret0, _ := ret[0].(models.LedgerAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSystemAccount indicates an expected call of FindSystemAccount.
+func (mr *MockPaymentRepositoryMockRecorder) FindSystemAccount(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSystemAccount", reflect.TypeOf((*MockPaymentRepository)(nil).FindSystemAccount), ctx, code)
+}
+
+// FindTopUpById mocks base method.
+func (m *MockPaymentRepository) FindTopUpById(ctx context.Context, id uint) (models.TopUp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopUpById", ctx, id)
+	ret0, _ := ret[0].(models.TopUp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopUpById indicates an expected call of FindTopUpById.
+func (mr *MockPaymentRepositoryMockRecorder) FindTopUpById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopUpById", reflect.TypeOf((*MockPaymentRepository)(nil).FindTopUpById), ctx, id)
+}
+
+// FindTopUpByIntentForUpdate mocks base method.
+func (m *MockPaymentRepository) FindTopUpByIntentForUpdate(ctx context.Context, gateway, intentId string) (models.TopUp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopUpByIntentForUpdate", ctx, gateway, intentId)
+	ret0, _ := ret[0].(models.TopUp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopUpByIntentForUpdate indicates an expected call of FindTopUpByIntentForUpdate.
+func (mr *MockPaymentRepositoryMockRecorder) FindTopUpByIntentForUpdate(ctx, gateway, intentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopUpByIntentForUpdate", reflect.TypeOf((*MockPaymentRepository)(nil).FindTopUpByIntentForUpdate), ctx, gateway, intentId)
+}
+
+// FindTotalDebt mocks base method.
+func (m *MockPaymentRepository) FindTotalDebt(ctx context.Context) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTotalDebt", ctx)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTotalDebt indicates an expected call of FindTotalDebt.
+func (mr *MockPaymentRepositoryMockRecorder) FindTotalDebt(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTotalDebt", reflect.TypeOf((*MockPaymentRepository)(nil).FindTotalDebt), ctx)
+}
+
+// FindUserBalances mocks base method.
+func (m *MockPaymentRepository) FindUserBalances(ctx context.Context) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserBalances", ctx)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserBalances indicates an expected call of FindUserBalances.
+func (mr *MockPaymentRepositoryMockRecorder) FindUserBalances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserBalances", reflect.TypeOf((*MockPaymentRepository)(nil).FindUserBalances), ctx)
+}
+
+// FindUserById mocks base method.
+func (m *MockPaymentRepository) FindUserById(ctx context.Context, id uint) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserById", ctx, id)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserById indicates an expected call of FindUserById.
+func (mr *MockPaymentRepositoryMockRecorder) FindUserById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserById", reflect.TypeOf((*MockPaymentRepository)(nil).FindUserById), ctx, id)
+}
+
+// FindUserPostings mocks base method.
+func (m *MockPaymentRepository) FindUserPostings(ctx context.Context, userId uint, page pagination.Request) ([]models.Posting, pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserPostings", ctx, userId, page)
+	ret0, _ := ret[0].([]models.Posting)
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindUserPostings indicates an expected call of FindUserPostings.
+func (mr *MockPaymentRepositoryMockRecorder) FindUserPostings(ctx, userId, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserPostings", reflect.TypeOf((*MockPaymentRepository)(nil).FindUserPostings), ctx, userId, page)
+}
+
+// FindWalletAccount mocks base method.
+func (m *MockPaymentRepository) FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWalletAccount", ctx, userId)
+	ret0, _ := ret[0].(models.LedgerAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWalletAccount indicates an expected call of FindWalletAccount.
+func (mr *MockPaymentRepositoryMockRecorder) FindWalletAccount(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWalletAccount", reflect.TypeOf((*MockPaymentRepository)(nil).FindWalletAccount), ctx, userId)
+}
+
+// LockAccount mocks base method.
+func (m *MockPaymentRepository) LockAccount(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAccount", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAccount indicates an expected call of LockAccount.
+func (mr *MockPaymentRepositoryMockRecorder) LockAccount(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAccount", reflect.TypeOf((*MockPaymentRepository)(nil).LockAccount), ctx, id)
+}
+
+// SaveTopUp mocks base method.
+func (m *MockPaymentRepository) SaveTopUp(ctx context.Context, topUp models.TopUp) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTopUp", ctx, topUp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTopUp indicates an expected call of SaveTopUp.
+func (mr *MockPaymentRepositoryMockRecorder) SaveTopUp(ctx, topUp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTopUp", reflect.TypeOf((*MockPaymentRepository)(nil).SaveTopUp), ctx, topUp)
+}
@@ -1,47 +1,209 @@
 package paymentUsecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/payments"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("simbirGo/internal/usecase/paymentUsecase")
+
+//go:generate mockgen -source=paymentUsecase.go -destination=mock/mock.go
+
 type PaymentRepository interface {
-	FindUserById(id uint) models.User
-	SaveUser(user models.User)
+	FindUserById(ctx context.Context, id uint) (models.User, error)
+	FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error)
+	FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error)
+	LockAccount(ctx context.Context, id uint) error
+	FindAccountBalance(ctx context.Context, id uint) (float64, error)
+	CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error)
+	FindUserPostings(ctx context.Context, userId uint, page pagination.Request) ([]models.Posting, pagination.Meta, error)
+	FindAccountBalances(ctx context.Context) ([]models.AccountBalance, error)
+	FindUserBalances(ctx context.Context) ([]models.User, error)
+	FindDebtors(ctx context.Context, page pagination.Request) ([]models.User, pagination.Meta, error)
+	FindTotalDebt(ctx context.Context) (float64, error)
+
+	CreateTopUp(ctx context.Context, topUp models.TopUp) (models.TopUp, error)
+	SaveTopUp(ctx context.Context, topUp models.TopUp) error
+	FindTopUpById(ctx context.Context, id uint) (models.TopUp, error)
+	FindTopUpByIntentForUpdate(ctx context.Context, gateway, intentId string) (models.TopUp, error)
 }
 
+// Transactor runs fn in a database transaction,
+// repository passed to fn is bound to the transaction
+type Transactor func(ctx context.Context, fn func(r PaymentRepository) error) error
+
 type PaymentUsecase struct {
-	r PaymentRepository
+	r   PaymentRepository
+	tx  Transactor
+	gw  payments.Gateway // nil when payment gateway is not configured
+	log *slog.Logger
 }
 
-func New(r PaymentRepository) PaymentUsecase {
-	return PaymentUsecase{r: r}
+func New(r PaymentRepository, tx Transactor, gw payments.Gateway, log *slog.Logger) PaymentUsecase {
+	return PaymentUsecase{r: r, tx: tx, gw: gw, log: log}
+}
+
+// GetTransactions returns changes of user's balance, newest first
+func (pu PaymentUsecase) GetTransactions(ctx context.Context, userId uint, page pagination.Request) ([]entities.Transaction, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.GetTransactions")
+	defer span.End()
+	op := "paymentUsecase.GetTransactions()"
+	postings, meta, err := pu.r.FindUserPostings(ctx, userId, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	transactions := make([]entities.Transaction, len(postings))
+	for i, posting := range postings {
+		transactions[i] = dto.PostingModelToTransaction(posting)
+	}
+	return transactions, meta, nil
 }
 
-func (pu PaymentUsecase) IncreaseBalance(balanceId, userId uint, isAdmin bool) (int, error) {
-	if isAdmin {
-		user := pu.r.FindUserById(balanceId)
-		if user.Id == 0 {
-			return 400, fmt.Errorf("user is not exist")
+// admin's usecase
+// Payout moves money from revenue to the wallet of transport owner
+func (pu PaymentUsecase) Payout(ctx context.Context, ownerId uint, amount float64) error {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.Payout")
+	defer span.End()
+	op := "paymentUsecase.Payout()"
+	if amount <= 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return entities.NewValidationError(entities.CodeValidationFailed, "invalid amount", entities.FieldError{Field: "amount", Message: "must be positive"})
+	}
+	if _, err := pu.findUser(ctx, ownerId); err != nil {
+		return err
+	}
+
+	err := pu.tx(ctx, func(r PaymentRepository) error {
+		revenue, err := r.FindSystemAccount(ctx, entities.AccountRevenue)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := r.LockAccount(ctx, revenue.Id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		balance, err := r.FindAccountBalance(ctx, revenue.Id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if amount > balance {
+			return entities.NewInsufficientFundsError("not enough money in revenue account")
+		}
+		wallet, err := r.FindWalletAccount(ctx, ownerId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		entry := models.NewTransfer(entities.EntryOwnerPayout, revenue.Id, wallet.Id, amount, "payout to transport owner")
+		if _, err := r.CreateJournalEntry(ctx, entry); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		user.Balance += 250000
-		pu.r.SaveUser(user)
+	pu.log.InfoContext(ctx, "payout to transport owner", slog.Uint64("owner_id", uint64(ownerId)), slog.Float64("amount", amount))
+	return nil
+}
+
+// Reconcile compares cached balances of users with balances of their wallets
+func (pu PaymentUsecase) Reconcile(ctx context.Context) (entities.ReconciliationReport, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.Reconcile")
+	defer span.End()
+	op := "paymentUsecase.Reconcile()"
+	accounts, err := pu.r.FindAccountBalances(ctx)
+	if err != nil {
+		return entities.ReconciliationReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	users, err := pu.r.FindUserBalances(ctx)
+	if err != nil {
+		return entities.ReconciliationReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	report := entities.ReconciliationReport{
+		GeneratedAt:    time.Now(),
+		Users:          len(users),
+		SystemAccounts: map[string]float64{},
+		Mismatches:     []entities.BalanceMismatch{},
+	}
+	wallets := map[uint]float64{}
+	for _, account := range accounts {
+		report.Imbalance += account.Balance
+		if account.UserId == nil {
+			report.SystemAccounts[account.Code] = roundMoney(account.Balance)
+			continue
+		}
+		wallets[*account.UserId] = account.Balance
+	}
+	report.Imbalance = roundMoney(report.Imbalance)
 
-		return 200, nil
+	for _, user := range users {
+		ledgerBalance := wallets[user.Id]
+		difference := roundMoney(user.Balance - ledgerBalance)
+		if difference == 0 {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, entities.BalanceMismatch{
+			UserId:        user.Id,
+			Username:      user.Username,
+			Balance:       user.Balance,
+			LedgerBalance: roundMoney(ledgerBalance),
+			Difference:    difference,
+		})
 	}
+	return report, nil
+}
 
-	if balanceId != userId {
-		return 403, fmt.Errorf("user can increase only his balance")
+// GetDebtors returns page of users whose balance is negative after rents, total debt is counted over all of them
+func (pu PaymentUsecase) GetDebtors(ctx context.Context, page pagination.Request) (entities.DebtorsReport, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.GetDebtors")
+	defer span.End()
+	op := "paymentUsecase.GetDebtors()"
+	users, meta, err := pu.r.FindDebtors(ctx, page)
+	if err != nil {
+		return entities.DebtorsReport{}, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	totalDebt, err := pu.r.FindTotalDebt(ctx)
+	if err != nil {
+		return entities.DebtorsReport{}, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	user := pu.r.FindUserById(balanceId)
-	if user.Id == 0 {
-		return 400, fmt.Errorf("user is not exist")
+	report := entities.DebtorsReport{GeneratedAt: time.Now(), TotalDebt: roundMoney(totalDebt), Debtors: make([]entities.Debtor, len(users))}
+	for i, user := range users {
+		debtor := entities.Debtor{UserId: user.Id, Username: user.Username, Debt: roundMoney(-user.Balance)}
+		if user.DebtSince != nil {
+			debtor.DebtSince = *user.DebtSince
+		}
+		report.Debtors[i] = debtor
 	}
+	return report, meta, nil
+}
 
-	user.Balance += 250000
-	pu.r.SaveUser(user)
+func (pu PaymentUsecase) findUser(ctx context.Context, id uint) (models.User, error) {
+	op := "paymentUsecase.findUser()"
+	user, err := pu.r.FindUserById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.User{}, entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return user, nil
+}
 
-	return 200, nil
+// roundMoney drops float errors below a cent
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
 }
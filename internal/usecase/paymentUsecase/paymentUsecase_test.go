@@ -0,0 +1,257 @@
+package paymentUsecase
+
+import (
+	"context"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/logging"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/payments"
+	mock_paymentUsecase "simbirGo/internal/usecase/paymentUsecase/mock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentUsecase_Payout(t *testing.T) {
+	type mockBehavior func(r *mock_paymentUsecase.MockPaymentRepository)
+	revenue := models.LedgerAccount{Id: 2, Code: entities.AccountRevenue}
+	ownerId := uint(7)
+	wallet := models.LedgerAccount{Id: 10, Code: "wallet:7", UserId: &ownerId}
+
+	testTable := []struct {
+		name         string
+		amount       float64
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:   "OK",
+			amount: 300,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository) {
+				r.EXPECT().FindUserById(gomock.Any(), ownerId).Return(models.User{Id: ownerId}, nil)
+				r.EXPECT().FindSystemAccount(gomock.Any(), entities.AccountRevenue).Return(revenue, nil)
+				r.EXPECT().LockAccount(gomock.Any(), revenue.Id).Return(nil)
+				r.EXPECT().FindAccountBalance(gomock.Any(), revenue.Id).Return(float64(500), nil)
+				r.EXPECT().FindWalletAccount(gomock.Any(), ownerId).Return(wallet, nil)
+				r.EXPECT().CreateJournalEntry(gomock.Any(), models.NewTransfer(entities.EntryOwnerPayout, revenue.Id, wallet.Id, 300, "payout to transport owner")).
+					Return(models.JournalEntry{Id: 1}, nil)
+			},
+		},
+		{
+			name:         "Non-positive amount",
+			amount:       0,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:   "Unknown owner",
+			amount: 300,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository) {
+				r.EXPECT().FindUserById(gomock.Any(), ownerId).Return(models.User{}, entities.ErrNotFound)
+			},
+			expectedErr: entities.ErrNotFound,
+		},
+		{
+			name:   "Not enough revenue",
+			amount: 600,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository) {
+				r.EXPECT().FindUserById(gomock.Any(), ownerId).Return(models.User{Id: ownerId}, nil)
+				r.EXPECT().FindSystemAccount(gomock.Any(), entities.AccountRevenue).Return(revenue, nil)
+				r.EXPECT().LockAccount(gomock.Any(), revenue.Id).Return(nil)
+				r.EXPECT().FindAccountBalance(gomock.Any(), revenue.Id).Return(float64(500), nil)
+			},
+			expectedErr: entities.ErrInsufficientFunds,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_paymentUsecase.NewMockPaymentRepository(c)
+			testCase.mockBehavior(repo)
+			pu := New(repo, func(ctx context.Context, fn func(r PaymentRepository) error) error {
+				return fn(repo)
+			}, nil, logging.Discard())
+
+			err := pu.Payout(context.Background(), ownerId, testCase.amount)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPaymentUsecase_Reconcile(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	first, second := uint(1), uint(2)
+	repo := mock_paymentUsecase.NewMockPaymentRepository(c)
+	repo.EXPECT().FindAccountBalances(gomock.Any()).Return([]models.AccountBalance{
+		{AccountId: 1, Code: entities.AccountExternal, Balance: -1000},
+		{AccountId: 2, Code: entities.AccountRevenue, Balance: 100.1},
+		{AccountId: 3, Code: "wallet:1", UserId: &first, Balance: 899.9},
+		{AccountId: 4, Code: "wallet:2", UserId: &second, Balance: 0},
+	}, nil)
+	repo.EXPECT().FindUserBalances(gomock.Any()).Return([]models.User{
+		{Id: 1, Username: "foo", Balance: 899.9},
+		{Id: 2, Username: "bar", Balance: 50},
+		{Id: 3, Username: "baz", Balance: 0},
+	}, nil)
+	pu := New(repo, nil, nil, logging.Discard())
+
+	report, err := pu.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Users)
+	assert.Zero(t, report.Imbalance)
+	assert.Equal(t, map[string]float64{entities.AccountExternal: -1000, entities.AccountRevenue: 100.1}, report.SystemAccounts)
+	assert.Equal(t, []entities.BalanceMismatch{
+		{UserId: 2, Username: "bar", Balance: 50, LedgerBalance: 0, Difference: 50},
+	}, report.Mismatches)
+}
+
+func TestPaymentUsecase_HandleWebhook(t *testing.T) {
+	type mockBehavior func(r *mock_paymentUsecase.MockPaymentRepository, intentId string)
+	userId := uint(3)
+	external := models.LedgerAccount{Id: 1, Code: entities.AccountExternal}
+	wallet := models.LedgerAccount{Id: 10, Code: "wallet:3", UserId: &userId}
+	pending := func(intentId string) models.TopUp {
+		return models.TopUp{Id: 5, UserId: userId, Amount: 500, Status: entities.TopUpPending, Gateway: "fake", IntentId: &intentId}
+	}
+
+	testTable := []struct {
+		name         string
+		status       payments.Status
+		tamper       bool
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:   "Succeeded",
+			status: payments.Succeeded,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository, intentId string) {
+				r.EXPECT().FindTopUpByIntentForUpdate(gomock.Any(), "fake", intentId).Return(pending(intentId), nil)
+				r.EXPECT().FindSystemAccount(gomock.Any(), entities.AccountExternal).Return(external, nil)
+				r.EXPECT().FindWalletAccount(gomock.Any(), userId).Return(wallet, nil)
+				r.EXPECT().CreateJournalEntry(gomock.Any(), models.NewTransfer(entities.EntryTopUp, external.Id, wallet.Id, 500, "top-up via fake")).
+					Return(models.JournalEntry{Id: 1}, nil)
+				r.EXPECT().SaveTopUp(gomock.Any(), gomock.Any()).Do(func(_ context.Context, topUp models.TopUp) {
+					assert.Equal(t, entities.TopUpSucceeded, topUp.Status)
+				})
+			},
+		},
+		{
+			name:   "Failed",
+			status: payments.Failed,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository, intentId string) {
+				r.EXPECT().FindTopUpByIntentForUpdate(gomock.Any(), "fake", intentId).Return(pending(intentId), nil)
+				r.EXPECT().SaveTopUp(gomock.Any(), gomock.Any()).Do(func(_ context.Context, topUp models.TopUp) {
+					assert.Equal(t, entities.TopUpFailed, topUp.Status)
+					assert.Equal(t, "card declined", topUp.FailureReason)
+				})
+			},
+		},
+		{
+			name:   "Duplicate webhook",
+			status: payments.Succeeded,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository, intentId string) {
+				topUp := pending(intentId)
+				topUp.Status = entities.TopUpSucceeded
+				r.EXPECT().FindTopUpByIntentForUpdate(gomock.Any(), "fake", intentId).Return(topUp, nil)
+			},
+		},
+		{
+			name:         "Invalid signature",
+			status:       payments.Succeeded,
+			tamper:       true,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository, intentId string) {},
+			expectedErr:  entities.ErrUnauthorized,
+		},
+		{
+			name:   "Unknown intent",
+			status: payments.Succeeded,
+			mockBehavior: func(r *mock_paymentUsecase.MockPaymentRepository, intentId string) {
+				r.EXPECT().FindTopUpByIntentForUpdate(gomock.Any(), "fake", intentId).Return(models.TopUp{}, entities.ErrNotFound)
+			},
+			expectedErr: entities.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			gateway := payments.NewFakeGateway("secret", "")
+			intent, err := gateway.CreateIntent(500, "5")
+			assert.NoError(t, err)
+			header, body, err := gateway.SignedEvent(intent.Id, testCase.status, "card declined")
+			assert.NoError(t, err)
+			if testCase.tamper {
+				body = []byte(strings.Replace(string(body), "500", "5000", 1))
+			}
+
+			repo := mock_paymentUsecase.NewMockPaymentRepository(c)
+			testCase.mockBehavior(repo, intent.Id)
+			pu := New(repo, func(ctx context.Context, fn func(r PaymentRepository) error) error {
+				return fn(repo)
+			}, gateway, logging.Discard())
+
+			err = pu.HandleWebhook(context.Background(), header, body)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPaymentUsecase_NoGateway(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	// repository is not called when the gateway is not configured
+	repo := mock_paymentUsecase.NewMockPaymentRepository(c)
+	pu := New(repo, nil, nil, logging.Discard())
+
+	_, err := pu.CreateTopUp(context.Background(), 1, 500)
+	assert.ErrorIs(t, err, entities.ErrPaymentGateway)
+	err = pu.HandleWebhook(context.Background(), nil, []byte("{}"))
+	assert.ErrorIs(t, err, entities.ErrUnauthorized)
+	_, err = pu.SimulateTopUp(context.Background(), 1, 5, payments.Succeeded, "")
+	assert.ErrorIs(t, err, entities.ErrForbidden)
+}
+
+func TestPaymentUsecase_GetDebtors(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	since := time.Now().Add(-time.Hour)
+	repo := mock_paymentUsecase.NewMockPaymentRepository(c)
+	page := pagination.Request{Limit: 2, Sort: pagination.Sort{Field: "debt", Desc: true}}
+	// the third debtor is on the next page, but its debt is in the total
+	repo.EXPECT().FindDebtors(gomock.Any(), page).Return([]models.User{
+		{Id: 2, Username: "bar", Balance: -150.5, DebtSince: &since},
+		{Id: 1, Username: "foo", Balance: -20},
+	}, pagination.Meta{NextCursor: "next", Total: 3}, nil)
+	repo.EXPECT().FindTotalDebt(gomock.Any()).Return(180.5, nil)
+	pu := New(repo, nil, nil, logging.Discard())
+
+	report, meta, err := pu.GetDebtors(context.Background(), page)
+	assert.NoError(t, err)
+	assert.Equal(t, pagination.Meta{NextCursor: "next", Total: 3}, meta)
+	assert.Equal(t, 180.5, report.TotalDebt)
+	assert.Equal(t, []entities.Debtor{
+		{UserId: 2, Username: "bar", Debt: 150.5, DebtSince: since},
+		{UserId: 1, Username: "foo", Debt: 20},
+	}, report.Debtors)
+}
@@ -0,0 +1,187 @@
+package paymentUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/payments"
+	"strconv"
+)
+
+// CreateTopUp registers payment of amount at the gateway,
+// balance is credited when the gateway confirms it by webhook
+func (pu PaymentUsecase) CreateTopUp(ctx context.Context, userId uint, amount float64) (entities.TopUp, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.CreateTopUp")
+	defer span.End()
+	op := "paymentUsecase.CreateTopUp()"
+	if amount <= 0 || math.IsInf(amount, 0) || math.IsNaN(amount) || roundMoney(amount) != amount {
+		return entities.TopUp{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid amount", entities.FieldError{Field: "amount", Message: "must be positive with at most 2 decimal places"})
+	}
+	if pu.gw == nil {
+		return entities.TopUp{}, entities.NewPaymentGatewayError("payment gateway is not configured")
+	}
+	if _, err := pu.findUser(ctx, userId); err != nil {
+		return entities.TopUp{}, err
+	}
+
+	topUp, err := pu.r.CreateTopUp(ctx, models.TopUp{
+		UserId:  userId,
+		Amount:  amount,
+		Status:  entities.TopUpPending,
+		Gateway: pu.gw.Name(),
+	})
+	if err != nil {
+		return entities.TopUp{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	intent, gwErr := pu.gw.CreateIntent(amount, strconv.FormatUint(uint64(topUp.Id), 10))
+	if gwErr != nil {
+		topUp.Status = entities.TopUpFailed
+		topUp.FailureReason = "gateway is unavailable"
+	} else {
+		topUp.IntentId = &intent.Id
+	}
+	if err := pu.r.SaveTopUp(ctx, topUp); err != nil {
+		return entities.TopUp{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if gwErr != nil {
+		return entities.TopUp{}, fmt.Errorf("%s: %w: %w", op, entities.NewPaymentGatewayError("failed to create payment"), gwErr)
+	}
+
+	topUpEntitie := dto.TopUpModelToEntitie(topUp)
+	topUpEntitie.ConfirmationURL = intent.ConfirmationURL
+	return topUpEntitie, nil
+}
+
+func (pu PaymentUsecase) GetTopUp(ctx context.Context, userId, id uint) (entities.TopUp, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.GetTopUp")
+	defer span.End()
+	topUp, err := pu.findTopUp(ctx, userId, id)
+	if err != nil {
+		return entities.TopUp{}, err
+	}
+	return dto.TopUpModelToEntitie(topUp), nil
+}
+
+// HandleWebhook verifies the gateway callback and completes the top-up.
+// Top-up is completed once, repeated webhooks are accepted and ignored.
+func (pu PaymentUsecase) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.HandleWebhook")
+	defer span.End()
+	op := "paymentUsecase.HandleWebhook()"
+	if pu.gw == nil {
+		return entities.NewUnauthorizedError(entities.CodeWebhookInvalid, "payment gateway is not configured")
+	}
+	event, err := pu.gw.ParseWebhook(header, body)
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		pu.log.WarnContext(ctx, "webhook with invalid signature is rejected", slog.String("gateway", pu.gw.Name()))
+		return entities.NewUnauthorizedError(entities.CodeWebhookInvalid, "invalid webhook signature")
+	}
+	if err != nil {
+		return entities.NewValidationError(entities.CodeWebhookInvalid, "invalid webhook event")
+	}
+
+	var (
+		topUp     models.TopUp
+		completed bool
+	)
+	err = pu.tx(ctx, func(r PaymentRepository) error {
+		var err error
+		topUp, err = r.FindTopUpByIntentForUpdate(ctx, pu.gw.Name(), event.IntentId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTopUpNotFound, "top-up is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if topUp.Status != entities.TopUpPending {
+			return nil
+		}
+
+		switch event.Status {
+		case payments.Succeeded:
+			if event.Amount != topUp.Amount {
+				return entities.NewValidationError(entities.CodeWebhookInvalid, "amount of webhook does not match top-up")
+			}
+			external, err := r.FindSystemAccount(ctx, entities.AccountExternal)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			wallet, err := r.FindWalletAccount(ctx, topUp.UserId)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			entry := models.NewTransfer(entities.EntryTopUp, external.Id, wallet.Id, topUp.Amount, "top-up via "+topUp.Gateway)
+			if _, err := r.CreateJournalEntry(ctx, entry); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			topUp.Status = entities.TopUpSucceeded
+		case payments.Failed:
+			topUp.Status = entities.TopUpFailed
+			topUp.FailureReason = event.FailureReason
+		}
+
+		if err := r.SaveTopUp(ctx, topUp); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if completed {
+		pu.log.InfoContext(ctx, "top-up is completed",
+			slog.Uint64("top_up_id", uint64(topUp.Id)),
+			slog.Uint64("user_id", uint64(topUp.UserId)),
+			slog.String("status", topUp.Status),
+			slog.Float64("amount", topUp.Amount),
+		)
+	}
+	return nil
+}
+
+// SimulateTopUp completes pending top-up of the user when the gateway is local fake
+func (pu PaymentUsecase) SimulateTopUp(ctx context.Context, userId, id uint, status payments.Status, failureReason string) (entities.TopUp, error) {
+	ctx, span := tracer.Start(ctx, "paymentUsecase.SimulateTopUp")
+	defer span.End()
+	op := "paymentUsecase.SimulateTopUp()"
+	simulator, ok := pu.gw.(payments.Simulator)
+	if !ok {
+		return entities.TopUp{}, entities.NewForbiddenError(entities.CodeSimulationUnsupported, "payment gateway does not support simulation")
+	}
+	if status != payments.Succeeded && status != payments.Failed {
+		return entities.TopUp{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid status", entities.FieldError{Field: "status", Message: "must be succeeded or failed"})
+	}
+	topUp, err := pu.findTopUp(ctx, userId, id)
+	if err != nil {
+		return entities.TopUp{}, err
+	}
+	if topUp.Status != entities.TopUpPending || topUp.IntentId == nil {
+		return dto.TopUpModelToEntitie(topUp), nil
+	}
+
+	if err := simulator.Complete(*topUp.IntentId, status, failureReason); err != nil {
+		return entities.TopUp{}, fmt.Errorf("%s: %w: %w", op, entities.NewPaymentGatewayError("failed to deliver webhook"), err)
+	}
+	return pu.GetTopUp(ctx, userId, id)
+}
+
+func (pu PaymentUsecase) findTopUp(ctx context.Context, userId, id uint) (models.TopUp, error) {
+	op := "paymentUsecase.findTopUp()"
+	topUp, err := pu.r.FindTopUpById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) || (err == nil && topUp.UserId != userId) {
+		return models.TopUp{}, entities.NewNotFoundError(entities.CodeTopUpNotFound, "top-up is not exist")
+	}
+	if err != nil {
+		return models.TopUp{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return topUp, nil
+}
@@ -0,0 +1,156 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pricingUsecase.go
+
+// Package mock_pricingUsecase is a generated GoMock package.
+package mock_pricingUsecase
+
+import (
+	context "context"
+	reflect "reflect"
+	models "simbirGo/internal/database/models"
+	pagination "simbirGo/internal/pagination"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPricingRepository is a mock of PricingRepository interface.
+type MockPricingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPricingRepositoryMockRecorder
+}
+
+// MockPricingRepositoryMockRecorder is the mock recorder for MockPricingRepository.
+type MockPricingRepositoryMockRecorder struct {
+	mock *MockPricingRepository
+}
+
+// NewMockPricingRepository creates a new mock instance.
+func NewMockPricingRepository(ctrl *gomock.Controller) *MockPricingRepository {
+	mock := &MockPricingRepository{ctrl: ctrl}
+	mock.recorder = &MockPricingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPricingRepository) EXPECT() *MockPricingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePricingPolicy mocks base method.
+func (m *MockPricingRepository) CreatePricingPolicy(ctx context.Context, policy models.PricingPolicy) (models.PricingPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePricingPolicy", ctx, policy)
+	ret0, _ := ret[0].(models.PricingPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePricingPolicy indicates an expected call of CreatePricingPolicy.
+func (mr *MockPricingRepositoryMockRecorder) CreatePricingPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePricingPolicy", reflect.TypeOf((*MockPricingRepository)(nil).CreatePricingPolicy), ctx, policy)
+}
+
+// DeletePricingPolicy mocks base method.
+func (m *MockPricingRepository) DeletePricingPolicy(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePricingPolicy", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePricingPolicy indicates an expected call of DeletePricingPolicy.
+func (mr *MockPricingRepositoryMockRecorder) DeletePricingPolicy(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePricingPolicy", reflect.TypeOf((*MockPricingRepository)(nil).DeletePricingPolicy), ctx, id)
+}
+
+// FindPricingPolicies mocks base method.
+func (m *MockPricingRepository) FindPricingPolicies(ctx context.Context, page pagination.Request) ([]models.PricingPolicy, pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPricingPolicies", ctx, page)
+	ret0, _ := ret[0].([]models.PricingPolicy)
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindPricingPolicies indicates an expected call of FindPricingPolicies.
+func (mr *MockPricingRepositoryMockRecorder) FindPricingPolicies(ctx, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPricingPolicies", reflect.TypeOf((*MockPricingRepository)(nil).FindPricingPolicies), ctx, page)
+}
+
+// FindPricingPolicyById mocks base method.
+func (m *MockPricingRepository) FindPricingPolicyById(ctx context.Context, id uint) (models.PricingPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPricingPolicyById", ctx, id)
+	ret0, _ := ret[0].(models.PricingPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPricingPolicyById indicates an expected call of FindPricingPolicyById.
+func (mr *MockPricingRepositoryMockRecorder) FindPricingPolicyById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPricingPolicyById", reflect.TypeOf((*MockPricingRepository)(nil).FindPricingPolicyById), ctx, id)
+}
+
+// FindTranspot mocks base method.
+func (m *MockPricingRepository) FindTranspot(ctx context.Context, id uint) (models.Transport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTranspot", ctx, id)
+	ret0, _ := ret[0].(models.Transport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTranspot indicates an expected call of FindTranspot.
+func (mr *MockPricingRepositoryMockRecorder) FindTranspot(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTranspot", reflect.TypeOf((*MockPricingRepository)(nil).FindTranspot), ctx, id)
+}
+
+// FindTypeById mocks base method.
+func (m *MockPricingRepository) FindTypeById(ctx context.Context, id uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTypeById", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTypeById indicates an expected call of FindTypeById.
+func (mr *MockPricingRepositoryMockRecorder) FindTypeById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTypeById", reflect.TypeOf((*MockPricingRepository)(nil).FindTypeById), ctx, id)
+}
+
+// FindTypeByName mocks base method.
+func (m *MockPricingRepository) FindTypeByName(ctx context.Context, typeName string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTypeByName", ctx, typeName)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTypeByName indicates an expected call of FindTypeByName.
+func (mr *MockPricingRepositoryMockRecorder) FindTypeByName(ctx, typeName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTypeByName", reflect.TypeOf((*MockPricingRepository)(nil).FindTypeByName), ctx, typeName)
+}
+
+// SavePricingPolicy mocks base method.
+func (m *MockPricingRepository) SavePricingPolicy(ctx context.Context, policy models.PricingPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePricingPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePricingPolicy indicates an expected call of SavePricingPolicy.
+func (mr *MockPricingRepositoryMockRecorder) SavePricingPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePricingPolicy", reflect.TypeOf((*MockPricingRepository)(nil).SavePricingPolicy), ctx, policy)
+}
@@ -0,0 +1,208 @@
+package pricingUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("simbirGo/internal/usecase/pricingUsecase")
+
+//go:generate mockgen -source=pricingUsecase.go -destination=mock/mock.go
+
+type PricingRepository interface {
+	FindPricingPolicies(ctx context.Context, page pagination.Request) ([]models.PricingPolicy, pagination.Meta, error)
+	FindPricingPolicyById(ctx context.Context, id uint) (models.PricingPolicy, error)
+	CreatePricingPolicy(ctx context.Context, policy models.PricingPolicy) (models.PricingPolicy, error)
+	SavePricingPolicy(ctx context.Context, policy models.PricingPolicy) error
+	DeletePricingPolicy(ctx context.Context, id uint) error
+	FindTypeById(ctx context.Context, id uint) (string, error)
+	FindTypeByName(ctx context.Context, typeName string) (uint, error)
+	FindTranspot(ctx context.Context, id uint) (models.Transport, error)
+}
+
+type PricingUsecase struct {
+	r PricingRepository
+}
+
+func New(r PricingRepository) PricingUsecase {
+	return PricingUsecase{r: r}
+}
+
+func (pu PricingUsecase) GetPolicies(ctx context.Context, page pagination.Request) ([]entities.PricingPolicy, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "pricingUsecase.GetPolicies")
+	defer span.End()
+	op := "pricingUsecase.GetPolicies()"
+	policyModels, meta, err := pu.r.FindPricingPolicies(ctx, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	policies := make([]entities.PricingPolicy, 0, len(policyModels))
+	for _, policy := range policyModels {
+		policyEntitie, err := pu.policyToEntitie(ctx, policy)
+		if err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		policies = append(policies, policyEntitie)
+	}
+	return policies, meta, nil
+}
+
+func (pu PricingUsecase) GetPolicy(ctx context.Context, id uint) (entities.PricingPolicy, error) {
+	ctx, span := tracer.Start(ctx, "pricingUsecase.GetPolicy")
+	defer span.End()
+	policy, err := pu.findPolicy(ctx, id)
+	if err != nil {
+		return entities.PricingPolicy{}, err
+	}
+	return pu.policyToEntitie(ctx, policy)
+}
+
+func (pu PricingUsecase) CreatePolicy(ctx context.Context, policy entities.PricingPolicy) (entities.PricingPolicy, error) {
+	ctx, span := tracer.Start(ctx, "pricingUsecase.CreatePolicy")
+	defer span.End()
+	op := "pricingUsecase.CreatePolicy()"
+	policyModel, err := pu.validatePolicy(ctx, policy)
+	if err != nil {
+		return entities.PricingPolicy{}, err
+	}
+	policyModel, err = pu.r.CreatePricingPolicy(ctx, policyModel)
+	if errors.Is(err, entities.ErrConflict) {
+		return entities.PricingPolicy{}, entities.NewConflictError(entities.CodePricingPolicyTaken, "pricing policy is already attached")
+	}
+	if err != nil {
+		return entities.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+	}
+	policy.Id = policyModel.Id
+	return policy, nil
+}
+
+func (pu PricingUsecase) UpdatePolicy(ctx context.Context, policy entities.PricingPolicy) (entities.PricingPolicy, error) {
+	ctx, span := tracer.Start(ctx, "pricingUsecase.UpdatePolicy")
+	defer span.End()
+	op := "pricingUsecase.UpdatePolicy()"
+	if _, err := pu.findPolicy(ctx, policy.Id); err != nil {
+		return entities.PricingPolicy{}, err
+	}
+	policyModel, err := pu.validatePolicy(ctx, policy)
+	if err != nil {
+		return entities.PricingPolicy{}, err
+	}
+	err = pu.r.SavePricingPolicy(ctx, policyModel)
+	if errors.Is(err, entities.ErrConflict) {
+		return entities.PricingPolicy{}, entities.NewConflictError(entities.CodePricingPolicyTaken, "pricing policy is already attached")
+	}
+	if err != nil {
+		return entities.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return policy, nil
+}
+
+func (pu PricingUsecase) DeletePolicy(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "pricingUsecase.DeletePolicy")
+	defer span.End()
+	op := "pricingUsecase.DeletePolicy()"
+	err := pu.r.DeletePricingPolicy(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodePricingPolicyNotFound, "pricing policy is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// validatePolicy checks options of the policy and where it is attached
+func (pu PricingUsecase) validatePolicy(ctx context.Context, policy entities.PricingPolicy) (models.PricingPolicy, error) {
+	op := "pricingUsecase.validatePolicy()"
+	if (policy.TransportType == "") == (policy.TransportId == nil) {
+		return models.PricingPolicy{}, entities.NewValidationError(entities.CodeValidationFailed, "policy must be attached to transport type or transport",
+			entities.FieldError{Field: "transportType", Message: "exactly one of transportType and transportId must be set"})
+	}
+	var fields []entities.FieldError
+	for field, value := range map[string]float64{
+		"unlockFee":         policy.UnlockFee,
+		"minimumCharge":     policy.MinimumCharge,
+		"freeMinutes":       float64(policy.FreeMinutes),
+		"dailyCap":          policy.DailyCap,
+		"nightMultiplier":   policy.NightMultiplier,
+		"weekendMultiplier": policy.WeekendMultiplier,
+		"minutesHold":       policy.MinutesHold,
+		"daysHold":          policy.DaysHold,
+	} {
+		if value < 0 {
+			fields = append(fields, entities.FieldError{Field: field, Message: "must not be negative"})
+		}
+	}
+	for field, value := range map[string]int{"nightStart": policy.NightStart, "nightEnd": policy.NightEnd} {
+		if value < 0 || value > 23 {
+			fields = append(fields, entities.FieldError{Field: field, Message: "must be an hour from 0 to 23"})
+		}
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return models.PricingPolicy{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid pricing options", fields...)
+	}
+	if policy.Timezone != "" {
+		if _, err := time.LoadLocation(policy.Timezone); err != nil {
+			return models.PricingPolicy{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid timezone",
+				entities.FieldError{Field: "timezone", Message: "must be IANA timezone name"})
+		}
+	}
+
+	var typeId *uint
+	if policy.TransportType != "" {
+		id, err := pu.r.FindTypeByName(ctx, policy.TransportType)
+		if errors.Is(err, entities.ErrNotFound) {
+			return models.PricingPolicy{}, entities.NewValidationError(entities.CodeValidationFailed, fmt.Sprintf("invalid transport type: %s", policy.TransportType),
+				entities.FieldError{Field: "transportType", Message: "must be one of Car, Bike, Scooter"})
+		}
+		if err != nil {
+			return models.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+		}
+		typeId = &id
+	}
+	if policy.TransportId != nil {
+		_, err := pu.r.FindTranspot(ctx, *policy.TransportId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return models.PricingPolicy{}, entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return models.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return dto.PricingPolicyEntitieToModel(policy, typeId), nil
+}
+
+func (pu PricingUsecase) findPolicy(ctx context.Context, id uint) (models.PricingPolicy, error) {
+	op := "pricingUsecase.findPolicy()"
+	policy, err := pu.r.FindPricingPolicyById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.PricingPolicy{}, entities.NewNotFoundError(entities.CodePricingPolicyNotFound, "pricing policy is not exist")
+	}
+	if err != nil {
+		return models.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return policy, nil
+}
+
+func (pu PricingUsecase) policyToEntitie(ctx context.Context, policy models.PricingPolicy) (entities.PricingPolicy, error) {
+	op := "pricingUsecase.policyToEntitie()"
+	var typeStr string
+	if policy.TransportTypeId != nil {
+		var err error
+		typeStr, err = pu.r.FindTypeById(ctx, *policy.TransportTypeId)
+		if err != nil {
+			return entities.PricingPolicy{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return dto.PricingPolicyModelToEntitie(policy, typeStr), nil
+}
@@ -0,0 +1,91 @@
+package pricingUsecase
+
+import (
+	"context"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pricing"
+	mock_pricingUsecase "simbirGo/internal/usecase/pricingUsecase/mock"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingUsecase_CreatePolicy(t *testing.T) {
+	type mockBehavior func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy)
+	transportId := uint(5)
+	carId := uint(1)
+
+	testTable := []struct {
+		name         string
+		policy       entities.PricingPolicy
+		mockBehavior mockBehavior
+		expectedErr  error
+	}{
+		{
+			name:   "Transport type policy",
+			policy: entities.PricingPolicy{Name: "cars", TransportType: "Car", Policy: pricing.Policy{UnlockFee: 50}},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {
+				r.EXPECT().FindTypeByName(gomock.Any(), "Car").Return(carId, nil)
+				r.EXPECT().CreatePricingPolicy(gomock.Any(), models.PricingPolicy{Name: "cars", TransportTypeId: &carId, Policy: policy.Policy}).
+					Return(models.PricingPolicy{Id: 1}, nil)
+			},
+		},
+		{
+			name:   "Transport policy",
+			policy: entities.PricingPolicy{Name: "premium", TransportId: &transportId, Policy: pricing.Policy{DailyCap: 3000}},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {
+				r.EXPECT().FindTranspot(gomock.Any(), transportId).Return(models.Transport{Id: transportId}, nil)
+				r.EXPECT().CreatePricingPolicy(gomock.Any(), models.PricingPolicy{Name: "premium", TransportId: &transportId, Policy: policy.Policy}).
+					Return(models.PricingPolicy{Id: 2}, nil)
+			},
+		},
+		{
+			name:         "Not attached",
+			policy:       entities.PricingPolicy{Name: "none"},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:         "Negative option",
+			policy:       entities.PricingPolicy{Name: "cars", TransportType: "Car", Policy: pricing.Policy{UnlockFee: -1}},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:         "Invalid timezone",
+			policy:       entities.PricingPolicy{Name: "cars", TransportType: "Car", Policy: pricing.Policy{Timezone: "Mars/Olympus"}},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:   "Type already has policy",
+			policy: entities.PricingPolicy{Name: "cars", TransportType: "Car"},
+			mockBehavior: func(r *mock_pricingUsecase.MockPricingRepository, policy entities.PricingPolicy) {
+				r.EXPECT().FindTypeByName(gomock.Any(), "Car").Return(carId, nil)
+				r.EXPECT().CreatePricingPolicy(gomock.Any(), gomock.Any()).Return(models.PricingPolicy{}, entities.ErrConflict)
+			},
+			expectedErr: entities.ErrConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_pricingUsecase.NewMockPricingRepository(c)
+			testCase.mockBehavior(repo, testCase.policy)
+			pu := New(repo)
+
+			policy, err := pu.CreatePolicy(context.Background(), testCase.policy)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotZero(t, policy.Id)
+		})
+	}
+}
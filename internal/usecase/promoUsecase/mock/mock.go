@@ -0,0 +1,156 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: promoUsecase.go
+
+// Package mock_promoUsecase is a generated GoMock package.
+package mock_promoUsecase
+
+import (
+	context "context"
+	reflect "reflect"
+	models "simbirGo/internal/database/models"
+	pagination "simbirGo/internal/pagination"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPromoRepository is a mock of PromoRepository interface.
+type MockPromoRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromoRepositoryMockRecorder
+}
+
+// MockPromoRepositoryMockRecorder is the mock recorder for MockPromoRepository.
+type MockPromoRepositoryMockRecorder struct {
+	mock *MockPromoRepository
+}
+
+// NewMockPromoRepository creates a new mock instance.
+func NewMockPromoRepository(ctrl *gomock.Controller) *MockPromoRepository {
+	mock := &MockPromoRepository{ctrl: ctrl}
+	mock.recorder = &MockPromoRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromoRepository) EXPECT() *MockPromoRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountPromoCodeUses mocks base method.
+func (m *MockPromoRepository) CountPromoCodeUses(ctx context.Context, promoCodeId, userId uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPromoCodeUses", ctx, promoCodeId, userId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPromoCodeUses indicates an expected call of CountPromoCodeUses.
+func (mr *MockPromoRepositoryMockRecorder) CountPromoCodeUses(ctx, promoCodeId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPromoCodeUses", reflect.TypeOf((*MockPromoRepository)(nil).CountPromoCodeUses), ctx, promoCodeId, userId)
+}
+
+// CreatePromoCode mocks base method.
+func (m *MockPromoRepository) CreatePromoCode(ctx context.Context, promoCode models.PromoCode) (models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromoCode", ctx, promoCode)
+	ret0, _ := ret[0].(models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromoCode indicates an expected call of CreatePromoCode.
+func (mr *MockPromoRepositoryMockRecorder) CreatePromoCode(ctx, promoCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockPromoRepository)(nil).CreatePromoCode), ctx, promoCode)
+}
+
+// DeletePromoCode mocks base method.
+func (m *MockPromoRepository) DeletePromoCode(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePromoCode", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePromoCode indicates an expected call of DeletePromoCode.
+func (mr *MockPromoRepositoryMockRecorder) DeletePromoCode(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromoCode", reflect.TypeOf((*MockPromoRepository)(nil).DeletePromoCode), ctx, id)
+}
+
+// FindPromoCodeById mocks base method.
+func (m *MockPromoRepository) FindPromoCodeById(ctx context.Context, id uint) (models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPromoCodeById", ctx, id)
+	ret0, _ := ret[0].(models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPromoCodeById indicates an expected call of FindPromoCodeById.
+func (mr *MockPromoRepositoryMockRecorder) FindPromoCodeById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPromoCodeById", reflect.TypeOf((*MockPromoRepository)(nil).FindPromoCodeById), ctx, id)
+}
+
+// FindPromoCodes mocks base method.
+func (m *MockPromoRepository) FindPromoCodes(ctx context.Context, page pagination.Request) ([]models.PromoCode, pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPromoCodes", ctx, page)
+	ret0, _ := ret[0].([]models.PromoCode)
+	ret1, _ := ret[1].(pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindPromoCodes indicates an expected call of FindPromoCodes.
+func (mr *MockPromoRepositoryMockRecorder) FindPromoCodes(ctx, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPromoCodes", reflect.TypeOf((*MockPromoRepository)(nil).FindPromoCodes), ctx, page)
+}
+
+// FindTypeById mocks base method.
+func (m *MockPromoRepository) FindTypeById(ctx context.Context, id uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTypeById", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTypeById indicates an expected call of FindTypeById.
+func (mr *MockPromoRepositoryMockRecorder) FindTypeById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTypeById", reflect.TypeOf((*MockPromoRepository)(nil).FindTypeById), ctx, id)
+}
+
+// FindTypeByName mocks base method.
+func (m *MockPromoRepository) FindTypeByName(ctx context.Context, typeName string) (uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTypeByName", ctx, typeName)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTypeByName indicates an expected call of FindTypeByName.
+func (mr *MockPromoRepositoryMockRecorder) FindTypeByName(ctx, typeName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTypeByName", reflect.TypeOf((*MockPromoRepository)(nil).FindTypeByName), ctx, typeName)
+}
+
+// SavePromoCode mocks base method.
+func (m *MockPromoRepository) SavePromoCode(ctx context.Context, promoCode models.PromoCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePromoCode", ctx, promoCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePromoCode indicates an expected call of SavePromoCode.
+func (mr *MockPromoRepositoryMockRecorder) SavePromoCode(ctx, promoCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePromoCode", reflect.TypeOf((*MockPromoRepository)(nil).SavePromoCode), ctx, promoCode)
+}
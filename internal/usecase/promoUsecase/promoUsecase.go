@@ -0,0 +1,196 @@
+package promoUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/pricing"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("simbirGo/internal/usecase/promoUsecase")
+
+//go:generate mockgen -source=promoUsecase.go -destination=mock/mock.go
+
+type PromoRepository interface {
+	FindPromoCodes(ctx context.Context, page pagination.Request) ([]models.PromoCode, pagination.Meta, error)
+	FindPromoCodeById(ctx context.Context, id uint) (models.PromoCode, error)
+	CountPromoCodeUses(ctx context.Context, promoCodeId, userId uint) (int64, error)
+	CreatePromoCode(ctx context.Context, promoCode models.PromoCode) (models.PromoCode, error)
+	SavePromoCode(ctx context.Context, promoCode models.PromoCode) error
+	DeletePromoCode(ctx context.Context, id uint) error
+	FindTypeById(ctx context.Context, id uint) (string, error)
+	FindTypeByName(ctx context.Context, typeName string) (uint, error)
+}
+
+type PromoUsecase struct {
+	r PromoRepository
+}
+
+func New(r PromoRepository) PromoUsecase {
+	return PromoUsecase{r: r}
+}
+
+func (pu PromoUsecase) GetPromoCodes(ctx context.Context, page pagination.Request) ([]entities.PromoCode, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "promoUsecase.GetPromoCodes")
+	defer span.End()
+	op := "promoUsecase.GetPromoCodes()"
+	promoCodeModels, meta, err := pu.r.FindPromoCodes(ctx, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	promoCodes := make([]entities.PromoCode, 0, len(promoCodeModels))
+	for _, promoCode := range promoCodeModels {
+		promoCodeEntitie, err := pu.promoCodeToEntitie(ctx, promoCode)
+		if err != nil {
+			return nil, pagination.Meta{}, err
+		}
+		promoCodes = append(promoCodes, promoCodeEntitie)
+	}
+	return promoCodes, meta, nil
+}
+
+func (pu PromoUsecase) GetPromoCode(ctx context.Context, id uint) (entities.PromoCode, error) {
+	ctx, span := tracer.Start(ctx, "promoUsecase.GetPromoCode")
+	defer span.End()
+	promoCode, err := pu.findPromoCode(ctx, id)
+	if err != nil {
+		return entities.PromoCode{}, err
+	}
+	return pu.promoCodeToEntitie(ctx, promoCode)
+}
+
+func (pu PromoUsecase) CreatePromoCode(ctx context.Context, promoCode entities.PromoCode) (entities.PromoCode, error) {
+	ctx, span := tracer.Start(ctx, "promoUsecase.CreatePromoCode")
+	defer span.End()
+	op := "promoUsecase.CreatePromoCode()"
+	promoCodeModel, err := pu.validatePromoCode(ctx, promoCode)
+	if err != nil {
+		return entities.PromoCode{}, err
+	}
+	promoCodeModel, err = pu.r.CreatePromoCode(ctx, promoCodeModel)
+	if errors.Is(err, entities.ErrConflict) {
+		return entities.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeTaken, "promo code is already exist")
+	}
+	if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return pu.promoCodeToEntitie(ctx, promoCodeModel)
+}
+
+func (pu PromoUsecase) UpdatePromoCode(ctx context.Context, promoCode entities.PromoCode) (entities.PromoCode, error) {
+	ctx, span := tracer.Start(ctx, "promoUsecase.UpdatePromoCode")
+	defer span.End()
+	op := "promoUsecase.UpdatePromoCode()"
+	if _, err := pu.findPromoCode(ctx, promoCode.Id); err != nil {
+		return entities.PromoCode{}, err
+	}
+	promoCodeModel, err := pu.validatePromoCode(ctx, promoCode)
+	if err != nil {
+		return entities.PromoCode{}, err
+	}
+	err = pu.r.SavePromoCode(ctx, promoCodeModel)
+	if errors.Is(err, entities.ErrConflict) {
+		return entities.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeTaken, "promo code is already exist")
+	}
+	if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return pu.promoCodeToEntitie(ctx, promoCodeModel)
+}
+
+func (pu PromoUsecase) DeletePromoCode(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "promoUsecase.DeletePromoCode")
+	defer span.End()
+	op := "promoUsecase.DeletePromoCode()"
+	err := pu.r.DeletePromoCode(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodePromoCodeNotFound, "promo code is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (pu PromoUsecase) validatePromoCode(ctx context.Context, promoCode entities.PromoCode) (models.PromoCode, error) {
+	op := "promoUsecase.validatePromoCode()"
+	promoCode.Code = entities.NormalizePromoCode(promoCode.Code)
+
+	var fields []entities.FieldError
+	if promoCode.Code == "" {
+		fields = append(fields, entities.FieldError{Field: "code", Message: "must not be empty"})
+	}
+	switch promoCode.Kind {
+	case pricing.Percent:
+		if promoCode.Value <= 0 || promoCode.Value > 100 {
+			fields = append(fields, entities.FieldError{Field: "value", Message: "must be a percentage from 0 to 100"})
+		}
+	case pricing.Fixed:
+		if promoCode.Value <= 0 {
+			fields = append(fields, entities.FieldError{Field: "value", Message: "must be positive"})
+		}
+	default:
+		fields = append(fields, entities.FieldError{Field: "kind", Message: "must be percent or fixed"})
+	}
+	if !promoCode.ValidTo.After(promoCode.ValidFrom) {
+		fields = append(fields, entities.FieldError{Field: "validTo", Message: "must be after validFrom"})
+	}
+	if promoCode.MaxUses < 0 {
+		fields = append(fields, entities.FieldError{Field: "maxUses", Message: "must not be negative"})
+	}
+	if promoCode.MaxUsesPerUser < 0 {
+		fields = append(fields, entities.FieldError{Field: "maxUsesPerUser", Message: "must not be negative"})
+	}
+	if len(fields) > 0 {
+		return models.PromoCode{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid promo code", fields...)
+	}
+
+	typeIds := make([]uint, 0, len(promoCode.TransportTypes))
+	for _, transportType := range promoCode.TransportTypes {
+		typeId, err := pu.r.FindTypeByName(ctx, transportType)
+		if errors.Is(err, entities.ErrNotFound) {
+			return models.PromoCode{}, entities.NewValidationError(entities.CodeValidationFailed, fmt.Sprintf("invalid transport type: %s", transportType),
+				entities.FieldError{Field: "transportTypes", Message: "must be Car, Bike or Scooter"})
+		}
+		if err != nil {
+			return models.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		typeIds = append(typeIds, typeId)
+	}
+	return dto.PromoCodeEntitieToModel(promoCode, typeIds), nil
+}
+
+func (pu PromoUsecase) findPromoCode(ctx context.Context, id uint) (models.PromoCode, error) {
+	op := "promoUsecase.findPromoCode()"
+	promoCode, err := pu.r.FindPromoCodeById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.PromoCode{}, entities.NewNotFoundError(entities.CodePromoCodeNotFound, "promo code is not exist")
+	}
+	if err != nil {
+		return models.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return promoCode, nil
+}
+
+func (pu PromoUsecase) promoCodeToEntitie(ctx context.Context, promoCode models.PromoCode) (entities.PromoCode, error) {
+	op := "promoUsecase.promoCodeToEntitie()"
+	typeStrs := make([]string, 0, len(promoCode.TransportTypes))
+	for _, transportType := range promoCode.TransportTypes {
+		typeStr, err := pu.r.FindTypeById(ctx, transportType.TransportTypeId)
+		if err != nil {
+			return entities.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		typeStrs = append(typeStrs, typeStr)
+	}
+	uses, err := pu.r.CountPromoCodeUses(ctx, promoCode.Id, 0)
+	if err != nil {
+		return entities.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return dto.PromoCodeModelToEntitie(promoCode, typeStrs, uses), nil
+}
@@ -0,0 +1,93 @@
+package promoUsecase
+
+import (
+	"context"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pricing"
+	mock_promoUsecase "simbirGo/internal/usecase/promoUsecase/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoUsecase_CreatePromoCode(t *testing.T) {
+	type mockBehavior func(r *mock_promoUsecase.MockPromoRepository)
+	validFrom := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	validTo := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name         string
+		promoCode    entities.PromoCode
+		mockBehavior mockBehavior
+		expectedCode string
+		expectedErr  error
+	}{
+		{
+			name: "OK",
+			promoCode: entities.PromoCode{Code: " summer23 ", Kind: pricing.Percent, Value: 15,
+				ValidFrom: validFrom, ValidTo: validTo, TransportTypes: []string{"Bike"}},
+			mockBehavior: func(r *mock_promoUsecase.MockPromoRepository) {
+				r.EXPECT().FindTypeByName(gomock.Any(), "Bike").Return(uint(2), nil)
+				r.EXPECT().CreatePromoCode(gomock.Any(), models.PromoCode{Code: "SUMMER23", Kind: "percent", Value: 15,
+					ValidFrom: validFrom, ValidTo: validTo,
+					TransportTypes: []models.PromoCodeTransportType{{TransportTypeId: 2}}}).
+					DoAndReturn(func(_ context.Context, p models.PromoCode) (models.PromoCode, error) {
+						p.Id = 1
+						return p, nil
+					})
+				r.EXPECT().FindTypeById(gomock.Any(), uint(2)).Return("Bike", nil)
+				r.EXPECT().CountPromoCodeUses(gomock.Any(), uint(1), uint(0)).Return(int64(0), nil)
+			},
+			expectedCode: "SUMMER23",
+		},
+		{
+			name:         "Percent above 100",
+			promoCode:    entities.PromoCode{Code: "MAX", Kind: pricing.Percent, Value: 150, ValidFrom: validFrom, ValidTo: validTo},
+			mockBehavior: func(r *mock_promoUsecase.MockPromoRepository) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:         "Unknown kind",
+			promoCode:    entities.PromoCode{Code: "MAX", Kind: "gift", Value: 10, ValidFrom: validFrom, ValidTo: validTo},
+			mockBehavior: func(r *mock_promoUsecase.MockPromoRepository) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:         "Invalid dates",
+			promoCode:    entities.PromoCode{Code: "MAX", Kind: pricing.Fixed, Value: 10, ValidFrom: validTo, ValidTo: validFrom},
+			mockBehavior: func(r *mock_promoUsecase.MockPromoRepository) {},
+			expectedErr:  entities.ErrValidation,
+		},
+		{
+			name:      "Code is taken",
+			promoCode: entities.PromoCode{Code: "MAX", Kind: pricing.Fixed, Value: 10, ValidFrom: validFrom, ValidTo: validTo},
+			mockBehavior: func(r *mock_promoUsecase.MockPromoRepository) {
+				r.EXPECT().CreatePromoCode(gomock.Any(), gomock.Any()).Return(models.PromoCode{}, entities.ErrConflict)
+			},
+			expectedErr: entities.ErrConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_promoUsecase.NewMockPromoRepository(c)
+			testCase.mockBehavior(repo)
+			pu := New(repo)
+
+			promoCode, err := pu.CreatePromoCode(context.Background(), testCase.promoCode)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedCode, promoCode.Code)
+			assert.Equal(t, []string{"Bike"}, promoCode.TransportTypes)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package rentUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"time"
+)
+
+// holdAmount returns money reserved for the rent which is about to start
+func (ru RentUsecase) holdAmount(rent models.Rent, rentType string) float64 {
+	var (
+		hold   float64
+		period time.Duration
+	)
+	switch rentType {
+	case "Minutes":
+		hold, period = rent.Tariff.MinutesHold, ru.opts.MinutesHoldPeriod
+	case "Days":
+		hold, period = rent.Tariff.DaysHold, ru.opts.DaysHoldPeriod
+	}
+	if hold > 0 {
+		return roundMoney(hold)
+	}
+	return projectedPrice(rent, rentType, rent.TimeStart.Add(period))
+}
+
+// checkFunds refuses the rent if the user has debt or available balance is less than the hold
+func checkFunds(ctx context.Context, r RentRepository, userId uint, hold float64) error {
+	op := "rentUsecase.checkFunds()"
+	user, err := r.FindUserForUpdate(ctx, userId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkDebt(user); err != nil {
+		return err
+	}
+	if hold > user.Balance {
+		return entities.NewInsufficientFundsError(fmt.Sprintf("not enough money in user's balance for the hold of %.2f", hold))
+	}
+	return nil
+}
+
+// checkDebt blocks new rents and reservations until the debt is paid
+func checkDebt(user models.User) error {
+	if user.Balance < 0 {
+		return entities.NewDebtError(fmt.Sprintf("user has debt of %.2f, top up the balance to continue", -user.Balance))
+	}
+	return nil
+}
+
+// placeHold moves the hold of the created rent from the wallet to the holds account
+func placeHold(ctx context.Context, r RentRepository, rent models.Rent) error {
+	op := "rentUsecase.placeHold()"
+	if rent.Hold <= 0 {
+		return nil
+	}
+	wallet, err := r.FindWalletAccount(ctx, rent.UserId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	holds, err := r.FindSystemAccount(ctx, entities.AccountHolds)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := postRentEntry(ctx, r, rent.Id, entities.EntryHold, wallet.Id, holds.Id, rent.Hold, "hold for rent"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// moveHold returns the hold of the running rent to its user and holds the same amount from another user
+func moveHold(ctx context.Context, r RentRepository, rent models.Rent, userId uint) error {
+	op := "rentUsecase.moveHold()"
+	if err := checkFunds(ctx, r, userId, rent.Hold); err != nil {
+		return err
+	}
+	wallet, err := r.FindWalletAccount(ctx, rent.UserId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	holds, err := r.FindSystemAccount(ctx, entities.AccountHolds)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := postRentEntry(ctx, r, rent.Id, entities.EntryHoldRelease, holds.Id, wallet.Id, rent.Hold, "hold released"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rent.UserId = userId
+	return placeHold(ctx, r, rent)
+}
+
+// AutoEndRents ends running rents whose price would exceed the hold and available balance
+// before the next check, transport stays where it is. It returns number of ended rents.
+// Failure to check one rent is logged and does not stop the others.
+func (ru RentUsecase) AutoEndRents(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AutoEndRents")
+	defer span.End()
+	op := "rentUsecase.AutoEndRents()"
+	rents, err := ru.r.FindRunningRentsWithHold(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var ended int
+	for _, rent := range rents {
+		done, err := ru.autoEndRent(ctx, rent, now)
+		if err != nil {
+			ru.log.ErrorContext(ctx, "failed to end rent automatically",
+				slog.Uint64("rent_id", uint64(rent.Id)), slog.Any("error", err))
+			continue
+		}
+		if done {
+			ru.log.InfoContext(ctx, "rent is ended automatically: available money is exceeded", slog.Uint64("rent_id", uint64(rent.Id)))
+			ended++
+		}
+	}
+	return ended, nil
+}
+
+// autoEndRent ends the rent if its price would exceed the hold and available balance
+// before the next check. It returns false if the rent is left running.
+func (ru RentUsecase) autoEndRent(ctx context.Context, rent models.Rent, now time.Time) (bool, error) {
+	op := "rentUsecase.autoEndRent()"
+	rentType, err := ru.r.FindRentTypeById(ctx, rent.RentTypeId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	user, err := ru.r.FindUserById(ctx, rent.UserId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if projectedPrice(rent, rentType, now.Add(ru.opts.AutoEndInterval)) <= rent.Hold+user.Balance {
+		return false, nil
+	}
+
+	transport, err := ru.r.FindTranspot(ctx, rent.TransportId)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = ru.endRent(ctx, int(rent.Id), transport.Latitude, transport.Longitude, func(rent models.Rent) bool {
+		return true
+	})
+	if errors.Is(err, entities.ErrConflict) {
+		// the rent is ended by the user meanwhile
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, nil
+}
+
+// projectedPrice is the final price of the rent if it ends at the time
+func projectedPrice(rent models.Rent, rentType string, end time.Time) float64 {
+	rent.TimeEnd = &end
+	chargeRent(&rent, rentType)
+	return rent.FinalPrice
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
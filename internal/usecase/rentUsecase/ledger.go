@@ -0,0 +1,167 @@
+package rentUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+)
+
+// postRentCharge records payment of the ended rent. The hold is captured up to the final price
+// and the rest of it is released, the price above the hold is charged from the wallet.
+// Revenue gets the original price, the discount of promo code is paid from the promo account.
+func postRentCharge(ctx context.Context, r RentRepository, rent models.Rent) error {
+	op := "rentUsecase.postRentCharge()"
+	wallet, err := r.FindWalletAccount(ctx, rent.UserId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	revenue, err := r.FindSystemAccount(ctx, entities.AccountRevenue)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	captured := capturedHold(rent)
+	if rent.Hold > 0 {
+		holds, err := r.FindSystemAccount(ctx, entities.AccountHolds)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if captured > 0 {
+			if err := postRentEntry(ctx, r, rent.Id, entities.EntryHoldCapture, holds.Id, revenue.Id, captured, "hold captured for rent"); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if rest := roundMoney(rent.Hold - captured); rest > 0 {
+			if err := postRentEntry(ctx, r, rent.Id, entities.EntryHoldRelease, holds.Id, wallet.Id, rest, "hold released"); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+	if charge := roundMoney(rent.OriginalPrice - captured); charge > 0 {
+		if err := postRentEntry(ctx, r, rent.Id, entities.EntryRentCharge, wallet.Id, revenue.Id, charge, "payment for rent"); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if rent.Discount <= 0 {
+		return nil
+	}
+
+	promo, err := r.FindSystemAccount(ctx, entities.AccountPromo)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := postRentEntry(ctx, r, rent.Id, entities.EntryPromoCredit, promo.Id, wallet.Id, rent.Discount, "promo code discount"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// capturedHold is part of the hold paying the final price of the ended rent
+func capturedHold(rent models.Rent) float64 {
+	return math.Min(rent.Hold, rent.FinalPrice)
+}
+
+// adjustRentCharge posts the difference between the new and the old final price of the ended rent.
+// When the rent is moved to another user, the old user gets the old price back and the new user pays the new one.
+func adjustRentCharge(ctx context.Context, r RentRepository, old, rent models.Rent) error {
+	op := "rentUsecase.adjustRentCharge()"
+	charges := map[uint]float64{rent.UserId: rent.FinalPrice}
+	charges[old.UserId] -= old.FinalPrice
+
+	revenue, err := r.FindSystemAccount(ctx, entities.AccountRevenue)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, userId := range []uint{old.UserId, rent.UserId} {
+		charge := roundMoney(charges[userId])
+		delete(charges, userId)
+		if charge == 0 {
+			continue
+		}
+		wallet, err := r.FindWalletAccount(ctx, userId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		from, to, description := wallet.Id, revenue.Id, "rent price increased by admin"
+		if charge < 0 {
+			from, to, description, charge = revenue.Id, wallet.Id, "rent price decreased by admin", -charge
+		}
+		if err := postRentEntry(ctx, r, rent.Id, entities.EntryAdjustment, from, to, charge, description); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+func postRentEntry(ctx context.Context, r RentRepository, rentId uint, kind string, fromAccountId, toAccountId uint, amount float64, description string) error {
+	entry := models.NewTransfer(kind, fromAccountId, toAccountId, amount, description)
+	entry.RentId = &rentId
+	_, err := r.CreateJournalEntry(ctx, entry)
+	return err
+}
+
+// admin's usecase
+// AdminRefundRent returns the final price of the ended rent to the user, rent can be refunded once
+func (ru RentUsecase) AdminRefundRent(ctx context.Context, id int) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminRefundRent")
+	defer span.End()
+	op := "rentUsecase.AdminRefundRent()"
+	var (
+		rentModel models.Rent
+		rentType  string
+	)
+	err := ru.tx(ctx, func(r RentRepository) error {
+		var err error
+		rentModel, err = r.FindRentForUpdate(ctx, id)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if rentModel.TimeEnd == nil {
+			return entities.NewConflictError(entities.CodeRentNotEnded, "rent is not ended")
+		}
+		refunded, err := r.HasRentEntry(ctx, rentModel.Id, entities.EntryRefund)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if refunded {
+			return entities.NewConflictError(entities.CodeRentAlreadyRefunded, "rent is already refunded")
+		}
+
+		rentType, err = r.FindRentTypeById(ctx, rentModel.RentTypeId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if rentModel.FinalPrice <= 0 {
+			return nil
+		}
+
+		revenue, err := r.FindSystemAccount(ctx, entities.AccountRevenue)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		wallet, err := r.FindWalletAccount(ctx, rentModel.UserId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := postRentEntry(ctx, r, rentModel.Id, entities.EntryRefund, revenue.Id, wallet.Id, rentModel.FinalPrice, "refund for rent"); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.Rent{}, err
+	}
+
+	ru.m.RentRefunded(rentModel.FinalPrice)
+	ru.log.InfoContext(ctx, "rent refunded", slog.Uint64("rent_id", uint64(rentModel.Id)), slog.Float64("amount", rentModel.FinalPrice))
+
+	return dto.RentModelToEntitie(rentModel, rentType), nil
+}
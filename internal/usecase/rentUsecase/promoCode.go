@@ -0,0 +1,59 @@
+package rentUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"time"
+)
+
+// findPromoCode returns promo code which can be attached to the rent of the user.
+// The promo code row is locked, so concurrent rents can not exceed its limits.
+func findPromoCode(ctx context.Context, r RentRepository, userId, typeId uint, code string, now time.Time) (models.PromoCode, error) {
+	op := "rentUsecase.findPromoCode()"
+	promoCode, err := r.FindPromoCodeForUpdate(ctx, entities.NormalizePromoCode(code))
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.PromoCode{}, entities.NewNotFoundError(entities.CodePromoCodeNotFound, "promo code is not exist")
+	}
+	if err != nil {
+		return models.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if now.Before(promoCode.ValidFrom) || !now.Before(promoCode.ValidTo) {
+		return models.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeExpired, "promo code is not valid at the moment")
+	}
+	if len(promoCode.TransportTypes) > 0 {
+		applicable := false
+		for _, transportType := range promoCode.TransportTypes {
+			if transportType.TransportTypeId == typeId {
+				applicable = true
+				break
+			}
+		}
+		if !applicable {
+			return models.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeNotApplicable, "promo code is not applicable to this transport type")
+		}
+	}
+
+	if promoCode.MaxUses > 0 {
+		uses, err := r.CountPromoCodeUses(ctx, promoCode.Id, 0)
+		if err != nil {
+			return models.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if uses >= int64(promoCode.MaxUses) {
+			return models.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeExhausted, "promo code is used up")
+		}
+	}
+	if promoCode.MaxUsesPerUser > 0 {
+		uses, err := r.CountPromoCodeUses(ctx, promoCode.Id, userId)
+		if err != nil {
+			return models.PromoCode{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if uses >= int64(promoCode.MaxUsesPerUser) {
+			return models.PromoCode{}, entities.NewConflictError(entities.CodePromoCodeExhausted, "you have already used this promo code")
+		}
+	}
+	return promoCode, nil
+}
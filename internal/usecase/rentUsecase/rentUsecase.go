@@ -1,359 +1,847 @@
 package rentUsecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"simbirGo/internal/database/models"
 	"simbirGo/internal/dto"
 	"simbirGo/internal/entities"
+	"simbirGo/internal/geo"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/pricing"
 	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("simbirGo/internal/usecase/rentUsecase")
+
 type RentRepository interface {
-	FindTypeByName(typeName string) uint
-	FindTypeById(id uint) string
-	FindAvalibleTransports(lat, long, radius float64, typeId uint) []models.Transport
-	FindUserById(id uint) models.User
-	FindTranspot(id uint) models.Transport
-	FindRentById(id int) models.Rent
-	SaveTransport(transport models.Transport)
-	CreateRent(rent models.Rent) models.Rent
-	SaveUser(user models.User)
-	FindUserRents(id int) []models.Rent
-	FindTransportRents(id int) []models.Rent
-	SaveRent(rent models.Rent)
-	DeleteRent(id int)
-	FindRentTypeById(id uint) string
-	FindRentTypeByName(typeName string) uint
-}
-
-const (
-	minuteUnix float64 = 60
-	dayUnix    float64 = 86400
-)
+	FindTypeByName(ctx context.Context, typeName string) (uint, error)
+	FindTypeById(ctx context.Context, id uint) (string, error)
+	FindUserById(ctx context.Context, id uint) (models.User, error)
+	FindTranspot(ctx context.Context, id uint) (models.Transport, error)
+	FindRentById(ctx context.Context, id int) (models.Rent, error)
+	SaveTransport(ctx context.Context, transport models.Transport) error
+	CreateRent(ctx context.Context, rent models.Rent) (models.Rent, error)
+	FindUserRents(ctx context.Context, id int, filter entities.RentFilter, page pagination.Request) ([]models.Rent, pagination.Meta, error)
+	FindTransportRents(ctx context.Context, id int, filter entities.RentFilter, page pagination.Request) ([]models.Rent, pagination.Meta, error)
+	SaveRent(ctx context.Context, rent models.Rent) error
+	DeleteRent(ctx context.Context, id int) error
+	FindRentTypeById(ctx context.Context, id uint) (string, error)
+	FindRentTypeByName(ctx context.Context, typeName string) (uint, error)
+	FindRentForUpdate(ctx context.Context, id int) (models.Rent, error)
+	FindRunningRentsWithHold(ctx context.Context) ([]models.Rent, error)
+	CountActiveRents(ctx context.Context) (int64, error)
+	CountAvailableTransports(ctx context.Context) (map[string]int64, error)
+	FindTranspotForUpdate(ctx context.Context, id uint) (models.Transport, error)
+	FindUserForUpdate(ctx context.Context, id uint) (models.User, error)
+	FindTransportPricingPolicy(ctx context.Context, transportId, typeId uint) (models.PricingPolicy, error)
+	FindPromoCodeForUpdate(ctx context.Context, code string) (models.PromoCode, error)
+	CountPromoCodeUses(ctx context.Context, promoCodeId, userId uint) (int64, error)
+
+	FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error)
+	FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error)
+	CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error)
+	HasRentEntry(ctx context.Context, rentId uint, kind string) (bool, error)
+	HasRentEntries(ctx context.Context, rentId uint) (bool, error)
+
+	FindReservationById(ctx context.Context, id uint) (models.Reservation, error)
+	FindReservationForUpdate(ctx context.Context, id uint) (models.Reservation, error)
+	FindUserReservations(ctx context.Context, userId uint, page pagination.Request) ([]models.Reservation, pagination.Meta, error)
+	FindActiveReservations(ctx context.Context, transportIds []uint, from, to time.Time) ([]models.Reservation, error)
+	CreateReservation(ctx context.Context, reservation models.Reservation) (models.Reservation, error)
+	SaveReservation(ctx context.Context, reservation models.Reservation) error
+	ExpireReservations(ctx context.Context, startedBefore time.Time) (int64, error)
+}
+
+// TransportLocator searches rentable transports within radius meters from the center,
+// results are sorted by distance
+type TransportLocator interface {
+	FindTransportsNear(ctx context.Context, center geo.Point, radius float64, typeId uint) ([]models.NearbyTransport, error)
+}
+
+// Metrics counts business events of rents
+type Metrics interface {
+	RentStarted(rentType string)
+	RentEnded(rentType string, charged float64)
+	RentRefunded(amount float64)
+}
+
+// Transactor runs fn in a database transaction,
+// repository passed to fn is bound to the transaction
+type Transactor func(ctx context.Context, fn func(r RentRepository) error) error
+
+// Options are periods and limits of rents and reservations
+type Options struct {
+	// ReservationGracePeriod is how long after the start a reservation waits to be converted into rent.
+	// The transport is held for the reservation the same time before the start.
+	ReservationGracePeriod time.Duration
+	// MaxReservationDuration limits length of the reserved window
+	MaxReservationDuration time.Duration
+	// MaxReservationAdvance limits how far in the future reservation can start
+	MaxReservationAdvance time.Duration
+	// AutoEndInterval is how often running rents are checked by AutoEndRents
+	AutoEndInterval time.Duration
+	// MinutesHoldPeriod and DaysHoldPeriod are rent time whose price is held
+	// when pricing policy does not set the hold amount
+	MinutesHoldPeriod time.Duration
+	DaysHoldPeriod    time.Duration
+}
+
+// DefaultOptions are the same as defaults of the config
+func DefaultOptions() Options {
+	return Options{
+		ReservationGracePeriod: 15 * time.Minute,
+		MaxReservationDuration: 24 * time.Hour,
+		MaxReservationAdvance:  30 * 24 * time.Hour,
+		AutoEndInterval:        time.Minute,
+		MinutesHoldPeriod:      time.Hour,
+		DaysHoldPeriod:         24 * time.Hour,
+	}
+}
 
 type RentUsecase struct {
-	r RentRepository
+	r    RentRepository
+	tx   Transactor
+	l    TransportLocator
+	log  *slog.Logger
+	m    Metrics
+	opts Options
 }
 
-func New(r RentRepository) RentUsecase {
-	return RentUsecase{r: r}
+func New(r RentRepository, tx Transactor, l TransportLocator, log *slog.Logger, m Metrics, opts Options) RentUsecase {
+	return RentUsecase{r: r, tx: tx, l: l, log: log, m: m, opts: opts}
 }
 
 // user's usecase
-func (ru RentUsecase) GetAvalibleTransport(lat, long, radius float64, transportType string) ([]entities.Transport, error) {
-	typeId := ru.r.FindTypeByName(transportType)
-	if typeId == 0 && transportType != "All" {
-		return nil, fmt.Errorf("invalid transport type: %s", transportType)
+// GetAvalibleTransport returns rentable transports within radius meters, nearest first.
+// Transports held for reservations are skipped.
+func (ru RentUsecase) GetAvalibleTransport(ctx context.Context, lat, long, radius float64, transportType string) ([]entities.NearbyTransport, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetAvalibleTransport")
+	defer span.End()
+	op := "rentUsecase.GetAvalibleTransport()"
+	var typeId uint
+	if transportType != "All" {
+		var err error
+		typeId, err = ru.r.FindTypeByName(ctx, transportType)
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, entities.NewValidationError(entities.CodeValidationFailed, fmt.Sprintf("invalid transport type: %s", transportType), entities.FieldError{Field: "transportType", Message: "must be one of Car, Bike, Scooter, All"})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	found, err := ru.l.FindTransportsNear(ctx, geo.Point{Lat: lat, Long: long}, radius, typeId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	transportModels := ru.r.FindAvalibleTransports(lat, long, radius, typeId)
 
-	transportEntites := make([]entities.Transport, 0, len(transportModels))
-	for _, transport := range transportModels {
-		typeName := ru.r.FindTypeById(transport.TypeId)
-		transportEntites = append(transportEntites, dto.TransportModelToEntite(transport, typeName))
+	ids := make([]uint, 0, len(found))
+	for _, nearby := range found {
+		ids = append(ids, nearby.Transport.Id)
+	}
+	reserved, err := ru.reservedTransports(ctx, ru.r, ids, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	typeNames := make(map[uint]string)
+	transportEntites := make([]entities.NearbyTransport, 0, len(found))
+	for _, nearby := range found {
+		if _, ok := reserved[nearby.Transport.Id]; ok {
+			continue
+		}
+		typeName, ok := typeNames[nearby.Transport.TypeId]
+		if !ok {
+			typeName, err = ru.r.FindTypeById(ctx, nearby.Transport.TypeId)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			typeNames[nearby.Transport.TypeId] = typeName
+		}
+		transportEntites = append(transportEntites, entities.NearbyTransport{
+			Transport: dto.TransportModelToEntite(nearby.Transport, typeName),
+			Distance:  nearby.Distance,
+		})
 	}
 
 	return transportEntites, nil
 }
 
-func (ru RentUsecase) GetRent(rentId int, userId uint) (entities.Rent, error) {
-	rentModel := ru.r.FindRentById(rentId)
-	if rentModel.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+// GetStats returns number of active rents and available transports
+func (ru RentUsecase) GetStats(ctx context.Context) (entities.RentStats, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetStats")
+	defer span.End()
+	op := "rentUsecase.GetStats()"
+	activeRents, err := ru.r.CountActiveRents(ctx)
+	if err != nil {
+		return entities.RentStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	availableTransports, err := ru.r.CountAvailableTransports(ctx)
+	if err != nil {
+		return entities.RentStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return entities.RentStats{ActiveRents: activeRents, AvailableTransports: availableTransports}, nil
+}
+
+func (ru RentUsecase) GetRent(ctx context.Context, rentId int, userId uint) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetRent")
+	defer span.End()
+	op := "rentUsecase.GetRent()"
+	rentModel, err := ru.findRent(ctx, rentId)
+	if err != nil {
+		return entities.Rent{}, err
 	}
 
-	transport := ru.r.FindTranspot(rentModel.TransportId)
+	transport, err := ru.r.FindTranspot(ctx, rentModel.TransportId)
+	if err != nil && !errors.Is(err, entities.ErrNotFound) {
+		return entities.Rent{}, fmt.Errorf("%s: %w", op, err)
+	}
 
 	if userId != rentModel.UserId && userId != transport.OwnerId {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+		return entities.Rent{}, entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
 	}
-	rentType := ru.r.FindRentTypeById(rentModel.RentTypeId)
 
-	return dto.RentModelToEntitie(rentModel, rentType), nil
+	return ru.rentToEntitie(ctx, rentModel)
 }
 
-func (ru RentUsecase) GetUserHistory(userId uint) []entities.Rent {
-	rentModels := ru.r.FindUserRents(int(userId))
-
-	rentEntites := make([]entities.Rent, 0, len(rentModels))
-	for _, rent := range rentModels {
-		rentType := ru.r.FindRentTypeById(rent.RentTypeId)
-		rentEntites = append(rentEntites, dto.RentModelToEntitie(rent, rentType))
+func (ru RentUsecase) GetUserHistory(ctx context.Context, userId uint, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetUserHistory")
+	defer span.End()
+	op := "rentUsecase.GetUserHistory()"
+	if err := ru.checkRentFilter(ctx, filter); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	rentModels, meta, err := ru.r.FindUserRents(ctx, int(userId), filter, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return rentEntites
+	rents, err := ru.rentsToEntities(ctx, rentModels)
+	return rents, meta, err
 }
 
-func (ru RentUsecase) GetTransportHistory(userId, transportId int) ([]entities.Rent, error) {
-	transport := ru.r.FindTranspot(uint(transportId))
-	if transport.Id == 0 {
-		return nil, fmt.Errorf("transport is not exist")
+func (ru RentUsecase) GetTransportHistory(ctx context.Context, userId, transportId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetTransportHistory")
+	defer span.End()
+	transport, err := ru.findTransport(ctx, uint(transportId))
+	if err != nil {
+		return nil, pagination.Meta{}, err
 	}
 	if transport.OwnerId != uint(userId) {
-		return nil, fmt.Errorf("transport is not exist")
+		return nil, pagination.Meta{}, entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
 	}
 
-	rentModels := ru.r.FindTransportRents(transportId)
+	return ru.transportHistory(ctx, transportId, filter, page)
+}
 
-	rentEntites := make([]entities.Rent, 0, len(rentModels))
-	for _, rent := range rentModels {
-		rentType := ru.r.FindRentTypeById(rent.RentTypeId)
-		rentEntites = append(rentEntites, dto.RentModelToEntitie(rent, rentType))
+// CreateNewRent starts rent of the transport, promo code is optional
+func (ru RentUsecase) CreateNewRent(ctx context.Context, userId uint, transportId int, rentType, promoCode string) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.CreateNewRent")
+	defer span.End()
+	op := "rentUsecase.CreateNewRent()"
+	rentTypeId, err := ru.findRentType(ctx, rentType)
+	if err != nil {
+		return entities.Rent{}, err
+	}
+
+	var rent models.Rent
+	err = ru.tx(ctx, func(r RentRepository) error {
+		transport, err := r.FindTranspotForUpdate(ctx, uint(transportId))
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := ru.checkReserved(ctx, r, transport.Id, userId, time.Now()); err != nil {
+			return err
+		}
+		rent, err = ru.startRent(ctx, r, userId, transport, rentType, rentTypeId, promoCode)
+		return err
+	})
+	if err != nil {
+		return entities.Rent{}, err
 	}
 
-	return rentEntites, nil
+	ru.rentStarted(ctx, rent, rentType)
+	return dto.RentModelToEntitie(rent, rentType), nil
 }
 
-func (ru RentUsecase) CreateNewRent(userId uint, transportId int, rentType string) (entities.Rent, error) {
-	rentTypeId := ru.r.FindRentTypeByName(rentType)
-	if rentTypeId == 0 {
-		return entities.Rent{}, fmt.Errorf("type id is not exist")
-	}
-	transport := ru.r.FindTranspot(uint(transportId))
-	if transport.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("transport is not exist")
-	}
-
+// startRent creates rent of the transport locked in the transaction and holds money for it
+func (ru RentUsecase) startRent(ctx context.Context, r RentRepository, userId uint, transport models.Transport, rentType string, rentTypeId uint, promoCode string) (models.Rent, error) {
+	op := "rentUsecase.startRent()"
 	if !transport.CanBeRented {
-		return entities.Rent{}, fmt.Errorf("transport can not be rented")
+		return models.Rent{}, entities.NewConflictError(entities.CodeTransportNotRentable, "transport can not be rented")
 	}
 
 	if userId == transport.OwnerId {
-		return entities.Rent{}, fmt.Errorf("you can not rent own transport")
+		return models.Rent{}, entities.NewForbiddenError(entities.CodeForbidden, "you can not rent own transport")
 	}
 
 	if transport.MinutePrice == 0 && transport.DayPrice == 0 {
-		return entities.Rent{}, fmt.Errorf("rental price for transport is not indicated")
+		return models.Rent{}, entities.NewConflictError(entities.CodeTransportNotRentable, "rental price for transport is not indicated")
 	}
 
 	var priceOfUnit float64
 	switch rentType {
 	case "Minutes":
 		if transport.MinutePrice == 0 {
-			return entities.Rent{}, fmt.Errorf("rental price per minute of transport is not indicated")
+			return models.Rent{}, entities.NewConflictError(entities.CodeTransportNotRentable, "rental price per minute of transport is not indicated")
 		}
 		priceOfUnit = transport.MinutePrice
 	case "Days":
 		if transport.DayPrice == 0 {
-			return entities.Rent{}, fmt.Errorf("rental price per day of transport is not indicated")
+			return models.Rent{}, entities.NewConflictError(entities.CodeTransportNotRentable, "rental price per day of transport is not indicated")
 		}
 		priceOfUnit = transport.DayPrice
 	}
 
+	tariff, err := findTariff(ctx, r, transport)
+	if err != nil {
+		return models.Rent{}, err
+	}
 	rent := models.Rent{
 		UserId:      userId,
-		TransportId: uint(transportId),
+		TransportId: transport.Id,
 		TimeStart:   time.Now(),
 		PriceOfUnit: priceOfUnit,
 		RentTypeId:  rentTypeId,
+		Tariff:      tariff,
+	}
+	if promoCode != "" {
+		promo, err := findPromoCode(ctx, r, userId, transport.TypeId, promoCode, rent.TimeStart)
+		if err != nil {
+			return models.Rent{}, err
+		}
+		rent.PromoCodeId = &promo.Id
+		rent.Tariff.Discount = &pricing.Discount{Kind: pricing.DiscountKind(promo.Kind), Value: promo.Value}
+	}
+	rent.Hold = ru.holdAmount(rent, rentType)
+	if err := checkFunds(ctx, r, userId, rent.Hold); err != nil {
+		return models.Rent{}, err
 	}
 	transport.CanBeRented = false
-	ru.r.SaveTransport(transport)
-	rent = ru.r.CreateRent(rent)
+	if err := r.SaveTransport(ctx, transport); err != nil {
+		return models.Rent{}, fmt.Errorf("%s: %w", op, err)
+	}
+	rent, err = r.CreateRent(ctx, rent)
+	if err != nil {
+		return models.Rent{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := placeHold(ctx, r, rent); err != nil {
+		return models.Rent{}, err
+	}
+	return rent, nil
+}
 
-	fmt.Printf("%+v", rent)
-	fmt.Printf("%+v", dto.RentModelToEntitie(rent, rentType))
-	return dto.RentModelToEntitie(rent, rentType), nil
+func (ru RentUsecase) UserEndRent(ctx context.Context, userId uint, rentId int, lat, long float64) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.UserEndRent")
+	defer span.End()
+	return ru.endRent(ctx, rentId, lat, long, func(rent models.Rent) bool {
+		return rent.UserId == userId
+	})
 }
 
-func (ru RentUsecase) UserEndRent(userId uint, rentId int, lat, long float64) (entities.Rent, error) {
-	rentModel := ru.r.FindRentById(rentId)
-	if rentModel.Id == 0 || rentModel.UserId != userId {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+// admin's usecase
+func (ru RentUsecase) AdminGetRent(ctx context.Context, id int) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminGetRent")
+	defer span.End()
+	rent, err := ru.findRent(ctx, id)
+	if err != nil {
+		return entities.Rent{}, err
 	}
 
-	transport := ru.r.FindTranspot(rentModel.TransportId)
-
-	transport.CanBeRented = true
-	transport.Latitude = lat
-	transport.Longitude = long
-
-	t := time.Now()
-	rentModel.TimeEnd = &t
-
-	rentType := ru.r.FindRentTypeById(rentModel.RentTypeId)
-	switch rentType {
-	case "Minutes":
-		rentModel.FinalPrice = ru.calculateRentPrice(rentModel.TimeStart.Unix(),
-			rentModel.TimeEnd.Unix(), minuteUnix, rentModel.PriceOfUnit)
-	case "Days":
-		rentModel.FinalPrice = ru.calculateRentPrice(rentModel.TimeStart.Unix(),
-			rentModel.TimeEnd.Unix(), dayUnix, rentModel.PriceOfUnit)
-	}
+	return ru.rentToEntitie(ctx, rent)
+}
 
-	user := ru.r.FindUserById(rentModel.UserId)
-	if rentModel.FinalPrice > user.Balance {
-		return entities.Rent{}, fmt.Errorf("not enough money in user's balance")
+func (ru RentUsecase) AdminGetUserHistory(ctx context.Context, userId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminGetUserHistory")
+	defer span.End()
+	if err := ru.checkUser(ctx, uint(userId)); err != nil {
+		return nil, pagination.Meta{}, err
 	}
-	user.Balance -= rentModel.FinalPrice
 
-	ru.r.SaveUser(user)
-	ru.r.SaveTransport(transport)
-	ru.r.SaveRent(rentModel)
-
-	return dto.RentModelToEntitie(rentModel, rentType), nil
+	return ru.GetUserHistory(ctx, uint(userId), filter, page)
 }
 
-// admin's usecase
-func (ru RentUsecase) AdminGetRent(id int) (entities.Rent, error) {
-	rent := ru.r.FindRentById(id)
-	if rent.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+func (ru RentUsecase) AdminGetTransportHistory(ctx context.Context, transportId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminGetTransportHistory")
+	defer span.End()
+	if _, err := ru.findTransport(ctx, uint(transportId)); err != nil {
+		return nil, pagination.Meta{}, err
 	}
-	rentType := ru.r.FindRentTypeById(rent.RentTypeId)
 
-	return dto.RentModelToEntitie(rent, rentType), nil
+	return ru.transportHistory(ctx, transportId, filter, page)
 }
 
-func (ru RentUsecase) AdminGetUserHistory(userId int) ([]entities.Rent, error) {
-	user := ru.r.FindUserById(uint(userId))
-	if user.Id == 0 {
-		return nil, fmt.Errorf("user is not exist")
+func (ru RentUsecase) transportHistory(ctx context.Context, transportId int, filter entities.RentFilter, page pagination.Request) ([]entities.Rent, pagination.Meta, error) {
+	op := "rentUsecase.transportHistory()"
+	if err := ru.checkRentFilter(ctx, filter); err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	rentModels, meta, err := ru.r.FindTransportRents(ctx, transportId, filter, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
 	}
-	rentEntites := ru.GetUserHistory(uint(userId))
 
-	return rentEntites, nil
+	rents, err := ru.rentsToEntities(ctx, rentModels)
+	return rents, meta, err
 }
 
-func (ru RentUsecase) AdminGetTransportHistory(transportId int) ([]entities.Rent, error) {
-	transport := ru.r.FindTranspot(uint(transportId))
+// AdminCreateRent creates running or ended rent. Running rent takes the transport and holds money
+// like rent started by the user, ended rent is charged from the wallet like rent ended by the user.
+func (ru RentUsecase) AdminCreateRent(ctx context.Context, rent entities.Rent) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminCreateRent")
+	defer span.End()
+	op := "rentUsecase.AdminCreateRent()"
+	if err := ru.checkUser(ctx, rent.UserId); err != nil {
+		return entities.Rent{}, err
+	}
 
-	if transport.Id == 0 {
-		return nil, fmt.Errorf("user is not exist")
+	rentTypeId, err := ru.r.FindRentTypeByName(ctx, rent.PriceType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.Rent{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid price type", entities.FieldError{Field: "priceType", Message: "must be Minutes or Days"})
+	}
+	if err != nil {
+		return entities.Rent{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	rentModels := ru.r.FindUserRents(transportId)
+	var rentModel models.Rent
+	err = ru.tx(ctx, func(r RentRepository) error {
+		transport, err := r.FindTranspotForUpdate(ctx, rent.TransportId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	rentEntites := make([]entities.Rent, 0, len(rentModels))
-	for _, rent := range rentModels {
-		rentType := ru.r.FindRentTypeById(rent.RentTypeId)
-		rentEntites = append(rentEntites, dto.RentModelToEntitie(rent, rentType))
+		rentModel = dto.RentEntitieToModel(rent, rentTypeId)
+		rentModel.Hold, rentModel.Debt = 0, 0
+		rentModel.Tariff, err = findTariff(ctx, r, transport)
+		if err != nil {
+			return err
+		}
+		if rentModel.TimeEnd == nil {
+			if !transport.CanBeRented {
+				return entities.NewConflictError(entities.CodeTransportNotRentable, "transport can not be rented")
+			}
+			rentModel.Hold = ru.holdAmount(rentModel, rent.PriceType)
+			if err := checkFunds(ctx, r, rentModel.UserId, rentModel.Hold); err != nil {
+				return err
+			}
+			transport.CanBeRented = false
+			if err := r.SaveTransport(ctx, transport); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		} else {
+			chargeRent(&rentModel, rent.PriceType)
+			if err := setDebt(ctx, r, &rentModel); err != nil {
+				return err
+			}
+		}
+
+		rentModel, err = r.CreateRent(ctx, rentModel)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if rentModel.TimeEnd != nil {
+			return postRentCharge(ctx, r, rentModel)
+		}
+		return placeHold(ctx, r, rentModel)
+	})
+	if err != nil {
+		return entities.Rent{}, err
 	}
 
-	return rentEntites, nil
+	if rentModel.TimeEnd == nil {
+		ru.rentStarted(ctx, rentModel, rent.PriceType)
+	}
+	return dto.RentModelToEntitie(rentModel, rent.PriceType), nil
 }
 
-func (ru RentUsecase) AdminCreateRent(rent entities.Rent) (entities.Rent, error) {
-	user := ru.r.FindUserById(rent.UserId)
-	if user.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("user is not exist")
-	}
+func (ru RentUsecase) AdminEndRent(ctx context.Context, id int, lat, long float64) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminEndRent")
+	defer span.End()
+	return ru.endRent(ctx, id, lat, long, func(rent models.Rent) bool {
+		return true
+	})
+}
 
-	transport := ru.r.FindTranspot(rent.TransportId)
-	if transport.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("transport is not exist")
-	}
+// AdminUpdateRent changes the rent and its ledger entries in one transaction.
+// Ending the running rent charges it like endRent, changing the ended rent
+// adjusts the charge by the difference of the prices. Ended rent can not be resumed.
+func (ru RentUsecase) AdminUpdateRent(ctx context.Context, rent entities.Rent) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminUpdateRent")
+	defer span.End()
+	op := "rentUsecase.AdminUpdateRent()"
+	rentTypeId, err := ru.r.FindRentTypeByName(ctx, rent.PriceType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.Rent{}, entities.NewValidationError(entities.CodeValidationFailed, "price type is not exist", entities.FieldError{Field: "priceType", Message: "must be Minutes or Days"})
+	}
+	if err != nil {
+		return entities.Rent{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var rentModel models.Rent
+	err = ru.tx(ctx, func(r RentRepository) error {
+		old, err := r.FindRentForUpdate(ctx, int(rent.Id))
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if old.TimeEnd != nil {
+			if rent.TimeEnd == nil {
+				return entities.NewConflictError(entities.CodeRentAlreadyEnded, "ended rent can not be resumed")
+			}
+			refunded, err := r.HasRentEntry(ctx, old.Id, entities.EntryRefund)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			if refunded {
+				return entities.NewConflictError(entities.CodeRentAlreadyRefunded, "refunded rent can not be changed")
+			}
+		}
 
-	if !transport.CanBeRented {
-		return entities.Rent{}, fmt.Errorf("transport can not be rented")
-	}
-	transport.CanBeRented = false
+		rentModel = old
+		rentModel.TransportId = rent.TransportId
+		rentModel.UserId = rent.UserId
+		rentModel.TimeStart = rent.TimeStart
+		rentModel.TimeEnd = rent.TimeEnd
+		rentModel.PriceOfUnit = rent.PriceOfUnit
+		rentModel.RentTypeId = rentTypeId
+
+		if old.TimeEnd == nil {
+			if err := moveRunningRent(ctx, r, old, rentModel); err != nil {
+				return err
+			}
+		}
+		if rentModel.TimeEnd != nil {
+			chargeRent(&rentModel, rent.PriceType)
+		}
+		if old.TimeEnd == nil && rentModel.TimeEnd != nil {
+			if err := setDebt(ctx, r, &rentModel); err != nil {
+				return err
+			}
+		}
 
-	if rent.TimeEnd != nil {
-		switch rent.PriceType {
-		case "Minutes":
-			rent.FinalPrice = ru.calculateRentPrice(rent.TimeStart.Unix(),
-				rent.TimeEnd.Unix(), minuteUnix, rent.PriceOfUnit)
-		case "Days":
-			rent.FinalPrice = ru.calculateRentPrice(rent.TimeStart.Unix(),
-				rent.TimeEnd.Unix(), dayUnix, rent.PriceOfUnit)
+		if err := r.SaveRent(ctx, rentModel); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		switch {
+		case old.TimeEnd == nil && rentModel.TimeEnd != nil:
+			return postRentCharge(ctx, r, rentModel)
+		case old.TimeEnd != nil:
+			return adjustRentCharge(ctx, r, old, rentModel)
 		}
+		return nil
+	})
+	if err != nil {
+		return entities.Rent{}, err
 	}
+	return dto.RentModelToEntitie(rentModel, rent.PriceType), nil
+}
 
-	rentTypeId := ru.r.FindRentTypeByName(rent.PriceType)
-	if rentTypeId == 0 {
-		return entities.Rent{}, fmt.Errorf("invalid price type")
+// moveRunningRent releases the transport of the running rent when it is ended or moved to another transport,
+// another transport is taken by the rent if it is still running. The hold follows the user of the rent.
+func moveRunningRent(ctx context.Context, r RentRepository, old, rent models.Rent) error {
+	op := "rentUsecase.moveRunningRent()"
+	if old.TransportId != rent.TransportId || rent.TimeEnd != nil {
+		transport, err := r.FindTranspotForUpdate(ctx, old.TransportId)
+		if err != nil && !errors.Is(err, entities.ErrNotFound) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err == nil {
+			transport.CanBeRented = true
+			if err := r.SaveTransport(ctx, transport); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+	if old.TransportId != rent.TransportId && rent.TimeEnd == nil {
+		transport, err := r.FindTranspotForUpdate(ctx, rent.TransportId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !transport.CanBeRented {
+			return entities.NewConflictError(entities.CodeTransportNotRentable, "transport can not be rented")
+		}
+		transport.CanBeRented = false
+		if err := r.SaveTransport(ctx, transport); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if old.UserId != rent.UserId && old.Hold > 0 {
+		if err := moveHold(ctx, r, old, rent.UserId); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	rentM := dto.RentEntitieToModel(rent, rentTypeId)
-	fmt.Printf("%+v\n", rentM)
+func (ru RentUsecase) AdminDeleteRent(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "rentUsecase.AdminDeleteRent")
+	defer span.End()
+	op := "rentUsecase.AdminDeleteRent()"
+	return ru.tx(ctx, func(r RentRepository) error {
+		rentModel, err := r.FindRentForUpdate(ctx, id)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		// hold of the running rent and its transport would stay locked
+		if rentModel.TimeEnd == nil {
+			return entities.NewConflictError(entities.CodeRentNotEnded, "running rent can not be deleted, end it first")
+		}
+		// journal entries are never deleted, they would point to the missing rent
+		posted, err := r.HasRentEntries(ctx, rentModel.Id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if posted {
+			return entities.NewConflictError(entities.CodeRentHasEntries, "rent with ledger entries can not be deleted, refund it instead")
+		}
+		if err := r.DeleteRent(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+}
 
-	rentModel := ru.r.CreateRent(dto.RentEntitieToModel(rent, rentTypeId))
-	ru.r.SaveTransport(transport)
-	rentEntite := dto.RentModelToEntitie(rentModel, rent.PriceType)
+// endRent closes the rent, posts the charge to the ledger and releases the transport in one transaction.
+// Balance of the user goes negative if it is not enough to pay the rent.
+// Rent, transport and user rows are locked in this order.
+func (ru RentUsecase) endRent(ctx context.Context, rentId int, lat, long float64, canEnd func(rent models.Rent) bool) (entities.Rent, error) {
+	op := "rentUsecase.endRent()"
+	var (
+		rentModel models.Rent
+		rentType  string
+	)
+	err := ru.tx(ctx, func(r RentRepository) error {
+		var err error
+		rentModel, err = r.FindRentForUpdate(ctx, rentId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !canEnd(rentModel) {
+			return entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
+		}
+		if rentModel.TimeEnd != nil {
+			return entities.NewConflictError(entities.CodeRentAlreadyEnded, "rent is already ended")
+		}
+
+		transport, err := r.FindTranspotForUpdate(ctx, rentModel.TransportId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		transport.CanBeRented = true
+		transport.Latitude = lat
+		transport.Longitude = long
+
+		t := time.Now()
+		rentModel.TimeEnd = &t
+
+		rentType, err = r.FindRentTypeById(ctx, rentModel.RentTypeId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		chargeRent(&rentModel, rentType)
+
+		if err := setDebt(ctx, r, &rentModel); err != nil {
+			return err
+		}
 
-	return rentEntite, nil
+		if err := r.SaveTransport(ctx, transport); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := r.SaveRent(ctx, rentModel); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return postRentCharge(ctx, r, rentModel)
+	})
+	if err != nil {
+		return entities.Rent{}, err
+	}
+
+	ru.m.RentEnded(rentType, rentModel.FinalPrice)
+	ru.log.InfoContext(ctx, "rent ended",
+		slog.Uint64("rent_id", uint64(rentModel.Id)),
+		slog.Uint64("user_id", uint64(rentModel.UserId)),
+		slog.Float64("final_price", rentModel.FinalPrice),
+		slog.Float64("debt", rentModel.Debt),
+	)
+	return dto.RentModelToEntitie(rentModel, rentType), nil
 }
 
-func (ru RentUsecase) AdminEndRent(id int, lat, long float64) (entities.Rent, error) {
-	rentModel := ru.r.FindRentById(id)
-	if rentModel.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+// setDebt locks the user of the ended rent and sets the part of the price
+// which is not paid by the hold and the balance as debt.
+// The rent is closed anyway, the balance goes negative by the debt.
+func setDebt(ctx context.Context, r RentRepository, rent *models.Rent) error {
+	op := "rentUsecase.setDebt()"
+	user, err := r.FindUserForUpdate(ctx, rent.UserId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
 	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rent.Debt = 0
+	if due := rent.FinalPrice - capturedHold(*rent); due > math.Max(user.Balance, 0) {
+		rent.Debt = roundMoney(due - math.Max(user.Balance, 0))
+	}
+	return nil
+}
 
-	transport := ru.r.FindTranspot(rentModel.TransportId)
-	transport.CanBeRented = true
-	transport.Latitude = lat
-	transport.Longitude = long
+// rentStarted records the started rent in logs and metrics
+func (ru RentUsecase) rentStarted(ctx context.Context, rent models.Rent, rentType string) {
+	ru.m.RentStarted(rentType)
+	ru.log.InfoContext(ctx, "rent started",
+		slog.Uint64("rent_id", uint64(rent.Id)),
+		slog.Uint64("user_id", uint64(rent.UserId)),
+		slog.Uint64("transport_id", uint64(rent.TransportId)),
+		slog.String("rent_type", rentType),
+		slog.Float64("hold", rent.Hold),
+	)
+}
 
-	t := time.Now()
-	rentModel.TimeEnd = &t
-	rentType := ru.r.FindRentTypeById(rentModel.RentTypeId)
-	switch rentType {
-	case "Minutes":
-		rentModel.FinalPrice = ru.calculateRentPrice(rentModel.TimeStart.Unix(),
-			rentModel.TimeEnd.Unix(), minuteUnix, rentModel.PriceOfUnit)
-	case "Days":
-		rentModel.FinalPrice = ru.calculateRentPrice(rentModel.TimeStart.Unix(),
-			rentModel.TimeEnd.Unix(), dayUnix, rentModel.PriceOfUnit)
+func (ru RentUsecase) findRent(ctx context.Context, id int) (models.Rent, error) {
+	op := "rentUsecase.findRent()"
+	rent, err := ru.r.FindRentById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.Rent{}, entities.NewNotFoundError(entities.CodeRentNotFound, "rent is not exist")
 	}
-
-	user := ru.r.FindUserById(rentModel.UserId)
-	if rentModel.FinalPrice > user.Balance {
-		return entities.Rent{}, fmt.Errorf("not enough money in user's balance")
+	if err != nil {
+		return models.Rent{}, fmt.Errorf("%s: %w", op, err)
 	}
+	return rent, nil
+}
 
-	user.Balance -= rentModel.FinalPrice
+func (ru RentUsecase) findRentType(ctx context.Context, rentType string) (uint, error) {
+	op := "rentUsecase.findRentType()"
+	rentTypeId, err := ru.r.FindRentTypeByName(ctx, rentType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return 0, entities.NewValidationError(entities.CodeValidationFailed, "type id is not exist", entities.FieldError{Field: "rentType", Message: "must be Minutes or Days"})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return rentTypeId, nil
+}
 
-	ru.r.SaveUser(user)
-	ru.r.SaveTransport(transport)
-	ru.r.SaveRent(rentModel)
+func (ru RentUsecase) findTransport(ctx context.Context, id uint) (models.Transport, error) {
+	op := "rentUsecase.findTransport()"
+	transport, err := ru.r.FindTranspot(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.Transport{}, entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+	}
+	if err != nil {
+		return models.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return transport, nil
+}
 
-	return dto.RentModelToEntitie(rentModel, rentType), nil
+func (ru RentUsecase) checkUser(ctx context.Context, id uint) error {
+	op := "rentUsecase.checkUser()"
+	_, err := ru.r.FindUserById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
 }
 
-func (ru RentUsecase) AdminUpdateRent(rent entities.Rent) (entities.Rent, error) {
-	rentModel := ru.r.FindRentById(int(rent.Id))
-	if rentModel.Id == 0 {
-		return entities.Rent{}, fmt.Errorf("rent is not exist")
+// checkRentFilter reports unknown transport type of the filter
+func (ru RentUsecase) checkRentFilter(ctx context.Context, filter entities.RentFilter) error {
+	op := "rentUsecase.checkRentFilter()"
+	if filter.TransportType == "" {
+		return nil
+	}
+	_, err := ru.r.FindTypeByName(ctx, filter.TransportType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewInvalidParamError("transportType", "must be one of Car, Bike, Scooter")
 	}
-	rentTypeId := ru.r.FindRentTypeByName(rent.PriceType)
-	if rentTypeId == 0 {
-		return entities.Rent{}, fmt.Errorf("price type is not exist")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (ru RentUsecase) rentToEntitie(ctx context.Context, rent models.Rent) (entities.Rent, error) {
+	op := "rentUsecase.rentToEntitie()"
+	rentType, err := ru.r.FindRentTypeById(ctx, rent.RentTypeId)
+	if err != nil {
+		return entities.Rent{}, fmt.Errorf("%s: %w", op, err)
 	}
-	rentModel.TransportId = rent.TransportId
-	rentModel.UserId = rent.UserId
-	rentModel.TimeStart = rent.TimeStart
-	rentModel.TimeEnd = rent.TimeEnd
-	rentModel.PriceOfUnit = rent.PriceOfUnit
-	rentModel.RentTypeId = rentTypeId
+	return dto.RentModelToEntitie(rent, rentType), nil
+}
 
-	if rentModel.TimeEnd != nil {
-		switch rent.PriceType {
-		case "Minutes":
-			rentModel.FinalPrice = ru.calculateRentPrice(rent.TimeStart.Unix(),
-				rent.TimeEnd.Unix(), minuteUnix, rent.PriceOfUnit)
-		case "Days":
-			rentModel.FinalPrice = ru.calculateRentPrice(rent.TimeStart.Unix(),
-				rent.TimeEnd.Unix(), dayUnix, rent.PriceOfUnit)
+func (ru RentUsecase) rentsToEntities(ctx context.Context, rentModels []models.Rent) ([]entities.Rent, error) {
+	rentEntites := make([]entities.Rent, 0, len(rentModels))
+	for _, rent := range rentModels {
+		rentEntite, err := ru.rentToEntitie(ctx, rent)
+		if err != nil {
+			return nil, err
 		}
+		rentEntites = append(rentEntites, rentEntite)
 	}
+	return rentEntites, nil
+}
 
-	ru.r.SaveRent(rentModel)
-	return dto.RentModelToEntitie(rentModel, rent.PriceType), nil
+// findTariff returns pricing policy of the transport or of its type with prices of the transport
+func findTariff(ctx context.Context, r RentRepository, transport models.Transport) (pricing.Tariff, error) {
+	op := "rentUsecase.findTariff()"
+	policy, err := r.FindTransportPricingPolicy(ctx, transport.Id, transport.TypeId)
+	if err != nil && !errors.Is(err, entities.ErrNotFound) {
+		return pricing.Tariff{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return pricing.Tariff{
+		Policy:      policy.Policy,
+		MinutePrice: transport.MinutePrice,
+		DayPrice:    transport.DayPrice,
+	}, nil
 }
 
-func (ru RentUsecase) AdminDeleteRent(id int) error {
-	rent := ru.r.FindRentById(id)
-	if rent.Id == 0 {
-		return fmt.Errorf("rent is not exist")
+// chargeRent sets the price of the ended rent and the discount of its promo code
+func chargeRent(rent *models.Rent, rentType string) {
+	price := rentPrice(*rent, rentType)
+	var discount float64
+	if rent.Tariff.Discount != nil {
+		discount = rent.Tariff.Discount.Apply(price)
 	}
-
-	ru.r.DeleteRent(id)
-	return nil
+	rent.OriginalPrice = price
+	rent.Discount = discount
+	rent.FinalPrice = roundMoney(price - discount)
 }
 
-func (ru RentUsecase) calculateRentPrice(startTime, endTime int64, timeUnit float64, priceOfUnit float64) float64 {
-	return math.Ceil((float64(endTime-startTime) / timeUnit)) * priceOfUnit
+// rentPrice calculates price of the ended rent by its tariff and price of unit.
+// Rents created before tariffs have empty tariff and are charged by price of unit only.
+func rentPrice(rent models.Rent, rentType string) float64 {
+	tariff := rent.Tariff
+	switch rentType {
+	case "Minutes":
+		tariff.MinutePrice = rent.PriceOfUnit
+		return tariff.Price(pricing.Minute, rent.TimeStart, *rent.TimeEnd)
+	case "Days":
+		tariff.DayPrice = rent.PriceOfUnit
+		return tariff.Price(pricing.Day, rent.TimeStart, *rent.TimeEnd)
+	}
+	return 0
 }
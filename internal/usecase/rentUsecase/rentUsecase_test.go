@@ -0,0 +1,1236 @@
+package rentUsecase
+
+import (
+	"context"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/geo"
+	"simbirGo/internal/logging"
+	"simbirGo/internal/pagination"
+	"simbirGo/internal/pricing"
+	"simbirGo/internal/tracing"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var transportTypes = map[uint]string{1: "Car", 2: "Bike", 3: "Scooter"}
+
+// fakeLocator returns found transports and remembers the search
+type fakeLocator struct {
+	found  []models.NearbyTransport
+	center geo.Point
+	radius float64
+	typeId uint
+}
+
+func (l *fakeLocator) FindTransportsNear(ctx context.Context, center geo.Point, radius float64, typeId uint) ([]models.NearbyTransport, error) {
+	l.center, l.radius, l.typeId = center, radius, typeId
+	return l.found, nil
+}
+
+// fakeMetrics remembers business events
+type fakeMetrics struct {
+	started  []string
+	charged  float64
+	refunded float64
+}
+
+func (m *fakeMetrics) RentStarted(rentType string) {
+	m.started = append(m.started, rentType)
+}
+
+func (m *fakeMetrics) RentEnded(rentType string, charged float64) {
+	m.charged += charged
+}
+
+func (m *fakeMetrics) RentRefunded(amount float64) {
+	m.refunded += amount
+}
+
+// fakeRepository keeps rows in memory. Transaction holds the lock for its whole
+// duration, like rows locked with SELECT ... FOR UPDATE in postgres.
+// Tests with it check that the usecase runs checks inside the transaction,
+// row locks and concurrent rents on postgres are checked by integration tests of the database package.
+type fakeRepository struct {
+	RentRepository
+
+	lock       *sync.Mutex
+	data       *sync.Mutex
+	transports map[uint]models.Transport
+	users      map[uint]models.User
+	rents      map[uint]models.Rent
+
+	reservations map[uint]models.Reservation
+	policies     map[uint]models.PricingPolicy
+	promoCodes   map[string]models.PromoCode
+
+	accounts map[string]models.LedgerAccount
+	entries  []models.JournalEntry
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		lock:       &sync.Mutex{},
+		data:       &sync.Mutex{},
+		transports: make(map[uint]models.Transport),
+		users:      make(map[uint]models.User),
+		rents:      make(map[uint]models.Rent),
+
+		reservations: make(map[uint]models.Reservation),
+		policies:     make(map[uint]models.PricingPolicy),
+		promoCodes:   make(map[string]models.PromoCode),
+
+		accounts: make(map[string]models.LedgerAccount),
+	}
+}
+
+func (f *fakeRepository) WithTx(ctx context.Context, fn func(r RentRepository) error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return fn(f)
+}
+
+func (f *fakeRepository) FindRentTypeByName(ctx context.Context, typeName string) (uint, error) {
+	switch typeName {
+	case "Minutes":
+		return 1, nil
+	case "Days":
+		return 2, nil
+	}
+	return 0, entities.ErrNotFound
+}
+
+func (f *fakeRepository) FindRentTypeById(ctx context.Context, id uint) (string, error) {
+	if id == 2 {
+		return "Days", nil
+	}
+	return "Minutes", nil
+}
+
+func (f *fakeRepository) FindTypeByName(ctx context.Context, typeName string) (uint, error) {
+	for id, name := range transportTypes {
+		if name == typeName {
+			return id, nil
+		}
+	}
+	return 0, entities.ErrNotFound
+}
+
+func (f *fakeRepository) FindTypeById(ctx context.Context, id uint) (string, error) {
+	name, ok := transportTypes[id]
+	if !ok {
+		return "", entities.ErrNotFound
+	}
+	return name, nil
+}
+
+func (f *fakeRepository) FindTranspotForUpdate(ctx context.Context, id uint) (models.Transport, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	transport, ok := f.transports[id]
+	if !ok {
+		return models.Transport{}, entities.ErrNotFound
+	}
+	return transport, nil
+}
+
+func (f *fakeRepository) FindUserForUpdate(ctx context.Context, id uint) (models.User, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return models.User{}, entities.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeRepository) FindRentForUpdate(ctx context.Context, id int) (models.Rent, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	rent, ok := f.rents[uint(id)]
+	if !ok {
+		return models.Rent{}, entities.ErrNotFound
+	}
+	return rent, nil
+}
+
+func (f *fakeRepository) DeleteRent(ctx context.Context, id int) error {
+	f.data.Lock()
+	defer f.data.Unlock()
+	if _, ok := f.rents[uint(id)]; !ok {
+		return entities.ErrNotFound
+	}
+	delete(f.rents, uint(id))
+	return nil
+}
+
+func (f *fakeRepository) FindTranspot(ctx context.Context, id uint) (models.Transport, error) {
+	return f.FindTranspotForUpdate(ctx, id)
+}
+
+func (f *fakeRepository) FindUserById(ctx context.Context, id uint) (models.User, error) {
+	return f.FindUserForUpdate(ctx, id)
+}
+
+// FindTransportRents returns all rents of the transport as one page
+func (f *fakeRepository) FindTransportRents(ctx context.Context, id int, filter entities.RentFilter, page pagination.Request) ([]models.Rent, pagination.Meta, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	var rents []models.Rent
+	for _, rent := range f.rents {
+		if rent.TransportId == uint(id) {
+			rents = append(rents, rent)
+		}
+	}
+	return rents, pagination.Meta{Total: int64(len(rents))}, nil
+}
+
+func (f *fakeRepository) FindRunningRentsWithHold(ctx context.Context) ([]models.Rent, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	var rents []models.Rent
+	for _, rent := range f.rents {
+		if rent.TimeEnd == nil && rent.Hold > 0 {
+			rents = append(rents, rent)
+		}
+	}
+	return rents, nil
+}
+
+func (f *fakeRepository) SaveTransport(ctx context.Context, transport models.Transport) error {
+	f.data.Lock()
+	defer f.data.Unlock()
+	f.transports[transport.Id] = transport
+	return nil
+}
+
+func (f *fakeRepository) account(code string, userId *uint) models.LedgerAccount {
+	account, ok := f.accounts[code]
+	if !ok {
+		account = models.LedgerAccount{Id: uint(len(f.accounts) + 1), Code: code, UserId: userId}
+		f.accounts[code] = account
+	}
+	return account
+}
+
+func (f *fakeRepository) FindWalletAccount(ctx context.Context, userId uint) (models.LedgerAccount, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	return f.account(fmt.Sprintf("wallet:%d", userId), &userId), nil
+}
+
+func (f *fakeRepository) FindSystemAccount(ctx context.Context, code string) (models.LedgerAccount, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	return f.account(code, nil), nil
+}
+
+func (f *fakeRepository) CreateJournalEntry(ctx context.Context, entry models.JournalEntry) (models.JournalEntry, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	entry.Id = uint(len(f.entries) + 1)
+	for _, posting := range entry.Postings {
+		for _, account := range f.accounts {
+			if account.Id != posting.AccountId || account.UserId == nil {
+				continue
+			}
+			user := f.users[*account.UserId]
+			user.Balance += posting.Amount
+			f.users[user.Id] = user
+		}
+	}
+	f.entries = append(f.entries, entry)
+	return entry, nil
+}
+
+func (f *fakeRepository) HasRentEntry(ctx context.Context, rentId uint, kind string) (bool, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	for _, entry := range f.entries {
+		if entry.RentId != nil && *entry.RentId == rentId && entry.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepository) HasRentEntries(ctx context.Context, rentId uint) (bool, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	for _, entry := range f.entries {
+		if entry.RentId != nil && *entry.RentId == rentId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// accountBalance sums postings of the account
+func (f *fakeRepository) accountBalance(code string) float64 {
+	var balance float64
+	for _, entry := range f.entries {
+		for _, posting := range entry.Postings {
+			if posting.AccountId == f.accounts[code].Id {
+				balance += posting.Amount
+			}
+		}
+	}
+	return balance
+}
+
+func (f *fakeRepository) SaveRent(ctx context.Context, rent models.Rent) error {
+	f.data.Lock()
+	defer f.data.Unlock()
+	f.rents[rent.Id] = rent
+	return nil
+}
+
+func (f *fakeRepository) CreateRent(ctx context.Context, rent models.Rent) (models.Rent, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	rent.Id = uint(len(f.rents) + 1)
+	f.rents[rent.Id] = rent
+	return rent, nil
+}
+
+// FindTransportPricingPolicy finds policies by transport id, type policies are kept with id of the type
+func (f *fakeRepository) FindTransportPricingPolicy(ctx context.Context, transportId, typeId uint) (models.PricingPolicy, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	for _, policy := range f.policies {
+		if policy.TransportId != nil && *policy.TransportId == transportId {
+			return policy, nil
+		}
+	}
+	for _, policy := range f.policies {
+		if policy.TransportTypeId != nil && *policy.TransportTypeId == typeId {
+			return policy, nil
+		}
+	}
+	return models.PricingPolicy{}, entities.ErrNotFound
+}
+
+func (f *fakeRepository) FindPromoCodeForUpdate(ctx context.Context, code string) (models.PromoCode, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	promoCode, ok := f.promoCodes[code]
+	if !ok {
+		return models.PromoCode{}, entities.ErrNotFound
+	}
+	return promoCode, nil
+}
+
+func (f *fakeRepository) CountPromoCodeUses(ctx context.Context, promoCodeId, userId uint) (int64, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	var uses int64
+	for _, rent := range f.rents {
+		if rent.PromoCodeId != nil && *rent.PromoCodeId == promoCodeId && (userId == 0 || rent.UserId == userId) {
+			uses++
+		}
+	}
+	return uses, nil
+}
+
+func (f *fakeRepository) FindReservationById(ctx context.Context, id uint) (models.Reservation, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	reservation, ok := f.reservations[id]
+	if !ok {
+		return models.Reservation{}, entities.ErrNotFound
+	}
+	return reservation, nil
+}
+
+func (f *fakeRepository) FindReservationForUpdate(ctx context.Context, id uint) (models.Reservation, error) {
+	return f.FindReservationById(ctx, id)
+}
+
+func (f *fakeRepository) FindActiveReservations(ctx context.Context, transportIds []uint, from, to time.Time) ([]models.Reservation, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	var found []models.Reservation
+	for _, reservation := range f.reservations {
+		for _, id := range transportIds {
+			if reservation.TransportId == id && reservation.Status == entities.ReservationActive &&
+				reservation.TimeStart.Before(to) && reservation.TimeEnd.After(from) {
+				found = append(found, reservation)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeRepository) CreateReservation(ctx context.Context, reservation models.Reservation) (models.Reservation, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	reservation.Id = uint(len(f.reservations) + 1)
+	f.reservations[reservation.Id] = reservation
+	return reservation, nil
+}
+
+func (f *fakeRepository) SaveReservation(ctx context.Context, reservation models.Reservation) error {
+	f.data.Lock()
+	defer f.data.Unlock()
+	f.reservations[reservation.Id] = reservation
+	return nil
+}
+
+func (f *fakeRepository) ExpireReservations(ctx context.Context, startedBefore time.Time) (int64, error) {
+	f.data.Lock()
+	defer f.data.Unlock()
+	var expired int64
+	for id, reservation := range f.reservations {
+		if reservation.Status == entities.ReservationActive && reservation.TimeStart.Before(startedBefore) {
+			reservation.Status = entities.ReservationExpired
+			f.reservations[id] = reservation
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func TestRentUsecase_NoDoubleBooking(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	const users = 50
+	for i := uint(1); i <= users; i++ {
+		repo.users[i] = models.User{Id: i, Balance: 1000}
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		succeed int
+	)
+	for i := 1; i <= users; i++ {
+		wg.Add(1)
+		go func(userId uint) {
+			defer wg.Done()
+			if _, err := ru.CreateNewRent(context.Background(), userId, 1, "Minutes", ""); err == nil {
+				mu.Lock()
+				succeed++
+				mu.Unlock()
+			}
+		}(uint(i))
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, succeed)
+	assert.Len(t, repo.rents, 1)
+	assert.False(t, repo.transports[1].CanBeRented)
+}
+
+func TestRentUsecase_EndRentOnce(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	started := repo.rents[rent.Id]
+	started.TimeStart = started.TimeStart.Add(-90 * time.Second)
+	repo.rents[rent.Id] = started
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		succeed int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1); err == nil {
+				mu.Lock()
+				succeed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, succeed)
+	assert.Equal(t, float64(980), repo.users[1].Balance)
+	assert.True(t, repo.transports[1].CanBeRented)
+}
+
+func TestRentUsecase_TariffSnapshot(t *testing.T) {
+	carType := uint(1)
+	transportId := uint(1)
+
+	testTable := []struct {
+		name          string
+		policies      []models.PricingPolicy
+		expectedPrice float64
+	}{
+		{
+			name:          "No policy",
+			expectedPrice: 20,
+		},
+		{
+			name: "Transport type policy",
+			policies: []models.PricingPolicy{
+				{Id: 1, TransportTypeId: &carType, Policy: pricing.Policy{UnlockFee: 50}},
+			},
+			expectedPrice: 70,
+		},
+		{
+			name: "Transport policy takes precedence",
+			policies: []models.PricingPolicy{
+				{Id: 1, TransportTypeId: &carType, Policy: pricing.Policy{UnlockFee: 50}},
+				{Id: 2, TransportId: &transportId, Policy: pricing.Policy{MinimumCharge: 100}},
+			},
+			expectedPrice: 100,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.transports[1] = models.Transport{Id: 1, TypeId: carType, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+			repo.users[1] = models.User{Id: 1, Balance: 1000}
+			for _, policy := range testCase.policies {
+				repo.policies[policy.Id] = policy
+			}
+			ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+			rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+			require.NoError(t, err)
+			started := repo.rents[rent.Id]
+			started.TimeStart = started.TimeStart.Add(-90 * time.Second)
+			repo.rents[rent.Id] = started
+
+			// changes of policies do not affect started rents
+			for id, policy := range repo.policies {
+				policy.Policy.UnlockFee = 500
+				repo.policies[id] = policy
+			}
+
+			ended, err := ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedPrice, ended.FinalPrice)
+			assert.Equal(t, 1000-testCase.expectedPrice, repo.users[1].Balance)
+		})
+	}
+}
+
+func TestRentUsecase_PromoCode(t *testing.T) {
+	now := time.Now()
+	bikeType := uint(2)
+	summer := models.PromoCode{
+		Id:        1,
+		Code:      "SUMMER",
+		Kind:      string(pricing.Percent),
+		Value:     10,
+		ValidFrom: now.Add(-time.Hour),
+		ValidTo:   now.Add(time.Hour),
+	}
+
+	testTable := []struct {
+		name             string
+		promoCode        func() models.PromoCode
+		code             string
+		previousUses     int
+		expectedErr      error
+		expectedDiscount float64
+	}{
+		{
+			name:             "Percent discount",
+			promoCode:        func() models.PromoCode { return summer },
+			code:             "summer",
+			expectedDiscount: 2,
+		},
+		{
+			name: "Fixed discount above the price",
+			promoCode: func() models.PromoCode {
+				p := summer
+				p.Kind, p.Value = string(pricing.Fixed), 100
+				return p
+			},
+			code:             "SUMMER",
+			expectedDiscount: 20,
+		},
+		{
+			name:        "Unknown code",
+			promoCode:   func() models.PromoCode { return summer },
+			code:        "WINTER",
+			expectedErr: entities.ErrNotFound,
+		},
+		{
+			name: "Expired",
+			promoCode: func() models.PromoCode {
+				p := summer
+				p.ValidTo = now.Add(-time.Minute)
+				return p
+			},
+			code:        "SUMMER",
+			expectedErr: entities.ErrConflict,
+		},
+		{
+			name: "Other transport type",
+			promoCode: func() models.PromoCode {
+				p := summer
+				p.TransportTypes = []models.PromoCodeTransportType{{PromoCodeId: 1, TransportTypeId: bikeType}}
+				return p
+			},
+			code:        "SUMMER",
+			expectedErr: entities.ErrConflict,
+		},
+		{
+			name: "Total limit",
+			promoCode: func() models.PromoCode {
+				p := summer
+				p.MaxUses = 2
+				return p
+			},
+			code:         "SUMMER",
+			previousUses: 2,
+			expectedErr:  entities.ErrConflict,
+		},
+		{
+			name: "Per user limit",
+			promoCode: func() models.PromoCode {
+				p := summer
+				p.MaxUses = 10
+				p.MaxUsesPerUser = 1
+				return p
+			},
+			code:         "SUMMER",
+			previousUses: 1,
+			expectedErr:  entities.ErrConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.transports[1] = models.Transport{Id: 1, TypeId: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+			repo.users[1] = models.User{Id: 1, Balance: 1000}
+			promoCode := testCase.promoCode()
+			repo.promoCodes[promoCode.Code] = promoCode
+			for i := 0; i < testCase.previousUses; i++ {
+				_, err := repo.CreateRent(context.Background(), models.Rent{UserId: 1, TransportId: 2, PromoCodeId: &promoCode.Id})
+				require.NoError(t, err)
+			}
+			ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+			rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", testCase.code)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				assert.True(t, repo.transports[1].CanBeRented)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, rent.PromoCodeId)
+			started := repo.rents[rent.Id]
+			started.TimeStart = started.TimeStart.Add(-90 * time.Second)
+			repo.rents[rent.Id] = started
+
+			ended, err := ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, float64(20), ended.OriginalPrice)
+			assert.Equal(t, testCase.expectedDiscount, ended.Discount)
+			assert.Equal(t, 20-testCase.expectedDiscount, ended.FinalPrice)
+			assert.Equal(t, 1000-ended.FinalPrice, repo.users[1].Balance)
+		})
+	}
+}
+
+func TestRentUsecase_LedgerEntries(t *testing.T) {
+	now := time.Now()
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, TypeId: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	repo.promoCodes["SUMMER"] = models.PromoCode{
+		Id: 1, Code: "SUMMER", Kind: string(pricing.Fixed), Value: 5,
+		ValidFrom: now.Add(-time.Hour), ValidTo: now.Add(time.Hour),
+	}
+	m := &fakeMetrics{}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), m, DefaultOptions())
+
+	rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "SUMMER")
+	require.NoError(t, err)
+	_, err = ru.AdminRefundRent(context.Background(), int(rent.Id))
+	assert.ErrorIs(t, err, entities.ErrConflict)
+
+	started := repo.rents[rent.Id]
+	started.TimeStart = started.TimeStart.Add(-90 * time.Second)
+	repo.rents[rent.Id] = started
+	_, err = ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+	require.NoError(t, err)
+
+	kinds := make([]string, len(repo.entries))
+	for i, entry := range repo.entries {
+		kinds[i] = entry.Kind
+	}
+	assert.Equal(t, []string{entities.EntryHold, entities.EntryHoldCapture, entities.EntryHoldRelease,
+		entities.EntryRentCharge, entities.EntryPromoCredit}, kinds)
+	assert.Equal(t, float64(985), repo.users[1].Balance)
+	assert.Zero(t, repo.accountBalance(entities.AccountHolds))
+	assert.Equal(t, float64(20), repo.accountBalance(entities.AccountRevenue))
+	assert.Equal(t, float64(-5), repo.accountBalance(entities.AccountPromo))
+	assert.Equal(t, []string{"Minutes"}, m.started)
+	assert.Equal(t, float64(15), m.charged)
+
+	refunded, err := ru.AdminRefundRent(context.Background(), int(rent.Id))
+	require.NoError(t, err)
+	assert.Equal(t, float64(15), refunded.FinalPrice)
+	assert.Equal(t, float64(1000), repo.users[1].Balance)
+	assert.Equal(t, float64(5), repo.accountBalance(entities.AccountRevenue))
+	assert.Equal(t, float64(15), m.refunded)
+
+	_, err = ru.AdminRefundRent(context.Background(), int(rent.Id))
+	assert.ErrorIs(t, err, entities.ErrConflict)
+	assert.Equal(t, float64(1000), repo.users[1].Balance)
+}
+
+func TestRentUsecase_AdminRentLedger(t *testing.T) {
+	now := time.Now()
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	repo.users[2] = models.User{Id: 2, Balance: 1000}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	// ended rent is charged and leaves the transport rentable
+	end := now.Add(-50 * time.Minute)
+	created, err := ru.AdminCreateRent(context.Background(), entities.Rent{
+		TransportId: 1, UserId: 1, TimeStart: now.Add(-time.Hour), TimeEnd: &end, PriceOfUnit: 10, PriceType: "Minutes",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), created.FinalPrice)
+	assert.Equal(t, float64(900), repo.users[1].Balance)
+	assert.Equal(t, float64(100), repo.accountBalance(entities.AccountRevenue))
+	assert.True(t, repo.transports[1].CanBeRented)
+
+	// longer rent is charged by the difference
+	end = now.Add(-40 * time.Minute)
+	created.TimeEnd = &end
+	updated, err := ru.AdminUpdateRent(context.Background(), created)
+	require.NoError(t, err)
+	assert.Equal(t, float64(200), updated.FinalPrice)
+	assert.Equal(t, float64(800), repo.users[1].Balance)
+	assert.Equal(t, float64(200), repo.accountBalance(entities.AccountRevenue))
+
+	// rent moved to another user is paid back to the previous one
+	updated.UserId = 2
+	_, err = ru.AdminUpdateRent(context.Background(), updated)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1000), repo.users[1].Balance)
+	assert.Equal(t, float64(800), repo.users[2].Balance)
+	assert.Equal(t, float64(200), repo.accountBalance(entities.AccountRevenue))
+
+	updated.TimeEnd = nil
+	_, err = ru.AdminUpdateRent(context.Background(), updated)
+	assert.ErrorIs(t, err, entities.ErrConflict)
+
+	// ending the running rent captures the hold like the user does
+	running, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	assert.Equal(t, float64(400), repo.users[1].Balance)
+	running.TimeStart = now.Add(-5*time.Minute - 30*time.Second)
+	end = now
+	running.TimeEnd = &end
+	ended, err := ru.AdminUpdateRent(context.Background(), running)
+	require.NoError(t, err)
+	assert.Equal(t, float64(60), ended.FinalPrice)
+	assert.Equal(t, float64(940), repo.users[1].Balance)
+	assert.Zero(t, repo.accountBalance(entities.AccountHolds))
+	assert.True(t, repo.transports[1].CanBeRented)
+
+	kinds := make([]string, len(repo.entries))
+	for i, entry := range repo.entries {
+		kinds[i] = entry.Kind
+	}
+	assert.Equal(t, []string{entities.EntryRentCharge, entities.EntryAdjustment, entities.EntryAdjustment, entities.EntryAdjustment,
+		entities.EntryHold, entities.EntryHoldCapture, entities.EntryHoldRelease}, kinds)
+}
+
+func TestRentUsecase_AdminCreateRentHold(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	repo.users[2] = models.User{Id: 2, Balance: 100}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	_, err := ru.AdminCreateRent(context.Background(), entities.Rent{
+		TransportId: 1, UserId: 2, TimeStart: time.Now(), PriceOfUnit: 10, PriceType: "Minutes",
+	})
+	assert.ErrorIs(t, err, entities.ErrInsufficientFunds)
+	assert.True(t, repo.transports[1].CanBeRented)
+
+	rent, err := ru.AdminCreateRent(context.Background(), entities.Rent{
+		TransportId: 1, UserId: 1, TimeStart: time.Now(), PriceOfUnit: 10, PriceType: "Minutes",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(600), rent.Hold)
+	assert.Equal(t, float64(400), repo.users[1].Balance)
+	assert.Equal(t, float64(600), repo.accountBalance(entities.AccountHolds))
+	assert.False(t, repo.transports[1].CanBeRented)
+
+	// the rent is checked by AutoEndRents like rents started by users
+	running, err := repo.FindRunningRentsWithHold(context.Background())
+	require.NoError(t, err)
+	require.Len(t, running, 1)
+	assert.Equal(t, rent.Id, running[0].Id)
+}
+
+func TestRentUsecase_AdminDeleteRent(t *testing.T) {
+	now := time.Now()
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	end := now.Add(-time.Hour)
+	repo.rents[1] = models.Rent{Id: 1, TransportId: 1, UserId: 1, TimeStart: now.Add(-2 * time.Hour), TimeEnd: &end}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	err := ru.AdminDeleteRent(context.Background(), 1)
+	require.NoError(t, err)
+	assert.NotContains(t, repo.rents, uint(1))
+
+	err = ru.AdminDeleteRent(context.Background(), 1)
+	assert.ErrorIs(t, err, entities.ErrNotFound)
+
+	rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	err = ru.AdminDeleteRent(context.Background(), int(rent.Id))
+	assert.ErrorIs(t, err, entities.ErrConflict)
+	assert.Equal(t, float64(400), repo.users[1].Balance)
+	assert.False(t, repo.transports[1].CanBeRented)
+
+	// the charge stays in the journal, so the rent can only be refunded
+	_, err = ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+	require.NoError(t, err)
+	err = ru.AdminDeleteRent(context.Background(), int(rent.Id))
+	assert.ErrorIs(t, err, entities.ErrConflict)
+	assert.Contains(t, repo.rents, rent.Id)
+}
+
+func TestRentUsecase_Hold(t *testing.T) {
+	testTable := []struct {
+		name         string
+		balance      float64
+		rentType     string
+		policy       pricing.Policy
+		expectedHold float64
+		expectedErr  error
+	}{
+		{
+			name:         "Price of hold period",
+			balance:      1000,
+			rentType:     "Minutes",
+			expectedHold: 600,
+		},
+		{
+			name:         "Policy hold",
+			balance:      1000,
+			rentType:     "Days",
+			policy:       pricing.Policy{MinutesHold: 100, DaysHold: 300},
+			expectedHold: 300,
+		},
+		{
+			name:        "Not enough money",
+			balance:     500,
+			rentType:    "Minutes",
+			expectedErr: entities.ErrInsufficientFunds,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			carType := uint(1)
+			repo := newFakeRepository()
+			repo.transports[1] = models.Transport{Id: 1, TypeId: carType, OwnerId: 100, CanBeRented: true, MinutePrice: 10, DayPrice: 1000}
+			repo.users[1] = models.User{Id: 1, Balance: testCase.balance}
+			repo.policies[1] = models.PricingPolicy{Id: 1, TransportTypeId: &carType, Policy: testCase.policy}
+			ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+			rent, err := ru.CreateNewRent(context.Background(), 1, 1, testCase.rentType, "")
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				assert.True(t, repo.transports[1].CanBeRented)
+				assert.Equal(t, testCase.balance, repo.users[1].Balance)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedHold, rent.Hold)
+			assert.Equal(t, testCase.balance-testCase.expectedHold, repo.users[1].Balance)
+			assert.Equal(t, testCase.expectedHold, repo.accountBalance(entities.AccountHolds))
+
+			ended, err := ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.balance-ended.FinalPrice, repo.users[1].Balance)
+			assert.Zero(t, repo.accountBalance(entities.AccountHolds))
+			assert.Equal(t, ended.FinalPrice, repo.accountBalance(entities.AccountRevenue))
+		})
+	}
+}
+
+func TestRentUsecase_AutoEndRents(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10, Latitude: 54.3, Longitude: 48.4}
+	repo.transports[2] = models.Transport{Id: 2, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 705}
+	repo.users[2] = models.User{Id: 2, Balance: 5000}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	short, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	rich, err := ru.CreateNewRent(context.Background(), 2, 2, "Minutes", "")
+	require.NoError(t, err)
+
+	// 70 started minutes cost 700, the next minute exceeds the hold with the rest of the balance
+	now := time.Now()
+	for _, id := range []uint{short.Id, rich.Id} {
+		rent := repo.rents[id]
+		rent.TimeStart = now.Add(-69*time.Minute - 30*time.Second)
+		repo.rents[id] = rent
+	}
+	ended, err := ru.AutoEndRents(context.Background(), now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, ended)
+
+	// rent of the missing user fails alone
+	repo.rents[100] = models.Rent{Id: 100, TransportId: 2, UserId: 99, TimeStart: now.Add(-time.Hour), PriceOfUnit: 10, RentTypeId: 1, Hold: 10}
+	ended, err = ru.AutoEndRents(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, ended)
+	require.NotNil(t, repo.rents[short.Id].TimeEnd)
+	assert.Nil(t, repo.rents[rich.Id].TimeEnd)
+	assert.True(t, repo.transports[1].CanBeRented)
+	assert.Equal(t, 54.3, repo.transports[1].Latitude)
+	assert.Equal(t, float64(5), repo.users[1].Balance)
+}
+
+func TestRentUsecase_Debt(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 650}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	started := repo.rents[rent.Id]
+	started.TimeStart = started.TimeStart.Add(-79*time.Minute - 30*time.Second)
+	repo.rents[rent.Id] = started
+
+	// 80 minutes cost 800, the hold of 600 and the balance of 50 are not enough
+	ended, err := ru.UserEndRent(context.Background(), 1, int(rent.Id), 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, float64(800), ended.FinalPrice)
+	assert.Equal(t, float64(150), ended.Debt)
+	assert.Equal(t, float64(-150), repo.users[1].Balance)
+	assert.True(t, repo.transports[1].CanBeRented)
+
+	_, err = ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	assert.ErrorIs(t, err, entities.ErrInsufficientFunds)
+	_, err = ru.CreateReservation(context.Background(), 1, 1, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	assert.ErrorIs(t, err, entities.ErrInsufficientFunds)
+
+	// top-up pays the debt
+	user := repo.users[1]
+	user.Balance += 1000
+	repo.users[1] = user
+	_, err = ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	assert.NoError(t, err)
+}
+
+func TestRentUsecase_GetAvalibleTransport(t *testing.T) {
+	locator := &fakeLocator{found: []models.NearbyTransport{
+		{Transport: models.Transport{Id: 2, TypeId: 1, CanBeRented: true, Latitude: 54.3190, Longitude: 48.3978}, Distance: 33.4},
+		{Transport: models.Transport{Id: 1, TypeId: 1, CanBeRented: true, Latitude: 54.3200, Longitude: 48.3978}, Distance: 144.6},
+	}}
+	ru := New(newFakeRepository(), nil, locator, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	transports, err := ru.GetAvalibleTransport(context.Background(), 54.3187, 48.3978, 500, "Car")
+	require.NoError(t, err)
+	assert.Equal(t, geo.Point{Lat: 54.3187, Long: 48.3978}, locator.center)
+	assert.Equal(t, 500.0, locator.radius)
+	assert.Equal(t, uint(1), locator.typeId)
+	require.Len(t, transports, 2)
+	assert.Equal(t, uint(2), transports[0].Id)
+	assert.Equal(t, "Car", transports[0].TransportType)
+	assert.Equal(t, 33.4, transports[0].Distance)
+	assert.Equal(t, uint(1), transports[1].Id)
+
+	_, err = ru.GetAvalibleTransport(context.Background(), 54.3187, 48.3978, 500, "All")
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), locator.typeId)
+
+	_, err = ru.GetAvalibleTransport(context.Background(), 54.3187, 48.3978, 500, "Plane")
+	assert.ErrorIs(t, err, entities.ErrValidation)
+}
+
+func TestRentUsecase_CreateReservation(t *testing.T) {
+	start := time.Now().Add(2 * time.Hour).Truncate(time.Minute)
+
+	testTable := []struct {
+		name        string
+		existing    []models.Reservation
+		timeStart   time.Time
+		timeEnd     time.Time
+		expectedErr error
+	}{
+		{
+			name:      "OK",
+			timeStart: start,
+			timeEnd:   start.Add(time.Hour),
+		},
+		{
+			name:      "Adjacent reservation",
+			existing:  []models.Reservation{{UserId: 2, TransportId: 1, TimeStart: start.Add(time.Hour), TimeEnd: start.Add(2 * time.Hour), Status: entities.ReservationActive}},
+			timeStart: start,
+			timeEnd:   start.Add(time.Hour),
+		},
+		{
+			name:        "Overlapping reservation",
+			existing:    []models.Reservation{{UserId: 2, TransportId: 1, TimeStart: start.Add(30 * time.Minute), TimeEnd: start.Add(2 * time.Hour), Status: entities.ReservationActive}},
+			timeStart:   start,
+			timeEnd:     start.Add(time.Hour),
+			expectedErr: entities.ErrConflict,
+		},
+		{
+			name:      "Overlapping cancelled reservation",
+			existing:  []models.Reservation{{UserId: 2, TransportId: 1, TimeStart: start, TimeEnd: start.Add(time.Hour), Status: entities.ReservationCancelled}},
+			timeStart: start,
+			timeEnd:   start.Add(time.Hour),
+		},
+		{
+			name:      "Overlapping expired reservation",
+			existing:  []models.Reservation{{UserId: 2, TransportId: 1, TimeStart: time.Now().Add(-time.Hour), TimeEnd: start.Add(time.Hour), Status: entities.ReservationActive}},
+			timeStart: start,
+			timeEnd:   start.Add(time.Hour),
+		},
+		{
+			name:        "Start in the past",
+			timeStart:   time.Now().Add(-time.Hour),
+			timeEnd:     start,
+			expectedErr: entities.ErrValidation,
+		},
+		{
+			name:        "End before start",
+			timeStart:   start,
+			timeEnd:     start.Add(-time.Minute),
+			expectedErr: entities.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+			repo.users[1] = models.User{Id: 1}
+			for _, reservation := range testCase.existing {
+				_, err := repo.CreateReservation(context.Background(), reservation)
+				require.NoError(t, err)
+			}
+			ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+			reservation, err := ru.CreateReservation(context.Background(), 1, 1, testCase.timeStart, testCase.timeEnd)
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, entities.ReservationActive, reservation.Status)
+			assert.Equal(t, uint(1), reservation.UserId)
+		})
+	}
+}
+
+// gracePeriod is the reservation grace period of DefaultOptions
+var gracePeriod = DefaultOptions().ReservationGracePeriod
+
+func TestRentUsecase_StartReservation(t *testing.T) {
+	now := time.Now()
+
+	testTable := []struct {
+		name        string
+		timeStart   time.Time
+		expectedErr error
+	}{
+		{
+			name:      "Within grace period before start",
+			timeStart: now.Add(gracePeriod / 2),
+		},
+		{
+			name:      "Within grace period after start",
+			timeStart: now.Add(-gracePeriod / 2),
+		},
+		{
+			name:        "Not begun",
+			timeStart:   now.Add(2 * gracePeriod),
+			expectedErr: entities.ErrConflict,
+		},
+		{
+			name:        "Expired",
+			timeStart:   now.Add(-2 * gracePeriod),
+			expectedErr: entities.ErrConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			repo.transports[1] = models.Transport{Id: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+			repo.users[1] = models.User{Id: 1, Balance: 1000}
+			reservation, err := repo.CreateReservation(context.Background(), models.Reservation{
+				UserId:      1,
+				TransportId: 1,
+				TimeStart:   testCase.timeStart,
+				TimeEnd:     testCase.timeStart.Add(time.Hour),
+				Status:      entities.ReservationActive,
+			})
+			require.NoError(t, err)
+			ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+			// the transport is held for the reservation
+			_, err = ru.CreateNewRent(context.Background(), 2, 1, "Minutes", "")
+			if testCase.expectedErr == nil {
+				assert.ErrorIs(t, err, entities.ErrConflict)
+			}
+
+			rent, err := ru.StartReservation(context.Background(), 1, reservation.Id, "Minutes", "")
+			if testCase.expectedErr != nil {
+				assert.ErrorIs(t, err, testCase.expectedErr)
+				assert.Equal(t, entities.ReservationActive, repo.reservations[reservation.Id].Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint(1), rent.UserId)
+			assert.False(t, repo.transports[1].CanBeRented)
+			converted := repo.reservations[reservation.Id]
+			assert.Equal(t, entities.ReservationConverted, converted.Status)
+			require.NotNil(t, converted.RentId)
+			assert.Equal(t, rent.Id, *converted.RentId)
+		})
+	}
+}
+
+func TestRentUsecase_ExpireReservations(t *testing.T) {
+	now := time.Now()
+	repo := newFakeRepository()
+	missed, err := repo.CreateReservation(context.Background(), models.Reservation{
+		UserId:      1,
+		TransportId: 1,
+		TimeStart:   now.Add(-2 * gracePeriod),
+		TimeEnd:     now.Add(time.Hour),
+		Status:      entities.ReservationActive,
+	})
+	require.NoError(t, err)
+	upcoming, err := repo.CreateReservation(context.Background(), models.Reservation{
+		UserId:      1,
+		TransportId: 2,
+		TimeStart:   now.Add(time.Hour),
+		TimeEnd:     now.Add(2 * time.Hour),
+		Status:      entities.ReservationActive,
+	})
+	require.NoError(t, err)
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	// reads show the missed reservation as expired without writing it
+	reservation, err := ru.GetReservation(context.Background(), 1, missed.Id)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReservationExpired, reservation.Status)
+	assert.Equal(t, entities.ReservationActive, repo.reservations[missed.Id].Status)
+
+	expired, err := ru.ExpireReservations(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, entities.ReservationExpired, repo.reservations[missed.Id].Status)
+	assert.Equal(t, entities.ReservationActive, repo.reservations[upcoming.Id].Status)
+}
+
+func TestRentUsecase_ReservedTransportIsNotAvailable(t *testing.T) {
+	locator := &fakeLocator{found: []models.NearbyTransport{
+		{Transport: models.Transport{Id: 1, TypeId: 1, CanBeRented: true}, Distance: 10},
+		{Transport: models.Transport{Id: 2, TypeId: 1, CanBeRented: true}, Distance: 20},
+	}}
+	repo := newFakeRepository()
+	_, err := repo.CreateReservation(context.Background(), models.Reservation{
+		UserId:      1,
+		TransportId: 1,
+		TimeStart:   time.Now().Add(gracePeriod / 2),
+		TimeEnd:     time.Now().Add(time.Hour),
+		Status:      entities.ReservationActive,
+	})
+	require.NoError(t, err)
+	ru := New(repo, repo.WithTx, locator, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	transports, err := ru.GetAvalibleTransport(context.Background(), 54.3187, 48.3978, 500, "All")
+	require.NoError(t, err)
+	require.Len(t, transports, 1)
+	assert.Equal(t, uint(2), transports[0].Id)
+}
+
+func TestRentUsecase_GetTransportHistory(t *testing.T) {
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, OwnerId: 1, TypeId: 1}
+	repo.rents[1] = models.Rent{Id: 1, TransportId: 1, UserId: 2, RentTypeId: 1}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+	page := pagination.Request{Limit: pagination.DefaultLimit, Sort: pagination.Sort{Field: "timeStart"}}
+
+	tests := []struct {
+		name      string
+		userId    int
+		filter    entities.RentFilter
+		wantTotal int64
+		wantErr   error
+	}{
+		{name: "owner", userId: 1, filter: entities.RentFilter{TransportType: "Car"}, wantTotal: 1},
+		{name: "not owner", userId: 2, wantErr: entities.ErrNotFound},
+		{name: "unknown transport type", userId: 1, filter: entities.RentFilter{TransportType: "Plane"}, wantErr: entities.ErrValidation},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			rents, meta, err := ru.GetTransportHistory(context.Background(), testCase.userId, 1, testCase.filter, page)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.wantTotal, meta.Total)
+			require.Len(t, rents, 1)
+			assert.Equal(t, "Minutes", rents[0].PriceType)
+		})
+	}
+}
+
+func TestRentUsecase_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(sdktrace.WithSyncer(exporter))
+	// tracers of usecases delegate to the global provider
+	otel.SetTracerProvider(tp)
+
+	repo := newFakeRepository()
+	repo.transports[1] = models.Transport{Id: 1, TypeId: 1, OwnerId: 100, CanBeRented: true, MinutePrice: 10}
+	repo.users[1] = models.User{Id: 1, Balance: 1000}
+	ru := New(repo, repo.WithTx, nil, logging.Discard(), &fakeMetrics{}, DefaultOptions())
+
+	rent, err := ru.CreateNewRent(context.Background(), 1, 1, "Minutes", "")
+	require.NoError(t, err)
+	exporter.Reset()
+
+	ctx, request := tp.Tracer("test").Start(context.Background(), "POST /api/Rent/End/:id")
+	_, err = ru.UserEndRent(ctx, 1, int(rent.Id), 1, 1)
+	request.End()
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "rentUsecase.UserEndRent", spans[0].Name)
+	assert.Equal(t, request.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+	assert.Equal(t, request.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
@@ -0,0 +1,312 @@
+package rentUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+	"time"
+)
+
+// clockSkew is allowed difference between client and server time
+const clockSkew = time.Minute
+
+func (ru RentUsecase) GetReservations(ctx context.Context, userId uint, page pagination.Request) ([]entities.Reservation, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetReservations")
+	defer span.End()
+	op := "rentUsecase.GetReservations()"
+	reservations, meta, err := ru.r.FindUserReservations(ctx, userId, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	reservationEntities := make([]entities.Reservation, 0, len(reservations))
+	for _, reservation := range reservations {
+		reservationEntities = append(reservationEntities, ru.reservationEntity(reservation, now))
+	}
+	return reservationEntities, meta, nil
+}
+
+func (ru RentUsecase) GetReservation(ctx context.Context, userId, id uint) (entities.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetReservation")
+	defer span.End()
+	op := "rentUsecase.GetReservation()"
+	reservation, err := ru.r.FindReservationById(ctx, id)
+	if err != nil && !errors.Is(err, entities.ErrNotFound) {
+		return entities.Reservation{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err != nil || reservation.UserId != userId {
+		return entities.Reservation{}, entities.NewNotFoundError(entities.CodeReservationNotFound, "reservation is not exist")
+	}
+	return ru.reservationEntity(reservation, time.Now()), nil
+}
+
+// ExpireReservations marks reservations which were not converted into rent in time as expired.
+// It is run periodically, so reads do not write and check isExpired instead.
+func (ru RentUsecase) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.ExpireReservations")
+	defer span.End()
+	op := "rentUsecase.ExpireReservations()"
+	expired, err := ru.r.ExpireReservations(ctx, now.Add(-ru.opts.ReservationGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return int(expired), nil
+}
+
+// CreateReservation reserves transport for [timeStart, timeEnd).
+// Reservations of the transport can not overlap.
+func (ru RentUsecase) CreateReservation(ctx context.Context, userId uint, transportId int, timeStart, timeEnd time.Time) (entities.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.CreateReservation")
+	defer span.End()
+	op := "rentUsecase.CreateReservation()"
+	now := time.Now()
+	if timeStart.Before(now.Add(-clockSkew)) {
+		return entities.Reservation{}, entities.NewValidationError(entities.CodeValidationFailed, "reservation can not start in the past",
+			entities.FieldError{Field: "timeStart", Message: "must not be in the past"})
+	}
+	if timeStart.After(now.Add(ru.opts.MaxReservationAdvance)) {
+		return entities.Reservation{}, entities.NewValidationError(entities.CodeValidationFailed, "reservation starts too late",
+			entities.FieldError{Field: "timeStart", Message: fmt.Sprintf("must be within %s from now", ru.opts.MaxReservationAdvance)})
+	}
+	if !timeEnd.After(timeStart) {
+		return entities.Reservation{}, entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value",
+			entities.FieldError{Field: "timeEnd", Message: "must be after start time"})
+	}
+	if timeEnd.Sub(timeStart) > ru.opts.MaxReservationDuration {
+		return entities.Reservation{}, entities.NewValidationError(entities.CodeValidationFailed, "reservation is too long",
+			entities.FieldError{Field: "timeEnd", Message: fmt.Sprintf("reservation must not be longer than %s", ru.opts.MaxReservationDuration)})
+	}
+
+	user, err := ru.r.FindUserById(ctx, userId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.Reservation{}, entities.NewNotFoundError(entities.CodeUserNotFound, "user is not exist")
+	}
+	if err != nil {
+		return entities.Reservation{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkDebt(user); err != nil {
+		return entities.Reservation{}, err
+	}
+
+	var reservation models.Reservation
+	err = ru.tx(ctx, func(r RentRepository) error {
+		transport, err := r.FindTranspotForUpdate(ctx, uint(transportId))
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if userId == transport.OwnerId {
+			return entities.NewForbiddenError(entities.CodeForbidden, "you can not reserve own transport")
+		}
+		if transport.MinutePrice == 0 && transport.DayPrice == 0 {
+			return entities.NewConflictError(entities.CodeTransportNotRentable, "rental price for transport is not indicated")
+		}
+		// transport is held from now on, so it must be free
+		if !transport.CanBeRented && timeStart.Add(-ru.opts.ReservationGracePeriod).Before(now) {
+			return entities.NewConflictError(entities.CodeTransportNotRentable, "transport can not be rented")
+		}
+
+		if _, err := r.ExpireReservations(ctx, now.Add(-ru.opts.ReservationGracePeriod)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		overlapping, err := r.FindActiveReservations(ctx, []uint{transport.Id}, timeStart, timeEnd)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if len(overlapping) > 0 {
+			return entities.NewConflictError(entities.CodeReservationOverlap, "transport is already reserved for this time")
+		}
+
+		reservation, err = r.CreateReservation(ctx, models.Reservation{
+			UserId:      userId,
+			TransportId: transport.Id,
+			TimeStart:   timeStart,
+			TimeEnd:     timeEnd,
+			Status:      entities.ReservationActive,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.Reservation{}, err
+	}
+
+	return dto.ReservationModelToEntitie(reservation), nil
+}
+
+func (ru RentUsecase) CancelReservation(ctx context.Context, userId, id uint) (entities.Reservation, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.CancelReservation")
+	defer span.End()
+	op := "rentUsecase.CancelReservation()"
+	var reservation models.Reservation
+	err := ru.tx(ctx, func(r RentRepository) error {
+		var err error
+		reservation, err = findUserReservation(ctx, r, userId, id)
+		if err != nil {
+			return err
+		}
+		if reservation.Status != entities.ReservationActive {
+			return entities.NewConflictError(entities.CodeReservationInactive, "reservation is not active")
+		}
+
+		reservation.Status = entities.ReservationCancelled
+		if err := r.SaveReservation(ctx, reservation); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.Reservation{}, err
+	}
+
+	return dto.ReservationModelToEntitie(reservation), nil
+}
+
+// StartReservation converts reservation into rent. It can be done
+// within grace period before or after the start of the reservation.
+func (ru RentUsecase) StartReservation(ctx context.Context, userId, id uint, rentType, promoCode string) (entities.Rent, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.StartReservation")
+	defer span.End()
+	op := "rentUsecase.StartReservation()"
+	rentTypeId, err := ru.findRentType(ctx, rentType)
+	if err != nil {
+		return entities.Rent{}, err
+	}
+
+	var rent models.Rent
+	err = ru.tx(ctx, func(r RentRepository) error {
+		reservation, err := findUserReservation(ctx, r, userId, id)
+		if err != nil {
+			return err
+		}
+		if reservation.Status != entities.ReservationActive {
+			return entities.NewConflictError(entities.CodeReservationInactive, "reservation is not active")
+		}
+		now := time.Now()
+		if now.Before(reservation.TimeStart.Add(-ru.opts.ReservationGracePeriod)) {
+			return entities.NewConflictError(entities.CodeReservationNotBegun, "reservation is not started yet")
+		}
+		if !now.Before(reservation.TimeStart.Add(ru.opts.ReservationGracePeriod)) || !now.Before(reservation.TimeEnd) {
+			return entities.NewConflictError(entities.CodeReservationExpired, "reservation is expired")
+		}
+
+		transport, err := r.FindTranspotForUpdate(ctx, reservation.TransportId)
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		rent, err = ru.startRent(ctx, r, userId, transport, rentType, rentTypeId, promoCode)
+		if err != nil {
+			return err
+		}
+
+		reservation.Status = entities.ReservationConverted
+		reservation.RentId = &rent.Id
+		if err := r.SaveReservation(ctx, reservation); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entities.Rent{}, err
+	}
+
+	ru.rentStarted(ctx, rent, rentType)
+	return dto.RentModelToEntitie(rent, rentType), nil
+}
+
+// GetTransportCalendar returns reserved windows of the transport intersecting with [from, to)
+func (ru RentUsecase) GetTransportCalendar(ctx context.Context, transportId int, from, to time.Time) ([]entities.ReservationSlot, error) {
+	ctx, span := tracer.Start(ctx, "rentUsecase.GetTransportCalendar")
+	defer span.End()
+	op := "rentUsecase.GetTransportCalendar()"
+	if !to.After(from) {
+		return nil, entities.NewValidationError(entities.CodeValidationFailed, "invalid end time value",
+			entities.FieldError{Field: "to", Message: "must be after from"})
+	}
+	if _, err := ru.findTransport(ctx, uint(transportId)); err != nil {
+		return nil, err
+	}
+
+	reservations, err := ru.r.FindActiveReservations(ctx, []uint{uint(transportId)}, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	now := time.Now()
+	slots := make([]entities.ReservationSlot, 0, len(reservations))
+	for _, reservation := range reservations {
+		if ru.isExpired(reservation, now) {
+			continue
+		}
+		slots = append(slots, entities.ReservationSlot{TimeStart: reservation.TimeStart, TimeEnd: reservation.TimeEnd})
+	}
+	return slots, nil
+}
+
+// checkReserved returns error if the transport is held for reservation of another user
+func (ru RentUsecase) checkReserved(ctx context.Context, r RentRepository, transportId, userId uint, now time.Time) error {
+	op := "rentUsecase.checkReserved()"
+	reserved, err := ru.reservedTransports(ctx, r, []uint{transportId}, now)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if holder, ok := reserved[transportId]; ok && holder != userId {
+		return entities.NewConflictError(entities.CodeTransportReserved, "transport is reserved")
+	}
+	return nil
+}
+
+// reservedTransports returns transports held for reservations at the moment
+// with id of the user who reserved them
+func (ru RentUsecase) reservedTransports(ctx context.Context, r RentRepository, transportIds []uint, now time.Time) (map[uint]uint, error) {
+	reserved := make(map[uint]uint)
+	if len(transportIds) == 0 {
+		return reserved, nil
+	}
+	reservations, err := r.FindActiveReservations(ctx, transportIds, now, now.Add(ru.opts.ReservationGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if !ru.isExpired(reservation, now) {
+			reserved[reservation.TransportId] = reservation.UserId
+		}
+	}
+	return reserved, nil
+}
+
+// isExpired reports whether active reservation was not converted into rent in time
+func (ru RentUsecase) isExpired(reservation models.Reservation, now time.Time) bool {
+	return !now.Before(reservation.TimeStart.Add(ru.opts.ReservationGracePeriod))
+}
+
+// reservationEntity shows reservation as expired before ExpireReservations marks it
+func (ru RentUsecase) reservationEntity(reservation models.Reservation, now time.Time) entities.Reservation {
+	if reservation.Status == entities.ReservationActive && ru.isExpired(reservation, now) {
+		reservation.Status = entities.ReservationExpired
+	}
+	return dto.ReservationModelToEntitie(reservation)
+}
+
+func findUserReservation(ctx context.Context, r RentRepository, userId, id uint) (models.Reservation, error) {
+	op := "rentUsecase.findUserReservation()"
+	reservation, err := r.FindReservationForUpdate(ctx, id)
+	if err != nil && !errors.Is(err, entities.ErrNotFound) {
+		return models.Reservation{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err != nil || reservation.UserId != userId {
+		return models.Reservation{}, entities.NewNotFoundError(entities.CodeReservationNotFound, "reservation is not exist")
+	}
+	return reservation, nil
+}
@@ -0,0 +1,266 @@
+package roleUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/dto"
+	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("simbirGo/internal/usecase/roleUsecase")
+
+//go:generate mockgen -source=roleUsecase.go -destination=mock/mock.go
+
+type RoleRepository interface {
+	FindRoles(ctx context.Context, page pagination.Request) ([]models.Role, pagination.Meta, error)
+	FindRoleById(ctx context.Context, id uint) (models.Role, error)
+	FindRoleByName(ctx context.Context, name string) (models.Role, error)
+	CreateRole(ctx context.Context, role models.Role) (models.Role, error)
+	SaveRole(ctx context.Context, role models.Role) error
+	DeleteRole(ctx context.Context, id uint) error
+	FindUserById(ctx context.Context, id uint) (models.User, error)
+	FindUserRoles(ctx context.Context, userId uint) ([]models.UserRole, error)
+	AssignRole(ctx context.Context, userRole models.UserRole) error
+	UnassignRole(ctx context.Context, userId, roleId uint) error
+}
+
+type RoleUsecase struct {
+	r RoleRepository
+}
+
+func New(r RoleRepository) RoleUsecase {
+	return RoleUsecase{r: r}
+}
+
+// Scope returns where user can use the permission.
+// Empty scope means the user has no such permission.
+func (ru RoleUsecase) Scope(ctx context.Context, userId uint, permission entities.Permission) (entities.Scope, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.Scope")
+	defer span.End()
+	op := "roleUsecase.Scope()"
+	userRoles, err := ru.r.FindUserRoles(ctx, userId)
+	if err != nil {
+		return entities.Scope{}, fmt.Errorf("%s: %w", op, err)
+	}
+	var scope entities.Scope
+	for _, userRole := range userRoles {
+		if !hasPermission(userRole.Role, permission) {
+			continue
+		}
+		if userRole.OperatorId == 0 {
+			return entities.Scope{All: true}, nil
+		}
+		scope.Operators = append(scope.Operators, userRole.OperatorId)
+	}
+	return scope, nil
+}
+
+func (ru RoleUsecase) GetPermissions() []entities.Permission {
+	return entities.Permissions
+}
+
+func (ru RoleUsecase) GetRoles(ctx context.Context, page pagination.Request) ([]entities.Role, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.GetRoles")
+	defer span.End()
+	op := "roleUsecase.GetRoles()"
+	roleModels, meta, err := ru.r.FindRoles(ctx, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	roles := make([]entities.Role, 0, len(roleModels))
+	for _, role := range roleModels {
+		roles = append(roles, dto.RoleModelToEntitie(role))
+	}
+	return roles, meta, nil
+}
+
+func (ru RoleUsecase) GetRole(ctx context.Context, id uint) (entities.Role, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.GetRole")
+	defer span.End()
+	role, err := ru.findRole(ctx, id)
+	if err != nil {
+		return entities.Role{}, err
+	}
+	return dto.RoleModelToEntitie(role), nil
+}
+
+func (ru RoleUsecase) CreateRole(ctx context.Context, role entities.Role) (entities.Role, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.CreateRole")
+	defer span.End()
+	op := "roleUsecase.CreateRole()"
+	if err := validatePermissions(role.Permissions); err != nil {
+		return entities.Role{}, err
+	}
+	if err := ru.checkRoleName(ctx, role.Name, 0); err != nil {
+		return entities.Role{}, err
+	}
+	roleModel, err := ru.r.CreateRole(ctx, dto.RoleEntitieToModel(role))
+	if err != nil {
+		return entities.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return dto.RoleModelToEntitie(roleModel), nil
+}
+
+func (ru RoleUsecase) UpdateRole(ctx context.Context, role entities.Role) (entities.Role, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.UpdateRole")
+	defer span.End()
+	op := "roleUsecase.UpdateRole()"
+	roleModel, err := ru.findRole(ctx, role.Id)
+	if err != nil {
+		return entities.Role{}, err
+	}
+	if roleModel.Name == entities.AdminRole {
+		return entities.Role{}, entities.NewConflictError(entities.CodeRoleImmutable, "admin role can not be changed")
+	}
+	if err := validatePermissions(role.Permissions); err != nil {
+		return entities.Role{}, err
+	}
+	if err := ru.checkRoleName(ctx, role.Name, role.Id); err != nil {
+		return entities.Role{}, err
+	}
+	roleModel = dto.RoleEntitieToModel(role)
+	if err := ru.r.SaveRole(ctx, roleModel); err != nil {
+		return entities.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return dto.RoleModelToEntitie(roleModel), nil
+}
+
+func (ru RoleUsecase) DeleteRole(ctx context.Context, id uint) error {
+	ctx, span := tracer.Start(ctx, "roleUsecase.DeleteRole")
+	defer span.End()
+	op := "roleUsecase.DeleteRole()"
+	role, err := ru.findRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.Name == entities.AdminRole {
+		return entities.NewConflictError(entities.CodeRoleImmutable, "admin role can not be deleted")
+	}
+	if err := ru.r.DeleteRole(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (ru RoleUsecase) GetUserRoles(ctx context.Context, userId uint) ([]entities.UserRole, error) {
+	ctx, span := tracer.Start(ctx, "roleUsecase.GetUserRoles")
+	defer span.End()
+	op := "roleUsecase.GetUserRoles()"
+	if err := ru.checkUser(ctx, userId, "user is not exist"); err != nil {
+		return nil, err
+	}
+	userRoleModels, err := ru.r.FindUserRoles(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	userRoles := make([]entities.UserRole, 0, len(userRoleModels))
+	for _, userRole := range userRoleModels {
+		userRoles = append(userRoles, dto.UserRoleModelToEntitie(userRole))
+	}
+	return userRoles, nil
+}
+
+func (ru RoleUsecase) AssignRole(ctx context.Context, userRole entities.UserRole) error {
+	ctx, span := tracer.Start(ctx, "roleUsecase.AssignRole")
+	defer span.End()
+	op := "roleUsecase.AssignRole()"
+	if err := ru.checkUser(ctx, userRole.UserId, "user is not exist"); err != nil {
+		return err
+	}
+	if _, err := ru.findRole(ctx, userRole.RoleId); err != nil {
+		return err
+	}
+	if userRole.OperatorId != 0 {
+		if err := ru.checkUser(ctx, userRole.OperatorId, "operator is not exist"); err != nil {
+			return err
+		}
+	}
+	err := ru.r.AssignRole(ctx, models.UserRole{
+		UserId:     userRole.UserId,
+		RoleId:     userRole.RoleId,
+		OperatorId: userRole.OperatorId,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (ru RoleUsecase) UnassignRole(ctx context.Context, userId, roleId uint) error {
+	ctx, span := tracer.Start(ctx, "roleUsecase.UnassignRole")
+	defer span.End()
+	op := "roleUsecase.UnassignRole()"
+	if err := ru.checkUser(ctx, userId, "user is not exist"); err != nil {
+		return err
+	}
+	if err := ru.r.UnassignRole(ctx, userId, roleId); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (ru RoleUsecase) findRole(ctx context.Context, id uint) (models.Role, error) {
+	op := "roleUsecase.findRole()"
+	role, err := ru.r.FindRoleById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.Role{}, entities.NewNotFoundError(entities.CodeRoleNotFound, "role is not exist")
+	}
+	if err != nil {
+		return models.Role{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return role, nil
+}
+
+// checkRoleName returns conflict error if name is taken by role other than roleId
+func (ru RoleUsecase) checkRoleName(ctx context.Context, name string, roleId uint) error {
+	op := "roleUsecase.checkRoleName()"
+	candidate, err := ru.r.FindRoleByName(ctx, name)
+	if errors.Is(err, entities.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if roleId == 0 {
+		return entities.NewConflictError(entities.CodeRoleNameTaken, "role is already exist")
+	}
+	if candidate.Id != roleId {
+		return entities.NewConflictError(entities.CodeRoleNameTaken, "role name is taken")
+	}
+	return nil
+}
+
+func (ru RoleUsecase) checkUser(ctx context.Context, id uint, notFoundMsg string) error {
+	op := "roleUsecase.checkUser()"
+	_, err := ru.r.FindUserById(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, notFoundMsg)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func hasPermission(role models.Role, permission entities.Permission) bool {
+	for _, p := range role.Permissions {
+		if p.Permission == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePermissions(permissions []entities.Permission) error {
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return entities.NewValidationError(entities.CodeValidationFailed, fmt.Sprintf("invalid permission: %s", permission), entities.FieldError{Field: "permissions", Message: "unknown permission " + string(permission)})
+		}
+	}
+	return nil
+}
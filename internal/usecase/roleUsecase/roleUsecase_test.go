@@ -0,0 +1,72 @@
+package roleUsecase
+
+import (
+	"context"
+	"simbirGo/internal/database/models"
+	"simbirGo/internal/entities"
+	mock_roleUsecase "simbirGo/internal/usecase/roleUsecase/mock"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleUsecase_Scope(t *testing.T) {
+	fleetManager := models.Role{
+		Id:          3,
+		Name:        "fleet_manager",
+		Permissions: []models.RolePermission{{RoleId: 3, Permission: string(entities.PermissionTransportsManage)}},
+	}
+	support := models.Role{
+		Id:          2,
+		Name:        "support",
+		Permissions: []models.RolePermission{{RoleId: 2, Permission: string(entities.PermissionRentsRead)}},
+	}
+
+	testTable := []struct {
+		name          string
+		userRoles     []models.UserRole
+		expectedScope entities.Scope
+	}{
+		{
+			name:          "No roles",
+			expectedScope: entities.Scope{},
+		},
+		{
+			name:          "Role without permission",
+			userRoles:     []models.UserRole{{UserId: 1, RoleId: 2, Role: support}},
+			expectedScope: entities.Scope{},
+		},
+		{
+			name: "Operator scoped roles",
+			userRoles: []models.UserRole{
+				{UserId: 1, RoleId: 3, Role: fleetManager, OperatorId: 10},
+				{UserId: 1, RoleId: 3, Role: fleetManager, OperatorId: 11},
+			},
+			expectedScope: entities.Scope{Operators: []uint{10, 11}},
+		},
+		{
+			name: "Global role wins",
+			userRoles: []models.UserRole{
+				{UserId: 1, RoleId: 3, Role: fleetManager, OperatorId: 10},
+				{UserId: 1, RoleId: 3, Role: fleetManager},
+			},
+			expectedScope: entities.Scope{All: true},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_roleUsecase.NewMockRoleRepository(c)
+			repo.EXPECT().FindUserRoles(gomock.Any(), uint(1)).Return(testCase.userRoles, nil)
+
+			scope, err := New(repo).Scope(context.Background(), 1, entities.PermissionTransportsManage)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedScope, scope)
+			assert.Equal(t, testCase.expectedScope.Allows(10), scope.Allows(10))
+		})
+	}
+}
@@ -1,23 +1,30 @@
 package transportusecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"simbirGo/internal/database/models"
 	"simbirGo/internal/dto"
 	"simbirGo/internal/entities"
+	"simbirGo/internal/pagination"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("simbirGo/internal/usecase/transportUsecase")
+
 type TransportRepository interface {
-	FindTypeById(id uint) string
-	FindTypeByName(typeName string) uint
-	FindTranspot(id uint) models.Transport
-	CreateTransport(transport models.Transport) models.Transport
-	FindUserTransport(userId, transportId uint) models.Transport
-	SaveTransport(transport models.Transport)
-	DeleteUserTransport(ownerId, transportId uint)
-	FindUserById(id uint) models.User
-	FindTranspots(start, count int, transportId uint) []models.Transport
-	DeleteTransport(id uint)
+	FindTypeById(ctx context.Context, id uint) (string, error)
+	FindTypeByName(ctx context.Context, typeName string) (uint, error)
+	FindTranspot(ctx context.Context, id uint) (models.Transport, error)
+	CreateTransport(ctx context.Context, transport models.Transport) (models.Transport, error)
+	FindUserTransport(ctx context.Context, userId, transportId uint) (models.Transport, error)
+	SaveTransport(ctx context.Context, transport models.Transport) error
+	DeleteUserTransport(ctx context.Context, ownerId, transportId uint) error
+	FindUserById(ctx context.Context, id uint) (models.User, error)
+	FindTranspots(ctx context.Context, typeId uint, ownerIds []uint, page pagination.Request) ([]models.Transport, pagination.Meta, error)
+	DeleteTransport(ctx context.Context, id uint) error
 }
 
 type TransportUsecase struct {
@@ -28,37 +35,55 @@ func New(r TransportRepository) TransportUsecase {
 	return TransportUsecase{r: r}
 }
 
-func (tu TransportUsecase) GetTransport(id uint) (entities.Transport, error) {
-	transportModel := tu.r.FindTranspot(id)
-	if transportModel.Id == 0 {
-		return entities.Transport{}, fmt.Errorf("transport is not exist")
+func (tu TransportUsecase) GetTransport(ctx context.Context, id uint) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.GetTransport")
+	defer span.End()
+	op := "transportUsecase.GetTransport()"
+	transportModel, err := tu.findTransport(ctx, id)
+	if err != nil {
+		return entities.Transport{}, err
 	}
 
-	typeStr := tu.r.FindTypeById(transportModel.TypeId)
+	typeStr, err := tu.r.FindTypeById(ctx, transportModel.TypeId)
+	if err != nil {
+		return entities.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
 	transportEntite := dto.TransportModelToEntite(transportModel, typeStr)
 	return transportEntite, nil
 }
 
-func (tu TransportUsecase) CreateTransport(transport entities.Transport) (entities.Transport, error) {
-	typeId := tu.r.FindTypeByName(transport.TransportType)
-	if typeId == 0 {
-		return entities.Transport{}, fmt.Errorf("invalid transport type")
+func (tu TransportUsecase) CreateTransport(ctx context.Context, transport entities.Transport) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.CreateTransport")
+	defer span.End()
+	op := "transportUsecase.CreateTransport()"
+	typeId, err := tu.findType(ctx, transport.TransportType)
+	if err != nil {
+		return entities.Transport{}, err
 	}
 	transportModel := dto.TransporEntitieToModel(transport, typeId)
-	transportModel = tu.r.CreateTransport(transportModel)
+	transportModel, err = tu.r.CreateTransport(ctx, transportModel)
+	if err != nil {
+		return entities.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
 	transportEntite := dto.TransportModelToEntite(transportModel, transport.TransportType)
 	return transportEntite, nil
 }
 
-func (tu TransportUsecase) UpdateUserTransport(transport entities.Transport) (entities.Transport, error) {
-	transportModel := tu.r.FindUserTransport(transport.OwnerId, transport.Id)
-	if transportModel.Id == 0 {
-		return entities.Transport{}, fmt.Errorf("transport is not exist")
+func (tu TransportUsecase) UpdateUserTransport(ctx context.Context, transport entities.Transport) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.UpdateUserTransport")
+	defer span.End()
+	op := "transportUsecase.UpdateUserTransport()"
+	transportModel, err := tu.r.FindUserTransport(ctx, transport.OwnerId, transport.Id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.Transport{}, entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+	}
+	if err != nil {
+		return entities.Transport{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	typeId := tu.r.FindTypeByName(transport.TransportType)
-	if typeId == 0 {
-		return entities.Transport{}, fmt.Errorf("invalid transport type")
+	typeId, err := tu.findType(ctx, transport.TransportType)
+	if err != nil {
+		return entities.Transport{}, err
 	}
 
 	transportModel.OwnerId = transport.OwnerId
@@ -73,58 +98,100 @@ func (tu TransportUsecase) UpdateUserTransport(transport entities.Transport) (en
 	transportModel.MinutePrice = transport.MinutePrice
 	transportModel.DayPrice = transport.DayPrice
 
-	tu.r.SaveTransport(transportModel)
+	if err := tu.r.SaveTransport(ctx, transportModel); err != nil {
+		return entities.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
 	transportEntite := dto.TransportModelToEntite(transportModel, transport.TransportType)
 	return transportEntite, nil
 }
 
-func (tu TransportUsecase) DeleteUserTransport(userId, transportId uint) error {
-	transport := tu.r.FindUserTransport(userId, transportId)
-	if transport.Id == 0 {
-		return fmt.Errorf("transport is not exist")
+func (tu TransportUsecase) DeleteUserTransport(ctx context.Context, userId, transportId uint) error {
+	ctx, span := tracer.Start(ctx, "transportUsecase.DeleteUserTransport")
+	defer span.End()
+	op := "transportUsecase.DeleteUserTransport()"
+	err := tu.r.DeleteUserTransport(ctx, userId, transportId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	tu.r.DeleteUserTransport(userId, transportId)
 	return nil
 }
 
-func (tu TransportUsecase) GetTransports(start, count int, transportType string) ([]entities.Transport, error) {
-	transportTypeId := tu.r.FindTypeByName(transportType)
-	if transportTypeId == 0 {
-		return nil, fmt.Errorf("invalid transport type: %s", transportType)
+func (tu TransportUsecase) GetTransports(ctx context.Context, transportType string, scope entities.Scope, page pagination.Request) ([]entities.Transport, pagination.Meta, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.GetTransports")
+	defer span.End()
+	op := "transportUsecase.GetTransports()"
+	transportTypeId, err := tu.r.FindTypeByName(ctx, transportType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return nil, pagination.Meta{}, entities.NewValidationError(entities.CodeValidationFailed, fmt.Sprintf("invalid transport type: %s", transportType), entities.FieldError{Field: "transportType", Message: "must be one of Car, Bike, Scooter, All"})
+	}
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	var ownerIds []uint
+	if !scope.All {
+		ownerIds = scope.Operators
+	}
+	transportModels, meta, err := tu.r.FindTranspots(ctx, transportTypeId, ownerIds, page)
+	if err != nil {
+		return nil, pagination.Meta{}, fmt.Errorf("%s: %w", op, err)
 	}
-	transportModels := tu.r.FindTranspots(start, count, transportTypeId)
 	transportEntites := make([]entities.Transport, 0, len(transportModels))
 	for _, tr := range transportModels {
 		transportEntites = append(transportEntites, dto.TransportModelToEntite(tr, transportType))
 	}
 
-	return transportEntites, nil
+	return transportEntites, meta, nil
+}
+
+func (tu TransportUsecase) AdminGetTransport(ctx context.Context, id uint, scope entities.Scope) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.AdminGetTransport")
+	defer span.End()
+	transport, err := tu.GetTransport(ctx, id)
+	if err != nil {
+		return entities.Transport{}, err
+	}
+	if !scope.Allows(transport.OwnerId) {
+		return entities.Transport{}, entities.ErrOutOfScope
+	}
+	return transport, nil
 }
 
-func (tu TransportUsecase) AdminCreateTransport(transport entities.Transport) (entities.Transport, error) {
+func (tu TransportUsecase) AdminCreateTransport(ctx context.Context, transport entities.Transport, scope entities.Scope) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.AdminCreateTransport")
+	defer span.End()
+	if !scope.Allows(transport.OwnerId) {
+		return entities.Transport{}, entities.ErrOutOfScope
+	}
 	//find user with ownerId
-	owner := tu.r.FindUserById(transport.OwnerId)
-	if owner.Id == 0 {
-		return entities.Transport{}, fmt.Errorf("user with id = %d is not exist", transport.OwnerId)
+	if err := tu.checkOwner(ctx, transport.OwnerId); err != nil {
+		return entities.Transport{}, err
 	}
 	//create
-	return tu.CreateTransport(transport)
+	return tu.CreateTransport(ctx, transport)
 }
 
-func (tu TransportUsecase) AdminUpdateTransport(transport entities.Transport) (entities.Transport, error) {
-	transportModel := tu.r.FindTranspot(transport.Id)
-	if transportModel.Id == 0 {
-		return entities.Transport{}, fmt.Errorf("transport is not exist")
+func (tu TransportUsecase) AdminUpdateTransport(ctx context.Context, transport entities.Transport, scope entities.Scope) (entities.Transport, error) {
+	ctx, span := tracer.Start(ctx, "transportUsecase.AdminUpdateTransport")
+	defer span.End()
+	op := "transportUsecase.AdminUpdateTransport()"
+	transportModel, err := tu.findTransport(ctx, transport.Id)
+	if err != nil {
+		return entities.Transport{}, err
+	}
+	if !scope.Allows(transportModel.OwnerId) || !scope.Allows(transport.OwnerId) {
+		return entities.Transport{}, entities.ErrOutOfScope
 	}
 
-	owner := tu.r.FindUserById(transport.OwnerId)
-	if owner.Id == 0 {
-		return entities.Transport{}, fmt.Errorf("user with id = %d is not exist", transport.OwnerId)
+	if err := tu.checkOwner(ctx, transport.OwnerId); err != nil {
+		return entities.Transport{}, err
 	}
 
-	typeId := tu.r.FindTypeByName(transport.TransportType)
-	if typeId == 0 {
-		return entities.Transport{}, fmt.Errorf("invalid transport type")
+	typeId, err := tu.findType(ctx, transport.TransportType)
+	if err != nil {
+		return entities.Transport{}, err
 	}
 
 	transportModel.OwnerId = transport.OwnerId
@@ -139,17 +206,63 @@ func (tu TransportUsecase) AdminUpdateTransport(transport entities.Transport) (e
 	transportModel.MinutePrice = transport.MinutePrice
 	transportModel.DayPrice = transport.DayPrice
 
-	tu.r.SaveTransport(transportModel)
+	if err := tu.r.SaveTransport(ctx, transportModel); err != nil {
+		return entities.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
 	transportEntite := dto.TransportModelToEntite(transportModel, transport.TransportType)
 
 	return transportEntite, nil
 }
 
-func (tu TransportUsecase) AdminDeleteTransport(id uint) error {
-	transport := tu.r.FindTranspot(id)
-	if transport.Id == 0 {
-		return fmt.Errorf("transport is not exist")
+func (tu TransportUsecase) AdminDeleteTransport(ctx context.Context, id uint, scope entities.Scope) error {
+	ctx, span := tracer.Start(ctx, "transportUsecase.AdminDeleteTransport")
+	defer span.End()
+	op := "transportUsecase.AdminDeleteTransport()"
+	transport, err := tu.findTransport(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !scope.Allows(transport.OwnerId) {
+		return entities.ErrOutOfScope
+	}
+	if err := tu.r.DeleteTransport(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (tu TransportUsecase) findTransport(ctx context.Context, id uint) (models.Transport, error) {
+	op := "transportUsecase.findTransport()"
+	transport, err := tu.r.FindTranspot(ctx, id)
+	if errors.Is(err, entities.ErrNotFound) {
+		return models.Transport{}, entities.NewNotFoundError(entities.CodeTransportNotFound, "transport is not exist")
+	}
+	if err != nil {
+		return models.Transport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return transport, nil
+}
+
+func (tu TransportUsecase) findType(ctx context.Context, transportType string) (uint, error) {
+	op := "transportUsecase.findType()"
+	typeId, err := tu.r.FindTypeByName(ctx, transportType)
+	if errors.Is(err, entities.ErrNotFound) {
+		return 0, entities.NewValidationError(entities.CodeValidationFailed, "invalid transport type", entities.FieldError{Field: "transportType", Message: "must be one of Car, Bike, Scooter"})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return typeId, nil
+}
+
+func (tu TransportUsecase) checkOwner(ctx context.Context, ownerId uint) error {
+	op := "transportUsecase.checkOwner()"
+	_, err := tu.r.FindUserById(ctx, ownerId)
+	if errors.Is(err, entities.ErrNotFound) {
+		return entities.NewNotFoundError(entities.CodeUserNotFound, fmt.Sprintf("user with id = %d is not exist", ownerId))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	tu.r.DeleteTransport(id)
 	return nil
 }